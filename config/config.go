@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func getEnv(key string) string {
@@ -11,6 +14,68 @@ func getEnv(key string) string {
 	return ""
 }
 
+// getDuration parses a duration such as "30s" or "5m", falling back when unset or invalid.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	if n, err := strconv.Atoi(getEnv(key)); err == nil {
+		return n
+	}
+	return fallback
+}
+
+// getIntList parses a comma separated list such as "7,3,1", falling back when unset or invalid.
+func getIntList(key string, fallback []int) []int {
+	val := getEnv(key)
+	if val == "" {
+		return fallback
+	}
+	var list []int
+	for _, part := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fallback
+		}
+		list = append(list, n)
+	}
+	return list
+}
+
+// getList parses a comma separated list such as "compliance,assignments", lower cased and
+// without empty items, falling back when unset.
+func getList(key string, fallback []string) []string {
+	val := getEnv(key)
+	if val == "" {
+		return fallback
+	}
+	var list []string
+	for _, part := range strings.Split(val, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+// getMap parses comma separated key=value pairs such as "reminder=smtp,escalation=webhook".
+// Keys and values are lower cased; malformed pairs are skipped.
+func getMap(key string) map[string]string {
+	m := map[string]string{}
+	for _, part := range strings.Split(getEnv(key), ",") {
+		k, v, ok := strings.Cut(part, "=")
+		k, v = strings.ToLower(strings.TrimSpace(k)), strings.ToLower(strings.TrimSpace(v))
+		if ok && k != "" && v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
 type PropertyConfig struct {
 	Database struct {
 		Host     string
@@ -25,6 +90,109 @@ type PropertyConfig struct {
 		GiteaToken    string
 		GiteaUsername string
 	}
+	Tags struct {
+		// StrictMode limits tagging to the curated vocabulary: unknown tags are rejected
+		// instead of being created on the fly.
+		StrictMode bool
+	}
+	Scheduler struct {
+		// Enabled runs the background jobs in this process. It is on unless SCHEDULER_ENABLED
+		// is "false". Every replica may run them; each run takes a Postgres advisory lock so
+		// only one replica does the work at a time. The jobs stop when the server shuts down.
+		Enabled bool
+		// AssessmentInterval is how often assessments are checked for opening and closing.
+		AssessmentInterval time.Duration
+		// RecertificationInterval is how often recurring assessments are checked for new
+		// cycles, lapsed certifications and expiry notices.
+		RecertificationInterval time.Duration
+		// AudienceInterval is how often audience rules are re-evaluated to assign users who
+		// started matching them.
+		AudienceInterval time.Duration
+		// ReminderInterval is how often due date reminders and escalations are checked.
+		ReminderInterval time.Duration
+		// OutboxInterval is how often the notification outbox is dispatched.
+		OutboxInterval time.Duration
+		// WebhookInterval is how often pending webhook deliveries are sent.
+		WebhookInterval time.Duration
+		// DigestInterval is how often digest subscriptions are checked for a digest due.
+		DigestInterval time.Duration
+		// ResultReleaseInterval is how often timed result releases are checked.
+		ResultReleaseInterval time.Duration
+	}
+	Outbox struct {
+		// A failed notification or webhook delivery is retried after BackoffBase, doubling up
+		// to BackoffMax, and dead-lettered after MaxAttempts attempts.
+		MaxAttempts int
+		BackoffBase time.Duration
+		BackoffMax  time.Duration
+		// BatchSize bounds the notifications delivered per dispatch.
+		BatchSize int
+		// ClaimTimeout is how long a dispatcher holds the notifications and deliveries it
+		// claimed. Ones still claimed after it, as when the instance stopped, are claimed again.
+		ClaimTimeout time.Duration
+	}
+	Notify struct {
+		// Channel delivers notifications: notify, smtp, webhook, file or inbox. ChannelByKind
+		// overrides it per notification kind, such as reminder=smtp.
+		Channel       string
+		ChannelByKind map[string]string
+		// TemplateCode is the notify server template the rendered notifications are sent
+		// with, unless their notification template names another.
+		TemplateCode int
+		// AssessmentLink is where notifications send users to take their assessments.
+		AssessmentLink string
+		// CertificateLink is where result notifications send users who passed to download their
+		// certificate.
+		CertificateLink string
+		// FilePath is where the file channel appends notifications, one JSON per line. Empty
+		// writes them to the log.
+		FilePath string
+		// MandatoryCategories are sent whatever the user's preferences.
+		MandatoryCategories []string
+	}
+	Webhooks struct {
+		// Timeout bounds one delivery request.
+		Timeout time.Duration
+	}
+	Inbox struct {
+		// StreamInterval is how often a notification stream checks the user's unread count.
+		StreamInterval time.Duration
+	}
+	SMTP struct {
+		Host     string
+		Port     string
+		Username string
+		Password string
+		From     string
+	}
+	NotifyWebhook struct {
+		// URL receives the notifications of the webhook channel, Token as a bearer token.
+		URL   string
+		Token string
+		// Timeout bounds one notification request.
+		Timeout time.Duration
+	}
+	Reminders struct {
+		// DaysBefore, OnDueDate, OverdueEveryDays and EscalateAfterDays are the reminder
+		// policy of assessments without one of their own.
+		DaysBefore        []int
+		OnDueDate         bool
+		OverdueEveryDays  int
+		EscalateAfterDays int
+		// No reminders go out from QuietHoursStart until QuietHoursEnd, in hours of the day
+		// in Timezone. Equal hours disable quiet hours.
+		QuietHoursStart int
+		QuietHoursEnd   int
+		Timezone        string
+	}
+	Digests struct {
+		// Digests go out from SendHour on, in hours of the day in the reminder timezone.
+		SendHour int
+		// UpcomingDays is how far ahead a digest lists the deadlines coming up.
+		UpcomingDays int
+		// MaxEntries bounds the entries listed per section; the counts still cover all.
+		MaxEntries int
+	}
 }
 
 var PropConfig *PropertyConfig = LoadConfigFromEnv()
@@ -42,5 +210,70 @@ func LoadConfigFromEnv() *PropertyConfig {
 	cfg.App.GiteaBaseURL = getEnv("GITEA_BASE_URL")
 	cfg.App.GiteaToken = getEnv("GITEA_TOKEN")
 	cfg.App.GiteaUsername = getEnv("GITEA_USERNAME")
+
+	cfg.Tags.StrictMode = strings.EqualFold(getEnv("TAG_STRICT_MODE"), "true")
+
+	cfg.Scheduler.Enabled = !strings.EqualFold(getEnv("SCHEDULER_ENABLED"), "false")
+	cfg.Scheduler.AssessmentInterval = getDuration("ASSESSMENT_SCHEDULER_INTERVAL", time.Minute)
+	cfg.Scheduler.RecertificationInterval = getDuration("RECERTIFICATION_SCHEDULER_INTERVAL", time.Hour)
+	cfg.Scheduler.AudienceInterval = getDuration("AUDIENCE_SCHEDULER_INTERVAL", 5*time.Minute)
+	cfg.Scheduler.ReminderInterval = getDuration("REMINDER_SCHEDULER_INTERVAL", 15*time.Minute)
+	cfg.Scheduler.OutboxInterval = getDuration("OUTBOX_DISPATCH_INTERVAL", 30*time.Second)
+	cfg.Scheduler.WebhookInterval = getDuration("WEBHOOK_DISPATCH_INTERVAL", 30*time.Second)
+	cfg.Scheduler.DigestInterval = getDuration("DIGEST_SCHEDULER_INTERVAL", time.Hour)
+	cfg.Scheduler.ResultReleaseInterval = getDuration("RESULT_RELEASE_SCHEDULER_INTERVAL", 5*time.Minute)
+
+	cfg.Outbox.MaxAttempts = getInt("OUTBOX_MAX_ATTEMPTS", 6)
+	cfg.Outbox.BackoffBase = getDuration("OUTBOX_BACKOFF_BASE", time.Minute)
+	cfg.Outbox.BackoffMax = getDuration("OUTBOX_BACKOFF_MAX", 6*time.Hour)
+	cfg.Outbox.BatchSize = getInt("OUTBOX_BATCH_SIZE", 100)
+	cfg.Outbox.ClaimTimeout = getDuration("OUTBOX_CLAIM_TIMEOUT", 15*time.Minute)
+
+	cfg.Notify.Channel = strings.ToLower(getEnv("NOTIFY_CHANNEL"))
+	if cfg.Notify.Channel == "" {
+		cfg.Notify.Channel = "notify"
+	}
+	// results and certificates are only announced in the app unless configured otherwise
+	cfg.Notify.ChannelByKind = map[string]string{"result": "inbox", "certificate": "inbox"}
+	for kind, channel := range getMap("NOTIFY_CHANNELS") {
+		cfg.Notify.ChannelByKind[kind] = channel
+	}
+	cfg.Notify.TemplateCode = getInt("NOTIFY_TEMPLATE_CODE", 13)
+	cfg.Notify.AssessmentLink = getEnv("ASSESSMENT_LINK")
+	if cfg.Notify.AssessmentLink == "" {
+		cfg.Notify.AssessmentLink = "https://dhl.catseye.cloud/"
+	}
+	cfg.Notify.CertificateLink = getEnv("CERTIFICATE_LINK")
+	if cfg.Notify.CertificateLink == "" {
+		cfg.Notify.CertificateLink = cfg.Notify.AssessmentLink
+	}
+	cfg.Notify.FilePath = getEnv("NOTIFY_FILE_PATH")
+	cfg.Notify.MandatoryCategories = getList("NOTIFY_MANDATORY_CATEGORIES", []string{"compliance"})
+
+	cfg.Inbox.StreamInterval = getDuration("INBOX_STREAM_INTERVAL", 5*time.Second)
+
+	cfg.Webhooks.Timeout = getDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+
+	cfg.SMTP.Host = getEnv("SMTP_HOST")
+	cfg.SMTP.Port = getEnv("SMTP_PORT")
+	cfg.SMTP.Username = getEnv("SMTP_USERNAME")
+	cfg.SMTP.Password = getEnv("SMTP_PASSWORD")
+	cfg.SMTP.From = getEnv("SMTP_FROM")
+
+	cfg.NotifyWebhook.URL = getEnv("NOTIFY_WEBHOOK_URL")
+	cfg.NotifyWebhook.Token = getEnv("NOTIFY_WEBHOOK_TOKEN")
+	cfg.NotifyWebhook.Timeout = getDuration("NOTIFY_WEBHOOK_TIMEOUT", 15*time.Second)
+
+	cfg.Reminders.DaysBefore = getIntList("REMINDER_DAYS_BEFORE", []int{7, 3, 1})
+	cfg.Reminders.OnDueDate = !strings.EqualFold(getEnv("REMINDER_ON_DUE_DATE"), "false")
+	cfg.Reminders.OverdueEveryDays = getInt("REMINDER_OVERDUE_EVERY_DAYS", 2)
+	cfg.Reminders.EscalateAfterDays = getInt("REMINDER_ESCALATE_AFTER_DAYS", 3)
+	cfg.Reminders.QuietHoursStart = getInt("REMINDER_QUIET_HOURS_START", 20)
+	cfg.Reminders.QuietHoursEnd = getInt("REMINDER_QUIET_HOURS_END", 8)
+	cfg.Reminders.Timezone = getEnv("REMINDER_TIMEZONE")
+
+	cfg.Digests.SendHour = getInt("DIGEST_SEND_HOUR", 7)
+	cfg.Digests.UpcomingDays = getInt("DIGEST_UPCOMING_DAYS", 7)
+	cfg.Digests.MaxEntries = getInt("DIGEST_MAX_ENTRIES", 50)
 	return cfg
 }
@@ -42,6 +42,7 @@ const (
 	Question                    = "/question"
 	Contact                     = "/contact"
 	ContactResponse             = "/contact-response"
+	ContactReply                = "/contact-response/:id/reply"
 	DistributeAssessmentUser    = "/distribute-assessment-user"
 	DistributeAssessmentManager = "/distribute-assessment-manger"
 	MapUsersToManager           = "/map-users-to-manger"
@@ -57,6 +58,67 @@ const (
 	AssessmentUserResult        = "/assessment-user-result"
 	AssessmentResultView        = "/assessment-result"
 	CheckAssessmentAssignment   = "/assessment/check-assignment"
+	GenerateJobAssessment       = "/generate-job-assessment"
+	ExportAssessment            = "/assessment/export"
+	Media                       = "/media"
+	MediaUpload                 = "/media/upload"
+	QuestionContent             = "/question/content"
+	AssessmentTranslation       = "/assessment/translation"
+	AssessmentLocales           = "/assessment/locales"
+	Blueprint                   = "/blueprint"
+	Blueprints                  = "/blueprints"
+	AssembleBlueprint           = "/blueprint/assemble"
+	QuestionDuplicates          = "/question/duplicates"
+	QuestionDuplicatesCheck     = "/question/duplicates/check"
+	ValidateImportAssessment    = "/import-assessment/validate"
+	ImportAssessmentTemplate    = "/import-assessment/template"
+	AssessmentTemplate          = "/assessment/template"
+	AssessmentTemplates         = "/assessment/templates"
+	AssessmentFromTemplate      = "/assessment/from-template"
+	AssessmentCloneCenters      = "/assessment/clone-centers"
+	QuestionBulkRetag           = "/question/bulk/retag"
+	QuestionBulkDifficulty      = "/question/bulk/difficulty"
+	QuestionBulkPoints          = "/question/bulk/points"
+	QuestionBulkStatus          = "/question/bulk/status"
+	QuestionBulkFindReplace     = "/question/bulk/find-replace"
+	QuestionEditHistory         = "/question/history"
+	Tags                        = "/tags"
+	Tag                         = "/tag"
+	TagUsage                    = "/tag/usage"
+	TagMerge                    = "/tag/merge"
+	TagMove                     = "/tag/move"
+	AssessmentSchedule          = "/assessment/schedule"
+	AssessmentRecurrence        = "/assessment/recurrence"
+	AssessmentCertifications    = "/assessment/certifications"
+	AudienceRule                = "/audience-rule"
+	AudienceRules               = "/audience-rules"
+	AudiencePreview             = "/audience-rule/preview"
+	AssessmentAudience          = "/assessment/audience"
+	AssessmentDueDate           = "/assessment/due-date"
+	AssessmentExtension         = "/assessment/extension"
+	AssessmentExtensions        = "/assessment/extensions"
+	AssessmentTeamProgress      = "/assessment/team-progress"
+	AssessmentDelegations       = "/assessment/delegations"
+	AssessmentReminderPolicy    = "/assessment/reminder-policy"
+	AssessmentReminders         = "/assessment/reminders"
+	NotificationOutbox          = "/notification-outbox"
+	NotificationOutboxReplay    = "/notification-outbox/replay"
+	NotificationTemplates       = "/notification-templates"
+	NotificationTemplate        = "/notification-template"
+	NotificationTemplatePreview = "/notification-template/preview"
+	Notifications               = "/notifications"
+	NotificationsUnread         = "/notifications/unread-count"
+	NotificationsRead           = "/notifications/read"
+	NotificationsReadAll        = "/notifications/read-all"
+	NotificationsStream         = "/notifications/stream"
+	Webhooks                    = "/webhooks"
+	Webhook                     = "/webhook"
+	WebhookDeliveries           = "/webhook-deliveries"
+	DigestSubscription          = "/digest-subscription"
+	DigestPreview               = "/digest/preview"
+	NotificationPreferences     = "/notification-preferences"
+	NotificationSuppressions    = "/notification-suppressions"
+	AssessmentResultRelease     = "/assessment/result-release"
 )
 
 type UserRole string
@@ -79,4 +141,240 @@ const (
 	Draft  AssessmentState = "draft"
 	Open   AssessmentState = "open"
 	Closed AssessmentState = "closed"
+	// Scheduled assessments are opened by the scheduler at their start time.
+	Scheduled AssessmentState = "scheduled"
+)
+
+// assessment_status values of a user's assignment.
+const (
+	AssignmentAssigned   = "ASSIGNED"
+	AssignmentReassigned = "REASSIGNED"
+	// AssignmentStarted is set when the user starts the assessment and kept until they submit.
+	AssignmentStarted   = "STARTED"
+	AssignmentCompleted = "COMPLETED"
+	// AssignmentExpired is set when the assessment closes before the user finished it.
+	AssignmentExpired = "EXPIRED"
+)
+
+// Hierarchies a manager distribution can cascade through: the user_manager_mapping table, or
+// the team_lead, manager or sdl column of the user's profile.
+const (
+	HierarchyMapping  = "mapping"
+	HierarchyTeamLead = "team_lead"
+	HierarchyManager  = "manager"
+	HierarchySDL      = "sdl"
+)
+
+// When the results of an assessment are shown to its users: on submission, once the
+// assessment closes, from a release date, or when an admin releases them. Until then users get
+// ResultPendingRelease instead of their result, and ResultHidden for assessments that never
+// show results.
+const (
+	ResultReleaseImmediate = "immediate"
+	ResultReleaseOnClose   = "on_close"
+	ResultReleaseOnDate    = "on_date"
+	ResultReleaseManual    = "manual"
+
+	ResultPendingRelease = "pending_release"
+	ResultHidden         = "hidden"
+)
+
+// Due date reminder events. Escalations go to the user's managers.
+const (
+	ReminderBeforeDue  = "before_due"
+	ReminderDueToday   = "due_today"
+	ReminderOverdue    = "overdue"
+	ReminderEscalation = "escalation"
+)
+
+// Kinds of notification in the outbox.
+const (
+	NotificationDistribution      = "distribution"
+	NotificationCertificateExpiry = "certificate_expiry"
+	NotificationReminder          = "reminder"
+	NotificationEscalation        = "escalation"
+	NotificationResult            = "result"
+	NotificationCertificate       = "certificate"
+	NotificationDigest            = "digest"
+	NotificationContactReply      = "contact_reply"
+)
+
+// Categories users choose their notification channels for. Compliance notifications, and any
+// category configured mandatory, cannot be switched off.
+const (
+	CategoryAssignments = "assignments"
+	CategoryReminders   = "reminders"
+	CategoryResults     = "results"
+	CategoryCompliance  = "compliance"
+	// CategoryMarketing covers mail users did not ask for as assessment takers, such as
+	// replies to the contact form.
+	CategoryMarketing = "marketing"
+)
+
+// Channels users receive a category of notifications through: email, whichever delivery
+// channel is configured for it, and the in-app inbox. PreferenceAll and PreferenceNone are the
+// defaults a user may keep in their notification type besides one channel.
+const (
+	PreferenceEmail = "email"
+	PreferenceInApp = "in_app"
+	PreferenceAll   = "all"
+	PreferenceNone  = "none"
+)
+
+// Channels a notification can be delivered through: the notify server, direct SMTP, a webhook,
+// or a file sink for development and testing. Inbox delivers nothing beyond the in-app inbox
+// entry every notification gets.
+const (
+	ChannelNotify  = "notify"
+	ChannelSMTP    = "smtp"
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+	ChannelInbox   = "inbox"
+)
+
+// Keys of the notification templates. Each key may have a variant per locale.
+const (
+	TemplateDistribution        = "distribution"
+	TemplateDistributionManager = "distribution_manager"
+	TemplateCertificateExpiry   = "certificate_expiry"
+	TemplateReminderBeforeDue   = "reminder_before_due"
+	TemplateReminderDueToday    = "reminder_due_today"
+	TemplateReminderOverdue     = "reminder_overdue"
+	TemplateEscalation          = "escalation"
+	TemplateResultPublished     = "result_published"
+	TemplateCertificateIssued   = "certificate_issued"
+	TemplateDigest              = "digest"
+	TemplateContactReply        = "contact_reply"
+)
+
+// How often a digest subscriber gets their digest.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+	DigestOff    = "off"
+)
+
+// What a digest covers: the subscriber's team, from user_manager_mapping, or every user, which
+// only admins may subscribe to.
+const (
+	DigestScopeTeam = "team"
+	DigestScopeAll  = "all"
+)
+
+// Lifecycle events webhooks can subscribe to. Ping is only sent by the test endpoint.
+const (
+	EventAssessmentPublished = "assessment.published"
+	EventAssignmentCreated   = "assignment.created"
+	EventSessionStarted      = "session.started"
+	EventSessionSubmitted    = "session.submitted"
+	EventResultGraded        = "result.graded"
+	EventCertificateIssued   = "certificate.issued"
+	EventPing                = "webhook.ping"
+)
+
+// Status of a notification in the outbox, or of a webhook delivery. Sending ones were claimed
+// by a dispatcher delivering them; dead ones ran out of attempts and wait for an admin to
+// replay them.
+const (
+	OutboxPending = "pending"
+	OutboxSending = "sending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+// Status of a due date extension request.
+const (
+	ExtensionPending  = "pending"
+	ExtensionApproved = "approved"
+	ExtensionRejected = "rejected"
+)
+
+// Certification status of a user for a recurring assessment. Expiring certifications run out
+// within the recurrence's notice period.
+const (
+	CertificationCurrent  = "current"
+	CertificationExpiring = "expiring"
+	CertificationExpired  = "expired"
+)
+
+type DifficultyLevel string
+
+const (
+	Easy   DifficultyLevel = "easy"
+	Medium DifficultyLevel = "medium"
+	Hard   DifficultyLevel = "hard"
+)
+
+// BulkEditOperation names a bulk question edit in the edit history.
+type BulkEditOperation string
+
+const (
+	BulkRetag       BulkEditOperation = "retag"
+	BulkDifficulty  BulkEditOperation = "difficulty"
+	BulkPoints      BulkEditOperation = "points"
+	BulkStatus      BulkEditOperation = "status"
+	BulkFindReplace BulkEditOperation = "find_replace"
+)
+
+// Scopes of a find-and-replace over question content.
+const (
+	FindReplaceAll     = "all"
+	FindReplaceStems   = "stems"
+	FindReplaceOptions = "options"
+)
+
+// AssessmentBundleVersion is the current version of the assessment export bundle format.
+const AssessmentBundleVersion = 1
+
+type ImportFormat string
+
+const (
+	ImportFormatExcel     ImportFormat = "excel"
+	ImportFormatBundle    ImportFormat = "bundle"
+	ImportFormatQTI       ImportFormat = "qti"
+	ImportFormatMoodleXML ImportFormat = "moodle_xml"
+	ImportFormatGIFT      ImportFormat = "gift"
+	ImportFormatExcelZip  ImportFormat = "excel_zip"
+)
+
+// content_type_config ids and names used for question and option content.
+const (
+	ContentTypeText     int64 = 1
+	ContentTypeImage    int64 = 2
+	ContentTypeAudio    int64 = 3
+	ContentTypeHTML     int64 = 4
+	ContentTypeMarkdown int64 = 5
+)
+
+const (
+	ContentText     = "text"
+	ContentImage    = "image"
+	ContentAudio    = "audio"
+	ContentHTML     = "html"
+	ContentMarkdown = "markdown"
+)
+
+// DefaultLocale is the language of the base content_mst values; translations are stored
+// for every other locale.
+const DefaultLocale = "en"
+
+// DuplicateAction decides what an import does with a question that matches the bank.
+type DuplicateAction string
+
+const (
+	// DuplicateWarn imports the question and reports the match.
+	DuplicateWarn DuplicateAction = "warn"
+	// DuplicateSkip leaves the question out of the import.
+	DuplicateSkip DuplicateAction = "skip"
+	// DuplicateMerge reuses the matching bank question instead of creating a new one.
+	DuplicateMerge DuplicateAction = "merge"
+)
+
+// DuplicateThreshold is the default similarity score from which two questions count as duplicates.
+const DuplicateThreshold = 0.85
+
+// Severities of the issues in an import validation report.
+const (
+	IssueError   = "error"
+	IssueWarning = "warning"
 )
@@ -5,9 +5,11 @@ import (
 	"dhl/models"
 	"dhl/services"
 	"dhl/utils"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,10 +23,25 @@ type AdminController struct {
 	contactService      services.ContactService
 	jobService          services.JobDescriptionService
 	questionService     services.QuestionService
+	questionEditService services.QuestionEditService
+	tagService          services.TagService
+	scheduleService     services.AssessmentScheduleService
+	recertService       services.RecertificationService
+	audienceService     services.AudienceService
+	deadlineService     services.AssessmentDeadlineService
+	delegationService   services.AssessmentDelegationService
+	reminderService     services.ReminderService
+	dispatcher          services.NotificationDispatcher
+	templateService     services.NotificationTemplateService
+	webhookService      services.WebhookService
+	digestService       services.DigestService
+	preferenceService   services.NotificationPreferenceService
+	releaseService      services.ResultReleaseService
+	duplicateService    services.DuplicateService
 }
 
-func NewAdminController(userService services.UserService, authService services.AuthService, assessmentService services.AssessmentService, notificationService services.NotificationService, contactService services.ContactService, jobService services.JobDescriptionService, questionService services.QuestionService) *AdminController {
-	return &AdminController{userService: userService, authService: authService, assessmentService: assessmentService, notificationService: notificationService, contactService: contactService, jobService: jobService, questionService: questionService}
+func NewAdminController(userService services.UserService, authService services.AuthService, assessmentService services.AssessmentService, notificationService services.NotificationService, contactService services.ContactService, jobService services.JobDescriptionService, questionService services.QuestionService, duplicateService services.DuplicateService, questionEditService services.QuestionEditService, tagService services.TagService, scheduleService services.AssessmentScheduleService, recertService services.RecertificationService, audienceService services.AudienceService, deadlineService services.AssessmentDeadlineService, delegationService services.AssessmentDelegationService, reminderService services.ReminderService, dispatcher services.NotificationDispatcher, templateService services.NotificationTemplateService, webhookService services.WebhookService, digestService services.DigestService, preferenceService services.NotificationPreferenceService, releaseService services.ResultReleaseService) *AdminController {
+	return &AdminController{userService: userService, authService: authService, assessmentService: assessmentService, notificationService: notificationService, contactService: contactService, jobService: jobService, questionService: questionService, duplicateService: duplicateService, questionEditService: questionEditService, tagService: tagService, scheduleService: scheduleService, recertService: recertService, audienceService: audienceService, deadlineService: deadlineService, delegationService: delegationService, reminderService: reminderService, dispatcher: dispatcher, templateService: templateService, webhookService: webhookService, digestService: digestService, preferenceService: preferenceService, releaseService: releaseService}
 }
 
 func (uc *AdminController) GetAssessments(ctx *gin.Context) {
@@ -108,44 +125,50 @@ func (uc *AdminController) UpdateAssessmentStatusController(ctx *gin.Context) {
 }
 
 func (uc *AdminController) DistributeAssessmentToUserController(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, uc.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
 	var req models.DistributeAssessmentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
 		return
 	}
-	err := uc.assessmentService.DistributeAssessmentUser(req.AssessmentSequence, req.UserIds)
+	err = uc.assessmentService.DistributeAssessmentUser(req.AssessmentSequence, req.UserIds, req.DueDate)
 	if err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to distribute", nil, err)
 		return
 	}
-
-	go func(userIds []string, seq string) {
-		if err := uc.notificationService.SendDistributeAssessmentMail(userIds, seq, false); err != nil {
-			log.Println("Error while sending mails:", err)
+	if role == string(constant.Manager) {
+		if err := uc.delegationService.RecordManagerDistribution(ctx.Request.Context(), req.AssessmentSequence, userId, req.UserIds); err != nil {
+			log.Printf("[ERROR] failed to record delegations of %s: %v", req.AssessmentSequence, err)
 		}
-	}(req.UserIds, req.AssessmentSequence)
+	}
 
 	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessments distributed", nil, nil, nil)
 	return
 }
 
+// DistributeAssessmentToManagerController hands an assessment to managers and, with cascade,
+// assigns it to the users under them.
 func (uc *AdminController) DistributeAssessmentToManagerController(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, uc.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
 	var req models.DistributeAssessmentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
 		return
 	}
-	err := uc.assessmentService.DistributeAssessmentManager(req.AssessmentSequence, req.UserIds)
+	result, err := uc.delegationService.DistributeToManagers(ctx.Request.Context(), req, userId)
 	if err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to distribute", nil, err)
 		return
 	}
-	go func(userIds []string, seq string) {
-		if err := uc.notificationService.SendDistributeAssessmentMail(userIds, seq, true); err != nil {
-			log.Println("Error while sending mails:", err)
-		}
-	}(req.UserIds, req.AssessmentSequence)
-	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessments distributed", nil, nil, nil)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessments distributed", result, nil, nil)
 	return
 }
 
@@ -196,6 +219,10 @@ func (uc *AdminController) GetUsers(ctx *gin.Context) {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to load users", nil, err)
 		return
 	}
+	if err := uc.recertService.AttachCertificationStatus(response); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to load certifications", nil, err)
+		return
+	}
 	pagination := utils.GetPagination(limit, page, offset, totalRecords)
 	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "users loaded", response, pagination, nil)
 	return
@@ -284,6 +311,30 @@ func (cc *AdminController) ListContactRespController(ctx *gin.Context) {
 	return
 }
 
+// ReplyContactResponse answers a contact form message by mail.
+func (ac *AdminController) ReplyContactResponse(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid message id", nil, err)
+		return
+	}
+	var req models.ContactReplyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	err = ac.contactService.Reply(ctx.Request.Context(), uint(id), req)
+	if errors.Is(err, services.ErrContactNotUser) {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnprocessableEntity, err.Error(), nil, err)
+		return
+	}
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Reply queued", nil, nil, nil)
+}
+
 func (ac *AdminController) CreateJobDescription(ctx *gin.Context) {
 
 	var req models.JobDescription
@@ -376,7 +427,7 @@ func (ac *AdminController) CreateMultipleQuestions(ctx *gin.Context) {
         return
     }
 
-    questionIDs, err := ac.questionService.CreateMultipleQuestions(req.Questions, userId)
+    response, err := ac.questionService.CreateMultipleQuestions(req.Questions, req.DuplicateAction, userId)
     if err != nil {
         models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to create questions", nil, err)
         return
@@ -384,10 +435,1035 @@ func (ac *AdminController) CreateMultipleQuestions(ctx *gin.Context) {
 
     models.SuccessResponse(ctx, constant.Success, http.StatusOK,
         "Questions created successfully",
-        map[string]interface{}{"question_ids": questionIDs},
+        response,
         nil, nil)
 }
 
+// CheckQuestionDuplicates compares questions with the bank without saving anything, so the
+// author can choose to merge or skip before importing.
+func (ac *AdminController) CheckQuestionDuplicates(ctx *gin.Context) {
+	var req models.CheckDuplicatesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	candidates := make([]services.DuplicateCandidate, 0, len(req.Questions))
+	for _, q := range req.Questions {
+		candidate := services.DuplicateCandidate{Title: q.Title}
+		for _, opt := range q.Options {
+			candidate.Options = append(candidate.Options, opt.Label)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	warnings, err := ac.duplicateService.Screen(candidates, string(constant.DuplicateWarn), req.Threshold)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to check duplicates", nil, err)
+		return
+	}
+
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Duplicate check completed", warnings, nil, nil)
+}
+
+// GetQuestionDuplicates lists clusters of near-duplicate questions across the bank.
+func (ac *AdminController) GetQuestionDuplicates(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+
+	threshold := constant.DuplicateThreshold
+	if value := ctx.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "threshold must be between 0 and 1", nil, err)
+			return
+		}
+		threshold = parsed
+	}
+
+	clusters, total, err := ac.duplicateService.ListClusters(threshold, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to list duplicate questions", nil, err)
+		return
+	}
+
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Duplicate questions fetched successfully", clusters, pagination, nil)
+}
+
+
+func (ac *AdminController) BulkRetagQuestions(ctx *gin.Context) {
+	var req models.BulkRetagRequest
+	if !ac.bindBulkEdit(ctx, &req) {
+		return
+	}
+	ac.runBulkEdit(ctx, func(userId string) (*models.BulkEditResult, error) {
+		return ac.questionEditService.Retag(ctx.Request.Context(), req, userId)
+	})
+}
+
+func (ac *AdminController) BulkSetQuestionDifficulty(ctx *gin.Context) {
+	var req models.BulkDifficultyRequest
+	if !ac.bindBulkEdit(ctx, &req) {
+		return
+	}
+	ac.runBulkEdit(ctx, func(userId string) (*models.BulkEditResult, error) {
+		return ac.questionEditService.SetDifficulty(ctx.Request.Context(), req, userId)
+	})
+}
+
+func (ac *AdminController) BulkSetQuestionPoints(ctx *gin.Context) {
+	var req models.BulkPointsRequest
+	if !ac.bindBulkEdit(ctx, &req) {
+		return
+	}
+	ac.runBulkEdit(ctx, func(userId string) (*models.BulkEditResult, error) {
+		return ac.questionEditService.SetPoints(ctx.Request.Context(), req, userId)
+	})
+}
+
+func (ac *AdminController) BulkSetQuestionStatus(ctx *gin.Context) {
+	var req models.BulkStatusRequest
+	if !ac.bindBulkEdit(ctx, &req) {
+		return
+	}
+	ac.runBulkEdit(ctx, func(userId string) (*models.BulkEditResult, error) {
+		return ac.questionEditService.SetStatus(ctx.Request.Context(), req, userId)
+	})
+}
+
+// BulkFindReplaceQuestions replaces text in question stems and options; with "preview" the
+// changes are only reported.
+func (ac *AdminController) BulkFindReplaceQuestions(ctx *gin.Context) {
+	var req models.FindReplaceRequest
+	if !ac.bindBulkEdit(ctx, &req) {
+		return
+	}
+	ac.runBulkEdit(ctx, func(userId string) (*models.BulkEditResult, error) {
+		return ac.questionEditService.FindReplace(ctx.Request.Context(), req, userId)
+	})
+}
+
+// GetQuestionEditHistory lists bulk edits, optionally for one question or one batch.
+func (ac *AdminController) GetQuestionEditHistory(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+
+	var questionID int64
+	if value := ctx.Query("question_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid question id", nil, err)
+			return
+		}
+		questionID = parsed
+	}
+
+	history, total, err := ac.questionEditService.ListHistory(questionID, ctx.Query("batch_id"), limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch edit history", nil, err)
+		return
+	}
+
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Question edit history fetched successfully", history, pagination, nil)
+}
+
+func (ac *AdminController) bindBulkEdit(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return false
+	}
+	return true
+}
+
+func (ac *AdminController) runBulkEdit(ctx *gin.Context, edit func(userId string) (*models.BulkEditResult, error)) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+
+	result, err := edit(userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	message := "Questions updated successfully"
+	if result.Preview {
+		message = "Preview of question changes"
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, message, result, nil, nil)
+}
+
+// GetTagTree lists the tag taxonomy as a tree with usage counts.
+func (ac *AdminController) GetTagTree(ctx *gin.Context) {
+	tree, err := ac.tagService.GetTree()
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch tags", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tags fetched successfully", tree, nil, nil)
+}
+
+func (ac *AdminController) GetTagUsage(ctx *gin.Context) {
+	tagID, ok := tagIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	usage, err := ac.tagService.GetUsage(tagID)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Tag not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tag usage fetched successfully", usage, nil, nil)
+}
+
+func (ac *AdminController) CreateTag(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.CreateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	tag, err := ac.tagService.CreateTag(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tag created successfully", tag, nil, nil)
+}
+
+func (ac *AdminController) RenameTag(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	tagID, ok := tagIDParam(ctx)
+	if !ok {
+		return
+	}
+	var req models.RenameTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	if err := ac.tagService.RenameTag(ctx.Request.Context(), tagID, req, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tag renamed successfully", nil, nil, nil)
+}
+
+func (ac *AdminController) MergeTags(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.MergeTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	result, err := ac.tagService.MergeTags(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tags merged successfully", result, nil, nil)
+}
+
+func (ac *AdminController) MoveTag(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	tagID, ok := tagIDParam(ctx)
+	if !ok {
+		return
+	}
+	var req models.MoveTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	if err := ac.tagService.MoveTag(ctx.Request.Context(), tagID, req, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tag moved successfully", nil, nil, nil)
+}
+
+func (ac *AdminController) DeleteTag(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	tagID, ok := tagIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ac.tagService.DeleteTag(ctx.Request.Context(), tagID, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Tag deleted successfully", nil, nil, nil)
+}
+
+func tagIDParam(ctx *gin.Context) (int64, bool) {
+	tagID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid tag id", nil, err)
+		return 0, false
+	}
+	return tagID, true
+}
+
+// GetAssessmentSchedule lists assessment opens and closes due in the next "days" days
+// (default 7), including overdue ones, and the state of the background scheduler.
+func (ac *AdminController) GetAssessmentSchedule(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+
+	days := 7
+	if value := ctx.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 366 {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "days must be between 0 and 366", nil, err)
+			return
+		}
+		days = parsed
+	}
+
+	overview, total, err := ac.scheduleService.GetOverview(time.Duration(days)*24*time.Hour, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch assessment schedule", nil, err)
+		return
+	}
+
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment schedule fetched successfully", overview, pagination, nil)
+}
+
+// SaveAssessmentRecurrence makes an assessment recur every interval_months, or updates the rule.
+func (ac *AdminController) SaveAssessmentRecurrence(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveRecurrenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	recurrence, err := ac.recertService.SaveRecurrence(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Recurrence saved successfully", recurrence, nil, nil)
+}
+
+func (ac *AdminController) GetAssessmentRecurrence(ctx *gin.Context) {
+	recurrence, err := ac.recertService.GetRecurrence(ctx.Param("id"))
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Recurrence fetched successfully", recurrence, nil, nil)
+}
+
+func (ac *AdminController) DeleteAssessmentRecurrence(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	if err := ac.recertService.DeleteRecurrence(ctx.Request.Context(), ctx.Param("id"), userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Recurrence stopped successfully", nil, nil, nil)
+}
+
+// GetCertifications lists the latest certification of each user, filtered by the
+// "assessment_sequence" and "status" (current, expiring or expired) query params.
+func (ac *AdminController) GetCertifications(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	certs, total, err := ac.recertService.ListCertifications(ctx.Query("assessment_sequence"), ctx.Query("status"), limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Certifications fetched successfully", certs, pagination, nil)
+}
+
+func (ac *AdminController) SaveAudienceRule(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveAudienceRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	rule, err := ac.audienceService.SaveRule(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience rule saved", rule, nil, nil)
+}
+
+func (ac *AdminController) GetAudienceRules(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	rules, total, err := ac.audienceService.ListRules(limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch audience rules", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience rules fetched", rules, pagination, nil)
+}
+
+func (ac *AdminController) GetAudienceRule(ctx *gin.Context) {
+	ruleID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid rule id", nil, err)
+		return
+	}
+	rule, err := ac.audienceService.GetRule(ruleID)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Audience rule not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience rule fetched", rule, nil, nil)
+}
+
+func (ac *AdminController) DeleteAudienceRule(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	ruleID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid rule id", nil, err)
+		return
+	}
+	if err := ac.audienceService.DeleteRule(ruleID, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience rule deleted", nil, nil, nil)
+}
+
+// PreviewAudience lists the users a rule would select, before it is saved or assigned.
+func (ac *AdminController) PreviewAudience(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	var req models.AudiencePreviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	members, total, err := ac.audienceService.Preview(req, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience preview", members, pagination, nil)
+}
+
+// AssignAssessmentAudience distributes an assessment to the users matching a rule and, with
+// auto_assign, to those matching it later.
+func (ac *AdminController) AssignAssessmentAudience(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.AssignAudienceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	audience, userIds, err := ac.audienceService.AssignAudience(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp := models.AssignAudienceResponse{Audience: audience, Assigned: len(userIds)}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment assigned to audience", resp, nil, nil)
+}
+
+func (ac *AdminController) GetAssessmentAudiences(ctx *gin.Context) {
+	assessmentSeq := ctx.Query("assessment_sequence")
+	if assessmentSeq == "" {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "assessment_sequence is required", nil, nil)
+		return
+	}
+	audiences, err := ac.audienceService.ListAudiences(assessmentSeq)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch audiences", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audiences fetched", audiences, nil, nil)
+}
+
+func (ac *AdminController) RemoveAssessmentAudience(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	audienceID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid audience id", nil, err)
+		return
+	}
+	if err := ac.audienceService.RemoveAudience(audienceID, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Audience not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Audience removed", nil, nil, nil)
+}
+
+// SetAssessmentDueDate gives assigned users their own due date, or clears it when due_date is
+// null. Managers can only change their own users.
+func (ac *AdminController) SetAssessmentDueDate(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SetDueDateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	updated, err := ac.deadlineService.SetDueDates(ctx.Request.Context(), req, role, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Due date updated", gin.H{"updated": updated}, nil, nil)
+}
+
+// GetExtensionRequests lists extension requests, filtered by the "status" and
+// "assessment_sequence" query params. Managers see their own users' requests.
+func (ac *AdminController) GetExtensionRequests(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.ExtensionFilter{
+		AssessmentSequence: ctx.Query("assessment_sequence"),
+		Status:             ctx.Query("status"),
+	}
+
+	list, total, err := ac.deadlineService.ListExtensions(filter, role, userId, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Extension requests fetched", list, pagination, nil)
+}
+
+func (ac *AdminController) ReviewExtensionRequest(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	extensionID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid extension request id", nil, err)
+		return
+	}
+	var req models.ReviewExtensionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	ext, err := ac.deadlineService.ReviewExtension(ctx.Request.Context(), extensionID, req, role, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Extension request "+ext.Status, ext, nil, nil)
+}
+
+// GetTeamProgress shows how the users a manager delegated an assessment to, directly or down
+// the line, are progressing. Admins pick the manager with the "manager_id" query param.
+func (ac *AdminController) GetTeamProgress(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	assessmentSeq := ctx.Query("assessment_sequence")
+	if assessmentSeq == "" {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "assessment_sequence is required", nil, nil)
+		return
+	}
+	managerID := userId
+	if role != string(constant.Manager) {
+		managerID = ctx.Query("manager_id")
+		if managerID == "" {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "manager_id is required", nil, nil)
+			return
+		}
+	}
+
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	progress, total, err := ac.delegationService.GetTeamProgress(managerID, assessmentSeq, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch team progress", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Team progress fetched", progress, pagination, nil)
+}
+
+// GetDelegations lists who delegated which assessment to whom, filtered by the
+// "assessment_sequence" and "delegated_by" query params.
+func (ac *AdminController) GetDelegations(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.DelegationFilter{
+		AssessmentSequence: ctx.Query("assessment_sequence"),
+		DelegatedBy:        ctx.Query("delegated_by"),
+	}
+
+	list, total, err := ac.delegationService.ListDelegations(filter, role, userId, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch delegations", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Delegations fetched", list, pagination, nil)
+}
+
+// SaveReminderPolicy sets when an assessment's reminders and escalations go out, in place of
+// the configured default.
+func (ac *AdminController) SaveReminderPolicy(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveReminderPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	policy, err := ac.reminderService.SavePolicy(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Reminder policy saved", policy, nil, nil)
+}
+
+func (ac *AdminController) GetReminderPolicy(ctx *gin.Context) {
+	policy, err := ac.reminderService.GetPolicy(ctx.Param("id"))
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch reminder policy", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Reminder policy fetched", policy, nil, nil)
+}
+
+func (ac *AdminController) DeleteReminderPolicy(ctx *gin.Context) {
+	if err := ac.reminderService.DeletePolicy(ctx.Param("id")); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Reminder policy not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Reminder policy removed, the default applies", nil, nil, nil)
+}
+
+// SaveResultReleasePolicy sets when the results of an assessment are released: immediate,
+// on_close, on_date (with release_on) or manual.
+func (ac *AdminController) SaveResultReleasePolicy(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveResultReleaseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	release, err := ac.releaseService.SavePolicy(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Result release policy saved", release, nil, nil)
+}
+
+func (ac *AdminController) GetResultReleasePolicy(ctx *gin.Context) {
+	release, err := ac.releaseService.GetPolicy(ctx.Param("id"))
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch result release policy", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Result release policy fetched", release, nil, nil)
+}
+
+// ReleaseResults releases the held back results of an assessment now and notifies every
+// candidate of theirs.
+func (ac *AdminController) ReleaseResults(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	notified, err := ac.releaseService.Release(ctx.Request.Context(), ctx.Param("id"), userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Results released", gin.H{"notified": notified}, nil, nil)
+}
+
+// GetReminders lists the reminders and escalations queued, filtered by the
+// "assessment_sequence", "user_id" and "event" query params.
+func (ac *AdminController) GetReminders(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.ReminderLogFilter{
+		AssessmentSequence: ctx.Query("assessment_sequence"),
+		UserID:             ctx.Query("user_id"),
+		Event:              ctx.Query("event"),
+	}
+	list, total, err := ac.reminderService.ListReminders(filter, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch reminders", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Reminders fetched", list, pagination, nil)
+}
+
+// GetNotificationOutbox lists the queued notifications, newest first, filtered by the "status",
+// "kind", "recipient_id" and "assessment_sequence" query params.
+func (ac *AdminController) GetNotificationOutbox(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.OutboxFilter{
+		Status:             ctx.Query("status"),
+		Kind:               ctx.Query("kind"),
+		RecipientID:        ctx.Query("recipient_id"),
+		AssessmentSequence: ctx.Query("assessment_sequence"),
+	}
+	list, total, err := ac.dispatcher.List(filter, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch notifications", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notifications fetched", list, pagination, nil)
+}
+
+// ReplayNotificationOutbox puts dead notifications back in the queue.
+func (ac *AdminController) ReplayNotificationOutbox(ctx *gin.Context) {
+	var req models.ReplayOutboxRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	replayed, err := ac.dispatcher.Replay(req)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notifications queued again", gin.H{"replayed": replayed}, nil, nil)
+}
+
+// GetNotificationTemplates lists the notification templates, of one "key" when given.
+func (ac *AdminController) GetNotificationTemplates(ctx *gin.Context) {
+	list, err := ac.templateService.List(ctx.Query("key"))
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch notification templates", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification templates fetched", list, nil, nil)
+}
+
+// SaveNotificationTemplate creates or replaces the template of a key in a locale.
+func (ac *AdminController) SaveNotificationTemplate(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveNotificationTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	tmpl, err := ac.templateService.Save(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification template saved", tmpl, nil, nil)
+}
+
+func (ac *AdminController) DeleteNotificationTemplate(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid template id", nil, err)
+		return
+	}
+	if err := ac.templateService.Delete(id); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Notification template not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification template deleted", nil, nil, nil)
+}
+
+// PreviewNotificationTemplate renders a template in a locale with sample data.
+func (ac *AdminController) PreviewNotificationTemplate(ctx *gin.Context) {
+	var req models.PreviewNotificationTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	rendered, err := ac.templateService.Preview(req)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification template rendered", rendered, nil, nil)
+}
+
+// GetNotificationSuppressions lists the notifications held back by user preferences, newest
+// first, filtered by the "user_id", "category" and "channel" query params.
+func (ac *AdminController) GetNotificationSuppressions(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.SuppressionFilter{
+		UserID:   ctx.Query("user_id"),
+		Category: ctx.Query("category"),
+		Channel:  ctx.Query("channel"),
+	}
+	list, total, err := ac.preferenceService.ListSuppressions(filter, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch suppressed notifications", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Suppressed notifications fetched", list, pagination, nil)
+}
+
+// GetWebhooks lists the webhook endpoints, without their secrets.
+func (ac *AdminController) GetWebhooks(ctx *gin.Context) {
+	list, err := ac.webhookService.ListEndpoints()
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch webhooks", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Webhooks fetched", list, nil, nil)
+}
+
+// CreateWebhook registers an endpoint. The response carries its secret, which is not shown again.
+func (ac *AdminController) CreateWebhook(ctx *gin.Context) {
+	ac.saveWebhook(ctx, 0)
+}
+
+func (ac *AdminController) UpdateWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid webhook id", nil, err)
+		return
+	}
+	ac.saveWebhook(ctx, id)
+}
+
+func (ac *AdminController) saveWebhook(ctx *gin.Context, id int64) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	endpoint, err := ac.webhookService.SaveEndpoint(ctx.Request.Context(), id, req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Webhook saved", endpoint, nil, nil)
+}
+
+func (ac *AdminController) DeleteWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid webhook id", nil, err)
+		return
+	}
+	if err := ac.webhookService.DeleteEndpoint(id); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Webhook not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Webhook deleted", nil, nil, nil)
+}
+
+// PingWebhook sends a test event to the endpoint and returns the logged delivery.
+func (ac *AdminController) PingWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid webhook id", nil, err)
+		return
+	}
+	delivery, err := ac.webhookService.Ping(ctx.Request.Context(), id)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Webhook pinged", delivery, nil, nil)
+}
+
+// GetWebhookDeliveries lists the webhook deliveries, newest first, filtered by the "webhook_id",
+// "event" and "status" query params.
+func (ac *AdminController) GetWebhookDeliveries(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.WebhookDeliveryFilter{
+		Event:  ctx.Query("event"),
+		Status: ctx.Query("status"),
+	}
+	if value := ctx.Query("webhook_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid webhook id", nil, err)
+			return
+		}
+		filter.EndpointID = id
+	}
+	list, total, err := ac.webhookService.ListDeliveries(filter, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch webhook deliveries", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Webhook deliveries fetched", list, pagination, nil)
+}
+
+// GetDigestSubscription returns how often the current user gets their digest.
+func (ac *AdminController) GetDigestSubscription(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	sub, err := ac.digestService.GetSubscription(userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch digest subscription", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Digest subscription fetched", sub, nil, nil)
+}
+
+// SaveDigestSubscription sets how often the current user gets their digest.
+func (ac *AdminController) SaveDigestSubscription(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveDigestSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	sub, err := ac.digestService.SaveSubscription(ctx.Request.Context(), userId, role, req)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Digest subscription saved", sub, nil, nil)
+}
+
+// PreviewDigest renders as HTML the digest of a manager's team over the "from" and "to" dates,
+// both YYYY-MM-DD and included, by default the last seven days. Managers see their own team;
+// admins pick one with "manager_id", or see every user without it.
+func (ac *AdminController) PreviewDigest(ctx *gin.Context) {
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	recipientID, managerID := userId, userId
+	if role != string(constant.Manager) {
+		managerID = ctx.Query("manager_id")
+		if managerID != "" {
+			recipientID = managerID
+		}
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	to := today.AddDate(0, 0, 1)
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "to must be a YYYY-MM-DD date", nil, err)
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -7)
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "from must be a YYYY-MM-DD date", nil, err)
+			return
+		}
+		from = parsed
+	}
+
+	rendered, err := ac.digestService.Preview(recipientID, managerID, from, to)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered.Body))
+}
 
 func (ac *AdminController) GetAssessmentUserResult(ctx *gin.Context) {
 
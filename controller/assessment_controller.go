@@ -1,31 +1,47 @@
 package controller
 
 import (
+	"bytes"
+	"dhl/config"
 	"dhl/constant"
 	"dhl/models"
 	"dhl/services"
 	"dhl/utils"
+	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AssessmentController struct {
-	assessmentService services.AssessmentService
-	userService       services.UserService
-	geminiService     services.GeminiService
+	assessmentService    services.AssessmentService
+	userService          services.UserService
+	geminiService        services.GeminiService
+	jobAssessmentService services.JobAssessmentService
+	transferService      services.AssessmentTransferService
+	mediaService         services.MediaService
+	translationService   services.TranslationService
+	blueprintService     services.BlueprintService
+	templateService      services.AssessmentTemplateService
+	deadlineService      services.AssessmentDeadlineService
+	inboxService         services.InboxService
+	preferenceService    services.NotificationPreferenceService
 }
 
-func NewAssessmentController(assessmentService services.AssessmentService, userService services.UserService, geminiService services.GeminiService) *AssessmentController {
-	return &AssessmentController{assessmentService: assessmentService, userService: userService, geminiService: geminiService}
+func NewAssessmentController(assessmentService services.AssessmentService, userService services.UserService, geminiService services.GeminiService, jobAssessmentService services.JobAssessmentService, transferService services.AssessmentTransferService, mediaService services.MediaService, translationService services.TranslationService, blueprintService services.BlueprintService, templateService services.AssessmentTemplateService, deadlineService services.AssessmentDeadlineService, inboxService services.InboxService, preferenceService services.NotificationPreferenceService) *AssessmentController {
+	return &AssessmentController{assessmentService: assessmentService, userService: userService, geminiService: geminiService, jobAssessmentService: jobAssessmentService, transferService: transferService, mediaService: mediaService, translationService: translationService, blueprintService: blueprintService, templateService: templateService, deadlineService: deadlineService, inboxService: inboxService, preferenceService: preferenceService}
 }
 
 func (ac *AssessmentController) GetAssessment(ctx *gin.Context) {
 	assessmentSeq := ctx.Query("assessment_sequence")
 
-	resp, err := ac.assessmentService.GetAssessment(assessmentSeq)
+	resp, err := ac.assessmentService.GetAssessment(assessmentSeq, ctx.Query("locale"))
 	if err != nil {
 		models.ErrorResponse(ctx, "Failed to fetch assessment", http.StatusInternalServerError, err.Error(), nil, err)
 		return
@@ -85,10 +101,35 @@ func (ac *AssessmentController) GetUserAssessments(ctx *gin.Context) {
 	return
 }
 
+// GetUserAssessmentCerficiate downloads the certificate of a session. Users only get their own,
+// and the pending release state instead while the results are held back.
 func (ac *AssessmentController) GetUserAssessmentCerficiate(ctx *gin.Context) {
 	assessmentSession := ctx.Query("assessment_session")
+	role, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
 
-	pdfBytes, err := ac.assessmentService.GenerateUserAssessmentCerficiate(assessmentSession)
+	var pdfBytes []byte
+	if role == string(constant.User) {
+		var pending *models.PendingResultResponse
+		pdfBytes, pending, err = ac.assessmentService.GenerateOwnCertificate(assessmentSession, userId)
+		if errors.Is(err, services.ErrSessionNotFound) {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Session not found", nil, err)
+			return
+		}
+		if errors.Is(err, services.ErrNoCertificate) {
+			models.ErrorResponse(ctx, constant.Failure, http.StatusForbidden, "Assessment does not award certificates", nil, err)
+			return
+		}
+		if err == nil && pending != nil {
+			models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Results pending release", pending, nil, nil)
+			return
+		}
+	} else {
+		pdfBytes, err = ac.assessmentService.GenerateUserAssessmentCerficiate(assessmentSession)
+	}
 	if err != nil {
 		models.ErrorResponse(ctx, "Failed to generate assessment certificate", http.StatusInternalServerError, err.Error(), nil, err)
 		return
@@ -202,15 +243,473 @@ func (ac *AssessmentController) UploadAssessment(c *gin.Context) {
 	}
 	defer f.Close()
 
-	response, err := ac.assessmentService.CreateAssessmentViaFileUpload(f, file.Filename, userId, nil)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "File read error", nil, err)
+		return
+	}
+
+	format, err := utils.DetectImportFormat(file.Filename, data)
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	switch format {
+	case constant.ImportFormatQTI, constant.ImportFormatMoodleXML, constant.ImportFormatGIFT:
+		response, err := ac.transferService.ImportItemFile(c.Request.Context(), format, data, file.Filename, userId)
+		if err != nil {
+			models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "Processing failed", nil, err)
+			return
+		}
+		models.SuccessResponse(c, constant.Success, http.StatusOK, "Assessment", response, nil, nil)
+		return
+
+	case constant.ImportFormatBundle:
+		// JSON files are assessment bundles produced by the export endpoint
+		var bundle models.AssessmentBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, "Invalid assessment bundle", nil, err)
+			return
+		}
+		response, err := ac.transferService.ImportAssessmentBundle(c.Request.Context(), bundle, userId)
+		if err != nil {
+			models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "Processing failed", nil, err)
+			return
+		}
+		models.SuccessResponse(c, constant.Success, http.StatusOK, "Assessment", response, nil, nil)
+		return
+
+	case constant.ImportFormatExcelZip:
+		// zip with a questionnaire workbook and the media files it references
+		response, err := ac.transferService.ImportExcelZip(c.Request.Context(), data, file.Filename, userId, c.PostForm("duplicate_action"))
+		if err != nil {
+			importFailed(c, err)
+			return
+		}
+		models.SuccessResponse(c, constant.Success, http.StatusOK, "Assessment", response, nil, nil)
+		return
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "File read error", nil, err)
+		return
+	}
+
+	response, err := ac.assessmentService.CreateAssessmentViaFileUpload(f, file.Filename, userId, nil, c.PostForm("duplicate_action"))
 	if err != nil {
-		models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "Processing failed", nil, err)
+		importFailed(c, err)
 		return
 	}
 
 	models.SuccessResponse(c, constant.Success, http.StatusOK, "Assessment", response, nil, nil)
 }
 
+// importFailed answers a rejected workbook with its validation report; nothing was imported.
+func importFailed(c *gin.Context, err error) {
+	var validationErr *utils.ExcelValidationError
+	if errors.As(err, &validationErr) {
+		models.SuccessResponse(c, constant.Failure, http.StatusUnprocessableEntity, "Excel validation failed", validationErr.Report, nil, nil)
+		return
+	}
+	models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "Processing failed", nil, err)
+}
+
+// ValidateAssessmentUpload is the dry run of UploadAssessment for Excel workbooks (or zips of a
+// workbook and its media): it returns every row and column problem without saving anything.
+func (ac *AssessmentController) ValidateAssessmentUpload(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, "File missing", nil, err)
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "File open error", nil, err)
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusInternalServerError, "File read error", nil, err)
+		return
+	}
+
+	format, err := utils.DetectImportFormat(file.Filename, data)
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	var report *models.ExcelValidationReport
+	switch format {
+	case constant.ImportFormatExcel:
+		report, err = ac.assessmentService.ValidateAssessmentUpload(bytes.NewReader(data), file.Filename)
+	case constant.ImportFormatExcelZip:
+		report, err = ac.transferService.ValidateExcelZip(data, file.Filename)
+	default:
+		models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, "Validation is only available for Excel uploads", nil, nil)
+		return
+	}
+	if err != nil {
+		models.ErrorResponse(c, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	models.SuccessResponse(c, constant.Success, http.StatusOK, "Validation report", report, nil, nil)
+}
+
+// DownloadImportTemplate serves an empty questionnaire workbook built from the import schema.
+func (ac *AssessmentController) DownloadImportTemplate(ctx *gin.Context) {
+	fileBytes, err := utils.BuildQuestionnaireTemplate()
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to build template", nil, err)
+		return
+	}
+	ctx.Header("Content-Disposition", "attachment; filename=assessment_import_template.xlsx")
+	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", fileBytes)
+}
+
+func (ac *AssessmentController) ExportAssessment(ctx *gin.Context) {
+	assessmentSeq := ctx.Query("assessment_sequence")
+	if assessmentSeq == "" {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "assessment_sequence is required", nil, nil)
+		return
+	}
+
+	switch strings.ToLower(ctx.DefaultQuery("format", "json")) {
+	case "excel", "xlsx":
+		fileBytes, err := ac.transferService.ExportAssessmentExcel(assessmentSeq)
+		if err != nil {
+			models.ErrorResponse(ctx, "Failed to export assessment", http.StatusInternalServerError, err.Error(), nil, err)
+			return
+		}
+		ctx.Header("Content-Disposition", "attachment; filename="+assessmentSeq+".xlsx")
+		ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", fileBytes)
+	case "qti":
+		fileBytes, issues, err := ac.transferService.ExportQTIPackage(assessmentSeq)
+		if err != nil {
+			models.ErrorResponse(ctx, "Failed to export assessment", http.StatusInternalServerError, err.Error(), nil, err)
+			return
+		}
+		if len(issues) > 0 {
+			skipped := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				skipped = append(skipped, issue.Identifier+":"+issue.Interaction)
+			}
+			ctx.Header("X-QTI-Unsupported-Items", strings.Join(skipped, ","))
+		}
+		ctx.Header("Content-Disposition", "attachment; filename="+assessmentSeq+"_qti.zip")
+		ctx.Data(http.StatusOK, "application/zip", fileBytes)
+	case "json":
+		bundle, err := ac.transferService.ExportAssessmentBundle(assessmentSeq)
+		if err != nil {
+			models.ErrorResponse(ctx, "Failed to export assessment", http.StatusInternalServerError, err.Error(), nil, err)
+			return
+		}
+		ctx.Header("Content-Disposition", "attachment; filename="+assessmentSeq+".json")
+		ctx.JSON(http.StatusOK, bundle)
+	default:
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "format must be json, excel or qti", nil, nil)
+	}
+}
+
+func (ac *AssessmentController) UploadMedia(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "File missing", nil, err)
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "File open error", nil, err)
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "File read error", nil, err)
+		return
+	}
+
+	resp, err := ac.mediaService.Upload(ctx.Request.Context(), file.Filename, data, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Media", resp, nil, nil)
+}
+
+func (ac *AssessmentController) GetMedia(ctx *gin.Context) {
+	key := ctx.Query("key")
+	if key == "" {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "key is required", nil, nil)
+		return
+	}
+
+	rc, media, err := ac.mediaService.Open(key)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Media not found", nil, err)
+		return
+	}
+	defer rc.Close()
+
+	ctx.Header("Cache-Control", "private, max-age=86400")
+	ctx.DataFromReader(http.StatusOK, media.SizeBytes, media.MimeType, rc, nil)
+}
+
+func (ac *AssessmentController) UpdateQuestionContent(ctx *gin.Context) {
+	var req models.UpdateContentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.mediaService.UpdateContent(ctx.Request.Context(), req)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Content updated", resp, nil, nil)
+}
+
+func (ac *AssessmentController) SaveTranslations(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveTranslationsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.translationService.SaveTranslations(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Translations saved", resp, nil, nil)
+}
+
+func (ac *AssessmentController) GetAssessmentLocales(ctx *gin.Context) {
+	resp, err := ac.translationService.GetAssessmentLocales(ctx.Query("assessment_sequence"))
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment locales", resp, nil, nil)
+}
+
+func (ac *AssessmentController) SaveBlueprint(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveBlueprintRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.blueprintService.SaveBlueprint(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Blueprint saved", resp, nil, nil)
+}
+
+func (ac *AssessmentController) GetBlueprints(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+
+	blueprints, totalRecords, err := ac.blueprintService.ListBlueprints(limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to retrieve blueprints", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, totalRecords)
+
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "blueprints", blueprints, pagination, nil)
+}
+
+func (ac *AssessmentController) GetBlueprint(ctx *gin.Context) {
+	blueprintID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid blueprint id", nil, err)
+		return
+	}
+
+	resp, err := ac.blueprintService.GetBlueprint(blueprintID)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Blueprint not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "blueprint", resp, nil, nil)
+}
+
+func (ac *AssessmentController) DeleteBlueprint(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	blueprintID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid blueprint id", nil, err)
+		return
+	}
+
+	if err := ac.blueprintService.DeleteBlueprint(blueprintID, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Blueprint not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Blueprint deleted", nil, nil, nil)
+}
+
+func (ac *AssessmentController) AssembleBlueprint(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.AssembleBlueprintRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.blueprintService.AssembleAssessment(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to assemble assessment", nil, err)
+		return
+	}
+	if !resp.Satisfied && resp.AssessmentSequence == "" && !req.DryRun {
+		// the shortfall report is the content of the failure
+		models.SuccessResponse(ctx, constant.Failure, http.StatusUnprocessableEntity, "Question bank cannot satisfy the blueprint", resp, nil, nil)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Blueprint assembly", resp, nil, nil)
+}
+
+func (ac *AssessmentController) SaveAssessmentTemplate(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.templateService.SaveTemplate(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Template saved", resp, nil, nil)
+}
+
+func (ac *AssessmentController) GetAssessmentTemplates(ctx *gin.Context) {
+	page, limit, offset := utils.GetPaginationParams(ctx)
+
+	templates, totalRecords, err := ac.templateService.ListTemplates(limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to retrieve templates", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, totalRecords)
+
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "templates", templates, pagination, nil)
+}
+
+func (ac *AssessmentController) GetAssessmentTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid template id", nil, err)
+		return
+	}
+
+	resp, err := ac.templateService.GetTemplate(templateID)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Template not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "template", resp, nil, nil)
+}
+
+func (ac *AssessmentController) DeleteAssessmentTemplate(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	templateID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "invalid template id", nil, err)
+		return
+	}
+
+	if err := ac.templateService.DeleteTemplate(templateID, userId); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusNotFound, "Template not found", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Template deleted", nil, nil, nil)
+}
+
+func (ac *AssessmentController) CreateAssessmentFromTemplate(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.CreateFromTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.templateService.CreateFromTemplate(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Failed to create assessment from template", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment created from template", resp, nil, nil)
+}
+
+func (ac *AssessmentController) CloneAssessmentToCenters(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.CloneToCentersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.templateService.CloneToCenters(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Failed to clone assessment to centers", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment cloned to centers", resp, nil, nil)
+}
+
 func (ac *AssessmentController) CreateAssessment(ctx *gin.Context) {
 	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
 	if err != nil {
@@ -312,6 +811,28 @@ func (ac *AssessmentController) GenerateAssessmentWithAI(ctx *gin.Context) {
 	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment generated successfully", response, nil, nil)
 }
 
+func (ac *AssessmentController) GenerateAssessmentFromJob(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+
+	var req models.GenerateJobAssessmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, "Invalid input", http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+
+	resp, err := ac.jobAssessmentService.GenerateAssessmentFromJob(ctx.Request.Context(), req, userId)
+	if err != nil {
+		models.ErrorResponse(ctx, "Failed to generate assessment from job description", http.StatusInternalServerError, err.Error(), nil, err)
+		return
+	}
+
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Draft assessment generated from job description", resp, nil, nil)
+}
+
 func (ac *AssessmentController) SaveGeneratedAssessment(ctx *gin.Context) {
 	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
 	if err != nil {
@@ -363,7 +884,7 @@ func (ac *AssessmentController) SaveGeneratedAssessment(ctx *gin.Context) {
 		}
 	}
 
-	response, err := ac.assessmentService.CreateAssessmentViaFileUpload(nil, "", userId, &req.Assessment)
+	response, err := ac.assessmentService.CreateAssessmentViaFileUpload(nil, "", userId, &req.Assessment, req.DuplicateAction)
 	if err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to save assessment", nil, err)
 		return
@@ -470,7 +991,7 @@ func (ac *AssessmentController) StartAssessment(ctx *gin.Context) {
 		return
 	}
 
-	sessionID, err := ac.assessmentService.StartAssessment(userId, req.AssessmentSequence)
+	sessionID, locale, err := ac.assessmentService.StartAssessment(userId, req.AssessmentSequence, req.Locale)
 	if err != nil {
 		models.ErrorResponse(ctx, "Failed to start assessment", http.StatusInternalServerError, err.Error(), nil, err)
 		return
@@ -479,6 +1000,7 @@ func (ac *AssessmentController) StartAssessment(ctx *gin.Context) {
 	response := models.StartAssessmentResponse{
 		SessionID:          sessionID,
 		AssessmentSequence: req.AssessmentSequence,
+		Locale:             locale,
 	}
 
 	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Assessment started", response, nil, nil)
@@ -502,7 +1024,7 @@ func (ac *AssessmentController) GetUserAssessmentResult(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := ac.assessmentService.GetAdminAssessmentUserResult(req.AssessmentSequence, userId)
+	resp, err := ac.assessmentService.GetUserAssessmentResult(req.AssessmentSequence, userId)
 	if err != nil {
 		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, err.Error(), nil, err)
 		return
@@ -511,6 +1033,54 @@ func (ac *AssessmentController) GetUserAssessmentResult(ctx *gin.Context) {
 	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "result", resp, nil, nil)
 }
 
+// FileExtensionRequest asks the user's manager for a later due date on an assignment.
+func (ac *AssessmentController) FileExtensionRequest(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.FileExtensionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+
+	ext, err := ac.deadlineService.FileExtension(ctx.Request.Context(), req, userId)
+	if errors.Is(err, services.ErrExtensionPending) {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusConflict, err.Error(), nil, err)
+		return
+	}
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Extension requested", ext, nil, nil)
+}
+
+// GetMyExtensionRequests lists the extension requests the user filed.
+func (ac *AssessmentController) GetMyExtensionRequests(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	filter := models.ExtensionFilter{
+		UserID:             userId,
+		AssessmentSequence: ctx.Query("assessment_sequence"),
+		Status:             ctx.Query("status"),
+	}
+
+	list, total, err := ac.deadlineService.ListExtensions(filter, string(constant.User), userId, limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Extension requests fetched", list, pagination, nil)
+}
+
 func (c *AssessmentController) DeleteAssessment(ctx *gin.Context) {
 	assessmentSeq := ctx.Param("id")
 
@@ -526,4 +1096,151 @@ func (c *AssessmentController) DeleteAssessment(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Assessment deleted successfully"})
-}
\ No newline at end of file
+}
+
+// GetMyNotifications lists the user's in-app notifications, only the unread ones with
+// "unread=true".
+func (ac *AssessmentController) GetMyNotifications(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	page, limit, offset := utils.GetPaginationParams(ctx)
+	list, total, err := ac.inboxService.List(userId, ctx.Query("unread") == "true", limit, offset)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch notifications", nil, err)
+		return
+	}
+	pagination := utils.GetPagination(limit, page, offset, total)
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notifications fetched", list, pagination, nil)
+}
+
+func (ac *AssessmentController) GetUnreadNotificationCount(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	count, err := ac.inboxService.UnreadCount(userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to count notifications", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Unread notifications counted", models.UnreadNotifications{Unread: count}, nil, nil)
+}
+
+func (ac *AssessmentController) MarkNotificationsRead(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.MarkNotificationsReadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	marked, err := ac.inboxService.MarkRead(userId, req.IDs)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to mark notifications read", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notifications marked read", gin.H{"marked": marked}, nil, nil)
+}
+
+func (ac *AssessmentController) MarkAllNotificationsRead(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	marked, err := ac.inboxService.MarkAllRead(userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to mark notifications read", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notifications marked read", gin.H{"marked": marked}, nil, nil)
+}
+
+// GetNotificationPreferences returns the channels the current user gets each category of
+// notifications through.
+func (ac *AssessmentController) GetNotificationPreferences(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	prefs, err := ac.preferenceService.Get(userId)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusInternalServerError, "Failed to fetch notification preferences", nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification preferences fetched", prefs, nil, nil)
+}
+
+// SaveNotificationPreferences changes the current user's default channels and those of the
+// categories given.
+func (ac *AssessmentController) SaveNotificationPreferences(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+	var req models.SaveNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, "Invalid request body", nil, err)
+		return
+	}
+	prefs, err := ac.preferenceService.Save(ctx.Request.Context(), userId, req)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusBadRequest, err.Error(), nil, err)
+		return
+	}
+	models.SuccessResponse(ctx, constant.Success, http.StatusOK, "Notification preferences saved", prefs, nil, nil)
+}
+
+// StreamNotifications keeps a Server-Sent Events stream open and sends an "unread" event with
+// the user's unread count when the stream opens and whenever the count changes. The count is
+// read from the database, so notifications queued by any instance show up.
+func (ac *AssessmentController) StreamNotifications(ctx *gin.Context) {
+	_, userId, _, err := utils.GetUserIDFromContext(ctx, ac.userService.FindUserIdBySub)
+	if err != nil {
+		models.ErrorResponse(ctx, constant.Failure, http.StatusUnauthorized, err.Error(), nil, err)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(config.PropConfig.Inbox.StreamInterval)
+	defer ticker.Stop()
+	shutdown := utils.Shutdown(ctx.Request.Context())
+	last := int64(-1)
+	ctx.Stream(func(w io.Writer) bool {
+		if last >= 0 {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case <-shutdown:
+				return false
+			case <-ticker.C:
+			}
+		}
+		count, err := ac.inboxService.UnreadCount(userId)
+		if err != nil {
+			log.Printf("[ERROR] notification stream of %s: %v", userId, err)
+			return false
+		}
+		if count != last {
+			ctx.SSEvent("unread", models.UnreadNotifications{Unread: count})
+			last = count
+		} else {
+			// a comment keeps proxies from closing an idle stream
+			io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"dhl/router"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -38,11 +36,7 @@ func main() {
 	config.Log.Info("ENV profile active", zap.String("env", env))
 	config.InitKeycloak()
 	database.InitDB()
+	// Routing returns once the server has shut down on SIGINT or SIGTERM.
 	router.Routing(env)
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
-
-	log.Println("Shutting down server...")
 	log.Println("Server gracefully stopped.")
 }
@@ -0,0 +1,23 @@
+-- Uploaded image and audio files referenced by question and option content.
+CREATE TABLE IF NOT EXISTS media_mst (
+    media_id    BIGSERIAL PRIMARY KEY,
+    storage_key VARCHAR(255) NOT NULL,
+    file_name   VARCHAR(255) NOT NULL DEFAULT '',
+    mime_type   VARCHAR(100) NOT NULL DEFAULT '',
+    size_bytes  BIGINT NOT NULL DEFAULT 0,
+    checksum    VARCHAR(64) NOT NULL DEFAULT '',
+    created_on  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    is_deleted  BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS media_mst_storage_key_idx ON media_mst (storage_key);
+
+-- Content types beyond plain text (constant.ContentType*).
+INSERT INTO content_type_config (content_type_id, content_type) VALUES
+    (1, 'text'),
+    (2, 'image'),
+    (3, 'audio'),
+    (4, 'html'),
+    (5, 'markdown')
+ON CONFLICT (content_type_id) DO NOTHING;
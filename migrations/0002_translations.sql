@@ -0,0 +1,25 @@
+-- Per-locale text of questions, options and assessments, and the locale a session runs in.
+CREATE TABLE IF NOT EXISTS content_translation (
+    content_id  BIGINT NOT NULL,
+    locale      VARCHAR(35) NOT NULL,
+    value       TEXT NOT NULL DEFAULT '',
+    created_on  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (content_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS assessment_translation (
+    assessment_sequence VARCHAR(255) NOT NULL,
+    locale              VARCHAR(35) NOT NULL,
+    title               TEXT NOT NULL DEFAULT '',
+    instructions        TEXT NOT NULL DEFAULT '',
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by         VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (assessment_sequence, locale)
+);
+
+ALTER TABLE assessment_user_session ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
@@ -0,0 +1,31 @@
+-- Blueprints that assemble assessments from the question bank, and the blueprint an
+-- assessment was assembled from.
+CREATE TABLE IF NOT EXISTS assessment_blueprint (
+    blueprint_id    BIGSERIAL PRIMARY KEY,
+    created_on      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    is_active       BOOLEAN NOT NULL DEFAULT true,
+    is_deleted      BOOLEAN NOT NULL DEFAULT false,
+    modified_on     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by     VARCHAR(255) NOT NULL DEFAULT '',
+    name            VARCHAR(255) NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    assessment_type VARCHAR(50) NOT NULL DEFAULT '',
+    duration        BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS blueprint_constraint (
+    constraint_id       BIGSERIAL PRIMARY KEY,
+    blueprint_id        BIGINT NOT NULL REFERENCES assessment_blueprint (blueprint_id) ON DELETE CASCADE,
+    sequence_id         BIGINT NOT NULL DEFAULT 0,
+    tag                 VARCHAR(255) NOT NULL DEFAULT '',
+    difficulty_level    VARCHAR(20) NOT NULL DEFAULT '',
+    question_count      INTEGER NOT NULL,
+    points              BIGINT NOT NULL DEFAULT 0,
+    negative_points     BIGINT NOT NULL DEFAULT 0,
+    duration_in_seconds BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS blueprint_constraint_blueprint_idx ON blueprint_constraint (blueprint_id);
+
+ALTER TABLE assessment_mst ADD COLUMN IF NOT EXISTS blueprint_id BIGINT;
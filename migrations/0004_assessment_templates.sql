@@ -0,0 +1,38 @@
+-- Reusable assessment settings with the source assessment or blueprint to take questions from.
+CREATE TABLE IF NOT EXISTS assessment_template (
+    template_id                BIGSERIAL PRIMARY KEY,
+    name                       VARCHAR(255) NOT NULL,
+    description                TEXT NOT NULL DEFAULT '',
+    source_assessment_sequence VARCHAR(255) NOT NULL DEFAULT '',
+    blueprint_id               BIGINT,
+    assessment_type            VARCHAR(50) NOT NULL DEFAULT '',
+    duration                   BIGINT NOT NULL DEFAULT 0,
+    instructions               TEXT NOT NULL DEFAULT '',
+    is_time_limited            BOOLEAN NOT NULL DEFAULT false,
+    time_limit                 DOUBLE PRECISION NOT NULL DEFAULT 0,
+    is_attempts_limited        BOOLEAN NOT NULL DEFAULT false,
+    attempts_limit             INTEGER NOT NULL DEFAULT 0,
+    certificate                BOOLEAN NOT NULL DEFAULT false,
+    show_result                BOOLEAN NOT NULL DEFAULT false,
+    users_login_required       BOOLEAN NOT NULL DEFAULT false,
+    users_can_go_back          BOOLEAN NOT NULL DEFAULT false,
+    scoring_type               VARCHAR(50) NOT NULL DEFAULT '',
+    questions_layout           VARCHAR(50) NOT NULL DEFAULT '',
+    questions_selection        VARCHAR(50) NOT NULL DEFAULT '',
+    no_of_random_questions     INTEGER NOT NULL DEFAULT 0,
+    is_typing_test             BOOLEAN NOT NULL DEFAULT false,
+    typing_test_time           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    thank_you_message          TEXT NOT NULL DEFAULT '',
+    center_id                  INTEGER NOT NULL DEFAULT 0,
+    service_line_id            INTEGER NOT NULL DEFAULT 0,
+    business_partner_id        INTEGER NOT NULL DEFAULT 0,
+    sub_business_partner_id    INTEGER NOT NULL DEFAULT 0,
+    service_group_id           INTEGER NOT NULL DEFAULT 0,
+    service_id                 INTEGER NOT NULL DEFAULT 0,
+    created_on                 TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by                 VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on                TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by                VARCHAR(255) NOT NULL DEFAULT '',
+    is_active                  BOOLEAN NOT NULL DEFAULT true,
+    is_deleted                 BOOLEAN NOT NULL DEFAULT false
+);
@@ -0,0 +1,17 @@
+-- Values changed by bulk question edits, grouped by batch.
+CREATE TABLE IF NOT EXISTS question_edit_history (
+    history_id          BIGSERIAL PRIMARY KEY,
+    batch_id            VARCHAR(64) NOT NULL,
+    operation           VARCHAR(50) NOT NULL,
+    question_id         BIGINT NOT NULL,
+    option_id           BIGINT,
+    assessment_sequence VARCHAR(255) NOT NULL DEFAULT '',
+    field               VARCHAR(50) NOT NULL,
+    old_value           TEXT NOT NULL DEFAULT '',
+    new_value           TEXT NOT NULL DEFAULT '',
+    edited_on           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    edited_by           VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS question_edit_history_batch_idx ON question_edit_history (batch_id);
+CREATE INDEX IF NOT EXISTS question_edit_history_question_idx ON question_edit_history (question_id);
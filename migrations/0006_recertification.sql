@@ -0,0 +1,30 @@
+-- Recurring assessments and the certifications users earn by passing them.
+CREATE TABLE IF NOT EXISTS assessment_recurrence (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL UNIQUE,
+    interval_months     INTEGER NOT NULL,
+    notify_days_before  INTEGER NOT NULL DEFAULT 0,
+    current_cycle       INTEGER NOT NULL DEFAULT 1,
+    cycle_started_on    TIMESTAMPTZ NOT NULL,
+    next_cycle_on       TIMESTAMPTZ NOT NULL,
+    is_active           BOOLEAN NOT NULL DEFAULT true,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by         VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS user_certification (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL,
+    user_id             VARCHAR(255) NOT NULL,
+    session_id          VARCHAR(255) NOT NULL UNIQUE,
+    cycle               INTEGER NOT NULL DEFAULT 1,
+    issued_on           TIMESTAMPTZ NOT NULL,
+    expires_on          TIMESTAMPTZ NOT NULL,
+    expiry_notified_on  TIMESTAMPTZ,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_certification_assessment_idx ON user_certification (assessment_sequence);
+CREATE INDEX IF NOT EXISTS user_certification_user_idx ON user_certification (user_id);
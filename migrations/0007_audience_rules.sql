@@ -0,0 +1,38 @@
+-- Saved user selections and the assessments assigned to them.
+CREATE TABLE IF NOT EXISTS audience_rule (
+    rule_id     BIGSERIAL PRIMARY KEY,
+    created_on  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    is_active   BOOLEAN NOT NULL DEFAULT true,
+    is_deleted  BOOLEAN NOT NULL DEFAULT false,
+    modified_on TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by VARCHAR(255) NOT NULL DEFAULT '',
+    name        VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS audience_condition (
+    condition_id BIGSERIAL PRIMARY KEY,
+    rule_id      BIGINT NOT NULL REFERENCES audience_rule (rule_id) ON DELETE CASCADE,
+    sequence_id  BIGINT NOT NULL DEFAULT 0,
+    field        VARCHAR(50) NOT NULL,
+    operator     VARCHAR(20) NOT NULL,
+    match_values TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS audience_condition_rule_idx ON audience_condition (rule_id);
+
+CREATE TABLE IF NOT EXISTS assessment_audience (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL,
+    rule_id             BIGINT NOT NULL REFERENCES audience_rule (rule_id),
+    auto_assign         BOOLEAN NOT NULL DEFAULT false,
+    is_active           BOOLEAN NOT NULL DEFAULT true,
+    last_synced_on      TIMESTAMPTZ,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by         VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS assessment_audience_assessment_idx ON assessment_audience (assessment_sequence);
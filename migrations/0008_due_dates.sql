@@ -0,0 +1,19 @@
+-- Per-user due dates and the extension requests that move them.
+ALTER TABLE assessment_status ADD COLUMN IF NOT EXISTS due_date TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS assessment_extension_request (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL,
+    user_id             VARCHAR(255) NOT NULL,
+    current_due_date    TIMESTAMPTZ,
+    requested_due_date  TIMESTAMPTZ NOT NULL,
+    reason              TEXT NOT NULL DEFAULT '',
+    status              VARCHAR(20) NOT NULL,
+    granted_due_date    TIMESTAMPTZ,
+    review_comment      TEXT NOT NULL DEFAULT '',
+    reviewed_by         VARCHAR(255),
+    reviewed_on         TIMESTAMPTZ,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS assessment_extension_request_user_idx ON assessment_extension_request (assessment_sequence, user_id);
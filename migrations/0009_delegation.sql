@@ -0,0 +1,13 @@
+-- Who handed an assessment to whom down the manager hierarchy.
+CREATE TABLE IF NOT EXISTS assessment_delegation (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL,
+    delegated_by        VARCHAR(255) NOT NULL,
+    delegated_to        VARCHAR(255) NOT NULL,
+    level               INTEGER NOT NULL DEFAULT 0,
+    hierarchy           VARCHAR(50) NOT NULL DEFAULT '',
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (assessment_sequence, delegated_to)
+);
+
+CREATE INDEX IF NOT EXISTS assessment_delegation_by_idx ON assessment_delegation (assessment_sequence, delegated_by);
@@ -0,0 +1,29 @@
+-- Per-assessment reminder cadence and the reminders already sent.
+CREATE TABLE IF NOT EXISTS assessment_reminder_policy (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL UNIQUE,
+    enabled             BOOLEAN NOT NULL DEFAULT true,
+    days_before         INTEGER[] NOT NULL DEFAULT '{}',
+    on_due_date         BOOLEAN NOT NULL DEFAULT false,
+    overdue_every_days  INTEGER NOT NULL DEFAULT 0,
+    escalate_after_days INTEGER NOT NULL DEFAULT 0,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by         VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS assessment_reminder_log (
+    id                  BIGSERIAL PRIMARY KEY,
+    assessment_sequence VARCHAR(255) NOT NULL,
+    user_id             VARCHAR(255) NOT NULL,
+    recipient_id        VARCHAR(255) NOT NULL,
+    event               VARCHAR(50) NOT NULL,
+    event_key           VARCHAR(50) NOT NULL,
+    due_date            TIMESTAMPTZ NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    error               TEXT NOT NULL DEFAULT '',
+    sent_on             TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS assessment_reminder_log_user_idx ON assessment_reminder_log (assessment_sequence, user_id, event_key);
@@ -0,0 +1,35 @@
+-- Notifications waiting to be delivered by the dispatcher. Reminder log rows now record when
+-- a reminder was queued; delivery status lives on the outbox row.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id                  BIGSERIAL PRIMARY KEY,
+    kind                VARCHAR(50) NOT NULL,
+    recipient_id        VARCHAR(255) NOT NULL,
+    assessment_sequence VARCHAR(255) NOT NULL DEFAULT '',
+    template            INTEGER NOT NULL DEFAULT 0,
+    payload             JSONB NOT NULL DEFAULT '{}',
+    status              VARCHAR(20) NOT NULL,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    next_attempt_on     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error          TEXT NOT NULL DEFAULT '',
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_on             TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_due_idx ON notification_outbox (status, next_attempt_on);
+
+ALTER TABLE assessment_reminder_log ADD COLUMN IF NOT EXISTS queued_on TIMESTAMPTZ NOT NULL DEFAULT now();
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'assessment_reminder_log' AND column_name = 'sent_on') THEN
+        -- Failed reminders are retried through the outbox from now on.
+        DELETE FROM assessment_reminder_log WHERE status <> 'sent';
+        UPDATE assessment_reminder_log SET queued_on = sent_on;
+    END IF;
+END $$;
+
+ALTER TABLE assessment_reminder_log
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS error,
+    DROP COLUMN IF EXISTS sent_on;
@@ -0,0 +1,19 @@
+-- Per-locale notification templates, template keys on outbox rows and the user's locale.
+CREATE TABLE IF NOT EXISTS notification_template (
+    id           BIGSERIAL PRIMARY KEY,
+    template_key VARCHAR(50) NOT NULL,
+    locale       VARCHAR(35) NOT NULL DEFAULT '',
+    subject      TEXT NOT NULL,
+    body         TEXT NOT NULL,
+    notify_code  INTEGER NOT NULL DEFAULT 0,
+    created_on   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by   VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by  VARCHAR(255) NOT NULL DEFAULT '',
+    UNIQUE (template_key, locale)
+);
+
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS template_key VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS template;
+
+ALTER TABLE assessment_user_mst ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT '';
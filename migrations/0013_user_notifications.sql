@@ -0,0 +1,14 @@
+-- The in-app notification inbox.
+CREATE TABLE IF NOT EXISTS user_notification (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             VARCHAR(255) NOT NULL,
+    kind                VARCHAR(50) NOT NULL,
+    template_key        VARCHAR(50) NOT NULL DEFAULT '',
+    assessment_sequence VARCHAR(255) NOT NULL DEFAULT '',
+    payload             JSONB NOT NULL DEFAULT '{}',
+    is_read             BOOLEAN NOT NULL DEFAULT false,
+    read_on             TIMESTAMPTZ,
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_notification_user_idx ON user_notification (user_id, is_read, created_on DESC);
@@ -0,0 +1,31 @@
+-- Outbound webhook endpoints and their delivery log.
+CREATE TABLE IF NOT EXISTS webhook_endpoint (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    url         TEXT NOT NULL,
+    secret      VARCHAR(255) NOT NULL DEFAULT '',
+    events      TEXT[] NOT NULL DEFAULT '{}',
+    is_active   BOOLEAN NOT NULL DEFAULT true,
+    created_on  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id              BIGSERIAL PRIMARY KEY,
+    endpoint_id     BIGINT NOT NULL REFERENCES webhook_endpoint (id) ON DELETE CASCADE,
+    event_id        VARCHAR(64) NOT NULL,
+    event           VARCHAR(50) NOT NULL,
+    payload         JSONB NOT NULL DEFAULT '{}',
+    status          VARCHAR(20) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_on TIMESTAMPTZ NOT NULL DEFAULT now(),
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_on      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_on    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (status, next_attempt_on);
+CREATE INDEX IF NOT EXISTS webhook_delivery_endpoint_idx ON webhook_delivery (endpoint_id, created_on DESC);
@@ -0,0 +1,10 @@
+-- Manager and admin digest subscriptions.
+CREATE TABLE IF NOT EXISTS digest_subscription (
+    user_id      VARCHAR(255) PRIMARY KEY,
+    frequency    VARCHAR(20) NOT NULL,
+    weekday      INTEGER NOT NULL DEFAULT 1,
+    scope        VARCHAR(20) NOT NULL DEFAULT '',
+    last_sent_on TIMESTAMPTZ,
+    created_on   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_on  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
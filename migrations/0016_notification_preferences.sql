@@ -0,0 +1,22 @@
+-- Per-category channel choices of users and the notifications they suppressed.
+CREATE TABLE IF NOT EXISTS user_notification_preference (
+    user_id     VARCHAR(255) NOT NULL,
+    category    VARCHAR(50) NOT NULL,
+    email       BOOLEAN NOT NULL DEFAULT true,
+    in_app      BOOLEAN NOT NULL DEFAULT true,
+    modified_on TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, category)
+);
+
+CREATE TABLE IF NOT EXISTS notification_suppression (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             VARCHAR(255) NOT NULL,
+    kind                VARCHAR(50) NOT NULL,
+    category            VARCHAR(50) NOT NULL,
+    channel             VARCHAR(20) NOT NULL,
+    template_key        VARCHAR(50) NOT NULL DEFAULT '',
+    assessment_sequence VARCHAR(255) NOT NULL DEFAULT '',
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notification_suppression_user_idx ON notification_suppression (user_id, created_on DESC);
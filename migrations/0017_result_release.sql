@@ -0,0 +1,12 @@
+-- When the results of an assessment are released to its users.
+CREATE TABLE IF NOT EXISTS assessment_result_release (
+    assessment_sequence VARCHAR(255) PRIMARY KEY,
+    mode                VARCHAR(20) NOT NULL,
+    release_on          TIMESTAMPTZ,
+    released_on         TIMESTAMPTZ,
+    released_by         VARCHAR(255) NOT NULL DEFAULT '',
+    created_on          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    modified_on         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_by         VARCHAR(255) NOT NULL DEFAULT ''
+);
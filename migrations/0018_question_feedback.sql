@@ -0,0 +1,4 @@
+-- Feedback imported with GIFT and Moodle XML questions: general feedback on the question and
+-- per-answer feedback on its options.
+ALTER TABLE question_mst ADD COLUMN IF NOT EXISTS feedback TEXT NOT NULL DEFAULT '';
+ALTER TABLE option_mst ADD COLUMN IF NOT EXISTS feedback TEXT NOT NULL DEFAULT '';
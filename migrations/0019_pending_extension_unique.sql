@@ -0,0 +1,16 @@
+-- At most one pending extension request per user and assessment. Older duplicates filed
+-- before the index existed are rejected in favour of the latest one.
+UPDATE assessment_extension_request r
+SET status = 'rejected', review_comment = 'Superseded by a later request', reviewed_on = now()
+WHERE r.status = 'pending'
+  AND EXISTS (
+      SELECT 1 FROM assessment_extension_request later
+      WHERE later.assessment_sequence = r.assessment_sequence
+        AND later.user_id = r.user_id
+        AND later.status = 'pending'
+        AND later.id > r.id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS assessment_extension_request_pending_idx
+    ON assessment_extension_request (assessment_sequence, user_id)
+    WHERE status = 'pending';
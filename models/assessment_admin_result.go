@@ -12,6 +12,7 @@ type AssessmentAttemptResult struct {
 	TotalMarks       int64                     `json:"total_marks"`
 	ObtainedMarks    int64                     `json:"obtained_marks"`
 	CompletionStatus string                    `json:"completion_status"`
+	Locale           string                    `json:"locale"`
 	Questions        []AdminQuestionResult     `json:"questions"`
 }
 
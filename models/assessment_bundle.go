@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// AssessmentBundle is the portable, versioned representation of an assessment used to move
+// assessments between environments.
+type AssessmentBundle struct {
+	Version        int                `json:"version"`
+	ExportedOn     time.Time          `json:"exported_on"`
+	SourceSequence string             `json:"source_sequence"`
+	Assessment     BundleAssessment   `json:"assessment"`
+	Settings       DhlSurveySurveyExt `json:"settings"`
+	Job            *BundleJob         `json:"job,omitempty"`
+	Tags           []TagRequest       `json:"tags,omitempty"`
+	Questions      []BundleQuestion   `json:"questions"`
+}
+
+type BundleAssessment struct {
+	Name            string                       `json:"name"`
+	Duration        int64                        `json:"duration"`
+	Marks           int64                        `json:"marks"`
+	StartTime       *string                      `json:"start_time,omitempty"`
+	PartnerID       int64                        `json:"partner_id,omitempty"`
+	ValidFrom       *time.Time                   `json:"valid_from,omitempty"`
+	ValidTo         *time.Time                   `json:"valid_to,omitempty"`
+	NoFixedSchedule bool                         `json:"no_fixed_schedule"`
+	Instructions    string                       `json:"instructions,omitempty"`
+	AssessmentType  string                       `json:"assessment_type"`
+	Translations    map[string]BundleTranslation `json:"translations,omitempty"`
+}
+
+// BundleTranslation is the assessment title and instructions in one locale.
+type BundleTranslation struct {
+	Title        string `json:"title,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// BundleJob carries the linked job description by value, since job IDs differ between environments.
+type BundleJob struct {
+	Title          string `json:"title"`
+	Description    string `json:"description,omitempty"`
+	RequiredSkills string `json:"required_skills,omitempty"`
+	Level          string `json:"level,omitempty"`
+}
+
+type BundleQuestion struct {
+	Sequence          int64             `json:"sequence"`
+	Title             string            `json:"title"`
+	QuestionType      string            `json:"question_type"`
+	MandatoryToAnswer bool              `json:"mandatory_to_answer"`
+	CorrectPoints     int64             `json:"correct_points"`
+	NegativePoints    int64             `json:"negative_points"`
+	DurationInSeconds int64             `json:"duration_in_seconds"`
+	DifficultyLevel   string            `json:"difficulty_level,omitempty"`
+	Options           []BundleOption    `json:"options"`
+	Tags              []TagRequest      `json:"tags,omitempty"`
+	Translations      map[string]string `json:"translations,omitempty"`
+	Feedback          string            `json:"feedback,omitempty"`
+}
+
+type BundleOption struct {
+	Sequence     int64             `json:"sequence"`
+	Label        string            `json:"label"`
+	IsCorrect    bool              `json:"is_correct"`
+	Score        int               `json:"score"`
+	Translations map[string]string `json:"translations,omitempty"`
+	Feedback     string            `json:"feedback,omitempty"`
+}
+
+type ImportBundleResponse struct {
+	AssessmentSequence string `json:"assessment_sequence"`
+	AssessmentName     string `json:"assessment_name"`
+	QuestionsCount     int    `json:"questions_count"`
+	JobID              *int64 `json:"job_id,omitempty"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// AssessmentDelegation records who handed an assessment to whom: an admin to a manager, or a
+// manager to a user under them. Each user is delegated an assessment at most once.
+type AssessmentDelegation struct {
+	ID                 int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	DelegatedBy        string `gorm:"column:delegated_by" json:"delegated_by"`
+	DelegatedTo        string `gorm:"column:delegated_to" json:"delegated_to"`
+	// Level is 0 for a manager picked by an admin and grows by one down each reporting line.
+	Level           int       `gorm:"column:level" json:"level"`
+	Hierarchy       string    `gorm:"column:hierarchy" json:"hierarchy"`
+	CreatedOn       time.Time `gorm:"column:created_on" json:"created_on"`
+	DelegatedByName string    `gorm:"column:delegated_by_name;->;-:migration" json:"delegated_by_name,omitempty"`
+	DelegatedToName string    `gorm:"column:delegated_to_name;->;-:migration" json:"delegated_to_name,omitempty"`
+	AssessmentTitle string    `gorm:"column:assessment_title;->;-:migration" json:"assessment_title,omitempty"`
+}
+
+func (AssessmentDelegation) TableName() string {
+	return "assessment_delegation"
+}
+
+// Subordinate is a user reporting to ManagerID in some hierarchy.
+type Subordinate struct {
+	ManagerID string `gorm:"column:manager_id"`
+	UserID    string `gorm:"column:user_id"`
+}
+
+type DelegationResult struct {
+	Managers int `json:"managers"`
+	// Assigned lists the users the cascade newly assigned the assessment to.
+	Assigned []string `json:"assigned"`
+	// AlreadyAssigned counts users under the managers who had the assessment already.
+	AlreadyAssigned int `json:"already_assigned"`
+}
+
+type DelegationFilter struct {
+	AssessmentSequence string
+	DelegatedBy        string
+}
+
+// TeamAssignment is the progress of one user in a manager's delegation subtree.
+type TeamAssignment struct {
+	UserID          string     `gorm:"column:user_id" json:"user_id"`
+	UserName        string     `gorm:"column:user_name" json:"user_name"`
+	Email           string     `gorm:"column:email" json:"email"`
+	DelegatedBy     string     `gorm:"column:delegated_by" json:"delegated_by"`
+	DelegatedByName string     `gorm:"column:delegated_by_name" json:"delegated_by_name"`
+	Level           int        `gorm:"column:level" json:"level"`
+	Status          string     `gorm:"column:status" json:"status"`
+	DueDate         *time.Time `gorm:"column:due_date" json:"due_date"`
+	IsOverdue       bool       `gorm:"column:is_overdue" json:"is_overdue"`
+}
+
+type TeamProgressSummary struct {
+	Total     int64 `gorm:"column:total" json:"total"`
+	Pending   int64 `gorm:"column:pending" json:"pending"`
+	Completed int64 `gorm:"column:completed" json:"completed"`
+	Expired   int64 `gorm:"column:expired" json:"expired"`
+	Overdue   int64 `gorm:"column:overdue" json:"overdue"`
+}
+
+type TeamProgress struct {
+	Summary TeamProgressSummary `json:"summary"`
+	Users   []TeamAssignment    `json:"users"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// AssessmentExtensionRequest is a user's request to move their due date, reviewed by a
+// manager or an admin.
+type AssessmentExtensionRequest struct {
+	ID                 int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string     `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	UserID             string     `gorm:"column:user_id" json:"user_id"`
+	CurrentDueDate     *time.Time `gorm:"column:current_due_date" json:"current_due_date"`
+	RequestedDueDate   time.Time  `gorm:"column:requested_due_date" json:"requested_due_date"`
+	Reason             string     `gorm:"column:reason" json:"reason"`
+	Status             string     `gorm:"column:status" json:"status"`
+	GrantedDueDate     *time.Time `gorm:"column:granted_due_date" json:"granted_due_date,omitempty"`
+	ReviewComment      string     `gorm:"column:review_comment" json:"review_comment,omitempty"`
+	ReviewedBy         *string    `gorm:"column:reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedOn         *time.Time `gorm:"column:reviewed_on" json:"reviewed_on,omitempty"`
+	CreatedOn          time.Time  `gorm:"column:created_on" json:"created_on"`
+	UserName           string     `gorm:"column:user_name;->;-:migration" json:"user_name,omitempty"`
+	AssessmentTitle    string     `gorm:"column:assessment_title;->;-:migration" json:"assessment_title,omitempty"`
+}
+
+func (AssessmentExtensionRequest) TableName() string {
+	return "assessment_extension_request"
+}
+
+// SetDueDateRequest sets or, with a null due_date, clears the due date of assigned users.
+type SetDueDateRequest struct {
+	AssessmentSequence string     `json:"assessment_sequence" binding:"required"`
+	UserIDs            []string   `json:"user_ids" binding:"required,min=1"`
+	DueDate            *time.Time `json:"due_date"`
+}
+
+type FileExtensionRequest struct {
+	AssessmentSequence string    `json:"assessment_sequence" binding:"required"`
+	RequestedDueDate   time.Time `json:"requested_due_date" binding:"required"`
+	Reason             string    `json:"reason" binding:"required"`
+}
+
+// ReviewExtensionRequest approves or rejects a request. An approval may grant another date
+// than the one requested.
+type ReviewExtensionRequest struct {
+	Approve bool       `json:"approve"`
+	DueDate *time.Time `json:"due_date"`
+	Comment string     `json:"comment"`
+}
+
+type ExtensionFilter struct {
+	UserID             string
+	ManagerID          string
+	AssessmentSequence string
+	Status             string
+}
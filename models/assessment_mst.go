@@ -29,6 +29,7 @@ type AssessmentMst struct {
 	NoFixedSchedule    bool       `gorm:"column:no_fixed_schedule"`
 	Instructions       string     `gorm:"column:instructions"`
 	JobID              *int64     `gorm:"column:job_id"`
+	BlueprintID        *int64     `gorm:"column:blueprint_id"`
 	AssessmentType     string     `gorm:"column:assessment_type"`
 }
 
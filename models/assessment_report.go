@@ -12,6 +12,8 @@ type AssessmentReportFilter struct {
 	Status             string     `json:"status"`
 	SkillSet           string     `json:"skill_set"`
 	QuestionnaireTitle string     `json:"questionnaire_title"`
+	// Overdue keeps only unfinished assignments past their due date.
+	Overdue bool `json:"overdue"`
 }
 
 type AssessmentReportRow struct {
@@ -32,7 +34,10 @@ type AssessmentReportRow struct {
 	TotalFailed     int `json:"total_failed"`
 	TotalNotStarted int `json:"total_not_started"`
 
-	AssessmentSequence string `json:"assessment_sequence"`
+	AssessmentSequence string     `json:"assessment_sequence"`
+	Locale             string     `json:"locale"`
+	DueDate            *time.Time `json:"due_date"`
+	IsOverdue          bool       `json:"is_overdue"`
 }
 
 type AssessmentReportResponse struct {
@@ -42,26 +42,33 @@ type AssessmentResponse struct {
 	Questions             []AssessmentQuestion `json:"questions"`
 	SessionImage          []byte               `json:"session_image,omitempty"`
 	Tags                  []TagRequest         `json:"tags,omitempty"`
+	Locale                string               `json:"locale,omitempty"`
+	AvailableLocales      []string             `json:"available_locales,omitempty"`
 }
 
 type AssessmentQuestion struct {
-	QuestionID      int          `json:"question_id"`
-	Sequence        int          `json:"sequence"`
-	Title           string       `json:"title"`
-	Answers         []Answer     `json:"answers"`
-	AttemptedAnswer *int         `json:"attempted_answer"`
-	QuestionTime    int          `json:"question_time"`
-	QuestionTypeId  uint64       `json:"question_type_id"`
-	QuestionType    string       `json:"question_type"`
-	SkippingAllowed bool         `json:"skipping_allowed"`
-	Tags            []TagRequest `json:"tags,omitempty"`
+	QuestionID      int              `json:"question_id"`
+	Sequence        int              `json:"sequence"`
+	Title           string           `json:"title"`
+	Answers         []Answer         `json:"answers"`
+	AttemptedAnswer *int             `json:"attempted_answer"`
+	QuestionTime    int              `json:"question_time"`
+	QuestionTypeId  uint64           `json:"question_type_id"`
+	QuestionType    string           `json:"question_type"`
+	SkippingAllowed bool             `json:"skipping_allowed"`
+	Tags            []TagRequest     `json:"tags,omitempty"`
+	Content         *RenderedContent `json:"content,omitempty"`
+	// Feedback is only returned to admins, as are the answers' feedback.
+	Feedback string `json:"feedback,omitempty"`
 }
 
 type Answer struct {
-	AnswerID      int    `json:"option_id"`
-	Sequence      *int64 `json:"sequence"`
-	OptionLabel   string `json:"option_label"`
-	CorrectAnswer bool   `json:"correctAnswer,omitempty"`
+	AnswerID      int              `json:"option_id"`
+	Sequence      *int64           `json:"sequence"`
+	OptionLabel   string           `json:"option_label"`
+	CorrectAnswer bool             `json:"correctAnswer,omitempty"`
+	Content       *RenderedContent `json:"content,omitempty"`
+	Feedback      string           `json:"feedback,omitempty"`
 }
 
 type AssessmentFilter struct {
@@ -97,22 +104,25 @@ type AssessmentListResponse struct {
 	SessionImage        []byte                  `json:"session_image,omitempty" gorm:"-"`
 	JobTitle            *string                 `json:"job_title"`
 	Tags                []TagRequest            `json:"tags" gorm:"-"`
+	IsOverdue           bool                    `json:"is_overdue" gorm:"column:is_overdue"`
 }
 
 type AssessmentAttendeesInfo struct {
-	AssessmentID     string  `json:"assessmentId" gorm:"column:assessment_id"`
-	SessionID        string  `json:"sessionId" gorm:"column:session_id"`
-	UserID           string  `json:"userId" gorm:"column:user_id"`
-	AssessmentStatus string  `json:"assessmentStatus" gorm:"column:assessment_status"`
-	FirstName        string  `json:"firstName" gorm:"column:first_name"`
-	LastName         string  `json:"lastName" gorm:"column:last_name"`
-	Email            string  `json:"email" gorm:"column:email"`
-	CenterName       *string `json:"centerName" gorm:"column:center_name"`
-	TeamLead         *string `json:"teamLead" gorm:"column:team_lead"`
-	Manager          *string `json:"manager" gorm:"column:manager"`
-	SeniorManager    *string `json:"seniorManager" gorm:"column:senior_manager"`
-	SDL              *string `json:"sdl" gorm:"column:sdl"`
-	SLL              *string `json:"sll" gorm:"column:sll"`
+	AssessmentID     string     `json:"assessmentId" gorm:"column:assessment_id"`
+	SessionID        string     `json:"sessionId" gorm:"column:session_id"`
+	UserID           string     `json:"userId" gorm:"column:user_id"`
+	AssessmentStatus string     `json:"assessmentStatus" gorm:"column:assessment_status"`
+	FirstName        string     `json:"firstName" gorm:"column:first_name"`
+	LastName         string     `json:"lastName" gorm:"column:last_name"`
+	Email            string     `json:"email" gorm:"column:email"`
+	CenterName       *string    `json:"centerName" gorm:"column:center_name"`
+	TeamLead         *string    `json:"teamLead" gorm:"column:team_lead"`
+	Manager          *string    `json:"manager" gorm:"column:manager"`
+	SeniorManager    *string    `json:"seniorManager" gorm:"column:senior_manager"`
+	SDL              *string    `json:"sdl" gorm:"column:sdl"`
+	SLL              *string    `json:"sll" gorm:"column:sll"`
+	DueDate          *time.Time `json:"dueDate" gorm:"column:due_date"`
+	IsOverdue        bool       `json:"isOverdue" gorm:"column:is_overdue"`
 }
 
 type AssessmentUserResponse struct {
@@ -129,9 +139,13 @@ type AssessmentUserResponse struct {
 
 // Upload Assessment
 type SheetOption struct {
-	Label     string `json:"label"`
-	IsCorrect bool   `json:"is_correct"`
-	Score     int    `json:"score"`
+	Label     string      `json:"label"`
+	IsCorrect bool        `json:"is_correct"`
+	Score     int         `json:"score"`
+	Feedback  string      `json:"feedback,omitempty"`
+	Media     *SheetMedia `json:"media,omitempty"`
+	// Translations maps a locale to the option label in that language.
+	Translations map[string]string `json:"translations,omitempty"`
 }
 
 // TagRequest represents a tag with optional parent and children
@@ -146,13 +160,28 @@ type SheetQuestion struct {
 	QuestionType      string        `json:"question_type"`
 	Options           []SheetOption `json:"options"`
 	Tags              []TagRequest  `json:"tags,omitempty"`
+	DifficultyLevel   string        `json:"difficulty_level,omitempty"`
+	Points            int64         `json:"points,omitempty"`
+	NegativePoints    int64         `json:"negative_points,omitempty"`
+	DurationInSeconds int64         `json:"duration_in_seconds,omitempty"`
+	Feedback          string        `json:"feedback,omitempty"`
+	Media             *SheetMedia   `json:"media,omitempty"`
+	// Translations maps a locale to the question title in that language.
+	Translations map[string]string `json:"translations,omitempty"`
+	// DuplicateAction overrides the import-wide duplicate handling for this question.
+	DuplicateAction string `json:"duplicate_action,omitempty"`
+	// ExistingQuestionID links an existing bank question instead of creating a new one.
+	ExistingQuestionID int64 `json:"existing_question_id,omitempty"`
 }
 
 type SheetAssessment struct {
-	AssessmentName     string          `json:"assessment_name"`
-	AssessmentSequence string          `json:"assessment_sequence"`
-	Questions          []SheetQuestion `json:"questions"`
-	Tags               []TagRequest    `json:"tags,omitempty"`
+	AssessmentName     string            `json:"assessment_name"`
+	AssessmentSequence string            `json:"assessment_sequence"`
+	Questions          []SheetQuestion   `json:"questions"`
+	Tags               []TagRequest      `json:"tags,omitempty"`
+	NameTranslations   map[string]string `json:"name_translations,omitempty"`
+	// Duplicates reports questions that matched the bank during import.
+	Duplicates []DuplicateWarning `json:"duplicates,omitempty"`
 }
 
 // Update Assessment Data Models
@@ -169,7 +198,7 @@ type AssessmentUpdate struct {
 	AssessmentType       *string    `json:"assessment_type"`
 	Duration             *int64     `json:"duration"`
 	Marks                *int64     `json:"marks"`
-	State                *string    `json:"state"`
+	State                *string    `json:"state" binding:"omitempty,oneof=draft open closed scheduled"`
 	TimeLimit            *int       `json:"time_limit"`
 	CenterId             *int       `json:"center_id"`
 	ServiceLineID        *int       `json:"service_line_id"`
@@ -202,7 +231,7 @@ type OptionDTO struct {
 // Update Assessment Status
 type UpdateAssessmentStatusRequest struct {
 	AssessmentSequence string `json:"assessment_sequence"  binding:"required"`
-	AssessmentStatus   string `json:"assessment_status"  binding:"required"`
+	AssessmentStatus   string `json:"assessment_status"  binding:"required,oneof=draft open closed scheduled"`
 }
 
 type SubmitUserAssessmentRequest struct {
@@ -219,6 +248,13 @@ type UserResponse struct {
 type DistributeAssessmentRequest struct {
 	AssessmentSequence string   `json:"assessment_sequence" binding:"required"`
 	UserIds            []string `json:"user_ids" binding:"required"`
+	// DueDate is the users' own due date; without it the assessment deadline applies.
+	DueDate *time.Time `json:"due_date"`
+	// Cascade, on a manager distribution, also assigns the assessment to the users under each
+	// manager in Hierarchy, and with Recursive to the users under those.
+	Cascade   bool   `json:"cascade"`
+	Hierarchy string `json:"hierarchy" binding:"omitempty,oneof=mapping team_lead manager sdl"`
+	Recursive bool   `json:"recursive"`
 }
 
 type MapUsersToManagerRequest struct {
@@ -236,6 +272,8 @@ type CertificateDetails struct {
 	PassingScore       float64
 	SessionID          string
 	CompletedAt        time.Time
+	// ExpiresOn is set for recurring assessments.
+	ExpiresOn *time.Time
 }
 
 type ManualAssessmentRequest struct {
@@ -274,13 +312,17 @@ type GenerateAssessmentResponse struct {
 
 type SaveGeneratedAssessmentRequest struct {
 	Assessment SheetAssessment `json:"assessment" binding:"required"`
+	// DuplicateAction is warn (default), skip or merge for questions matching the bank.
+	DuplicateAction string `json:"duplicate_action,omitempty"`
 }
 
 type StartAssessmentRequest struct {
 	AssessmentSequence string `json:"assessment_sequence" binding:"required"`
+	Locale             string `json:"locale"`
 }
 
 type StartAssessmentResponse struct {
 	SessionID          string `json:"session_id"`
 	AssessmentSequence string `json:"assessment_sequence"`
+	Locale             string `json:"locale"`
 }
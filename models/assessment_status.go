@@ -13,6 +13,8 @@ type AssessmentStatus struct {
 	AssessmentID     string    `gorm:"column:assessment_id"`
 	AssessmentStatus string    `gorm:"column:assessment_status"`
 	UserID           string    `gorm:"column:user_id"`
+	// DueDate overrides the assessment deadline for this user.
+	DueDate *time.Time `gorm:"column:due_date"`
 }
 
 func (AssessmentStatus) TableName() string {
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"dhl/constant"
+	"time"
+)
+
+// AssessmentSettings are the assessment_mst and dhl_survey_survey_ext values a template
+// carries, including where in the organisation the assessment is placed.
+type AssessmentSettings struct {
+	AssessmentType       string  `gorm:"column:assessment_type" json:"assessment_type"`
+	Duration             int64   `gorm:"column:duration" json:"duration"`
+	Instructions         string  `gorm:"column:instructions" json:"instructions"`
+	IsTimeLimited        bool    `gorm:"column:is_time_limited" json:"is_time_limited"`
+	TimeLimit            float64 `gorm:"column:time_limit" json:"time_limit"`
+	IsAttemptsLimited    bool    `gorm:"column:is_attempts_limited" json:"is_attempts_limited"`
+	AttemptsLimit        int     `gorm:"column:attempts_limit" json:"attempts_limit"`
+	Certificate          bool    `gorm:"column:certificate" json:"certificate"`
+	ShowResult           bool    `gorm:"column:show_result" json:"show_result"`
+	UsersLoginRequired   bool    `gorm:"column:users_login_required" json:"users_login_required"`
+	UsersCanGoBack       bool    `gorm:"column:users_can_go_back" json:"users_can_go_back"`
+	ScoringType          string  `gorm:"column:scoring_type" json:"scoring_type,omitempty"`
+	QuestionsLayout      string  `gorm:"column:questions_layout" json:"questions_layout,omitempty"`
+	QuestionsSelection   string  `gorm:"column:questions_selection" json:"questions_selection,omitempty"`
+	NoOfRandomQuestions  int     `gorm:"column:no_of_random_questions" json:"no_of_random_questions,omitempty"`
+	IsTypingTest         bool    `gorm:"column:is_typing_test" json:"is_typing_test"`
+	TypingTestTime       float64 `gorm:"column:typing_test_time" json:"typing_test_time,omitempty"`
+	ThankYouMessage      string  `gorm:"column:thank_you_message" json:"thank_you_message,omitempty"`
+	CenterID             int     `gorm:"column:center_id" json:"center_id,omitempty"`
+	ServiceLineID        int     `gorm:"column:service_line_id" json:"service_line_id,omitempty"`
+	BusinessPartnerID    int     `gorm:"column:business_partner_id" json:"business_partner_id,omitempty"`
+	SubBusinessPartnerID int     `gorm:"column:sub_business_partner_id" json:"sub_business_partner_id,omitempty"`
+	ServiceGroupID       int     `gorm:"column:service_group_id" json:"service_group_id,omitempty"`
+	ServiceID            int     `gorm:"column:service_id" json:"service_id,omitempty"`
+}
+
+// DefaultAssessmentSettings are the settings of assessments created without any given: uploaded,
+// generated from a job, assembled from a blueprint, or a template's starting point.
+func DefaultAssessmentSettings() AssessmentSettings {
+	return AssessmentSettings{
+		AssessmentType: "survey",
+		Certificate:    true,
+		AttemptsLimit:  1,
+		ShowResult:     true,
+		TimeLimit:      30,
+	}
+}
+
+// DraftSurveyExt is the dhl_survey_survey_ext row of a new draft assessment with the settings.
+func (s AssessmentSettings) DraftSurveyExt(surveyID int64, assessmentSequence string) DhlSurveySurveyExt {
+	return DhlSurveySurveyExt{
+		SurveyID:             surveyID,
+		AssessmentSequence:   assessmentSequence,
+		State:                string(constant.Draft),
+		ThankYouMessage:      s.ThankYouMessage,
+		QuestionsLayout:      s.QuestionsLayout,
+		QuestionsSelection:   s.QuestionsSelection,
+		UsersLoginRequired:   s.UsersLoginRequired,
+		UsersCanGoBack:       s.UsersCanGoBack,
+		ScoringType:          s.ScoringType,
+		IsAttemptsLimited:    s.IsAttemptsLimited,
+		AttemptsLimit:        s.AttemptsLimit,
+		IsTimeLimited:        s.IsTimeLimited,
+		TimeLimit:            s.TimeLimit,
+		Certificate:          s.Certificate,
+		CenterID:             s.CenterID,
+		ServiceLineID:        s.ServiceLineID,
+		BusinessPartnerID:    s.BusinessPartnerID,
+		SubBusinessPartnerID: s.SubBusinessPartnerID,
+		ServiceGroupID:       s.ServiceGroupID,
+		ServiceID:            s.ServiceID,
+		NoOfRandomQuestions:  s.NoOfRandomQuestions,
+		ShowResult:           s.ShowResult,
+		IsTypingTest:         s.IsTypingTest,
+		TypingTestTime:       s.TypingTestTime,
+	}
+}
+
+// AssessmentSettingsOverride changes individual settings; nil fields keep their value.
+type AssessmentSettingsOverride struct {
+	AssessmentType       *string  `json:"assessment_type,omitempty"`
+	Duration             *int64   `json:"duration,omitempty"`
+	Instructions         *string  `json:"instructions,omitempty"`
+	IsTimeLimited        *bool    `json:"is_time_limited,omitempty"`
+	TimeLimit            *float64 `json:"time_limit,omitempty"`
+	IsAttemptsLimited    *bool    `json:"is_attempts_limited,omitempty"`
+	AttemptsLimit        *int     `json:"attempts_limit,omitempty"`
+	Certificate          *bool    `json:"certificate,omitempty"`
+	ShowResult           *bool    `json:"show_result,omitempty"`
+	UsersLoginRequired   *bool    `json:"users_login_required,omitempty"`
+	UsersCanGoBack       *bool    `json:"users_can_go_back,omitempty"`
+	ScoringType          *string  `json:"scoring_type,omitempty"`
+	QuestionsLayout      *string  `json:"questions_layout,omitempty"`
+	QuestionsSelection   *string  `json:"questions_selection,omitempty"`
+	NoOfRandomQuestions  *int     `json:"no_of_random_questions,omitempty"`
+	IsTypingTest         *bool    `json:"is_typing_test,omitempty"`
+	TypingTestTime       *float64 `json:"typing_test_time,omitempty"`
+	ThankYouMessage      *string  `json:"thank_you_message,omitempty"`
+	CenterID             *int     `json:"center_id,omitempty"`
+	ServiceLineID        *int     `json:"service_line_id,omitempty"`
+	BusinessPartnerID    *int     `json:"business_partner_id,omitempty"`
+	SubBusinessPartnerID *int     `json:"sub_business_partner_id,omitempty"`
+	ServiceGroupID       *int     `json:"service_group_id,omitempty"`
+	ServiceID            *int     `json:"service_id,omitempty"`
+}
+
+// AssessmentTemplate is a reusable starting point for new assessments: settings, and the
+// questions of a source assessment or a blueprint to draw fresh questions from.
+type AssessmentTemplate struct {
+	TemplateID               int64              `gorm:"column:template_id;primaryKey;autoIncrement" json:"template_id"`
+	Name                     string             `gorm:"column:name" json:"name"`
+	Description              string             `gorm:"column:description" json:"description,omitempty"`
+	SourceAssessmentSequence string             `gorm:"column:source_assessment_sequence" json:"source_assessment_sequence,omitempty"`
+	BlueprintID              *int64             `gorm:"column:blueprint_id" json:"blueprint_id,omitempty"`
+	Settings                 AssessmentSettings `gorm:"embedded" json:"settings"`
+	CreatedOn                time.Time          `gorm:"column:created_on" json:"created_on"`
+	CreatedBy                string             `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn               time.Time          `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy               string             `gorm:"column:modified_by" json:"modified_by"`
+	IsActive                 bool               `gorm:"column:is_active" json:"is_active"`
+	IsDeleted                bool               `gorm:"column:is_deleted" json:"-"`
+}
+
+func (AssessmentTemplate) TableName() string {
+	return "assessment_template"
+}
+
+// SaveTemplateRequest creates (or, with TemplateID, replaces) a template. Settings start from
+// FromAssessmentSequence when given, otherwise from the defaults of a new assessment, and
+// Settings overrides are applied on top.
+type SaveTemplateRequest struct {
+	TemplateID             *int64                     `json:"template_id,omitempty"`
+	Name                   string                     `json:"name" binding:"required"`
+	Description            string                     `json:"description"`
+	FromAssessmentSequence string                     `json:"from_assessment_sequence,omitempty"`
+	BlueprintID            *int64                     `json:"blueprint_id,omitempty"`
+	Settings               AssessmentSettingsOverride `json:"settings"`
+}
+
+type CreateFromTemplateRequest struct {
+	TemplateID     int64                      `json:"template_id" binding:"required"`
+	AssessmentName string                     `json:"assessment_name"`
+	ValidFrom      *time.Time                 `json:"valid_from,omitempty"`
+	ValidTo        *time.Time                 `json:"valid_to,omitempty"`
+	Overrides      AssessmentSettingsOverride `json:"overrides"`
+	// RegenerateQuestions draws new questions from the template's blueprint instead of
+	// reusing the questions of its source assessment.
+	RegenerateQuestions bool `json:"regenerate_questions"`
+}
+
+type CloneToCentersRequest struct {
+	AssessmentSequence string                     `json:"assessment_sequence" binding:"required"`
+	CenterIDs          []int                      `json:"center_ids" binding:"required,min=1"`
+	Overrides          AssessmentSettingsOverride `json:"overrides"`
+}
+
+type CreatedAssessment struct {
+	AssessmentSequence string `json:"assessment_sequence"`
+	AssessmentName     string `json:"assessment_name"`
+	CenterID           int    `json:"center_id,omitempty"`
+	QuestionsCount     int    `json:"questions_count"`
+}
+
+type CloneToCentersResponse struct {
+	SourceAssessmentSequence string              `json:"source_assessment_sequence"`
+	Assessments              []CreatedAssessment `json:"assessments"`
+}
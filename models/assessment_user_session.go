@@ -20,6 +20,7 @@ type AssessmentUserSession struct {
 	AssessmentID   string    `gorm:"column:assessment_id" json:"assessment_sequence"`
 	AssessmentType string    `gorm:"column:assessment_type" json:"assessment_type"`
 	OdooToken      string    `gorm:"column:odoo_token" json:"-"`
+	Locale         string    `gorm:"column:locale" json:"locale"`
 }
 
 func (AssessmentUserSession) TableName() string {
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AudienceRule is a saved selection of users, such as "center = 12 AND selection_type = X". A
+// user matches when every condition holds.
+type AudienceRule struct {
+	RuleID      int64               `gorm:"column:rule_id;primaryKey;autoIncrement" json:"rule_id"`
+	CreatedOn   time.Time           `gorm:"column:created_on" json:"created_on"`
+	CreatedBy   string              `gorm:"column:created_by" json:"created_by"`
+	IsActive    bool                `gorm:"column:is_active" json:"is_active"`
+	IsDeleted   bool                `gorm:"column:is_deleted" json:"is_deleted"`
+	ModifiedOn  time.Time           `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy  string              `gorm:"column:modified_by" json:"modified_by"`
+	Name        string              `gorm:"column:name" json:"name"`
+	Description string              `gorm:"column:description" json:"description"`
+	Conditions  []AudienceCondition `gorm:"foreignKey:RuleID" json:"conditions"`
+}
+
+func (AudienceRule) TableName() string {
+	return "audience_rule"
+}
+
+// AudienceCondition compares a user attribute such as center, manager or user_type with Values.
+// "eq" and "neq" take one value, "in" and "not_in" any number. Comparison ignores case.
+type AudienceCondition struct {
+	ConditionID int64          `gorm:"column:condition_id;primaryKey;autoIncrement" json:"condition_id"`
+	RuleID      int64          `gorm:"column:rule_id" json:"rule_id"`
+	SequenceID  int64          `gorm:"column:sequence_id" json:"sequence"`
+	Field       string         `gorm:"column:field" json:"field"`
+	Operator    string         `gorm:"column:operator" json:"operator"`
+	Values      pq.StringArray `gorm:"column:match_values;type:text[]" json:"values"`
+}
+
+func (AudienceCondition) TableName() string {
+	return "audience_condition"
+}
+
+// AssessmentAudience assigns an assessment to everyone matching a rule. With AutoAssign, users
+// who come to match the rule later are assigned by the scheduler.
+type AssessmentAudience struct {
+	ID                 int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string     `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	RuleID             int64      `gorm:"column:rule_id" json:"rule_id"`
+	AutoAssign         bool       `gorm:"column:auto_assign" json:"auto_assign"`
+	IsActive           bool       `gorm:"column:is_active" json:"is_active"`
+	LastSyncedOn       *time.Time `gorm:"column:last_synced_on" json:"last_synced_on,omitempty"`
+	CreatedOn          time.Time  `gorm:"column:created_on" json:"created_on"`
+	CreatedBy          string     `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn         time.Time  `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy         string     `gorm:"column:modified_by" json:"modified_by"`
+	RuleName           string     `gorm:"column:rule_name;->;-:migration" json:"rule_name,omitempty"`
+}
+
+func (AssessmentAudience) TableName() string {
+	return "assessment_audience"
+}
+
+type SaveAudienceRuleRequest struct {
+	RuleID      *int64                     `json:"rule_id"`
+	Name        string                     `json:"name" binding:"required"`
+	Description string                     `json:"description"`
+	Conditions  []AudienceConditionRequest `json:"conditions" binding:"required,min=1,dive"`
+}
+
+type AudienceConditionRequest struct {
+	Field    string   `json:"field" binding:"required"`
+	Operator string   `json:"operator" binding:"required,oneof=eq neq in not_in"`
+	Values   []string `json:"values" binding:"required,min=1"`
+}
+
+// AudiencePreviewRequest previews a saved rule, or unsaved conditions when RuleID is not set.
+type AudiencePreviewRequest struct {
+	RuleID     *int64                     `json:"rule_id"`
+	Conditions []AudienceConditionRequest `json:"conditions" binding:"dive"`
+	// AssessmentSequence, when set, tells which matching users are already assigned.
+	AssessmentSequence string `json:"assessment_sequence"`
+}
+
+type AudienceMember struct {
+	UserID          uuid.UUID `gorm:"column:user_id" json:"user_id"`
+	FirstName       string    `gorm:"column:first_name" json:"first_name"`
+	LastName        string    `gorm:"column:last_name" json:"last_name"`
+	Email           string    `gorm:"column:email" json:"email"`
+	UserType        string    `gorm:"column:user_type" json:"user_type"`
+	EmpCode         *string   `gorm:"column:emp_code" json:"emp_code"`
+	Center          *int      `gorm:"column:center" json:"center"`
+	SelectionType   *string   `gorm:"column:selection_type" json:"selection_type"`
+	Manager         *string   `gorm:"column:manager" json:"manager"`
+	AlreadyAssigned bool      `gorm:"column:already_assigned" json:"already_assigned"`
+}
+
+type AssignAudienceRequest struct {
+	AssessmentSequence string `json:"assessment_sequence" binding:"required"`
+	RuleID             int64  `json:"rule_id" binding:"required"`
+	AutoAssign         bool   `json:"auto_assign"`
+}
+
+type AssignAudienceResponse struct {
+	Audience *AssessmentAudience `json:"audience"`
+	Assigned int                 `json:"assigned"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// AssessmentBlueprint is a reusable recipe for assembling assessments from the question bank.
+type AssessmentBlueprint struct {
+	BlueprintID    int64                 `gorm:"column:blueprint_id;primaryKey;autoIncrement" json:"blueprint_id"`
+	CreatedOn      time.Time             `gorm:"column:created_on" json:"created_on"`
+	CreatedBy      string                `gorm:"column:created_by" json:"created_by"`
+	IsActive       bool                  `gorm:"column:is_active" json:"is_active"`
+	IsDeleted      bool                  `gorm:"column:is_deleted" json:"is_deleted"`
+	ModifiedOn     time.Time             `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy     string                `gorm:"column:modified_by" json:"modified_by"`
+	Name           string                `gorm:"column:name" json:"name"`
+	Description    string                `gorm:"column:description" json:"description"`
+	AssessmentType string                `gorm:"column:assessment_type" json:"assessment_type"`
+	Duration       int64                 `gorm:"column:duration" json:"duration"`
+	Constraints    []BlueprintConstraint `gorm:"foreignKey:BlueprintID" json:"constraints"`
+}
+
+func (AssessmentBlueprint) TableName() string {
+	return "assessment_blueprint"
+}
+
+// BlueprintConstraint asks for QuestionCount questions carrying Tag and, when set, used at
+// DifficultyLevel. Tag is either a single tag name ("Safety") or "Parent/Child" ("Customs/Import").
+type BlueprintConstraint struct {
+	ConstraintID      int64  `gorm:"column:constraint_id;primaryKey;autoIncrement" json:"constraint_id"`
+	BlueprintID       int64  `gorm:"column:blueprint_id" json:"blueprint_id"`
+	SequenceID        int64  `gorm:"column:sequence_id" json:"sequence"`
+	Tag               string `gorm:"column:tag" json:"tag"`
+	DifficultyLevel   string `gorm:"column:difficulty_level" json:"difficulty_level,omitempty"`
+	QuestionCount     int    `gorm:"column:question_count" json:"question_count"`
+	Points            int64  `gorm:"column:points" json:"points"`
+	NegativePoints    int64  `gorm:"column:negative_points" json:"negative_points"`
+	DurationInSeconds int64  `gorm:"column:duration_in_seconds" json:"duration_in_seconds"`
+}
+
+func (BlueprintConstraint) TableName() string {
+	return "blueprint_constraint"
+}
+
+type SaveBlueprintRequest struct {
+	BlueprintID    *int64                       `json:"blueprint_id"`
+	Name           string                       `json:"name" binding:"required"`
+	Description    string                       `json:"description"`
+	AssessmentType string                       `json:"assessment_type"`
+	Duration       int64                        `json:"duration" binding:"omitempty,min=0"`
+	Constraints    []BlueprintConstraintRequest `json:"constraints" binding:"required,min=1,dive"`
+}
+
+type BlueprintConstraintRequest struct {
+	Tag               string `json:"tag"`
+	DifficultyLevel   string `json:"difficulty_level" binding:"omitempty,oneof=easy medium hard"`
+	QuestionCount     int    `json:"question_count" binding:"required,min=1,max=500"`
+	Points            int64  `json:"points" binding:"omitempty,min=0"`
+	NegativePoints    int64  `json:"negative_points" binding:"omitempty,min=0"`
+	DurationInSeconds int64  `json:"duration_in_seconds" binding:"omitempty,min=0"`
+}
+
+type AssembleBlueprintRequest struct {
+	BlueprintID    int64  `json:"blueprint_id" binding:"required"`
+	AssessmentName string `json:"assessment_name"`
+	// AllowPartial saves the assessment even when some constraints cannot be filled.
+	AllowPartial bool `json:"allow_partial"`
+	// DryRun only reports what the bank can supply.
+	DryRun bool `json:"dry_run"`
+}
+
+// BankQuestion is a question picked from the bank together with the difficulty it was last used at.
+type BankQuestion struct {
+	QuestionID      int64  `gorm:"column:question_id"`
+	DifficultyLevel string `gorm:"column:difficulty_level"`
+}
+
+type ConstraintFulfilment struct {
+	Sequence        int64  `json:"sequence"`
+	Tag             string `json:"tag"`
+	DifficultyLevel string `json:"difficulty_level,omitempty"`
+	Requested       int    `json:"requested"`
+	Selected        int    `json:"selected"`
+	Shortfall       int    `json:"shortfall"`
+}
+
+type BlueprintAssemblyResponse struct {
+	BlueprintID        int64                  `json:"blueprint_id"`
+	AssessmentSequence string                 `json:"assessment_sequence,omitempty"`
+	AssessmentName     string                 `json:"assessment_name"`
+	QuestionsCount     int                    `json:"questions_count"`
+	Marks              int64                  `json:"marks"`
+	Satisfied          bool                   `json:"satisfied"`
+	Constraints        []ConstraintFulfilment `json:"constraints"`
+}
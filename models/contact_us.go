@@ -17,3 +17,8 @@ type ContactUsResponse struct {
 func (ContactUsResponse) TableName() string {
 	return "dhl_contact_us_responses"
 }
+
+// ContactReplyRequest answers a contact form message by mail.
+type ContactReplyRequest struct {
+	Reply string `json:"reply" binding:"required"`
+}
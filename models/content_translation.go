@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// ContentTranslation holds a content_mst value in another locale. For image and audio
+// content the translation is the alt text.
+type ContentTranslation struct {
+	ContentID  int64     `gorm:"column:content_id;primaryKey" json:"content_id"`
+	Locale     string    `gorm:"column:locale;primaryKey" json:"locale"`
+	Value      string    `gorm:"column:value" json:"value"`
+	CreatedOn  time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy  string    `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn time.Time `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy string    `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (ContentTranslation) TableName() string {
+	return "content_translation"
+}
+
+// AssessmentTranslation holds the assessment title and instructions in another locale.
+type AssessmentTranslation struct {
+	AssessmentSequence string    `gorm:"column:assessment_sequence;primaryKey" json:"assessment_sequence"`
+	Locale             string    `gorm:"column:locale;primaryKey" json:"locale"`
+	Title              string    `gorm:"column:title" json:"title"`
+	Instructions       string    `gorm:"column:instructions" json:"instructions"`
+	CreatedOn          time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy          string    `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn         time.Time `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy         string    `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (AssessmentTranslation) TableName() string {
+	return "assessment_translation"
+}
+
+type SaveTranslationsRequest struct {
+	AssessmentSequence string                `json:"assessment_sequence" binding:"required"`
+	Locale             string                `json:"locale" binding:"required"`
+	Title              string                `json:"title"`
+	Instructions       string                `json:"instructions"`
+	Questions          []QuestionTranslation `json:"questions"`
+}
+
+type QuestionTranslation struct {
+	QuestionID int64               `json:"question_id" binding:"required"`
+	Title      string              `json:"title"`
+	Options    []OptionTranslation `json:"options"`
+}
+
+type OptionTranslation struct {
+	OptionID int64  `json:"option_id" binding:"required"`
+	Label    string `json:"label"`
+}
+
+type SaveTranslationsResponse struct {
+	AssessmentSequence string `json:"assessment_sequence"`
+	Locale             string `json:"locale"`
+	Translated         int    `json:"translated"`
+}
+
+type AssessmentLocalesResponse struct {
+	AssessmentSequence string   `json:"assessment_sequence"`
+	DefaultLocale      string   `json:"default_locale"`
+	Locales            []string `json:"locales"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// DigestSubscription is how often a manager or admin gets the digest of their team, or of
+// every user for an admin with the "all" scope.
+type DigestSubscription struct {
+	UserID    string `gorm:"column:user_id;primaryKey" json:"user_id"`
+	Frequency string `gorm:"column:frequency" json:"frequency"`
+	// Weekday is the day weekly digests go out on, 0 for Sunday.
+	Weekday    int        `gorm:"column:weekday" json:"weekday"`
+	Scope      string     `gorm:"column:scope" json:"scope"`
+	LastSentOn *time.Time `gorm:"column:last_sent_on" json:"last_sent_on"`
+	CreatedOn  time.Time  `gorm:"column:created_on" json:"created_on"`
+	ModifiedOn time.Time  `gorm:"column:modified_on" json:"modified_on"`
+}
+
+func (DigestSubscription) TableName() string {
+	return "digest_subscription"
+}
+
+type SaveDigestSubscriptionRequest struct {
+	Frequency string `json:"frequency" binding:"required,oneof=daily weekly off"`
+	Weekday   *int   `json:"weekday" binding:"omitempty,min=0,max=6"`
+	Scope     string `json:"scope" binding:"omitempty,oneof=team all"`
+}
+
+// DigestEntry is one user's assignment in a digest. Date is when it was completed, or when it
+// is due for the overdue and upcoming ones.
+type DigestEntry struct {
+	UserID             string    `gorm:"column:user_id" json:"user_id"`
+	UserName           string    `gorm:"column:user_name" json:"user_name"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	AssessmentName     string    `gorm:"column:assessment_name" json:"assessment_name"`
+	Date               time.Time `gorm:"column:date" json:"date"`
+	Percentage         *float64  `gorm:"column:percentage" json:"percentage,omitempty"`
+	Passed             bool      `gorm:"column:passed" json:"passed"`
+}
+
+// Digest sums up a period for a manager's team, or for every user when ManagerID is empty.
+// The lists are cut at the configured maximum; the counts are not.
+type Digest struct {
+	ManagerID       string        `json:"manager_id,omitempty"`
+	From            time.Time     `json:"from"`
+	To              time.Time     `json:"to"`
+	Completions     []DigestEntry `json:"completions"`
+	Failures        []DigestEntry `json:"failures"`
+	Overdue         []DigestEntry `json:"overdue"`
+	Upcoming        []DigestEntry `json:"upcoming"`
+	CompletionCount int           `json:"completion_count"`
+	FailureCount    int           `json:"failure_count"`
+	OverdueCount    int           `json:"overdue_count"`
+	UpcomingCount   int           `json:"upcoming_count"`
+}
+
+// IsEmpty tells whether the digest has nothing to report.
+func (d *Digest) IsEmpty() bool {
+	return d.CompletionCount+d.FailureCount+d.OverdueCount+d.UpcomingCount == 0
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// BankQuestionText is a bank question with its raw stem and options, as loaded for
+// duplicate detection. Options holds "content_type\x1evalue" pairs separated by \x1f.
+type BankQuestionText struct {
+	QuestionID  int64  `gorm:"column:question_id"`
+	ContentType string `gorm:"column:content_type"`
+	Value       string `gorm:"column:value"`
+	Options     string `gorm:"column:options"`
+}
+
+// BankVersion changes whenever a bank question is added, removed, (de)activated or edited,
+// so a snapshot of the bank taken at one version is still current while it stays the same.
+type BankVersion struct {
+	Questions     int64      `gorm:"column:questions"`
+	MaxQuestionID int64      `gorm:"column:max_question_id"`
+	ModifiedOn    *time.Time `gorm:"column:modified_on"`
+}
+
+func (v BankVersion) Equal(other BankVersion) bool {
+	if v.Questions != other.Questions || v.MaxQuestionID != other.MaxQuestionID {
+		return false
+	}
+	if v.ModifiedOn == nil || other.ModifiedOn == nil {
+		return v.ModifiedOn == other.ModifiedOn
+	}
+	return v.ModifiedOn.Equal(*other.ModifiedOn)
+}
+
+// QuestionAssessmentCount is the number of assessments a question is used in.
+type QuestionAssessmentCount struct {
+	QuestionID      int64 `gorm:"column:question_id"`
+	AssessmentCount int64 `gorm:"column:assessment_count"`
+}
+
+type DuplicateMatch struct {
+	QuestionID       int64   `json:"question_id"`
+	Title            string  `json:"title"`
+	Score            float64 `json:"score"`
+	TextSimilarity   float64 `json:"text_similarity"`
+	OptionSimilarity float64 `json:"option_similarity"`
+}
+
+// DuplicateWarning describes one incoming question that resembles existing questions.
+// Index is the position of the question in the request or sheet.
+type DuplicateWarning struct {
+	Index        int              `json:"index"`
+	Title        string           `json:"title"`
+	Action       string           `json:"action"`
+	QuestionID   int64            `json:"question_id,omitempty"`
+	Matches      []DuplicateMatch `json:"matches,omitempty"`
+	BatchMatches []int            `json:"batch_matches,omitempty"`
+}
+
+type CheckDuplicatesRequest struct {
+	Questions []CreateQuestionRequest `json:"questions" binding:"required,min=1"`
+	Threshold float64                 `json:"threshold,omitempty"`
+}
+
+type DuplicateClusterQuestion struct {
+	QuestionID      int64    `json:"question_id"`
+	Title           string   `json:"title"`
+	Options         []string `json:"options,omitempty"`
+	AssessmentCount int64    `json:"assessment_count"`
+}
+
+// DuplicateCluster is a group of bank questions that are near-duplicates of each other.
+// Score is the lowest similarity that linked two of its questions.
+type DuplicateCluster struct {
+	Score     float64                    `json:"score"`
+	Questions []DuplicateClusterQuestion `json:"questions"`
+}
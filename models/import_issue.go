@@ -0,0 +1,41 @@
+package models
+
+// ImportItemIssue reports an item that could not be imported or exported, for example a
+// question type the target format has no equivalent for.
+type ImportItemIssue struct {
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title,omitempty"`
+	Interaction string `json:"interaction,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+type ItemImportResponse struct {
+	Format      string            `json:"format"`
+	Assessment  *SheetAssessment  `json:"assessment"`
+	Imported    int               `json:"imported"`
+	Unsupported []ImportItemIssue `json:"unsupported,omitempty"`
+}
+
+// ExcelValidationIssue points at the cell (or row, when Column is empty) a problem was found in.
+// Row numbers are the ones shown in Excel, so the header is row 1.
+type ExcelValidationIssue struct {
+	Sheet    string `json:"sheet,omitempty"`
+	Row      int    `json:"row,omitempty"`
+	Column   string `json:"column,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ExcelValidationReport is the result of checking an import workbook. A workbook with errors
+// is never imported; warnings are informational.
+type ExcelValidationReport struct {
+	Valid          bool                   `json:"valid"`
+	Sheet          string                 `json:"sheet,omitempty"`
+	QuestionsCount int                    `json:"questions_count"`
+	OptionsCount   int                    `json:"options_count"`
+	Errors         []ExcelValidationIssue `json:"errors"`
+	Warnings       []ExcelValidationIssue `json:"warnings"`
+	Duplicates     []DuplicateWarning     `json:"duplicates,omitempty"`
+	Assessment     *SheetAssessment       `json:"assessment,omitempty"`
+}
@@ -20,3 +20,28 @@ type JobDescription struct {
 func (JobDescription) TableName() string {
 	return "job_descriptions"
 }
+
+type GenerateJobAssessmentRequest struct {
+	JobID                int64  `json:"job_id" binding:"required"`
+	AssessmentName       string `json:"assessment_name"`
+	NumberOfQuestions    int    `json:"number_of_questions" binding:"omitempty,min=1,max=200"`
+	QuestionsPerSkill    int    `json:"questions_per_skill" binding:"omitempty,min=1,max=50"`
+	UseExistingQuestions bool   `json:"use_existing_questions"`
+}
+
+type SkillBlueprint struct {
+	Skill           string `json:"skill"`
+	DifficultyLevel string `json:"difficulty_level"`
+	Requested       int    `json:"requested"`
+	FromBank        int    `json:"from_bank"`
+	Generated       int    `json:"generated"`
+}
+
+type JobAssessmentResponse struct {
+	AssessmentSequence string           `json:"assessment_sequence"`
+	AssessmentName     string           `json:"assessment_name"`
+	JobID              int64            `json:"job_id"`
+	AssessmentStatus   string           `json:"assessment_status"`
+	QuestionsCount     int              `json:"questions_count"`
+	Blueprint          []SkillBlueprint `json:"blueprint"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+type MediaMst struct {
+	MediaID    int64     `gorm:"column:media_id;primaryKey;autoIncrement" json:"media_id"`
+	StorageKey string    `gorm:"column:storage_key" json:"storage_key"`
+	FileName   string    `gorm:"column:file_name" json:"file_name"`
+	MimeType   string    `gorm:"column:mime_type" json:"mime_type"`
+	SizeBytes  int64     `gorm:"column:size_bytes" json:"size_bytes"`
+	Checksum   string    `gorm:"column:checksum" json:"checksum"`
+	CreatedOn  time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy  string    `gorm:"column:created_by" json:"created_by"`
+	IsDeleted  bool      `gorm:"column:is_deleted" json:"is_deleted"`
+}
+
+func (MediaMst) TableName() string {
+	return "media_mst"
+}
+
+// MediaContentValue is stored as JSON in content_mst.value for image and audio content.
+type MediaContentValue struct {
+	MediaID  int64  `json:"media_id"`
+	Key      string `json:"key"`
+	MimeType string `json:"mime_type"`
+	Alt      string `json:"alt,omitempty"`
+}
+
+type MediaUploadResponse struct {
+	MediaID     int64  `json:"media_id"`
+	StorageKey  string `json:"storage_key"`
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+// RenderedContent is the content-type-aware form of a question stem or option sent to clients.
+type RenderedContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	HTML     string `json:"html,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+	URL      string `json:"url,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Alt      string `json:"alt,omitempty"`
+}
+
+type UpdateContentRequest struct {
+	QuestionID  *int64 `json:"question_id"`
+	OptionID    *int64 `json:"option_id"`
+	ContentType string `json:"content_type" binding:"required,oneof=text image audio html markdown"`
+	Value       string `json:"value"`
+	MediaID     int64  `json:"media_id"`
+	Alt         string `json:"alt"`
+}
+
+// SheetMedia references a media file for an imported question or option. FileName is the
+// name inside the uploaded zip; the remaining fields are filled once the file is stored.
+type SheetMedia struct {
+	FileName string `json:"file_name"`
+	MediaID  int64  `json:"media_id,omitempty"`
+	Key      string `json:"key,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+}
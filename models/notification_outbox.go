@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationOutbox is a notification waiting for delivery. It is written in the same
+// transaction as the change it reports, so a rollback drops it too, and delivered by the
+// outbox dispatcher.
+type NotificationOutbox struct {
+	ID                 int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Kind               string `gorm:"column:kind" json:"kind"`
+	RecipientID        string `gorm:"column:recipient_id" json:"recipient_id"`
+	AssessmentSequence string `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	TemplateKey        string `gorm:"column:template_key" json:"template_key"`
+	// Payload is the template data; the recipient's name is added on delivery.
+	Payload       json.RawMessage `gorm:"column:payload;type:jsonb" json:"payload"`
+	Status        string          `gorm:"column:status" json:"status"`
+	Attempts      int             `gorm:"column:attempts" json:"attempts"`
+	NextAttemptOn time.Time       `gorm:"column:next_attempt_on" json:"next_attempt_on"`
+	LastError     string          `gorm:"column:last_error" json:"last_error,omitempty"`
+	CreatedOn     time.Time       `gorm:"column:created_on" json:"created_on"`
+	SentOn        *time.Time      `gorm:"column:sent_on" json:"sent_on,omitempty"`
+}
+
+func (NotificationOutbox) TableName() string {
+	return "notification_outbox"
+}
+
+type OutboxFilter struct {
+	Status             string
+	Kind               string
+	RecipientID        string
+	AssessmentSequence string
+}
+
+// ReplayOutboxRequest puts dead notifications back in the queue: the listed ones, or all of
+// them with AllDead.
+type ReplayOutboxRequest struct {
+	IDs     []int64 `json:"ids"`
+	AllDead bool    `json:"all_dead"`
+}
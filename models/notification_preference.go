@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// NotificationPreference is the channels a user receives one category of notifications
+// through. Categories without one follow the notification type of the user's profile.
+type NotificationPreference struct {
+	UserID     string    `gorm:"column:user_id;primaryKey" json:"-"`
+	Category   string    `gorm:"column:category;primaryKey" json:"category"`
+	Email      bool      `gorm:"column:email" json:"email"`
+	InApp      bool      `gorm:"column:in_app" json:"in_app"`
+	ModifiedOn time.Time `gorm:"column:modified_on" json:"modified_on"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "user_notification_preference"
+}
+
+// CategoryPreference is the channels of one category as the user gets them, their own choice
+// or their default. Mandatory categories are always sent on every channel.
+type CategoryPreference struct {
+	Category  string `json:"category"`
+	Email     bool   `json:"email"`
+	InApp     bool   `json:"in_app"`
+	Mandatory bool   `json:"mandatory"`
+	Custom    bool   `json:"custom"`
+}
+
+type NotificationPreferences struct {
+	Default    string               `json:"default"`
+	Categories []CategoryPreference `json:"categories"`
+}
+
+type SaveCategoryPreference struct {
+	Category string `json:"category" binding:"required,oneof=assignments reminders results compliance marketing"`
+	Email    bool   `json:"email"`
+	InApp    bool   `json:"in_app"`
+}
+
+// SaveNotificationPreferencesRequest changes the user's default, stored as the notification
+// type of their profile, and the channels of the categories given.
+type SaveNotificationPreferencesRequest struct {
+	Default    *string                  `json:"default" binding:"omitempty,oneof=all email in_app none"`
+	Categories []SaveCategoryPreference `json:"categories" binding:"dive"`
+}
+
+// NotificationChannels is what a recipient is sent a notification through.
+type NotificationChannels struct {
+	Email bool
+	InApp bool
+}
+
+// NotificationSuppression records a notification not sent to a user on a channel they opted
+// out of.
+type NotificationSuppression struct {
+	ID                 int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID             string    `gorm:"column:user_id" json:"user_id"`
+	Kind               string    `gorm:"column:kind" json:"kind"`
+	Category           string    `gorm:"column:category" json:"category"`
+	Channel            string    `gorm:"column:channel" json:"channel"`
+	TemplateKey        string    `gorm:"column:template_key" json:"template_key"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence,omitempty"`
+	CreatedOn          time.Time `gorm:"column:created_on" json:"created_on"`
+}
+
+func (NotificationSuppression) TableName() string {
+	return "notification_suppression"
+}
+
+type SuppressionFilter struct {
+	UserID   string
+	Category string
+	Channel  string
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// NotificationTemplate is the text of one kind of notification in one locale. Subject is a
+// text/template and Body an html/template, both executed with the notification's data.
+type NotificationTemplate struct {
+	ID      int64  `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Key     string `gorm:"column:template_key" json:"key"`
+	Locale  string `gorm:"column:locale" json:"locale"`
+	Subject string `gorm:"column:subject" json:"subject"`
+	Body    string `gorm:"column:body;type:text" json:"body"`
+	// NotifyCode is the notify server template to send it with; 0 uses the configured one.
+	NotifyCode int       `gorm:"column:notify_code" json:"notify_code"`
+	CreatedOn  time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy  string    `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn time.Time `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy string    `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_template"
+}
+
+type SaveNotificationTemplateRequest struct {
+	Key        string `json:"key" binding:"required"`
+	Locale     string `json:"locale"`
+	Subject    string `json:"subject" binding:"required"`
+	Body       string `json:"body" binding:"required"`
+	NotifyCode int    `json:"notify_code" binding:"min=0"`
+}
+
+// PreviewNotificationTemplateRequest renders a template in a locale with sample data.
+type PreviewNotificationTemplateRequest struct {
+	Key    string                 `json:"key" binding:"required"`
+	Locale string                 `json:"locale"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// RenderedNotification is a template executed for one recipient. Locale is the variant that
+// was used, which may be a fallback of the one asked for.
+type RenderedNotification struct {
+	Key        string `json:"key"`
+	Locale     string `json:"locale"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	NotifyCode int    `json:"notify_code"`
+}
@@ -1,12 +1,13 @@
 package models
 
 type OptionMst struct {
-	OptionID    int64 `gorm:"column:option_id;primaryKey;autoIncrement"`
-	ContentID   int64 `gorm:"column:content_id"`
-	IsAnswer    bool  `gorm:"column:is_answer"`
-	QuestionID  int64 `gorm:"column:question_id"`
-	SequenceID  int64 `gorm:"column:sequence_id"`
-	AnswerScore int   `gorm:"column:answer_score"`
+	OptionID    int64  `gorm:"column:option_id;primaryKey;autoIncrement"`
+	ContentID   int64  `gorm:"column:content_id"`
+	IsAnswer    bool   `gorm:"column:is_answer"`
+	QuestionID  int64  `gorm:"column:question_id"`
+	SequenceID  int64  `gorm:"column:sequence_id"`
+	AnswerScore int    `gorm:"column:answer_score"`
+	Feedback    string `gorm:"column:feedback"`
 }
 
 func (OptionMst) TableName() string {
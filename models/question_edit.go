@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// QuestionEditHistory records one value changed by a bulk edit. All changes of one operation
+// share a BatchID.
+type QuestionEditHistory struct {
+	HistoryID          int64     `gorm:"column:history_id;primaryKey;autoIncrement" json:"history_id"`
+	BatchID            string    `gorm:"column:batch_id" json:"batch_id"`
+	Operation          string    `gorm:"column:operation" json:"operation"`
+	QuestionID         int64     `gorm:"column:question_id" json:"question_id"`
+	OptionID           *int64    `gorm:"column:option_id" json:"option_id,omitempty"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence,omitempty"`
+	Field              string    `gorm:"column:field" json:"field"`
+	OldValue           string    `gorm:"column:old_value" json:"old_value"`
+	NewValue           string    `gorm:"column:new_value" json:"new_value"`
+	EditedOn           time.Time `gorm:"column:edited_on" json:"edited_on"`
+	EditedBy           string    `gorm:"column:edited_by" json:"edited_by"`
+}
+
+func (QuestionEditHistory) TableName() string {
+	return "question_edit_history"
+}
+
+// EditableContent is the text of a question stem (OptionID nil) or of one of its options.
+type EditableContent struct {
+	QuestionID    int64  `gorm:"column:question_id"`
+	OptionID      *int64 `gorm:"column:option_id"`
+	ContentID     int64  `gorm:"column:content_id"`
+	ContentTypeID int64  `gorm:"column:content_type_id"`
+	Value         string `gorm:"column:value"`
+}
+
+type BulkRetagRequest struct {
+	QuestionIDs []int64      `json:"question_ids" binding:"required,min=1"`
+	AddTags     []TagRequest `json:"add_tags"`
+	// RemoveTagIDs are unmapped from every question; ReplaceTags unmaps all current tags first.
+	RemoveTagIDs []int64 `json:"remove_tag_ids"`
+	ReplaceTags  bool    `json:"replace_tags"`
+}
+
+// BulkDifficultyRequest sets the difficulty of the questions in one assessment, or in every
+// assessment using them when AssessmentSequence is empty.
+type BulkDifficultyRequest struct {
+	QuestionIDs        []int64 `json:"question_ids" binding:"required,min=1"`
+	AssessmentSequence string  `json:"assessment_sequence"`
+	DifficultyLevel    string  `json:"difficulty_level" binding:"required"`
+}
+
+// BulkPointsRequest sets points across an assessment, or only for QuestionIDs when given.
+type BulkPointsRequest struct {
+	AssessmentSequence string  `json:"assessment_sequence" binding:"required"`
+	QuestionIDs        []int64 `json:"question_ids"`
+	CorrectPoints      *int64  `json:"correct_points"`
+	NegativePoints     *int64  `json:"negative_points"`
+}
+
+type BulkStatusRequest struct {
+	QuestionIDs []int64 `json:"question_ids" binding:"required,min=1"`
+	IsActive    *bool   `json:"is_active" binding:"required"`
+}
+
+// FindReplaceRequest replaces text in the stems and/or options of the selected questions,
+// chosen by id or by assessment. Choosing by assessment still edits the shared bank questions,
+// so other assessments using them change too. Preview reports the changes without saving them.
+type FindReplaceRequest struct {
+	Find               string  `json:"find" binding:"required"`
+	Replace            string  `json:"replace"`
+	QuestionIDs        []int64 `json:"question_ids"`
+	AssessmentSequence string  `json:"assessment_sequence"`
+	// Scope is stems, options or all (default).
+	Scope         string `json:"scope"`
+	CaseSensitive bool   `json:"case_sensitive"`
+	Preview       bool   `json:"preview"`
+}
+
+type QuestionEditChange struct {
+	QuestionID         int64  `json:"question_id"`
+	OptionID           *int64 `json:"option_id,omitempty"`
+	AssessmentSequence string `json:"assessment_sequence,omitempty"`
+	Field              string `json:"field"`
+	Before             string `json:"before"`
+	After              string `json:"after"`
+}
+
+type BulkEditResult struct {
+	BatchID   string               `json:"batch_id,omitempty"`
+	Operation string               `json:"operation"`
+	Preview   bool                 `json:"preview"`
+	Affected  int                  `json:"affected"`
+	Changes   []QuestionEditChange `json:"changes"`
+}
@@ -12,6 +12,7 @@ type QuestionMst struct {
 	ModifiedBy     string    `gorm:"column:modified_by"`
 	ContentID      int64     `gorm:"column:content_id"`
 	QuestionTypeID int64     `gorm:"column:question_type_id"`
+	Feedback       string    `gorm:"column:feedback"`
 }
 
 func (QuestionMst) TableName() string {
@@ -31,4 +32,5 @@ type QuestionContentWithType struct {
 	Value          string `gorm:"column:value"`
 	QuestionTypeId uint64 `gorm:"column:question_type_id"`
 	QuestionType   string `gorm:"column:question_type"`
+	Feedback       string `gorm:"column:feedback"`
 }
@@ -6,6 +6,10 @@ type CreateQuestionRequest struct {
 	QuestionType      string       `json:"question_type"`
 	Options           []OptionReq  `json:"options"`
 	Tags              []TagRequest `json:"tags"`
+	// DuplicateAction overrides the request-wide duplicate handling for this question.
+	DuplicateAction string `json:"duplicate_action,omitempty"`
+	// ExistingQuestionID is the bank question to merge into; defaults to the best match.
+	ExistingQuestionID int64 `json:"existing_question_id,omitempty"`
 }
 
 type OptionReq struct {
@@ -16,4 +20,11 @@ type OptionReq struct {
 
 type CreateQuestionsRequest struct {
 	Questions []CreateQuestionRequest `json:"questions" binding:"required,min=1"`
+	// DuplicateAction is warn (default), skip or merge.
+	DuplicateAction string `json:"duplicate_action,omitempty"`
+}
+
+type CreateQuestionsResponse struct {
+	QuestionIDs []int64            `json:"question_ids"`
+	Duplicates  []DuplicateWarning `json:"duplicates,omitempty"`
 }
\ No newline at end of file
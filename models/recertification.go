@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// AssessmentRecurrence makes an assessment a recertification: every IntervalMonths a new cycle
+// starts and users whose last pass is older than the interval are assigned it again.
+type AssessmentRecurrence struct {
+	ID                 int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence;uniqueIndex" json:"assessment_sequence"`
+	IntervalMonths     int       `gorm:"column:interval_months" json:"interval_months"`
+	NotifyDaysBefore   int       `gorm:"column:notify_days_before" json:"notify_days_before"`
+	CurrentCycle       int       `gorm:"column:current_cycle" json:"current_cycle"`
+	CycleStartedOn     time.Time `gorm:"column:cycle_started_on" json:"cycle_started_on"`
+	NextCycleOn        time.Time `gorm:"column:next_cycle_on" json:"next_cycle_on"`
+	IsActive           bool      `gorm:"column:is_active" json:"is_active"`
+	CreatedOn          time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy          string    `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn         time.Time `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy         string    `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (AssessmentRecurrence) TableName() string {
+	return "assessment_recurrence"
+}
+
+// UserCertification is a pass of a recurring assessment, valid until ExpiresOn.
+type UserCertification struct {
+	ID                 int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string     `gorm:"column:assessment_sequence;index" json:"assessment_sequence"`
+	UserID             string     `gorm:"column:user_id;index" json:"user_id"`
+	SessionID          string     `gorm:"column:session_id;uniqueIndex" json:"session_id"`
+	Cycle              int        `gorm:"column:cycle" json:"cycle"`
+	IssuedOn           time.Time  `gorm:"column:issued_on" json:"issued_on"`
+	ExpiresOn          time.Time  `gorm:"column:expires_on" json:"expires_on"`
+	ExpiryNotifiedOn   *time.Time `gorm:"column:expiry_notified_on" json:"expiry_notified_on,omitempty"`
+	CreatedOn          time.Time  `gorm:"column:created_on" json:"created_on"`
+}
+
+func (UserCertification) TableName() string {
+	return "user_certification"
+}
+
+type SaveRecurrenceRequest struct {
+	AssessmentSequence string `json:"assessment_sequence" binding:"required"`
+	IntervalMonths     int    `json:"interval_months" binding:"required,min=1,max=120"`
+	NotifyDaysBefore   int    `json:"notify_days_before" binding:"min=0,max=365"`
+	// FirstCycleOn is when the next cycle starts; defaults to one interval from now.
+	FirstCycleOn *time.Time `json:"first_cycle_on"`
+}
+
+// CertificationSummary is a user's latest certification of one recurring assessment.
+type CertificationSummary struct {
+	UserID             string    `gorm:"column:user_id" json:"user_id"`
+	UserName           string    `gorm:"column:user_name" json:"user_name,omitempty"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	AssessmentName     string    `gorm:"column:assessment_desc" json:"assessment_name"`
+	Cycle              int       `gorm:"column:cycle" json:"cycle"`
+	IssuedOn           time.Time `gorm:"column:issued_on" json:"issued_on"`
+	ExpiresOn          time.Time `gorm:"column:expires_on" json:"expires_on"`
+	Status             string    `gorm:"column:status" json:"status"`
+}
+
+// ExpiringCertification is a certification due for its expiry notice.
+// IssuedCertification is a certification just recorded for a passing session.
+type IssuedCertification struct {
+	UserID             string    `gorm:"column:user_id"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence"`
+	ExpiresOn          time.Time `gorm:"column:expires_on"`
+}
+
+type ExpiringCertification struct {
+	ID                 int64     `gorm:"column:id"`
+	UserID             string    `gorm:"column:user_id"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence"`
+	ExpiresOn          time.Time `gorm:"column:expires_on"`
+}
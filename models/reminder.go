@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ReminderPolicy overrides the configured reminder cadence for one assessment.
+type ReminderPolicy struct {
+	ID                 int64         `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string        `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	Enabled            bool          `gorm:"column:enabled" json:"enabled"`
+	DaysBefore         pq.Int64Array `gorm:"column:days_before;type:integer[]" json:"days_before"`
+	OnDueDate          bool          `gorm:"column:on_due_date" json:"on_due_date"`
+	// OverdueEveryDays repeats the overdue reminder; 0 sends it once.
+	OverdueEveryDays int `gorm:"column:overdue_every_days" json:"overdue_every_days"`
+	// EscalateAfterDays overdue, the user's managers are told; 0 never escalates.
+	EscalateAfterDays int       `gorm:"column:escalate_after_days" json:"escalate_after_days"`
+	CreatedOn         time.Time `gorm:"column:created_on" json:"created_on"`
+	CreatedBy         string    `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn        time.Time `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy        string    `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (ReminderPolicy) TableName() string {
+	return "assessment_reminder_policy"
+}
+
+type SaveReminderPolicyRequest struct {
+	AssessmentSequence string  `json:"assessment_sequence" binding:"required"`
+	Enabled            *bool   `json:"enabled"`
+	DaysBefore         []int64 `json:"days_before" binding:"dive,min=1,max=365"`
+	OnDueDate          bool    `json:"on_due_date"`
+	OverdueEveryDays   int     `json:"overdue_every_days" binding:"min=0,max=365"`
+	EscalateAfterDays  int     `json:"escalate_after_days" binding:"min=0,max=365"`
+}
+
+// ReminderLog records a reminder or escalation handed to the outbox, so it is not sent twice. Key
+// tells the reminders of one event apart, such as "before_due:3" or "overdue:1".
+type ReminderLog struct {
+	ID                 int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	UserID             string    `gorm:"column:user_id" json:"user_id"`
+	RecipientID        string    `gorm:"column:recipient_id" json:"recipient_id"`
+	Event              string    `gorm:"column:event" json:"event"`
+	EventKey           string    `gorm:"column:event_key" json:"event_key"`
+	DueDate            time.Time `gorm:"column:due_date" json:"due_date"`
+	QueuedOn           time.Time `gorm:"column:queued_on" json:"queued_on"`
+}
+
+func (ReminderLog) TableName() string {
+	return "assessment_reminder_log"
+}
+
+// ReminderCandidate is an unfinished assignment with a due date.
+type ReminderCandidate struct {
+	AssessmentSequence string    `gorm:"column:assessment_sequence"`
+	UserID             string    `gorm:"column:user_id"`
+	DueDate            time.Time `gorm:"column:due_date"`
+}
+
+type ReminderLogFilter struct {
+	AssessmentSequence string
+	UserID             string
+	Event              string
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ResultRelease holds back the results of an assessment until they are released. Assessments
+// without one show results on submission.
+type ResultRelease struct {
+	AssessmentSequence string `gorm:"column:assessment_sequence;primaryKey" json:"assessment_sequence"`
+	Mode               string `gorm:"column:mode" json:"mode"`
+	// ReleaseOn is when on_date results are released.
+	ReleaseOn  *time.Time `gorm:"column:release_on" json:"release_on,omitempty"`
+	ReleasedOn *time.Time `gorm:"column:released_on" json:"released_on,omitempty"`
+	ReleasedBy string     `gorm:"column:released_by" json:"released_by,omitempty"`
+	CreatedOn  time.Time  `gorm:"column:created_on" json:"created_on"`
+	CreatedBy  string     `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn time.Time  `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy string     `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (ResultRelease) TableName() string {
+	return "assessment_result_release"
+}
+
+type SaveResultReleaseRequest struct {
+	AssessmentSequence string     `json:"assessment_sequence" binding:"required"`
+	Mode               string     `json:"mode" binding:"required,oneof=immediate on_close on_date manual"`
+	ReleaseOn          *time.Time `json:"release_on" binding:"required_if=Mode on_date"`
+}
+
+// ReleasedResult is the score of a user's latest session of an assessment, as a result
+// notification tells it.
+type ReleasedResult struct {
+	UserID        string  `gorm:"column:user_id"`
+	SessionID     string  `gorm:"column:session_id"`
+	MarksObtained float64 `gorm:"column:marks_obtained"`
+	TotalMarks    float64 `gorm:"column:total_marks"`
+	Percentage    float64 `gorm:"column:percentage"`
+	Passed        bool    `gorm:"column:passed"`
+	Certificate   bool    `gorm:"column:certificate"`
+	Recurring     bool    `gorm:"column:recurring"`
+}
+
+// PendingResultResponse is what users get instead of a result not yet released, or hidden.
+type PendingResultResponse struct {
+	AssessmentSequence string     `json:"assessment_sequence"`
+	ResultStatus       string     `json:"result_status"`
+	ReleaseMode        string     `json:"release_mode,omitempty"`
+	ReleaseOn          *time.Time `json:"release_on,omitempty"`
+}
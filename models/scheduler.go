@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// SchedulerTaskStatus is what this server instance knows about one background task.
+type SchedulerTaskStatus struct {
+	Name     string `json:"name"`
+	Interval string `json:"interval"`
+	// LastRunAt is the last run that got the lock; other replicas may have run it since.
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextCheckAt   *time.Time `json:"next_check_at,omitempty"`
+}
+
+// ScheduledAssessmentJob is an upcoming (or overdue) open or close of an assessment.
+type ScheduledAssessmentJob struct {
+	AssessmentSequence string    `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	AssessmentName     string    `gorm:"column:assessment_desc" json:"assessment_name"`
+	State              string    `gorm:"column:state" json:"state"`
+	Action             string    `gorm:"column:action" json:"action"`
+	DueAt              time.Time `gorm:"column:due_at" json:"due_at"`
+	Overdue            bool      `gorm:"-" json:"overdue"`
+}
+
+// StartWindow is what decides whether a user may start an assessment: its state and schedule,
+// and the user's assignment when they have one.
+type StartWindow struct {
+	State            string     `gorm:"column:state"`
+	OpenAt           *time.Time `gorm:"column:open_at"`
+	CloseAt          *time.Time `gorm:"column:close_at"`
+	AssignmentStatus *string    `gorm:"column:assessment_status"`
+	DueDate          *time.Time `gorm:"column:due_date"`
+}
+
+type ScheduleOverview struct {
+	Enabled bool                     `json:"enabled"`
+	Tasks   []SchedulerTaskStatus    `json:"tasks"`
+	Jobs    []ScheduledAssessmentJob `json:"jobs"`
+}
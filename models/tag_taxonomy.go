@@ -0,0 +1,55 @@
+package models
+
+// TagUsage counts where a tag is used.
+type TagUsage struct {
+	TagID           int64 `gorm:"column:tag_id" json:"tag_id"`
+	QuestionCount   int64 `gorm:"column:question_count" json:"question_count"`
+	AssessmentCount int64 `gorm:"column:assessment_count" json:"assessment_count"`
+	BlueprintCount  int64 `gorm:"column:blueprint_count" json:"blueprint_count"`
+	ChildCount      int64 `gorm:"column:child_count" json:"child_count"`
+}
+
+// TagNode is a tag in the taxonomy tree with its usage.
+type TagNode struct {
+	TagID       int64      `json:"tag_id"`
+	Tag         string     `json:"tag"`
+	ParentTagID *int64     `json:"parent_tag_id,omitempty"`
+	Path        string     `json:"path"`
+	Usage       TagUsage   `json:"usage"`
+	Children    []*TagNode `json:"children"`
+}
+
+type TagTreeResponse struct {
+	StrictMode bool       `json:"strict_mode"`
+	Tags       []*TagNode `json:"tags"`
+}
+
+type CreateTagRequest struct {
+	Tag         string `json:"tag" binding:"required"`
+	ParentTagID *int64 `json:"parent_tag_id"`
+}
+
+type RenameTagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+// MergeTagRequest folds SourceTagID into TargetTagID: its question and assessment mappings
+// and its children move to the target and the source is deleted.
+type MergeTagRequest struct {
+	SourceTagID int64 `json:"source_tag_id" binding:"required"`
+	TargetTagID int64 `json:"target_tag_id" binding:"required"`
+}
+
+// MoveTagRequest re-parents a tag and its subtree; a nil ParentTagID makes it a root tag.
+type MoveTagRequest struct {
+	ParentTagID *int64 `json:"parent_tag_id"`
+}
+
+type MergeTagResult struct {
+	SourceTagID        int64 `json:"source_tag_id"`
+	TargetTagID        int64 `json:"target_tag_id"`
+	QuestionMappings   int64 `json:"question_mappings"`
+	AssessmentMappings int64 `json:"assessment_mappings"`
+	ChildrenMoved      int64 `json:"children_moved"`
+	BlueprintsUpdated  int64 `json:"blueprints_updated"`
+}
@@ -18,6 +18,8 @@ type AssessmentUser struct {
 	Password   string    `gorm:"type:varchar(255);column:password" json:"-"`
 	IsActive   bool      `gorm:"default:true;column:is_active" json:"is_active"`
 	NotifyId   string    `gorm:"column:notify_id;type:varchar" json:"notify_id"`
+	// Locale picks the language of the notifications the user receives.
+	Locale     string    `gorm:"column:locale;type:varchar(35)" json:"locale"`
 
 	UserType string `gorm:"type:varchar(20);column:user_type" json:"user_type"` // THIS
 
@@ -104,6 +106,7 @@ type UserProfileUpdate struct {
 	Phone         *string  `json:"phone,omitempty"`
 	AuthUserID    *string  `json:"auth_user_id,omitempty"`
 	NotifyId      *string  `json:"notify_id,omitempty"`
+	Locale        *string  `json:"locale,omitempty"`
 	Password      *string  `json:"password,omitempty"`
 	CompanyID     *int     `json:"company_id,omitempty"`
 	Karma         *int     `json:"karma,omitempty"`
@@ -143,4 +146,9 @@ type UserFullData struct {
 	SelectionType *string  `json:"selection_type"`
 	Roles         []string `json:"roles"`
 	UserType      string   `json:"user_type"`
+
+	// CertificationStatus is the worst status across Certifications; empty when the user
+	// holds no recurring certification.
+	CertificationStatus string                 `json:"certification_status,omitempty"`
+	Certifications      []CertificationSummary `json:"certifications,omitempty"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserNotification is an entry of a user's in-app inbox. It is written with the outbox
+// notification of the same event; Title and Body are rendered in the user's locale when read.
+type UserNotification struct {
+	ID                 int64           `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID             string          `gorm:"column:user_id" json:"user_id"`
+	Kind               string          `gorm:"column:kind" json:"kind"`
+	TemplateKey        string          `gorm:"column:template_key" json:"template_key"`
+	AssessmentSequence string          `gorm:"column:assessment_sequence" json:"assessment_sequence"`
+	Payload            json.RawMessage `gorm:"column:payload;type:jsonb" json:"data"`
+	IsRead             bool            `gorm:"column:is_read" json:"is_read"`
+	ReadOn             *time.Time      `gorm:"column:read_on" json:"read_on,omitempty"`
+	CreatedOn          time.Time       `gorm:"column:created_on" json:"created_on"`
+
+	Title string `gorm:"-" json:"title"`
+	Body  string `gorm:"-" json:"body"`
+}
+
+func (UserNotification) TableName() string {
+	return "user_notification"
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1"`
+}
+
+type UnreadNotifications struct {
+	Unread int64 `json:"unread"`
+}
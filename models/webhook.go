@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WebhookEndpoint is an external URL told about the lifecycle events it subscribes to. Every
+// request is signed with its Secret.
+type WebhookEndpoint struct {
+	ID         int64          `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name       string         `gorm:"column:name" json:"name"`
+	URL        string         `gorm:"column:url" json:"url"`
+	Secret     string         `gorm:"column:secret" json:"secret,omitempty"`
+	Events     pq.StringArray `gorm:"column:events;type:text[]" json:"events"`
+	IsActive   bool           `gorm:"column:is_active" json:"is_active"`
+	CreatedOn  time.Time      `gorm:"column:created_on" json:"created_on"`
+	CreatedBy  string         `gorm:"column:created_by" json:"created_by"`
+	ModifiedOn time.Time      `gorm:"column:modified_on" json:"modified_on"`
+	ModifiedBy string         `gorm:"column:modified_by" json:"modified_by"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoint"
+}
+
+// SaveWebhookRequest registers or updates an endpoint. A secret is generated when none is
+// given; it is only shown in the response of the request that sets it.
+type SaveWebhookRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	URL      string   `json:"url" binding:"required,url"`
+	Secret   string   `json:"secret" binding:"omitempty,min=16"`
+	Events   []string `json:"events" binding:"required,min=1,dive,oneof=assessment.published assignment.created session.started session.submitted result.graded certificate.issued"`
+	IsActive *bool    `json:"is_active"`
+}
+
+// WebhookDelivery is one event for one endpoint, kept until it is delivered or runs out of
+// attempts, and as the delivery log after.
+type WebhookDelivery struct {
+	ID             int64           `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	EndpointID     int64           `gorm:"column:endpoint_id" json:"endpoint_id"`
+	EventID        string          `gorm:"column:event_id" json:"event_id"`
+	Event          string          `gorm:"column:event" json:"event"`
+	Payload        json.RawMessage `gorm:"column:payload;type:jsonb" json:"payload"`
+	Status         string          `gorm:"column:status" json:"status"`
+	Attempts       int             `gorm:"column:attempts" json:"attempts"`
+	NextAttemptOn  time.Time       `gorm:"column:next_attempt_on" json:"next_attempt_on"`
+	ResponseStatus int             `gorm:"column:response_status" json:"response_status,omitempty"`
+	LastError      string          `gorm:"column:last_error" json:"last_error,omitempty"`
+	CreatedOn      time.Time       `gorm:"column:created_on" json:"created_on"`
+	DeliveredOn    *time.Time      `gorm:"column:delivered_on" json:"delivered_on,omitempty"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+type WebhookDeliveryFilter struct {
+	EndpointID int64
+	Event      string
+	Status     string
+}
+
+// WebhookEvent is the body posted to endpoints. ID is the same on every attempt, so receivers
+// can drop the duplicates at-least-once delivery brings.
+type WebhookEvent struct {
+	ID         string                 `json:"id"`
+	Event      string                 `json:"event"`
+	OccurredOn time.Time              `json:"occurred_on"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// SessionScore is the score of a submitted session.
+type SessionScore struct {
+	MarksObtained float64 `gorm:"column:marks_obtained" json:"marks_obtained"`
+	TotalMarks    float64 `gorm:"column:total_marks" json:"total_marks"`
+	PassingScore  float64 `gorm:"column:passing_score" json:"passing_score"`
+}
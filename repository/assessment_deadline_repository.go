@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dueDateSQL is when an assignment is due: the user's own due date, else the assessment
+// deadline, where a zero deadline counts as unset. It expects assessment_status as ast and
+// dhl_survey_survey_ext as sse.
+const dueDateSQL = `COALESCE(ast.due_date, CASE WHEN sse.deadline > '1900-01-01' THEN sse.deadline END)`
+
+// openAssignmentStatuses are the assessment_status values of assignments the user has yet to
+// finish, whether or not they started.
+var openAssignmentStatuses = []string{constant.AssignmentAssigned, constant.AssignmentReassigned, constant.AssignmentStarted}
+
+// overdueSQL tells whether an unfinished assignment is past its due date.
+const overdueSQL = `(ast.assessment_status IN ('` + constant.AssignmentAssigned + `', '` + constant.AssignmentReassigned + `', '` + constant.AssignmentStarted + `')
+	AND ` + dueDateSQL + ` IS NOT NULL AND ` + dueDateSQL + ` < NOW())`
+
+type AssessmentDeadlineRepository interface {
+	GetAssignment(tx *gorm.DB, assessmentSeq, userID string) (*models.AssessmentStatus, error)
+	GetDueDate(tx *gorm.DB, assessmentSeq, userID string) (*time.Time, error)
+	SetDueDates(tx *gorm.DB, assessmentSeq string, userIDs []string, dueDate *time.Time, modifiedBy string) (int64, error)
+	ReopenExpired(tx *gorm.DB, assessmentSeq, userID, modifiedBy string) error
+	CountManagedUsers(managerID string, userIDs []string) (int64, error)
+
+	CreateExtension(tx *gorm.DB, ext *models.AssessmentExtensionRequest) error
+	GetExtension(tx *gorm.DB, extensionID int64) (*models.AssessmentExtensionRequest, error)
+	HasPendingExtension(tx *gorm.DB, assessmentSeq, userID string) (bool, error)
+	SaveExtension(tx *gorm.DB, ext *models.AssessmentExtensionRequest) error
+	ListExtensions(filter models.ExtensionFilter, limit, offset int) ([]models.AssessmentExtensionRequest, int64, error)
+}
+
+type AssessmentDeadlineRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssessmentDeadlineRepository(db *gorm.DB) AssessmentDeadlineRepository {
+	return &AssessmentDeadlineRepositoryImpl{db: db}
+}
+
+func (r *AssessmentDeadlineRepositoryImpl) GetAssignment(tx *gorm.DB, assessmentSeq, userID string) (*models.AssessmentStatus, error) {
+	var st models.AssessmentStatus
+	if err := tx.Where("assessment_id = ? AND user_id = ? AND is_deleted = false", assessmentSeq, userID).First(&st).Error; err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// GetDueDate returns when the user's assignment is due, or nil when it has no due date.
+func (r *AssessmentDeadlineRepositoryImpl) GetDueDate(tx *gorm.DB, assessmentSeq, userID string) (*time.Time, error) {
+	var due *time.Time
+	err := tx.Raw(`
+		SELECT `+dueDateSQL+`
+		FROM assessment_status ast
+		LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = ast.assessment_id
+		WHERE ast.assessment_id = ? AND ast.user_id = ? AND ast.is_deleted = false
+		LIMIT 1
+	`, assessmentSeq, userID).Row().Scan(&due)
+	return due, err
+}
+
+// SetDueDates sets the due date of the given users' assignments; a nil dueDate falls back to
+// the assessment deadline. Users without an assignment are skipped.
+func (r *AssessmentDeadlineRepositoryImpl) SetDueDates(tx *gorm.DB, assessmentSeq string, userIDs []string, dueDate *time.Time, modifiedBy string) (int64, error) {
+	res := tx.Model(&models.AssessmentStatus{}).
+		Where("assessment_id = ? AND user_id IN ? AND is_deleted = false", assessmentSeq, userIDs).
+		Updates(map[string]interface{}{
+			"due_date":    dueDate,
+			"modified_on": time.Now(),
+			"modified_by": modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// ReopenExpired gives an expired assignment back to the user after an extension.
+func (r *AssessmentDeadlineRepositoryImpl) ReopenExpired(tx *gorm.DB, assessmentSeq, userID, modifiedBy string) error {
+	return tx.Model(&models.AssessmentStatus{}).
+		Where("assessment_id = ? AND user_id = ? AND assessment_status = ? AND is_deleted = false",
+			assessmentSeq, userID, constant.AssignmentExpired).
+		Updates(map[string]interface{}{
+			"assessment_status": constant.AssignmentReassigned,
+			"modified_on":       time.Now(),
+			"modified_by":       modifiedBy,
+		}).Error
+}
+
+// CountManagedUsers counts how many of userIDs report to the manager.
+func (r *AssessmentDeadlineRepositoryImpl) CountManagedUsers(managerID string, userIDs []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserManagerMapping{}).
+		Where("manager_id = ? AND user_id IN ? AND is_active = true", managerID, userIDs).
+		Distinct("user_id").
+		Count(&count).Error
+	return count, err
+}
+
+// CreateExtension files a pending extension request. It returns gorm.ErrDuplicatedKey when the
+// user already has one pending for the assessment.
+func (r *AssessmentDeadlineRepositoryImpl) CreateExtension(tx *gorm.DB, ext *models.AssessmentExtensionRequest) error {
+	res := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "assessment_sequence"}, {Name: "user_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Name: "status"}, Value: constant.ExtensionPending}}},
+		DoNothing:   true,
+	}).Create(ext)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrDuplicatedKey
+	}
+	return nil
+}
+
+func (r *AssessmentDeadlineRepositoryImpl) GetExtension(tx *gorm.DB, extensionID int64) (*models.AssessmentExtensionRequest, error) {
+	var ext models.AssessmentExtensionRequest
+	if err := tx.Where("id = ?", extensionID).First(&ext).Error; err != nil {
+		return nil, err
+	}
+	return &ext, nil
+}
+
+func (r *AssessmentDeadlineRepositoryImpl) HasPendingExtension(tx *gorm.DB, assessmentSeq, userID string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.AssessmentExtensionRequest{}).
+		Where("assessment_sequence = ? AND user_id = ? AND status = ?", assessmentSeq, userID, constant.ExtensionPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *AssessmentDeadlineRepositoryImpl) SaveExtension(tx *gorm.DB, ext *models.AssessmentExtensionRequest) error {
+	return tx.Save(ext).Error
+}
+
+// ListExtensions pages through extension requests, newest first. ManagerID limits them to the
+// manager's users.
+func (r *AssessmentDeadlineRepositoryImpl) ListExtensions(filter models.ExtensionFilter, limit, offset int) ([]models.AssessmentExtensionRequest, int64, error) {
+	query := r.db.Table("assessment_extension_request er").
+		Joins("LEFT JOIN assessment_user_mst u ON u.user_id::text = er.user_id").
+		Joins("LEFT JOIN assessment_mst am ON am.assessment_sequence = er.assessment_sequence")
+	if filter.UserID != "" {
+		query = query.Where("er.user_id = ?", filter.UserID)
+	}
+	if filter.ManagerID != "" {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM user_manager_mapping um
+			WHERE um.user_id = er.user_id AND um.manager_id = ? AND um.is_active = true
+		)`, filter.ManagerID)
+	}
+	if filter.AssessmentSequence != "" {
+		query = query.Where("er.assessment_sequence = ?", filter.AssessmentSequence)
+	}
+	if filter.Status != "" {
+		query = query.Where("er.status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []models.AssessmentExtensionRequest
+	err := query.
+		Select("er.*, CONCAT(u.first_name, ' ', u.last_name) AS user_name, am.assessment_desc AS assessment_title").
+		Order("er.created_on DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// hierarchyColumns maps the profile hierarchies to the column naming a user's superior. The
+// column holds the superior's employee code or email.
+var hierarchyColumns = map[string]string{
+	constant.HierarchyTeamLead: "ext.team_lead",
+	constant.HierarchyManager:  "ext.manager",
+	constant.HierarchySDL:      "ext.sdl",
+}
+
+// teamTreeSQL walks the delegations of @seq down from @manager, at most @maxLevel deep.
+const teamTreeSQL = `
+	WITH RECURSIVE tree AS (
+		SELECT d.delegated_to AS user_id, d.delegated_by, 1 AS level
+		FROM assessment_delegation d
+		WHERE d.assessment_sequence = @seq AND d.delegated_by = @manager
+		UNION
+		SELECT d.delegated_to, d.delegated_by, t.level + 1
+		FROM assessment_delegation d
+		JOIN tree t ON d.delegated_by = t.user_id
+		WHERE d.assessment_sequence = @seq AND t.level < @maxLevel
+	)
+`
+
+type AssessmentDelegationRepository interface {
+	GetSubordinates(tx *gorm.DB, managerIDs []string, hierarchy string) ([]models.Subordinate, error)
+	AssignUnassigned(tx *gorm.DB, assessmentSeq string, userIDs []string, dueDate *time.Time, createdBy string) ([]string, error)
+	RecordDelegations(tx *gorm.DB, delegations []models.AssessmentDelegation) error
+	ListDelegations(filter models.DelegationFilter, limit, offset int) ([]models.AssessmentDelegation, int64, error)
+	GetTeamProgress(managerID, assessmentSeq string, maxLevel, limit, offset int) ([]models.TeamAssignment, int64, error)
+	GetTeamSummary(managerID, assessmentSeq string, maxLevel int) (*models.TeamProgressSummary, error)
+}
+
+type AssessmentDelegationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssessmentDelegationRepository(db *gorm.DB) AssessmentDelegationRepository {
+	return &AssessmentDelegationRepositoryImpl{db: db}
+}
+// GetSubordinates returns the active users directly under each manager in the hierarchy. Blank
+// superior columns, employee codes and emails never match.
+// GetSubordinates returns the active users directly under each manager in the hierarchy.
+func (r *AssessmentDelegationRepositoryImpl) GetSubordinates(tx *gorm.DB, managerIDs []string, hierarchy string) ([]models.Subordinate, error) {
+	var subs []models.Subordinate
+	if len(managerIDs) == 0 {
+		return subs, nil
+	}
+	if hierarchy == constant.HierarchyMapping {
+		err := tx.Raw(`
+			SELECT um.manager_id, um.user_id
+			FROM user_manager_mapping um
+			JOIN assessment_user_mst u ON u.user_id::text = um.user_id
+			WHERE um.manager_id IN ? AND um.is_active = true AND u.is_active = true
+		`, managerIDs).Scan(&subs).Error
+		return subs, err
+	}
+
+	column, ok := hierarchyColumns[hierarchy]
+	if !ok {
+		return nil, fmt.Errorf("unknown hierarchy %q", hierarchy)
+	}
+	err := tx.Raw(fmt.Sprintf(`
+		SELECT m.user_id::text AS manager_id, u.user_id::text AS user_id
+		FROM assessment_user_mst m
+		LEFT JOIN dhl_assessment_user_mst_ext mx ON mx.user_id = m.user_id::text
+		JOIN dhl_assessment_user_mst_ext ext
+			ON LOWER(NULLIF(TRIM(%[1]s), '')) = LOWER(NULLIF(TRIM(mx.emp_code), ''))
+			OR LOWER(NULLIF(TRIM(%[1]s), '')) = LOWER(NULLIF(TRIM(m.email), ''))
+		JOIN assessment_user_mst u ON u.user_id::text = ext.user_id
+		WHERE m.user_id::text IN ? AND u.is_active = true AND u.user_id <> m.user_id
+	`, column), managerIDs).Scan(&subs).Error
+	return subs, err
+}
+
+// AssignUnassigned assigns the assessment to the users who have no assignment of it, in any
+// state, and returns them.
+func (r *AssessmentDelegationRepositoryImpl) AssignUnassigned(tx *gorm.DB, assessmentSeq string, userIDs []string, dueDate *time.Time, createdBy string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var assigned []string
+	if err := tx.Model(&models.AssessmentStatus{}).
+		Where("assessment_id = ? AND user_id IN ?", assessmentSeq, userIDs).
+		Pluck("user_id", &assigned).Error; err != nil {
+		return nil, err
+	}
+	skip := make(map[string]bool, len(assigned))
+	for _, uid := range assigned {
+		skip[uid] = true
+	}
+
+	now := time.Now()
+	var statuses []models.AssessmentStatus
+	var newIDs []string
+	for _, uid := range userIDs {
+		if skip[uid] {
+			continue
+		}
+		skip[uid] = true
+		newIDs = append(newIDs, uid)
+		statuses = append(statuses, models.AssessmentStatus{
+			CreatedOn:        now,
+			CreatedBy:        createdBy,
+			IsActive:         true,
+			ModifiedOn:       now,
+			ModifiedBy:       createdBy,
+			AssessmentID:     assessmentSeq,
+			AssessmentStatus: constant.AssignmentAssigned,
+			UserID:           uid,
+			DueDate:          dueDate,
+		})
+	}
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	return newIDs, tx.CreateInBatches(&statuses, 500).Error
+}
+
+// RecordDelegations saves the delegations to users not delegated the assessment before.
+func (r *AssessmentDelegationRepositoryImpl) RecordDelegations(tx *gorm.DB, delegations []models.AssessmentDelegation) error {
+	for i := range delegations {
+		d := &delegations[i]
+		var count int64
+		if err := tx.Model(&models.AssessmentDelegation{}).
+			Where("assessment_sequence = ? AND delegated_to = ?", d.AssessmentSequence, d.DelegatedTo).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(d).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *AssessmentDelegationRepositoryImpl) ListDelegations(filter models.DelegationFilter, limit, offset int) ([]models.AssessmentDelegation, int64, error) {
+	query := r.db.Table("assessment_delegation d").
+		Joins("LEFT JOIN assessment_user_mst fu ON fu.user_id::text = d.delegated_by").
+		Joins("LEFT JOIN assessment_user_mst tu ON tu.user_id::text = d.delegated_to").
+		Joins("LEFT JOIN assessment_mst am ON am.assessment_sequence = d.assessment_sequence")
+	if filter.AssessmentSequence != "" {
+		query = query.Where("d.assessment_sequence = ?", filter.AssessmentSequence)
+	}
+	if filter.DelegatedBy != "" {
+		query = query.Where("d.delegated_by = ?", filter.DelegatedBy)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []models.AssessmentDelegation
+	err := query.
+		Select(`d.*,
+			CONCAT(fu.first_name, ' ', fu.last_name) AS delegated_by_name,
+			CONCAT(tu.first_name, ' ', tu.last_name) AS delegated_to_name,
+			am.assessment_desc AS assessment_title`).
+		Order("d.assessment_sequence, d.level, d.created_on").
+		Limit(limit).
+		Offset(offset).
+		Scan(&list).Error
+	return list, total, err
+}
+
+// GetTeamProgress pages through the assignments of everyone the manager delegated the
+// assessment to, directly or down the line.
+func (r *AssessmentDelegationRepositoryImpl) GetTeamProgress(managerID, assessmentSeq string, maxLevel, limit, offset int) ([]models.TeamAssignment, int64, error) {
+	params := map[string]interface{}{
+		"seq":      assessmentSeq,
+		"manager":  managerID,
+		"maxLevel": maxLevel,
+		"limit":    limit,
+		"offset":   offset,
+	}
+
+	var total int64
+	if err := r.db.Raw(teamTreeSQL+"SELECT COUNT(DISTINCT user_id) FROM tree", params).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []models.TeamAssignment
+	err := r.db.Raw(teamTreeSQL+`
+		SELECT * FROM (
+			SELECT DISTINCT ON (t.user_id)
+				t.user_id,
+				CONCAT(u.first_name, ' ', u.last_name) AS user_name,
+				u.email,
+				t.delegated_by,
+				CONCAT(du.first_name, ' ', du.last_name) AS delegated_by_name,
+				t.level,
+				COALESCE(ast.assessment_status, '') AS status,
+				`+dueDateSQL+` AS due_date,
+				COALESCE(`+overdueSQL+`, false) AS is_overdue
+			FROM tree t
+			LEFT JOIN assessment_user_mst u ON u.user_id::text = t.user_id
+			LEFT JOIN assessment_user_mst du ON du.user_id::text = t.delegated_by
+			LEFT JOIN assessment_status ast
+				ON ast.assessment_id = @seq AND ast.user_id = t.user_id AND ast.is_deleted = false
+			LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = @seq
+			ORDER BY t.user_id, t.level
+		) p
+		ORDER BY p.level, p.user_name
+		LIMIT @limit OFFSET @offset
+	`, params).Scan(&rows).Error
+	return rows, total, err
+}
+
+// GetTeamSummary counts the assignments in the manager's delegation subtree by progress.
+func (r *AssessmentDelegationRepositoryImpl) GetTeamSummary(managerID, assessmentSeq string, maxLevel int) (*models.TeamProgressSummary, error) {
+	var summary models.TeamProgressSummary
+	err := r.db.Raw(teamTreeSQL+`
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE ast.assessment_status IN ('`+constant.AssignmentAssigned+`', '`+constant.AssignmentReassigned+`', '`+constant.AssignmentStarted+`')) AS pending,
+			COUNT(*) FILTER (WHERE ast.assessment_status = '`+constant.AssignmentCompleted+`') AS completed,
+			COUNT(*) FILTER (WHERE ast.assessment_status = '`+constant.AssignmentExpired+`') AS expired,
+			COUNT(*) FILTER (WHERE `+overdueSQL+`) AS overdue
+		FROM (SELECT DISTINCT user_id FROM tree) t
+		JOIN assessment_status ast
+			ON ast.assessment_id = @seq AND ast.user_id = t.user_id AND ast.is_deleted = false
+		LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = @seq
+	`, map[string]interface{}{
+		"seq":      assessmentSeq,
+		"manager":  managerID,
+		"maxLevel": maxLevel,
+	}).Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
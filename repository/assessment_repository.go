@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"dhl/config"
 	"dhl/constant"
 	"dhl/models"
 	"dhl/utils"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -17,7 +19,8 @@ import (
 type AssessmentRepository interface {
 	GetAssessmentMstByAssmtSeq(id string) (*models.AssessmentMst, error)
 	GetDhlSurveyExtByAssmtSeq(id string) (*models.DhlSurveySurveyExtResponse, error)
-	CreateUserSession(tx *gorm.DB, userID, assessmentID, assessmentType string, partnerID int64) (*models.AssessmentUserSession, error)
+	GetSurveyExtSettingsByAssmtSeq(id string) (*models.DhlSurveySurveyExt, error)
+	CreateUserSession(tx *gorm.DB, userID, assessmentID, assessmentType string, partnerID int64, locale string) (*models.AssessmentUserSession, error)
 	GetUserAssessmentsMap(userID string) ([]models.AssessmentStatus, error)
 	GetUserAssessmentStatus(tx *gorm.DB, userID, assessmentID string) (*models.AssessmentStatus, error)
 	GetAssessmentQuestions(assessmentSeq string) ([]models.AssessmentQuestionMst, error)
@@ -40,6 +43,7 @@ type AssessmentRepository interface {
 	AddNewQuestion(tx *gorm.DB, question models.QuestionMain, assessmentSequence string) (int64, error)
 	AddNewOption(tx *gorm.DB, option models.OptionMain) error
 	SaveAssessmentWithQuestions(ctx context.Context, tx *gorm.DB, assessment models.SheetAssessment, userId string) (string, error)
+	SaveSheetQuestions(ctx context.Context, tx *gorm.DB, assessmentID int64, assessmentSequence string, questions []models.SheetQuestion, userId string, startSequence int64) ([]int64, error)
 	SaveAssessmentResponse(tx *gorm.DB, session *models.AssessmentUserSession, assessmentSeq string, response models.UserResponse) error
 	UpdateAssessmentStatus(tx *gorm.DB, userID, assessmentID, status string) (*models.AssessmentStatus, error)
 	AddManagerAssessmentMapping(tx *gorm.DB, userID, assessmentID string) (*models.ManagerAssessmentMapping, error)
@@ -102,6 +106,14 @@ func (r *AssessmentRepositoryImpl) GetAssessmentMstByAssmtSeq(id string) (*model
 	return &a, nil
 }
 
+func (r *AssessmentRepositoryImpl) GetSurveyExtSettingsByAssmtSeq(id string) (*models.DhlSurveySurveyExt, error) {
+	var ext models.DhlSurveySurveyExt
+	if err := r.db.Where("assessment_sequence = ?", id).First(&ext).Error; err != nil {
+		return nil, err
+	}
+	return &ext, nil
+}
+
 func (r *AssessmentRepositoryImpl) GetDhlSurveyExtByAssmtSeq(id string) (*models.DhlSurveySurveyExtResponse, error) {
 	var resp models.DhlSurveySurveyExtResponse
 
@@ -160,6 +172,7 @@ func (r *AssessmentRepositoryImpl) CreateUserSession(
 	assessmentID,
 	assessmentType string,
 	partnerID int64,
+	locale string,
 ) (*models.AssessmentUserSession, error) {
 
 	createdOn := time.Now()
@@ -170,6 +183,7 @@ func (r *AssessmentRepositoryImpl) CreateUserSession(
 		AssessmentID:   assessmentID,
 		AssessmentType: assessmentType,
 		PartnerID:      partnerID,
+		Locale:         locale,
 		IsActive:       true,
 		IsDeleted:      false,
 		AccessTime:     createdOn,
@@ -217,7 +231,8 @@ func (r *AssessmentRepositoryImpl) GetAssessmentsForUserWithPagination(userId st
     am.assessment_desc AS assessment_title,
     sse.time_limit,
     am.marks,
-    sse.deadline,
+    ` + dueDateSQL + ` AS deadline,
+    ` + overdueSQL + ` AS is_overdue,
     sse.state as assessment_status,
     sse.show_result,
     sse.certificate,
@@ -354,7 +369,7 @@ func (r *AssessmentRepositoryImpl) GetUserAssessmentStatus(tx *gorm.DB, userID,
 			status = models.AssessmentStatus{
 				UserID:           userID,
 				AssessmentID:     assessmentID,
-				AssessmentStatus: "STARTED",
+				AssessmentStatus: constant.AssignmentStarted,
 				IsActive:         true,
 				IsDeleted:        false,
 				CreatedBy:        userID,
@@ -385,7 +400,8 @@ func (r *AssessmentRepositoryImpl) GetContentByQuestionID(questionID int64) (mod
 			c.font,
 			c.value,
 			q.question_type_id,
-			tc.question_type			
+			tc.question_type,
+			q.feedback
 		FROM content_mst c
 		INNER JOIN content_type_config ct ON ct.content_type_id = c.content_type_id
 		INNER JOIN question_mst q ON q.content_id = c.content_id
@@ -440,7 +456,12 @@ func (r *AssessmentRepositoryImpl) GetCertificateDetailsBySessionId(sessionId st
 			) as marks_obtained,
 			a.marks AS total_marks,
 			a.passing_score,
-			s.created_on AS completed_at
+			s.created_on AS completed_at,
+			COALESCE(
+				(SELECT uc.expires_on FROM user_certification uc WHERE uc.session_id = s.session_id::text),
+				(SELECT s.created_on + make_interval(months => rc.interval_months) FROM assessment_recurrence rc
+				WHERE rc.assessment_sequence = a.assessment_sequence AND rc.is_active = true)
+			) AS expires_on
         FROM assessment_user_session s
         JOIN assessment_mst a ON a.assessment_sequence = s.assessment_id
         JOIN assessment_user_mst u ON u.user_id::text = s.user_id
@@ -723,7 +744,9 @@ func (r *AssessmentRepositoryImpl) UpdateOption(tx *gorm.DB, option *models.Opti
 	if err != nil {
 		return err
 	}
-	return nil
+	return tx.Model(&models.QuestionMst{}).
+		Where("question_id = ?", opst.QuestionID).
+		Update("modified_on", time.Now()).Error
 }
 
 // Admin Repo functions
@@ -835,8 +858,12 @@ WHERE assessment_id = ?;
     u.first_name,
     u.last_name,
     u.email,
-    dc.center_name
+    dc.center_name,
+    ` + dueDateSQL + ` AS due_date,
+    ` + overdueSQL + ` AS is_overdue
 FROM assessment_status ast
+LEFT JOIN dhl_survey_survey_ext sse
+    ON sse.assessment_sequence = ast.assessment_id
 LEFT JOIN assessment_user_mst u
     ON ast.user_id = u.user_id::text
 LEFT JOIN dhl_assessment_user_mst_ext ue
@@ -1154,56 +1181,87 @@ func (r *AssessmentRepositoryImpl) SaveAssessmentWithQuestions(ctx context.Conte
 	}
 
 	// 2️⃣ Insert into dhl_survey_survey_ext using struct
-	surveyExt := models.DhlSurveySurveyExt{
-		SurveyID:           assessmentID,
-		State:              string(constant.Draft),
-		AssessmentSequence: assessmentSequence,
-		Certificate:        true,
-		AttemptsLimit:      1,
-		ShowResult:         true,
-		TimeLimit:          30,
-		IsTypingTest:       false,
-	}
+	surveyExt := models.DefaultAssessmentSettings().DraftSurveyExt(assessmentID, assessmentSequence)
 	if err := tx.WithContext(ctx).Create(&surveyExt).Error; err != nil {
 		return "", fmt.Errorf("failed to insert dhl_survey_survey_ext: %w", err)
 	}
 
-	// 3️⃣ Loop through questions and collect question IDs
-	var questionIDs []int64
-
-	for _, q := range assessment.Questions {
-		// Insert question text in content_mst
-		contentQ := models.ContentMst{
-			ContentTypeID: 1,
-			Value:         q.Title,
+	for locale, name := range assessment.NameTranslations {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		translation := models.AssessmentTranslation{
+			AssessmentSequence: assessmentSequence,
+			Locale:             locale,
+			Title:              strings.TrimSpace(name),
+			CreatedOn:          createdAt,
+			CreatedBy:          userId,
+			ModifiedOn:         createdAt,
+			ModifiedBy:         userId,
 		}
-		if err := tx.WithContext(ctx).Create(&contentQ).Error; err != nil {
-			return "", fmt.Errorf("failed to insert question content: %w", err)
+		if err := tx.WithContext(ctx).Create(&translation).Error; err != nil {
+			return "", fmt.Errorf("failed to insert assessment translation: %w", err)
 		}
+	}
 
-		questionContentID := contentQ.ContentID
+	// 3️⃣ Save questions and collect question IDs
+	questionIDs, err := r.SaveSheetQuestions(ctx, tx, assessmentID, assessmentSequence, assessment.Questions, userId, Unnumbered)
+	if err != nil {
+		return "", err
+	}
 
-		// Insert into question_mst
-		questionTypeID := 0
-		if id, ok := utils.QuestionTypeMap[q.QuestionType]; ok {
-			questionTypeID = id
-		}
+	if len(assessment.Tags) > 0 {
+		for _, tagReq := range assessment.Tags {
+			tagIDs, err := r.ProcessTagRequest(tx, tagReq, userId)
+			if err != nil {
+				return "", fmt.Errorf("failed to process tag request for assessment: %w", err)
+			}
+			for _, tagID := range tagIDs {
+				if err := r.CreateAssessmentTagMappingWithParents(tx, assessmentSequence, tagID, userId); err != nil {
+					return "", fmt.Errorf("failed to create assessment tag mapping: %w", err)
+				}
 
-		questionMst := models.QuestionMst{
-			ContentID:      questionContentID,
-			QuestionTypeID: int64(questionTypeID),
-			IsActive:       true,
-			IsDeleted:      false,
-			CreatedOn:      createdAt,
-			ModifiedOn:     createdAt,
-		}
-		if err := tx.WithContext(ctx).Create(&questionMst).Error; err != nil {
-			return "", fmt.Errorf("failed to insert question_mst: %w", err)
+				// Map to ALL questions in the assessment
+				for _, qID := range questionIDs {
+					if err := r.CreateQuestionTagMappingWithParents(tx, qID, tagID, userId); err != nil {
+						return "", fmt.Errorf("failed to create question tag mapping for assessment tag: %w", err)
+					}
+				}
+			}
 		}
+	}
+
+	return assessmentSequence, nil
+}
 
-		questionID := questionMst.QuestionID
+// Unnumbered leaves the questions saved by SaveSheetQuestions at sequence 0, as sheet imports
+// always have.
+const Unnumbered int64 = -1
+
+// SaveSheetQuestions creates content, question, option and tag rows for each sheet question
+// and links them to the given assessment, numbering them after startSequence, or not at all
+// with Unnumbered.
+func (r *AssessmentRepositoryImpl) SaveSheetQuestions(ctx context.Context, tx *gorm.DB, assessmentID int64, assessmentSequence string, questions []models.SheetQuestion, userId string, startSequence int64) ([]int64, error) {
+	createdAt := time.Now()
+	var questionIDs []int64
+
+	for idx, q := range questions {
+		// Merged duplicates reuse the bank question and only get linked to the assessment
+		questionID := q.ExistingQuestionID
+		if questionID == 0 {
+			var err error
+			questionID, err = createSheetQuestion(ctx, tx, q, userId, createdAt)
+			if err != nil {
+				return nil, err
+			}
+		}
 		questionIDs = append(questionIDs, questionID) // Collect question ID
 
+		var sequence int64
+		if startSequence != Unnumbered {
+			sequence = startSequence + int64(idx+1)
+		}
+
 		// Link question to assessment
 		// Note: skipping_allowed should be opposite of mandatory_to_answer
 		skippingAllowed := !q.MandatoryToAnswer
@@ -1219,38 +1277,15 @@ func (r *AssessmentRepositoryImpl) SaveAssessmentWithQuestions(ctx context.Conte
 			CreatedBy:          userId,
 			ModifiedOn:         createdAt,
 			ModifiedBy:         userId,
-			SequenceID:         0,
-			CorrectPoints:      0,
-			DurationInSeconds:  0,
-			NegativePoints:     0,
-			DifficultyLevel:    "",
+			SequenceID:         sequence,
+			CorrectPoints:      q.Points,
+			DurationInSeconds:  q.DurationInSeconds,
+			NegativePoints:     q.NegativePoints,
+			DifficultyLevel:    q.DifficultyLevel,
 		}
 
 		if err := tx.WithContext(ctx).Create(&assessmentQ).Error; err != nil {
-			return "", fmt.Errorf("failed to insert assessment_question_mst: %w", err)
-		}
-
-		// 4️⃣ Loop through options
-		for _, opt := range q.Options {
-			contentOpt := models.ContentMst{
-				ContentTypeID: 1,
-				Value:         opt.Label,
-			}
-			if err := tx.WithContext(ctx).Create(&contentOpt).Error; err != nil {
-				return "", fmt.Errorf("failed to insert option content: %w", err)
-			}
-
-			optionContentID := contentOpt.ContentID
-
-			optionMst := models.OptionMst{
-				ContentID:   optionContentID,
-				IsAnswer:    opt.IsCorrect,
-				QuestionID:  questionID,
-				AnswerScore: opt.Score,
-			}
-			if err := tx.WithContext(ctx).Create(&optionMst).Error; err != nil {
-				return "", fmt.Errorf("failed to insert option_mst: %w", err)
-			}
+			return nil, fmt.Errorf("failed to insert assessment_question_mst: %w", err)
 		}
 
 		// Handle question tags if provided (with parent-child tag support)
@@ -1258,40 +1293,122 @@ func (r *AssessmentRepositoryImpl) SaveAssessmentWithQuestions(ctx context.Conte
 			for _, tagReq := range q.Tags {
 				tagIDs, err := r.ProcessTagRequest(tx, tagReq, userId)
 				if err != nil {
-					return "", fmt.Errorf("failed to process tag request for question: %w", err)
+					return nil, fmt.Errorf("failed to process tag request for question: %w", err)
 				}
 				// Create mappings for all tags (parent and children)
 				for _, tagID := range tagIDs {
 					if err := r.CreateQuestionTagMappingWithParents(tx, questionID, tagID, userId); err != nil {
-						return "", fmt.Errorf("failed to create question tag mapping: %w", err)
+						return nil, fmt.Errorf("failed to create question tag mapping: %w", err)
 					}
 				}
 			}
 		}
 	}
 
-	if len(assessment.Tags) > 0 {
-		for _, tagReq := range assessment.Tags {
-			tagIDs, err := r.ProcessTagRequest(tx, tagReq, userId)
-			if err != nil {
-				return "", fmt.Errorf("failed to process tag request for assessment: %w", err)
-			}
-			for _, tagID := range tagIDs {
-				if err := r.CreateAssessmentTagMappingWithParents(tx, assessmentSequence, tagID, userId); err != nil {
-					return "", fmt.Errorf("failed to create assessment tag mapping: %w", err)
-				}
+	return questionIDs, nil
+}
 
-				// Map to ALL questions in the assessment
-				for _, qID := range questionIDs {
-					if err := r.CreateQuestionTagMappingWithParents(tx, qID, tagID, userId); err != nil {
-						return "", fmt.Errorf("failed to create question tag mapping for assessment tag: %w", err)
-					}
-				}
-			}
+// createSheetQuestion inserts the content, question and options of an imported question.
+func createSheetQuestion(ctx context.Context, tx *gorm.DB, q models.SheetQuestion, userId string, createdAt time.Time) (int64, error) {
+	// Insert question text (or media) in content_mst
+	contentQ := sheetContent(q.Title, q.Media)
+	if err := tx.WithContext(ctx).Create(&contentQ).Error; err != nil {
+		return 0, fmt.Errorf("failed to insert question content: %w", err)
+	}
+
+	questionContentID := contentQ.ContentID
+	if err := saveContentTranslations(ctx, tx, questionContentID, q.Translations, userId); err != nil {
+		return 0, err
+	}
+
+	// Insert into question_mst
+	questionTypeID := 0
+	if id, ok := utils.QuestionTypeMap[q.QuestionType]; ok {
+		questionTypeID = id
+	}
+
+	questionMst := models.QuestionMst{
+		ContentID:      questionContentID,
+		QuestionTypeID: int64(questionTypeID),
+		Feedback:       q.Feedback,
+		IsActive:       true,
+		IsDeleted:      false,
+		CreatedOn:      createdAt,
+		ModifiedOn:     createdAt,
+	}
+	if err := tx.WithContext(ctx).Create(&questionMst).Error; err != nil {
+		return 0, fmt.Errorf("failed to insert question_mst: %w", err)
+	}
+
+	// Loop through options
+	for optIdx, opt := range q.Options {
+		contentOpt := sheetContent(opt.Label, opt.Media)
+		if err := tx.WithContext(ctx).Create(&contentOpt).Error; err != nil {
+			return 0, fmt.Errorf("failed to insert option content: %w", err)
+		}
+
+		optionContentID := contentOpt.ContentID
+		if err := saveContentTranslations(ctx, tx, optionContentID, opt.Translations, userId); err != nil {
+			return 0, err
+		}
+
+		optionMst := models.OptionMst{
+			ContentID:   optionContentID,
+			IsAnswer:    opt.IsCorrect,
+			QuestionID:  questionMst.QuestionID,
+			SequenceID:  int64(optIdx + 1),
+			AnswerScore: opt.Score,
+			Feedback:    opt.Feedback,
+		}
+		if err := tx.WithContext(ctx).Create(&optionMst).Error; err != nil {
+			return 0, fmt.Errorf("failed to insert option_mst: %w", err)
 		}
 	}
 
-	return assessmentSequence, nil
+	return questionMst.QuestionID, nil
+}
+
+// saveContentTranslations stores the per-locale values of a newly created content row.
+func saveContentTranslations(ctx context.Context, tx *gorm.DB, contentID int64, translations map[string]string, userId string) error {
+	createdAt := time.Now()
+	for locale, value := range translations {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		translation := models.ContentTranslation{
+			ContentID:  contentID,
+			Locale:     locale,
+			Value:      strings.TrimSpace(value),
+			CreatedOn:  createdAt,
+			CreatedBy:  userId,
+			ModifiedOn: createdAt,
+			ModifiedBy: userId,
+		}
+		if err := tx.WithContext(ctx).Create(&translation).Error; err != nil {
+			return fmt.Errorf("failed to insert %s translation: %w", locale, err)
+		}
+	}
+	return nil
+}
+
+// sheetContent builds the content_mst row for an imported stem or option: plain text, or an
+// image/audio reference with the text kept as its alt text.
+func sheetContent(text string, media *models.SheetMedia) models.ContentMst {
+	if media == nil || media.Key == "" {
+		return models.ContentMst{ContentTypeID: constant.ContentTypeText, Value: text}
+	}
+
+	contentTypeID := constant.ContentTypeImage
+	if strings.HasPrefix(media.MimeType, "audio/") {
+		contentTypeID = constant.ContentTypeAudio
+	}
+	value, _ := json.Marshal(models.MediaContentValue{
+		MediaID:  media.MediaID,
+		Key:      media.Key,
+		MimeType: media.MimeType,
+		Alt:      text,
+	})
+	return models.ContentMst{ContentTypeID: contentTypeID, Value: string(value)}
 }
 
 func (r *AssessmentRepositoryImpl) CreateAssessment(ctx context.Context, tx *gorm.DB, assessment *models.AssessmentMst) (*models.AssessmentMst, error) {
@@ -1467,7 +1584,7 @@ select
     ast.created_on as assessment_date,
     am.assessment_desc as assessment_title,
     ast.assessment_status as status,
-    ae.attempts_limit as attempts,
+    sse.attempts_limit as attempts,
     (select count(*) from assessment_status where assessment_id = ast.assessment_id) as total_assigned,
 
     -- total passed
@@ -1498,7 +1615,18 @@ select
     ) as marks_obtained,
 
     am.marks as total_marks,
-    ast.user_id
+    ast.user_id,
+
+    -- language of the latest session, empty before the first
+    (select COALESCE(NULLIF(locale, ''), '` + constant.DefaultLocale + `')
+        from assessment_user_session
+        where assessment_id = ast.assessment_id
+          and user_id = ast.user_id
+        order by created_on desc
+        limit 1
+    ) as locale,
+    ` + dueDateSQL + ` as due_date,
+    ` + overdueSQL + ` as is_overdue
 
 from assessment_status ast
 LEFT JOIN assessment_user_mst u 
@@ -1507,8 +1635,8 @@ LEFT JOIN dhl_assessment_user_mst_ext ue
     on ue.user_id = ast.user_id
 LEFT JOIN assessment_mst am
     on am.assessment_sequence = ast.assessment_id
-LEFT JOIN dhl_survey_survey_ext ae
-    on ae.assessment_sequence = ast.assessment_id
+LEFT JOIN dhl_survey_survey_ext sse
+    on sse.assessment_sequence = ast.assessment_id
 WHERE 1=1 
     `
 	params := []interface{}{}
@@ -1526,13 +1654,16 @@ WHERE 1=1
 		params = append(params, filter.AssessmentID)
 	}
 	if filter.CenterID != nil {
-		query += " AND ae.center_id = ?"
+		query += " AND sse.center_id = ?"
 		params = append(params, *filter.CenterID)
 	}
 	if filter.Status != "" {
 		query += " AND ast.assessment_status ILIKE ?"
 		params = append(params, filter.Status)
 	}
+	if filter.Overdue {
+		query += " AND " + overdueSQL
+	}
 	if filter.QuestionnaireTitle != "" {
 		query += " AND am.assessment_desc ILIKE ?"
 		params = append(params, "%"+filter.QuestionnaireTitle+"%")
@@ -1585,6 +1716,9 @@ func (r *AssessmentRepositoryImpl) GetOrCreateTagByName(tx *gorm.DB, tagName, cr
 	return r.GetOrCreateTagWithParent(tx, tagName, nil, createdBy)
 }
 
+// ErrTagNotInVocabulary is returned in strict tag mode for tags missing from the taxonomy.
+var ErrTagNotInVocabulary = errors.New("tag is not in the curated vocabulary")
+
 // GetOrCreateTagWithParent creates or retrieves a tag with optional parent
 func (r *AssessmentRepositoryImpl) GetOrCreateTagWithParent(tx *gorm.DB, tagName string, parentTagID *int64, createdBy string) (*models.TagMaster, error) {
 	var tag models.TagMaster
@@ -1601,6 +1735,9 @@ func (r *AssessmentRepositoryImpl) GetOrCreateTagWithParent(tx *gorm.DB, tagName
 	err := query.First(&tag).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if config.PropConfig.Tags.StrictMode {
+				return nil, fmt.Errorf("%w: %q", ErrTagNotInVocabulary, tagName)
+			}
 			// Create new tag
 			tag = models.TagMaster{
 				TagName:     tagName,
@@ -2117,6 +2254,7 @@ func (r *AssessmentRepositoryImpl) GetAdminAssessmentUserResult(
 SELECT 
     ar.assessment_session_id,
     aus.created_on AS session_created_on,
+    aus.locale,
 
     ar.question_id,
     cq.value AS question_text,
@@ -2170,6 +2308,7 @@ AND aus.user_id = ?
 GROUP BY
     ar.assessment_session_id,
     aus.created_on,
+    aus.locale,
     ar.question_id,
     cq.value,
     correct_opt.correct_options,
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// scheduleTimesSQL computes when an assessment opens (ValidFrom, at StartTime on that day when
+// set) and closes (the survey deadline, else ValidTo). A zero deadline counts as unset.
+const scheduleTimesSQL = `
+	SELECT
+		am.assessment_sequence,
+		am.assessment_desc,
+		sse.state,
+		CASE
+			WHEN am.valid_from IS NOT NULL AND am.start_time IS NOT NULL THEN am.valid_from::date + am.start_time
+			ELSE am.valid_from
+		END AS open_at,
+		CASE
+			WHEN sse.deadline > '1900-01-01' THEN sse.deadline
+			ELSE am.valid_to
+		END AS close_at
+	FROM assessment_mst am
+	JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = am.assessment_sequence
+	WHERE am.is_deleted = false
+	  AND am.no_fixed_schedule = false
+`
+
+type AssessmentScheduleRepository interface {
+	GetDueToOpen(tx *gorm.DB, now time.Time) ([]string, error)
+	GetDueToClose(tx *gorm.DB, now time.Time) ([]string, error)
+	SetState(tx *gorm.DB, assessmentSeqs []string, state constant.AssessmentState) error
+	ExpireAssignments(tx *gorm.DB, assessmentSeq, modifiedBy string) (int64, error)
+	ExpireOverdueAssignments(tx *gorm.DB, now time.Time, modifiedBy string) (int64, error)
+	GetUpcoming(now, until time.Time, limit, offset int) ([]models.ScheduledAssessmentJob, int64, error)
+	GetOpenAt(tx *gorm.DB, assessmentSeq string) (*time.Time, error)
+	GetState(tx *gorm.DB, assessmentSeq string) (string, error)
+	GetStartWindow(tx *gorm.DB, userID, assessmentSeq string) (*models.StartWindow, error)
+}
+
+type AssessmentScheduleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssessmentScheduleRepository(db *gorm.DB) AssessmentScheduleRepository {
+	return &AssessmentScheduleRepositoryImpl{db: db}
+}
+
+// GetDueToOpen returns scheduled assessments whose opening time has passed and which have not
+// closed yet.
+func (r *AssessmentScheduleRepositoryImpl) GetDueToOpen(tx *gorm.DB, now time.Time) ([]string, error) {
+	var seqs []string
+	err := tx.Raw(`
+		SELECT s.assessment_sequence FROM (`+scheduleTimesSQL+`) s
+		WHERE s.state = ? AND s.open_at IS NOT NULL AND s.open_at <= ?
+		  AND (s.close_at IS NULL OR s.close_at > ?)
+		ORDER BY s.open_at
+	`, string(constant.Scheduled), now, now).Scan(&seqs).Error
+	return seqs, err
+}
+
+// GetDueToClose returns open or scheduled assessments whose closing time has passed.
+func (r *AssessmentScheduleRepositoryImpl) GetDueToClose(tx *gorm.DB, now time.Time) ([]string, error) {
+	var seqs []string
+	err := tx.Raw(`
+		SELECT s.assessment_sequence FROM (`+scheduleTimesSQL+`) s
+		WHERE s.state IN ? AND s.close_at IS NOT NULL AND s.close_at <= ?
+		ORDER BY s.close_at
+	`, []string{string(constant.Open), string(constant.Scheduled)}, now).Scan(&seqs).Error
+	return seqs, err
+}
+
+func (r *AssessmentScheduleRepositoryImpl) SetState(tx *gorm.DB, assessmentSeqs []string, state constant.AssessmentState) error {
+	if len(assessmentSeqs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.DhlSurveySurveyExt{}).
+		Where("assessment_sequence IN ?", assessmentSeqs).
+		Update("state", string(state)).Error
+}
+
+// ExpireAssignments marks users who never finished the assessment as expired, except those
+// whose own due date is still ahead.
+func (r *AssessmentScheduleRepositoryImpl) ExpireAssignments(tx *gorm.DB, assessmentSeq, modifiedBy string) (int64, error) {
+	res := tx.Model(&models.AssessmentStatus{}).
+		Where("assessment_id = ? AND is_deleted = false", assessmentSeq).
+		Where("assessment_status IN ?", openAssignmentStatuses).
+		Where("(due_date IS NULL OR due_date <= ?)", time.Now()).
+		Updates(map[string]interface{}{
+			"assessment_status": constant.AssignmentExpired,
+			"modified_on":       time.Now(),
+			"modified_by":       modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// ExpireOverdueAssignments expires unfinished assignments of closed assessments once the
+// user's own due date, which kept them open past the close, has passed.
+func (r *AssessmentScheduleRepositoryImpl) ExpireOverdueAssignments(tx *gorm.DB, now time.Time, modifiedBy string) (int64, error) {
+	res := tx.Model(&models.AssessmentStatus{}).
+		Where("is_deleted = false AND due_date IS NOT NULL AND due_date <= ?", now).
+		Where("assessment_status IN ?", openAssignmentStatuses).
+		Where(`EXISTS (
+			SELECT 1 FROM dhl_survey_survey_ext sse
+			WHERE sse.assessment_sequence = assessment_status.assessment_id AND sse.state = ?
+		)`, string(constant.Closed)).
+		Updates(map[string]interface{}{
+			"assessment_status": constant.AssignmentExpired,
+			"modified_on":       now,
+			"modified_by":       modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// GetUpcoming lists the opens and closes due before until, including overdue ones the
+// scheduler has not handled yet, soonest first.
+func (r *AssessmentScheduleRepositoryImpl) GetUpcoming(now, until time.Time, limit, offset int) ([]models.ScheduledAssessmentJob, int64, error) {
+	query := `
+		SELECT s.assessment_sequence, s.assessment_desc, s.state, 'open' AS action, s.open_at AS due_at
+		FROM (` + scheduleTimesSQL + `) s
+		WHERE s.state = @scheduled AND s.open_at IS NOT NULL AND s.open_at <= @until
+		  AND (s.close_at IS NULL OR s.close_at > @now)
+		UNION ALL
+		SELECT s.assessment_sequence, s.assessment_desc, s.state, 'close' AS action, s.close_at AS due_at
+		FROM (` + scheduleTimesSQL + `) s
+		WHERE s.state IN @active AND s.close_at IS NOT NULL AND s.close_at <= @until
+	`
+	params := map[string]interface{}{
+		"scheduled": string(constant.Scheduled),
+		"active":    []string{string(constant.Open), string(constant.Scheduled)},
+		"now":       now,
+		"until":     until,
+	}
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) FROM ("+query+") jobs", params).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []models.ScheduledAssessmentJob
+	params["limit"] = limit
+	params["offset"] = offset
+	if err := r.db.Raw("SELECT * FROM ("+query+") jobs ORDER BY due_at, assessment_sequence LIMIT @limit OFFSET @offset", params).Scan(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range jobs {
+		jobs[i].Overdue = !jobs[i].DueAt.After(now)
+	}
+	return jobs, total, nil
+}
+
+// GetOpenAt returns when an assessment with a fixed schedule opens, or nil without one.
+func (r *AssessmentScheduleRepositoryImpl) GetOpenAt(tx *gorm.DB, assessmentSeq string) (*time.Time, error) {
+	var openAt *time.Time
+	err := tx.Raw(`
+		SELECT s.open_at FROM (`+scheduleTimesSQL+`) s
+		WHERE s.assessment_sequence = ?
+	`, assessmentSeq).Scan(&openAt).Error
+	return openAt, err
+}
+
+// GetState returns the stored state of an assessment, empty for one without settings.
+func (r *AssessmentScheduleRepositoryImpl) GetState(tx *gorm.DB, assessmentSeq string) (string, error) {
+	var state string
+	err := tx.Model(&models.DhlSurveySurveyExt{}).
+		Select("state").
+		Where("assessment_sequence = ?", assessmentSeq).
+		Limit(1).
+		Scan(&state).Error
+	return state, err
+}
+
+// GetStartWindow returns the state and schedule of an assessment with the user's assignment, or
+// nil for an assessment without settings.
+func (r *AssessmentScheduleRepositoryImpl) GetStartWindow(tx *gorm.DB, userID, assessmentSeq string) (*models.StartWindow, error) {
+	var windows []models.StartWindow
+	err := tx.Raw(`
+		SELECT sse.state, s.open_at, s.close_at, st.assessment_status, st.due_date
+		FROM dhl_survey_survey_ext sse
+		LEFT JOIN (`+scheduleTimesSQL+`) s ON s.assessment_sequence = sse.assessment_sequence
+		LEFT JOIN assessment_status st ON st.assessment_id = sse.assessment_sequence
+			AND st.user_id = ? AND st.is_deleted = false
+		WHERE sse.assessment_sequence = ?
+		LIMIT 1
+	`, userID, assessmentSeq).Scan(&windows).Error
+	if err != nil || len(windows) == 0 {
+		return nil, err
+	}
+	return &windows[0], nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AssessmentTemplateRepository interface {
+	Create(tx *gorm.DB, template *models.AssessmentTemplate) error
+	Update(tx *gorm.DB, template *models.AssessmentTemplate) error
+	GetByID(templateID int64) (*models.AssessmentTemplate, error)
+	List(limit, offset int) ([]models.AssessmentTemplate, int64, error)
+	Delete(templateID int64, userId string) error
+}
+
+type AssessmentTemplateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssessmentTemplateRepository(db *gorm.DB) AssessmentTemplateRepository {
+	return &AssessmentTemplateRepositoryImpl{db: db}
+}
+
+func (r *AssessmentTemplateRepositoryImpl) Create(tx *gorm.DB, template *models.AssessmentTemplate) error {
+	return tx.Create(template).Error
+}
+
+// Update replaces everything but the creation audit fields.
+func (r *AssessmentTemplateRepositoryImpl) Update(tx *gorm.DB, template *models.AssessmentTemplate) error {
+	res := tx.Model(&models.AssessmentTemplate{}).
+		Where("template_id = ? AND is_deleted = false", template.TemplateID).
+		Select("*").
+		Omit("template_id", "created_on", "created_by").
+		Updates(template)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AssessmentTemplateRepositoryImpl) GetByID(templateID int64) (*models.AssessmentTemplate, error) {
+	var template models.AssessmentTemplate
+	err := r.db.Where("template_id = ? AND is_deleted = false", templateID).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *AssessmentTemplateRepositoryImpl) List(limit, offset int) ([]models.AssessmentTemplate, int64, error) {
+	var list []models.AssessmentTemplate
+	var total int64
+
+	query := r.db.Model(&models.AssessmentTemplate{}).Where("is_deleted = false")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.
+		Order("modified_on DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *AssessmentTemplateRepositoryImpl) Delete(templateID int64, userId string) error {
+	res := r.db.Model(&models.AssessmentTemplate{}).
+		Where("template_id = ? AND is_deleted = false", templateID).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"is_active":   false,
+			"modified_on": time.Now(),
+			"modified_by": userId,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// audienceColumns maps the fields a rule may test to their column in the user query. Only
+// these ever reach the SQL.
+var audienceColumns = map[string]string{
+	"user_type":      "u.user_type",
+	"center":         "ext.center",
+	"selection_type": "ext.selection_type",
+	"manager":        "ext.manager",
+	"team_lead":      "ext.team_lead",
+	"senior_manager": "ext.senior_manager",
+	"sdl":            "ext.sdl",
+	"sll":            "ext.sll",
+	"company_id":     "ext.company_id",
+	"rank_id":        "ext.rank_id",
+	"emp_code":       "ext.emp_code",
+}
+
+// audienceRoleField matches users holding a role, by role label.
+const audienceRoleField = "role"
+
+// AudienceFields lists the fields an audience condition can test.
+func AudienceFields() []string {
+	fields := []string{audienceRoleField}
+	for field := range audienceColumns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+type AudienceRepository interface {
+	CreateRule(tx *gorm.DB, rule *models.AudienceRule) error
+	UpdateRule(tx *gorm.DB, rule *models.AudienceRule) error
+	GetRule(ruleID int64) (*models.AudienceRule, error)
+	ListRules(limit, offset int) ([]models.AudienceRule, int64, error)
+	DeleteRule(ruleID int64, userId string) error
+	CountActiveAudiences(ruleID int64) (int64, error)
+
+	MatchUsers(conditions []models.AudienceCondition, assessmentSeq string, limit, offset int) ([]models.AudienceMember, int64, error)
+	GetUnassignedMatches(tx *gorm.DB, conditions []models.AudienceCondition, assessmentSeq string) ([]string, error)
+	AssignUsers(tx *gorm.DB, assessmentSeq string, userIDs []string, createdBy string) error
+
+	GetAudience(tx *gorm.DB, assessmentSeq string, ruleID int64) (*models.AssessmentAudience, error)
+	SaveAudience(tx *gorm.DB, audience *models.AssessmentAudience) error
+	ListAudiences(assessmentSeq string) ([]models.AssessmentAudience, error)
+	GetAutoAssignAudiences(tx *gorm.DB) ([]models.AssessmentAudience, error)
+	DeactivateAudience(audienceID int64, userId string) error
+}
+
+type AudienceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAudienceRepository(db *gorm.DB) AudienceRepository {
+	return &AudienceRepositoryImpl{db: db}
+}
+
+func (r *AudienceRepositoryImpl) CreateRule(tx *gorm.DB, rule *models.AudienceRule) error {
+	return tx.Create(rule).Error
+}
+
+// UpdateRule saves the rule fields and replaces its conditions.
+func (r *AudienceRepositoryImpl) UpdateRule(tx *gorm.DB, rule *models.AudienceRule) error {
+	if err := tx.Model(&models.AudienceRule{}).
+		Where("rule_id = ? AND is_deleted = false", rule.RuleID).
+		Updates(map[string]interface{}{
+			"name":        rule.Name,
+			"description": rule.Description,
+			"modified_on": rule.ModifiedOn,
+			"modified_by": rule.ModifiedBy,
+		}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("rule_id = ?", rule.RuleID).Delete(&models.AudienceCondition{}).Error; err != nil {
+		return err
+	}
+	for i := range rule.Conditions {
+		rule.Conditions[i].RuleID = rule.RuleID
+	}
+	if len(rule.Conditions) == 0 {
+		return nil
+	}
+	return tx.Create(&rule.Conditions).Error
+}
+
+func (r *AudienceRepositoryImpl) GetRule(ruleID int64) (*models.AudienceRule, error) {
+	var rule models.AudienceRule
+	err := r.db.
+		Preload("Conditions", func(db *gorm.DB) *gorm.DB { return db.Order("sequence_id") }).
+		Where("rule_id = ? AND is_deleted = false", ruleID).
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AudienceRepositoryImpl) ListRules(limit, offset int) ([]models.AudienceRule, int64, error) {
+	var list []models.AudienceRule
+	var total int64
+
+	query := r.db.Model(&models.AudienceRule{}).Where("is_deleted = false")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.
+		Preload("Conditions", func(db *gorm.DB) *gorm.DB { return db.Order("sequence_id") }).
+		Order("modified_on DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *AudienceRepositoryImpl) DeleteRule(ruleID int64, userId string) error {
+	res := r.db.Model(&models.AudienceRule{}).
+		Where("rule_id = ? AND is_deleted = false", ruleID).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"is_active":   false,
+			"modified_on": time.Now(),
+			"modified_by": userId,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AudienceRepositoryImpl) CountActiveAudiences(ruleID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AssessmentAudience{}).
+		Where("rule_id = ? AND is_active = true", ruleID).
+		Count(&count).Error
+	return count, err
+}
+
+// matchQuery selects the active users meeting every condition.
+func (r *AudienceRepositoryImpl) matchQuery(db *gorm.DB, conditions []models.AudienceCondition) (*gorm.DB, error) {
+	query := db.Table("assessment_user_mst u").
+		Joins("LEFT JOIN dhl_assessment_user_mst_ext ext ON ext.user_id = u.user_id::text").
+		Where("u.is_active = true")
+
+	for _, c := range conditions {
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = strings.ToLower(strings.TrimSpace(v))
+		}
+		negate := c.Operator == "neq" || c.Operator == "not_in"
+		if c.Operator != "eq" && c.Operator != "neq" && c.Operator != "in" && c.Operator != "not_in" {
+			return nil, fmt.Errorf("unknown operator %q", c.Operator)
+		}
+
+		if c.Field == audienceRoleField {
+			exists := `EXISTS (
+				SELECT 1 FROM assessment_user_role_mapping m
+				JOIN assessment_user_role_mst rm ON rm.role_id = m.role_id
+				WHERE m.user_id = u.user_id AND LOWER(rm.role_label) IN ?)`
+			if negate {
+				exists = "NOT " + exists
+			}
+			query = query.Where(exists, values)
+			continue
+		}
+
+		column, ok := audienceColumns[c.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", c.Field)
+		}
+		if negate {
+			query = query.Where(fmt.Sprintf("(%s IS NULL OR LOWER(%s::text) NOT IN ?)", column, column), values)
+		} else {
+			query = query.Where(fmt.Sprintf("LOWER(%s::text) IN ?", column), values)
+		}
+	}
+	return query, nil
+}
+
+// MatchUsers pages through the users a rule selects. With assessmentSeq it also tells who
+// already has the assessment.
+func (r *AudienceRepositoryImpl) MatchUsers(conditions []models.AudienceCondition, assessmentSeq string, limit, offset int) ([]models.AudienceMember, int64, error) {
+	query, err := r.matchQuery(r.db, conditions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Distinct("u.user_id").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []models.AudienceMember
+	err = query.
+		Select(`DISTINCT u.user_id, u.first_name, u.last_name, u.email, u.user_type,
+			ext.emp_code, ext.center, ext.selection_type, ext.manager,
+			EXISTS (
+				SELECT 1 FROM assessment_status st
+				WHERE st.user_id = u.user_id::text AND st.assessment_id = ? AND st.is_deleted = false
+			) AS already_assigned`, assessmentSeq).
+		Order("u.first_name, u.last_name, u.user_id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&members).Error
+	return members, total, err
+}
+
+// GetUnassignedMatches returns the users a rule selects who have no assignment of the assessment,
+// in any state.
+func (r *AudienceRepositoryImpl) GetUnassignedMatches(tx *gorm.DB, conditions []models.AudienceCondition, assessmentSeq string) ([]string, error) {
+	query, err := r.matchQuery(tx, conditions)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []string
+	err = query.
+		Where(`NOT EXISTS (
+			SELECT 1 FROM assessment_status st
+			WHERE st.user_id = u.user_id::text AND st.assessment_id = ?
+		)`, assessmentSeq).
+		Select("DISTINCT u.user_id::text").
+		Scan(&userIDs).Error
+	return userIDs, err
+}
+
+func (r *AudienceRepositoryImpl) AssignUsers(tx *gorm.DB, assessmentSeq string, userIDs []string, createdBy string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	statuses := make([]models.AssessmentStatus, len(userIDs))
+	for i, uid := range userIDs {
+		statuses[i] = models.AssessmentStatus{
+			CreatedOn:        now,
+			CreatedBy:        createdBy,
+			IsActive:         true,
+			ModifiedOn:       now,
+			ModifiedBy:       createdBy,
+			AssessmentID:     assessmentSeq,
+			AssessmentStatus: constant.AssignmentAssigned,
+			UserID:           uid,
+		}
+	}
+	return tx.CreateInBatches(&statuses, 500).Error
+}
+
+func (r *AudienceRepositoryImpl) GetAudience(tx *gorm.DB, assessmentSeq string, ruleID int64) (*models.AssessmentAudience, error) {
+	var audience models.AssessmentAudience
+	if err := tx.Where("assessment_sequence = ? AND rule_id = ?", assessmentSeq, ruleID).First(&audience).Error; err != nil {
+		return nil, err
+	}
+	return &audience, nil
+}
+
+func (r *AudienceRepositoryImpl) SaveAudience(tx *gorm.DB, audience *models.AssessmentAudience) error {
+	return tx.Save(audience).Error
+}
+
+func (r *AudienceRepositoryImpl) ListAudiences(assessmentSeq string) ([]models.AssessmentAudience, error) {
+	var audiences []models.AssessmentAudience
+	err := r.db.Table("assessment_audience aa").
+		Select("aa.*, ar.name AS rule_name").
+		Joins("JOIN audience_rule ar ON ar.rule_id = aa.rule_id").
+		Where("aa.assessment_sequence = ? AND aa.is_active = true", assessmentSeq).
+		Order("aa.created_on").
+		Scan(&audiences).Error
+	return audiences, err
+}
+
+// GetAutoAssignAudiences returns the auto-assigning audiences of live rules whose assessment is
+// neither deleted nor closed.
+func (r *AudienceRepositoryImpl) GetAutoAssignAudiences(tx *gorm.DB) ([]models.AssessmentAudience, error) {
+	var audiences []models.AssessmentAudience
+	err := tx.Table("assessment_audience aa").
+		Select("aa.*").
+		Joins("JOIN audience_rule ar ON ar.rule_id = aa.rule_id AND ar.is_deleted = false").
+		Joins("JOIN assessment_mst am ON am.assessment_sequence = aa.assessment_sequence AND am.is_deleted = false").
+		Joins("JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = aa.assessment_sequence").
+		Where("aa.is_active = true AND aa.auto_assign = true AND sse.state <> ?", string(constant.Closed)).
+		Order("aa.id").
+		Scan(&audiences).Error
+	return audiences, err
+}
+
+func (r *AudienceRepositoryImpl) DeactivateAudience(audienceID int64, userId string) error {
+	res := r.db.Model(&models.AssessmentAudience{}).
+		Where("id = ? AND is_active = true", audienceID).
+		Updates(map[string]interface{}{
+			"is_active":   false,
+			"modified_on": time.Now(),
+			"modified_by": userId,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BlueprintRepository interface {
+	Create(tx *gorm.DB, blueprint *models.AssessmentBlueprint) error
+	Update(tx *gorm.DB, blueprint *models.AssessmentBlueprint) error
+	GetByID(blueprintID int64) (*models.AssessmentBlueprint, error)
+	List(limit, offset int) ([]models.AssessmentBlueprint, int64, error)
+	Delete(blueprintID int64, userId string) error
+	GetFormQuestionIDs(blueprintID int64) ([]int64, error)
+}
+
+type BlueprintRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewBlueprintRepository(db *gorm.DB) BlueprintRepository {
+	return &BlueprintRepositoryImpl{db: db}
+}
+
+func (r *BlueprintRepositoryImpl) Create(tx *gorm.DB, blueprint *models.AssessmentBlueprint) error {
+	return tx.Create(blueprint).Error
+}
+
+// Update saves the blueprint fields and replaces its constraints.
+func (r *BlueprintRepositoryImpl) Update(tx *gorm.DB, blueprint *models.AssessmentBlueprint) error {
+	if err := tx.Model(&models.AssessmentBlueprint{}).
+		Where("blueprint_id = ? AND is_deleted = false", blueprint.BlueprintID).
+		Updates(map[string]interface{}{
+			"name":            blueprint.Name,
+			"description":     blueprint.Description,
+			"assessment_type": blueprint.AssessmentType,
+			"duration":        blueprint.Duration,
+			"modified_on":     blueprint.ModifiedOn,
+			"modified_by":     blueprint.ModifiedBy,
+		}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("blueprint_id = ?", blueprint.BlueprintID).Delete(&models.BlueprintConstraint{}).Error; err != nil {
+		return err
+	}
+	for i := range blueprint.Constraints {
+		blueprint.Constraints[i].BlueprintID = blueprint.BlueprintID
+	}
+	if len(blueprint.Constraints) == 0 {
+		return nil
+	}
+	return tx.Create(&blueprint.Constraints).Error
+}
+
+func (r *BlueprintRepositoryImpl) GetByID(blueprintID int64) (*models.AssessmentBlueprint, error) {
+	var blueprint models.AssessmentBlueprint
+	err := r.db.
+		Preload("Constraints", func(db *gorm.DB) *gorm.DB { return db.Order("sequence_id") }).
+		Where("blueprint_id = ? AND is_deleted = false", blueprintID).
+		First(&blueprint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &blueprint, nil
+}
+
+func (r *BlueprintRepositoryImpl) List(limit, offset int) ([]models.AssessmentBlueprint, int64, error) {
+	var list []models.AssessmentBlueprint
+	var total int64
+
+	query := r.db.Model(&models.AssessmentBlueprint{}).Where("is_deleted = false")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.
+		Preload("Constraints", func(db *gorm.DB) *gorm.DB { return db.Order("sequence_id") }).
+		Order("modified_on DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *BlueprintRepositoryImpl) Delete(blueprintID int64, userId string) error {
+	res := r.db.Model(&models.AssessmentBlueprint{}).
+		Where("blueprint_id = ? AND is_deleted = false", blueprintID).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"is_active":   false,
+			"modified_on": time.Now(),
+			"modified_by": userId,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetFormQuestionIDs returns the questions already used by assessments assembled from the blueprint.
+func (r *BlueprintRepositoryImpl) GetFormQuestionIDs(blueprintID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Raw(`
+	SELECT DISTINCT aq.question_id
+	FROM assessment_question_mst aq
+	JOIN assessment_mst am ON am.assessment_sequence = aq.assessment_sequence
+	WHERE am.blueprint_id = ? AND am.is_deleted = false AND aq.is_deleted = false
+	`, blueprintID).Scan(&ids).Error
+	return ids, err
+}
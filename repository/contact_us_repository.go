@@ -9,6 +9,7 @@ import (
 type ContactRepository interface {
 	Save(response *models.ContactUsResponse) error
 	GetAll() ([]models.ContactUsResponse, error)
+	GetByID(id uint) (*models.ContactUsResponse, error)
 }
 
 type ContactRepoImpl struct {
@@ -28,3 +29,11 @@ func (r *ContactRepoImpl) GetAll() ([]models.ContactUsResponse, error) {
 	err := r.db.Order("created_at DESC").Find(&responses).Error
 	return responses, err
 }
+
+func (r *ContactRepoImpl) GetByID(id uint) (*models.ContactUsResponse, error) {
+	var response models.ContactUsResponse
+	if err := r.db.First(&response, id).Error; err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
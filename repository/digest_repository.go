@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// digestTeamSQL limits assessment_status as ast to the reports of a manager.
+const digestTeamSQL = `EXISTS (
+	SELECT 1 FROM user_manager_mapping um
+	WHERE um.user_id = ast.user_id AND um.manager_id = ? AND um.is_active = true
+)`
+
+type DigestRepository interface {
+	GetSubscription(userID string) (*models.DigestSubscription, error)
+	SaveSubscription(tx *gorm.DB, sub *models.DigestSubscription) error
+	GetActiveSubscriptions(tx *gorm.DB) ([]models.DigestSubscription, error)
+	MarkSent(tx *gorm.DB, userID string, sentOn time.Time) error
+
+	GetCompletions(managerID string, from, to time.Time) ([]models.DigestEntry, error)
+	GetOpenAssignments(managerID string, dueBefore time.Time) ([]models.DigestEntry, error)
+}
+
+type DigestRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewDigestRepository(db *gorm.DB) DigestRepository {
+	return &DigestRepositoryImpl{db: db}
+}
+
+// GetSubscription returns the user's subscription, or nil when they never subscribed.
+func (r *DigestRepositoryImpl) GetSubscription(userID string) (*models.DigestSubscription, error) {
+	var sub models.DigestSubscription
+	if err := r.db.Where("user_id = ?", userID).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *DigestRepositoryImpl) SaveSubscription(tx *gorm.DB, sub *models.DigestSubscription) error {
+	return tx.Save(sub).Error
+}
+
+func (r *DigestRepositoryImpl) GetActiveSubscriptions(tx *gorm.DB) ([]models.DigestSubscription, error) {
+	var subs []models.DigestSubscription
+	err := tx.Where("frequency <> ?", constant.DigestOff).Order("user_id").Find(&subs).Error
+	return subs, err
+}
+
+func (r *DigestRepositoryImpl) MarkSent(tx *gorm.DB, userID string, sentOn time.Time) error {
+	return tx.Model(&models.DigestSubscription{}).
+		Where("user_id = ?", userID).
+		Update("last_sent_on", sentOn).Error
+}
+
+// GetCompletions lists the assignments completed from from until to, newest first, with the
+// score of the user's latest session. An empty managerID covers every user.
+func (r *DigestRepositoryImpl) GetCompletions(managerID string, from, to time.Time) ([]models.DigestEntry, error) {
+	query := r.db.Table("assessment_status ast").
+		Select(`ast.user_id, CONCAT(u.first_name, ' ', u.last_name) AS user_name,
+			ast.assessment_id AS assessment_sequence, am.assessment_desc AS assessment_name,
+			ast.modified_on AS date, sc.percentage,
+			COALESCE(sc.percentage >= am.passing_score, false) AS passed`).
+		Joins("JOIN assessment_mst am ON am.assessment_sequence = ast.assessment_id AND am.is_deleted = false").
+		Joins("LEFT JOIN assessment_user_mst u ON u.user_id::text = ast.user_id").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT COALESCE(SUM(ar.point_assigned), 0) * 100.0 / NULLIF(am.marks, 0) AS percentage
+			FROM assessment_user_session s
+			LEFT JOIN assessment_result ar ON ar.assessment_session_id = s.session_id::text
+				AND ar.assessment_sequence = s.assessment_id
+				AND ar.is_deleted = false
+			WHERE s.assessment_id = ast.assessment_id AND s.user_id = ast.user_id AND s.is_deleted = false
+			GROUP BY s.session_id, s.created_on
+			ORDER BY s.created_on DESC
+			LIMIT 1
+		) sc ON true`).
+		Where("ast.is_deleted = false AND ast.assessment_status = ?", constant.AssignmentCompleted).
+		Where("ast.modified_on >= ? AND ast.modified_on < ?", from, to)
+	if managerID != "" {
+		query = query.Where(digestTeamSQL, managerID)
+	}
+
+	var entries []models.DigestEntry
+	err := query.Order("ast.modified_on DESC").Scan(&entries).Error
+	return entries, err
+}
+
+// GetOpenAssignments lists the unfinished assignments due before dueBefore, overdue ones
+// included, soonest due first. An empty managerID covers every user.
+func (r *DigestRepositoryImpl) GetOpenAssignments(managerID string, dueBefore time.Time) ([]models.DigestEntry, error) {
+	query := r.db.Table("assessment_status ast").
+		Select(`ast.user_id, CONCAT(u.first_name, ' ', u.last_name) AS user_name,
+			ast.assessment_id AS assessment_sequence, am.assessment_desc AS assessment_name,
+			`+dueDateSQL+` AS date`).
+		Joins("JOIN assessment_mst am ON am.assessment_sequence = ast.assessment_id AND am.is_deleted = false").
+		Joins("LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = ast.assessment_id").
+		Joins("LEFT JOIN assessment_user_mst u ON u.user_id::text = ast.user_id").
+		Where("ast.is_deleted = false AND ast.assessment_status IN ?",
+			openAssignmentStatuses).
+		Where(dueDateSQL+" IS NOT NULL AND "+dueDateSQL+" < ?", dueBefore)
+	if managerID != "" {
+		query = query.Where(digestTeamSQL, managerID)
+	}
+
+	var entries []models.DigestEntry
+	err := query.Order("date").Scan(&entries).Error
+	return entries, err
+}
@@ -13,6 +13,7 @@ type JobDescriptionRepository interface {
 	Update(tx *gorm.DB, job *models.JobDescription) error
 	SoftDelete(tx *gorm.DB, jobID int64, modifiedBy string) error
 	GetByID(jobID int64) (*models.JobDescription, error)
+	GetByTitle(title string) (*models.JobDescription, error)
 }
 
 type JobDescriptionRepositoryImpl struct {
@@ -60,6 +61,20 @@ func (r *JobDescriptionRepositoryImpl) GetByID(jobID int64) (*models.JobDescript
 	return &job, nil
 }
 
+func (r *JobDescriptionRepositoryImpl) GetByTitle(title string) (*models.JobDescription, error) {
+	var job models.JobDescription
+	err := r.db.
+		Where("LOWER(title) = LOWER(?) AND is_deleted = false", title).
+		Order("job_id").
+		First(&job).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
 func (r *JobDescriptionRepositoryImpl) Update(tx *gorm.DB, job *models.JobDescription) error {
 	return tx.Save(job).Error
 }
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"dhl/models"
+
+	"gorm.io/gorm"
+)
+
+type MediaRepository interface {
+	Create(tx *gorm.DB, media *models.MediaMst) error
+	GetByID(mediaID int64) (*models.MediaMst, error)
+	GetByKey(key string) (*models.MediaMst, error)
+}
+
+type MediaRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &MediaRepositoryImpl{db: db}
+}
+
+func (r *MediaRepositoryImpl) Create(tx *gorm.DB, media *models.MediaMst) error {
+	return tx.Create(media).Error
+}
+
+func (r *MediaRepositoryImpl) GetByID(mediaID int64) (*models.MediaMst, error) {
+	var media models.MediaMst
+	if err := r.db.Where("media_id = ? AND is_deleted = false", mediaID).First(&media).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (r *MediaRepositoryImpl) GetByKey(key string) (*models.MediaMst, error) {
+	var media models.MediaMst
+	if err := r.db.Where("storage_key = ? AND is_deleted = false", key).First(&media).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationOutboxRepository interface {
+	Enqueue(tx *gorm.DB, messages []models.NotificationOutbox) error
+	Claim(tx *gorm.DB, now, claimedUntil time.Time, limit int) ([]models.NotificationOutbox, error)
+	SaveAttempt(tx *gorm.DB, message *models.NotificationOutbox) error
+	List(filter models.OutboxFilter, limit, offset int) ([]models.NotificationOutbox, int64, error)
+	Replay(ids []int64, allDead bool, now time.Time) (int64, error)
+}
+
+type NotificationOutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationOutboxRepository(db *gorm.DB) NotificationOutboxRepository {
+	return &NotificationOutboxRepositoryImpl{db: db}
+}
+
+func (r *NotificationOutboxRepositoryImpl) Enqueue(tx *gorm.DB, messages []models.NotificationOutbox) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&messages, 500).Error
+}
+
+// Claim marks up to limit notifications due for an attempt as sending until claimedUntil,
+// oldest first, counts the attempt and returns them. Rows another dispatcher is claiming are
+// skipped; sending ones whose claim ran out are due again.
+func (r *NotificationOutboxRepositoryImpl) Claim(tx *gorm.DB, now, claimedUntil time.Time, limit int) ([]models.NotificationOutbox, error) {
+	var messages []models.NotificationOutbox
+	err := tx.Raw(`
+		UPDATE notification_outbox
+		SET status = ?, attempts = attempts + 1, next_attempt_on = ?
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status IN ? AND next_attempt_on <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, constant.OutboxSending, claimedUntil, []string{constant.OutboxPending, constant.OutboxSending}, now, limit).
+		Scan(&messages).Error
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, err
+}
+
+func (r *NotificationOutboxRepositoryImpl) SaveAttempt(tx *gorm.DB, message *models.NotificationOutbox) error {
+	return tx.Model(&models.NotificationOutbox{}).
+		Where("id = ?", message.ID).
+		Updates(map[string]interface{}{
+			"status":          message.Status,
+			"attempts":        message.Attempts,
+			"next_attempt_on": message.NextAttemptOn,
+			"last_error":      message.LastError,
+			"sent_on":         message.SentOn,
+		}).Error
+}
+
+func (r *NotificationOutboxRepositoryImpl) List(filter models.OutboxFilter, limit, offset int) ([]models.NotificationOutbox, int64, error) {
+	query := r.db.Model(&models.NotificationOutbox{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.RecipientID != "" {
+		query = query.Where("recipient_id = ?", filter.RecipientID)
+	}
+	if filter.AssessmentSequence != "" {
+		query = query.Where("assessment_sequence = ?", filter.AssessmentSequence)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var messages []models.NotificationOutbox
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&messages).Error
+	return messages, total, err
+}
+
+// Replay queues dead notifications again with a fresh set of attempts.
+func (r *NotificationOutboxRepositoryImpl) Replay(ids []int64, allDead bool, now time.Time) (int64, error) {
+	query := r.db.Model(&models.NotificationOutbox{}).Where("status = ?", constant.OutboxDead)
+	if !allDead {
+		query = query.Where("id IN ?", ids)
+	}
+	res := query.Updates(map[string]interface{}{
+		"status":          constant.OutboxPending,
+		"attempts":        0,
+		"next_attempt_on": now,
+	})
+	return res.RowsAffected, res.Error
+}
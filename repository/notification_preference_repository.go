@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository interface {
+	GetNotificationTypes(tx *gorm.DB, userIDs []string) (map[string]string, error)
+	SetNotificationType(tx *gorm.DB, userID, notificationType string) error
+	GetPreferences(tx *gorm.DB, userIDs []string, category string) (map[string]models.NotificationPreference, error)
+	ListPreferences(userID string) ([]models.NotificationPreference, error)
+	SavePreferences(tx *gorm.DB, prefs []models.NotificationPreference) error
+
+	LogSuppressions(tx *gorm.DB, suppressions []models.NotificationSuppression) error
+	ListSuppressions(filter models.SuppressionFilter, limit, offset int) ([]models.NotificationSuppression, int64, error)
+}
+
+type NotificationPreferenceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &NotificationPreferenceRepositoryImpl{db: db}
+}
+
+// GetNotificationTypes returns the notification type of the users' profiles, by user id. Users
+// without one are left out.
+func (r *NotificationPreferenceRepositoryImpl) GetNotificationTypes(tx *gorm.DB, userIDs []string) (map[string]string, error) {
+	types := make(map[string]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return types, nil
+	}
+	var rows []models.DhlAssessmentUserMstExt
+	err := tx.Select("user_id", "notification_type").
+		Where("user_id IN ? AND notification_type IS NOT NULL", userIDs).
+		Find(&rows).Error
+	for _, row := range rows {
+		types[row.UserID] = *row.NotificationType
+	}
+	return types, err
+}
+
+// SetNotificationType stores the user's default on their profile, which is created when the
+// user has none yet.
+func (r *NotificationPreferenceRepositoryImpl) SetNotificationType(tx *gorm.DB, userID, notificationType string) error {
+	res := tx.Model(&models.DhlAssessmentUserMstExt{}).
+		Where("user_id = ?", userID).
+		Update("notification_type", notificationType)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	return tx.Create(&models.DhlAssessmentUserMstExt{
+		UserID:           userID,
+		NotificationType: &notificationType,
+		CreatedAt:        time.Now(),
+	}).Error
+}
+
+// GetPreferences returns the users' preferences for a category, by user id.
+func (r *NotificationPreferenceRepositoryImpl) GetPreferences(tx *gorm.DB, userIDs []string, category string) (map[string]models.NotificationPreference, error) {
+	prefs := make(map[string]models.NotificationPreference, len(userIDs))
+	if len(userIDs) == 0 {
+		return prefs, nil
+	}
+	var rows []models.NotificationPreference
+	err := tx.Where("user_id IN ? AND category = ?", userIDs, category).Find(&rows).Error
+	for _, row := range rows {
+		prefs[row.UserID] = row
+	}
+	return prefs, err
+}
+
+func (r *NotificationPreferenceRepositoryImpl) ListPreferences(userID string) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Order("category").Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *NotificationPreferenceRepositoryImpl) SavePreferences(tx *gorm.DB, prefs []models.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "in_app", "modified_on"}),
+	}).Create(&prefs).Error
+}
+
+func (r *NotificationPreferenceRepositoryImpl) LogSuppressions(tx *gorm.DB, suppressions []models.NotificationSuppression) error {
+	if len(suppressions) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&suppressions, 500).Error
+}
+
+func (r *NotificationPreferenceRepositoryImpl) ListSuppressions(filter models.SuppressionFilter, limit, offset int) ([]models.NotificationSuppression, int64, error) {
+	query := r.db.Model(&models.NotificationSuppression{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []models.NotificationSuppression
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"dhl/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type NotificationTemplateRepository interface {
+	GetVariants(key string) ([]models.NotificationTemplate, error)
+	Find(key, locale string) (*models.NotificationTemplate, error)
+	List(key string) ([]models.NotificationTemplate, error)
+	Save(tx *gorm.DB, tmpl *models.NotificationTemplate) error
+	Delete(id int64) error
+}
+
+type NotificationTemplateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationTemplateRepository(db *gorm.DB) NotificationTemplateRepository {
+	return &NotificationTemplateRepositoryImpl{db: db}
+}
+
+// GetVariants returns every locale of a template.
+func (r *NotificationTemplateRepositoryImpl) GetVariants(key string) ([]models.NotificationTemplate, error) {
+	var list []models.NotificationTemplate
+	err := r.db.Where("template_key = ?", key).Find(&list).Error
+	return list, err
+}
+
+// Find returns the template in exactly this locale, or nil when there is none.
+func (r *NotificationTemplateRepositoryImpl) Find(key, locale string) (*models.NotificationTemplate, error) {
+	var tmpl models.NotificationTemplate
+	if err := r.db.Where("template_key = ? AND locale = ?", key, locale).First(&tmpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+func (r *NotificationTemplateRepositoryImpl) List(key string) ([]models.NotificationTemplate, error) {
+	query := r.db.Model(&models.NotificationTemplate{})
+	if key != "" {
+		query = query.Where("template_key = ?", key)
+	}
+	var list []models.NotificationTemplate
+	err := query.Order("template_key, locale").Find(&list).Error
+	return list, err
+}
+
+func (r *NotificationTemplateRepositoryImpl) Save(tx *gorm.DB, tmpl *models.NotificationTemplate) error {
+	return tx.Save(tmpl).Error
+}
+
+func (r *NotificationTemplateRepositoryImpl) Delete(id int64) error {
+	res := r.db.Delete(&models.NotificationTemplate{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuestionTag is a tag currently mapped to a question.
+type QuestionTag struct {
+	QuestionID int64  `gorm:"column:question_id"`
+	TagID      int64  `gorm:"column:tag_id"`
+	Tag        string `gorm:"column:tag"`
+}
+
+type QuestionEditRepository interface {
+	GetQuestions(tx *gorm.DB, questionIDs []int64) ([]models.QuestionMst, error)
+	SetQuestionsActive(tx *gorm.DB, questionIDs []int64, isActive bool, modifiedBy string) error
+	TouchQuestions(tx *gorm.DB, questionIDs []int64, modifiedBy string) error
+	GetQuestionTags(tx *gorm.DB, questionIDs []int64) ([]QuestionTag, error)
+	RemoveQuestionTags(tx *gorm.DB, questionIDs, tagIDs []int64, modifiedBy string) error
+	GetAssessmentQuestionMappings(tx *gorm.DB, assessmentSeq string, questionIDs []int64) ([]models.AssessmentQuestionMst, error)
+	UpdateAssessmentQuestions(tx *gorm.DB, mappingIDs []int64, updates map[string]interface{}) error
+	UpdateAssessmentMarks(tx *gorm.DB, assessmentSeq string, marks int64, modifiedBy string) error
+	GetEditableContents(tx *gorm.DB, assessmentSeq string, questionIDs []int64) ([]models.EditableContent, error)
+	UpdateContentValue(tx *gorm.DB, contentID int64, value string) error
+	CreateHistory(tx *gorm.DB, entries []models.QuestionEditHistory) error
+	ListHistory(questionID int64, batchID string, limit, offset int) ([]models.QuestionEditHistory, int64, error)
+}
+
+type QuestionEditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewQuestionEditRepository(db *gorm.DB) QuestionEditRepository {
+	return &QuestionEditRepositoryImpl{db: db}
+}
+
+func (r *QuestionEditRepositoryImpl) GetQuestions(tx *gorm.DB, questionIDs []int64) ([]models.QuestionMst, error) {
+	var questions []models.QuestionMst
+	err := tx.Where("question_id IN ? AND is_deleted = false", questionIDs).
+		Order("question_id").
+		Find(&questions).Error
+	return questions, err
+}
+
+func (r *QuestionEditRepositoryImpl) SetQuestionsActive(tx *gorm.DB, questionIDs []int64, isActive bool, modifiedBy string) error {
+	return tx.Model(&models.QuestionMst{}).
+		Where("question_id IN ? AND is_deleted = false", questionIDs).
+		Updates(map[string]interface{}{
+			"is_active":   isActive,
+			"modified_on": time.Now(),
+			"modified_by": modifiedBy,
+		}).Error
+}
+
+func (r *QuestionEditRepositoryImpl) TouchQuestions(tx *gorm.DB, questionIDs []int64, modifiedBy string) error {
+	return tx.Model(&models.QuestionMst{}).
+		Where("question_id IN ?", questionIDs).
+		Updates(map[string]interface{}{
+			"modified_on": time.Now(),
+			"modified_by": modifiedBy,
+		}).Error
+}
+
+func (r *QuestionEditRepositoryImpl) GetQuestionTags(tx *gorm.DB, questionIDs []int64) ([]QuestionTag, error) {
+	var tags []QuestionTag
+	err := tx.Table("tag_question_mapping tqm").
+		Select("tqm.question_id, tqm.tag_id, t.tag").
+		Joins("JOIN tag_mst t ON t.tag_id = tqm.tag_id").
+		Where("tqm.question_id IN ? AND tqm.is_deleted = false", questionIDs).
+		Order("tqm.question_id, t.tag").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// RemoveQuestionTags unmaps tagIDs from the questions, or every tag when tagIDs is empty.
+func (r *QuestionEditRepositoryImpl) RemoveQuestionTags(tx *gorm.DB, questionIDs, tagIDs []int64, modifiedBy string) error {
+	query := tx.Model(&models.QuestionTagMapping{}).
+		Where("question_id IN ? AND is_deleted = false", questionIDs)
+	if len(tagIDs) > 0 {
+		query = query.Where("tag_id IN ?", tagIDs)
+	}
+	return query.Updates(map[string]interface{}{
+		"is_deleted":  true,
+		"is_active":   false,
+		"modified_on": time.Now(),
+		"modified_by": modifiedBy,
+	}).Error
+}
+
+// GetAssessmentQuestionMappings returns the mappings of the questions in one assessment, or in
+// all assessments when assessmentSeq is empty. Without questionIDs, the whole assessment.
+func (r *QuestionEditRepositoryImpl) GetAssessmentQuestionMappings(tx *gorm.DB, assessmentSeq string, questionIDs []int64) ([]models.AssessmentQuestionMst, error) {
+	var mappings []models.AssessmentQuestionMst
+	query := tx.Where("is_deleted = false")
+	if assessmentSeq != "" {
+		query = query.Where("assessment_sequence = ?", assessmentSeq)
+	}
+	if len(questionIDs) > 0 {
+		query = query.Where("question_id IN ?", questionIDs)
+	}
+	err := query.Order("assessment_sequence, sequence_id").Find(&mappings).Error
+	return mappings, err
+}
+
+func (r *QuestionEditRepositoryImpl) UpdateAssessmentQuestions(tx *gorm.DB, mappingIDs []int64, updates map[string]interface{}) error {
+	return tx.Model(&models.AssessmentQuestionMst{}).
+		Where("assessment_question_id IN ?", mappingIDs).
+		Updates(updates).Error
+}
+
+func (r *QuestionEditRepositoryImpl) UpdateAssessmentMarks(tx *gorm.DB, assessmentSeq string, marks int64, modifiedBy string) error {
+	return tx.Model(&models.AssessmentMst{}).
+		Where("assessment_sequence = ?", assessmentSeq).
+		Updates(map[string]interface{}{
+			"marks":       marks,
+			"modified_on": time.Now(),
+			"modified_by": modifiedBy,
+		}).Error
+}
+
+// GetEditableContents returns the stem and option content of the questions, selected by id
+// and/or by the assessment using them.
+func (r *QuestionEditRepositoryImpl) GetEditableContents(tx *gorm.DB, assessmentSeq string, questionIDs []int64) ([]models.EditableContent, error) {
+	var contents []models.EditableContent
+
+	query := `
+		SELECT q.question_id, NULL::bigint AS option_id, c.content_id, c.content_type_id, c.value, 0 AS sort_key
+		FROM question_mst q
+		JOIN content_mst c ON c.content_id = q.content_id
+		WHERE q.is_deleted = false AND q.question_id IN (?)
+		UNION ALL
+		SELECT o.question_id, o.option_id, c.content_id, c.content_type_id, c.value, o.sequence_id + 1 AS sort_key
+		FROM option_mst o
+		JOIN question_mst q ON q.question_id = o.question_id
+		JOIN content_mst c ON c.content_id = o.content_id
+		WHERE q.is_deleted = false AND q.question_id IN (?)
+		ORDER BY question_id, sort_key, option_id
+	`
+	selected := r.selectQuestionIDs(tx, assessmentSeq, questionIDs)
+	if err := tx.Raw(query, selected, selected).Scan(&contents).Error; err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+func (r *QuestionEditRepositoryImpl) selectQuestionIDs(tx *gorm.DB, assessmentSeq string, questionIDs []int64) *gorm.DB {
+	query := tx.Model(&models.QuestionMst{}).Select("question_id")
+	if assessmentSeq != "" {
+		query = query.Where("question_id IN (?)", tx.Model(&models.AssessmentQuestionMst{}).
+			Select("question_id").
+			Where("assessment_sequence = ? AND is_deleted = false", assessmentSeq))
+	}
+	if len(questionIDs) > 0 {
+		query = query.Where("question_id IN ?", questionIDs)
+	}
+	return query
+}
+
+func (r *QuestionEditRepositoryImpl) UpdateContentValue(tx *gorm.DB, contentID int64, value string) error {
+	return tx.Model(&models.ContentMst{}).
+		Where("content_id = ?", contentID).
+		Update("value", value).Error
+}
+
+func (r *QuestionEditRepositoryImpl) CreateHistory(tx *gorm.DB, entries []models.QuestionEditHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(entries, 500).Error
+}
+
+func (r *QuestionEditRepositoryImpl) ListHistory(questionID int64, batchID string, limit, offset int) ([]models.QuestionEditHistory, int64, error) {
+	var list []models.QuestionEditHistory
+	var total int64
+
+	query := r.db.Model(&models.QuestionEditHistory{})
+	if questionID > 0 {
+		query = query.Where("question_id = ?", questionID)
+	}
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.
+		Order("edited_on DESC, history_id").
+		Limit(limit).
+		Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"dhl/models"
      "fmt"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -12,6 +14,14 @@ type QuestionRepository interface {
 	CreateOption(tx *gorm.DB, option *models.OptionMst) error
 
 	GetQuestionTypeID(tx *gorm.DB, typeValue string) (int64, error) 
+	FindQuestionIDsByTag(tagName, difficultyLevel string, limit int, excludeIDs []int64) ([]int64, error)
+	FindBlueprintQuestions(parentTag, tagName, difficultyLevel string, limit int, excludeIDs, avoidIDs []int64) ([]models.BankQuestion, error)
+	GetQuestionContentID(questionID int64) (int64, error)
+	GetOptionContentID(optionID int64) (int64, error)
+	UpdateContent(tx *gorm.DB, content *models.ContentMst) error
+	GetBankQuestionTexts() ([]models.BankQuestionText, error)
+	GetBankVersion() (models.BankVersion, error)
+	GetQuestionAssessmentCounts(questionIDs []int64) ([]models.QuestionAssessmentCount, error)
 }
 
 type QuestionRepositoryImpl struct {
@@ -53,4 +63,213 @@ func (r *QuestionRepositoryImpl) GetQuestionTypeID(tx *gorm.DB, typeValue string
 	}
 
 	return id, nil
-}
\ No newline at end of file
+}
+
+// FindQuestionIDsByTag picks active questions tagged with tagName, matched exactly but for
+// case, preferring questions already used at the given difficulty level elsewhere in the bank.
+func (r *QuestionRepositoryImpl) FindQuestionIDsByTag(tagName, difficultyLevel string, limit int, excludeIDs []int64) ([]int64, error) {
+	var ids []int64
+	if limit <= 0 {
+		return ids, nil
+	}
+
+	// The questions used at the level are collected once rather than probed per candidate.
+	query := `
+		WITH level_questions AS (
+			SELECT DISTINCT aq.question_id
+			FROM assessment_question_mst aq
+			WHERE aq.difficulty_level = ?
+		)
+		SELECT q.question_id
+		FROM question_mst q
+		JOIN tag_question_mapping qtm
+			ON qtm.question_id = q.question_id
+			AND qtm.is_deleted = false
+			AND qtm.is_active = true
+		JOIN tag_mst tm
+			ON tm.tag_id = qtm.tag_id
+			AND tm.is_deleted = false
+		LEFT JOIN level_questions lq
+			ON lq.question_id = q.question_id
+		WHERE q.is_deleted = false
+		AND q.is_active = true
+		AND LOWER(tm.tag) = LOWER(?)
+	`
+	params := []interface{}{difficultyLevel, tagName}
+
+	if len(excludeIDs) > 0 {
+		query += " AND q.question_id NOT IN ?"
+		params = append(params, excludeIDs)
+	}
+
+	query += `
+		GROUP BY q.question_id, lq.question_id
+		ORDER BY (lq.question_id IS NOT NULL) DESC, RANDOM()
+		LIMIT ?
+	`
+	params = append(params, limit)
+
+	if err := r.db.Raw(query, params...).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *QuestionRepositoryImpl) GetQuestionContentID(questionID int64) (int64, error) {
+	var question models.QuestionMst
+	if err := r.db.Select("content_id").Where("question_id = ? AND is_deleted = false", questionID).First(&question).Error; err != nil {
+		return 0, err
+	}
+	return question.ContentID, nil
+}
+
+func (r *QuestionRepositoryImpl) GetOptionContentID(optionID int64) (int64, error) {
+	var option models.OptionMst
+	if err := r.db.Select("content_id").Where("option_id = ?", optionID).First(&option).Error; err != nil {
+		return 0, err
+	}
+	return option.ContentID, nil
+}
+
+// UpdateContent replaces a stem or option content and marks its question as modified.
+func (r *QuestionRepositoryImpl) UpdateContent(tx *gorm.DB, content *models.ContentMst) error {
+	err := tx.Model(&models.ContentMst{}).
+		Where("content_id = ?", content.ContentID).
+		Updates(map[string]interface{}{
+			"content_type_id": content.ContentTypeID,
+			"value":           content.Value,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.QuestionMst{}).
+		Where("content_id = ? OR question_id IN (?)", content.ContentID,
+			tx.Model(&models.OptionMst{}).Select("question_id").Where("content_id = ?", content.ContentID)).
+		Update("modified_on", time.Now()).Error
+}
+
+// FindBlueprintQuestions picks active questions for one blueprint constraint. The tag (and its
+// parent, when given) and the difficulty are hard filters; avoidIDs are only used when nothing
+// else is left, so repeated forms from the same blueprint differ where the bank allows.
+func (r *QuestionRepositoryImpl) FindBlueprintQuestions(parentTag, tagName, difficultyLevel string, limit int, excludeIDs, avoidIDs []int64) ([]models.BankQuestion, error) {
+	var rows []models.BankQuestion
+	if limit <= 0 {
+		return rows, nil
+	}
+
+	query := `
+		SELECT q.question_id,
+			COALESCE((
+				SELECT aq.difficulty_level FROM assessment_question_mst aq
+				WHERE aq.question_id = q.question_id AND COALESCE(aq.difficulty_level, '') <> ''
+				ORDER BY aq.modified_on DESC
+				LIMIT 1
+			), '') AS difficulty_level
+		FROM question_mst q
+		WHERE q.is_deleted = false
+		AND q.is_active = true
+	`
+	var params []interface{}
+
+	if tagName != "" {
+		query += `
+		AND EXISTS (
+			SELECT 1
+			FROM tag_question_mapping qtm
+			JOIN tag_mst tm ON tm.tag_id = qtm.tag_id AND tm.is_deleted = false
+			LEFT JOIN tag_mst pt ON pt.tag_id = tm.parent_tag_id
+			WHERE qtm.question_id = q.question_id
+			AND qtm.is_deleted = false
+			AND qtm.is_active = true
+			AND LOWER(tm.tag) = LOWER(?)`
+		params = append(params, tagName)
+		if parentTag != "" {
+			query += " AND LOWER(pt.tag) = LOWER(?)"
+			params = append(params, parentTag)
+		}
+		query += ")"
+	}
+
+	if difficultyLevel != "" {
+		query += `
+		AND EXISTS (
+			SELECT 1 FROM assessment_question_mst aq
+			WHERE aq.question_id = q.question_id
+			AND LOWER(aq.difficulty_level) = LOWER(?)
+		)`
+		params = append(params, difficultyLevel)
+	}
+
+	if len(excludeIDs) > 0 {
+		query += " AND q.question_id NOT IN ?"
+		params = append(params, excludeIDs)
+	}
+
+	query += " ORDER BY "
+	if len(avoidIDs) > 0 {
+		query += "CASE WHEN q.question_id IN ? THEN 1 ELSE 0 END, "
+		params = append(params, avoidIDs)
+	}
+	query += "RANDOM() LIMIT ?"
+	params = append(params, limit)
+
+	if err := r.db.Raw(query, params...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetBankQuestionTexts loads the stem and options of every active bank question for
+// duplicate detection.
+func (r *QuestionRepositoryImpl) GetBankQuestionTexts() ([]models.BankQuestionText, error) {
+	var rows []models.BankQuestionText
+	err := r.db.Raw(`
+		SELECT q.question_id,
+			ct.content_type,
+			c.value,
+			COALESCE((
+				SELECT STRING_AGG(oct.content_type || E'\x1e' || oc.value, E'\x1f' ORDER BY o.sequence_id, o.option_id)
+				FROM option_mst o
+				JOIN content_mst oc ON oc.content_id = o.content_id
+				JOIN content_type_config oct ON oct.content_type_id = oc.content_type_id
+				WHERE o.question_id = q.question_id
+			), '') AS options
+		FROM question_mst q
+		JOIN content_mst c ON c.content_id = q.content_id
+		JOIN content_type_config ct ON ct.content_type_id = c.content_type_id
+		WHERE q.is_deleted = false
+		AND q.is_active = true
+		ORDER BY q.question_id
+	`).Scan(&rows).Error
+	return rows, err
+}
+
+// GetBankVersion summarises the active bank in one aggregate over question_mst. Every write to
+// a question or its content also sets the question's modified_on.
+func (r *QuestionRepositoryImpl) GetBankVersion() (models.BankVersion, error) {
+	var version models.BankVersion
+	err := r.db.Raw(`
+		SELECT COUNT(*) AS questions,
+			COALESCE(MAX(question_id), 0) AS max_question_id,
+			MAX(modified_on) AS modified_on
+		FROM question_mst
+		WHERE is_deleted = false
+		AND is_active = true
+	`).Scan(&version).Error
+	return version, err
+}
+
+// GetQuestionAssessmentCounts counts the assessments each of the questions is used in.
+func (r *QuestionRepositoryImpl) GetQuestionAssessmentCounts(questionIDs []int64) ([]models.QuestionAssessmentCount, error) {
+	var rows []models.QuestionAssessmentCount
+	if len(questionIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Raw(`
+		SELECT aq.question_id, COUNT(DISTINCT aq.assessment_sequence) AS assessment_count
+		FROM assessment_question_mst aq
+		WHERE aq.question_id IN ? AND aq.is_deleted = false
+		GROUP BY aq.question_id
+	`, questionIDs).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// latestCertificationsSQL picks each user's most recent certification per recurring assessment
+// and classifies it against @now.
+const latestCertificationsSQL = `
+	SELECT DISTINCT ON (uc.user_id, uc.assessment_sequence)
+		uc.user_id,
+		CONCAT(u.first_name, ' ', u.last_name) AS user_name,
+		uc.assessment_sequence,
+		am.assessment_desc,
+		uc.cycle,
+		uc.issued_on,
+		uc.expires_on,
+		CASE
+			WHEN uc.expires_on <= @now THEN 'expired'
+			WHEN uc.expires_on <= @now + make_interval(days => r.notify_days_before) THEN 'expiring'
+			ELSE 'current'
+		END AS status
+	FROM user_certification uc
+	JOIN assessment_recurrence r ON r.assessment_sequence = uc.assessment_sequence AND r.is_active = true
+	JOIN assessment_mst am ON am.assessment_sequence = uc.assessment_sequence AND am.is_deleted = false
+	LEFT JOIN assessment_user_mst u ON u.user_id::text = uc.user_id
+	ORDER BY uc.user_id, uc.assessment_sequence, uc.expires_on DESC
+`
+
+type RecertificationRepository interface {
+	GetRecurrence(tx *gorm.DB, assessmentSeq string) (*models.AssessmentRecurrence, error)
+	SaveRecurrence(tx *gorm.DB, recurrence *models.AssessmentRecurrence) error
+	GetActiveRecurrences(tx *gorm.DB) ([]models.AssessmentRecurrence, error)
+	RecordPasses(tx *gorm.DB, now time.Time) ([]models.IssuedCertification, error)
+	ReassignLapsed(tx *gorm.DB, assessmentSeq string, now time.Time, includeNeverPassed bool, modifiedBy string) ([]string, error)
+	ReopenForCycle(tx *gorm.DB, assessmentSeq string, start, previousStart time.Time, modifiedBy string) error
+	GetExpiringToNotify(tx *gorm.DB, now time.Time) ([]models.ExpiringCertification, error)
+	MarkExpiryNotified(tx *gorm.DB, certificationIDs []int64, now time.Time) error
+	GetLatestForUsers(userIDs []string, now time.Time) ([]models.CertificationSummary, error)
+	ListCertifications(assessmentSeq, status string, now time.Time, limit, offset int) ([]models.CertificationSummary, int64, error)
+}
+
+type RecertificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRecertificationRepository(db *gorm.DB) RecertificationRepository {
+	return &RecertificationRepositoryImpl{db: db}
+}
+
+// GetRecurrence returns the rule of an assessment, active or not.
+func (r *RecertificationRepositoryImpl) GetRecurrence(tx *gorm.DB, assessmentSeq string) (*models.AssessmentRecurrence, error) {
+	var recurrence models.AssessmentRecurrence
+	if err := tx.Where("assessment_sequence = ?", assessmentSeq).First(&recurrence).Error; err != nil {
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+func (r *RecertificationRepositoryImpl) SaveRecurrence(tx *gorm.DB, recurrence *models.AssessmentRecurrence) error {
+	return tx.Save(recurrence).Error
+}
+
+func (r *RecertificationRepositoryImpl) GetActiveRecurrences(tx *gorm.DB) ([]models.AssessmentRecurrence, error) {
+	var recurrences []models.AssessmentRecurrence
+	err := tx.Where("is_active = true").Order("next_cycle_on").Find(&recurrences).Error
+	return recurrences, err
+}
+
+// RecordPasses issues a certification for every passed session of a recurring assessment that
+// has none yet, and returns them. The score rule is the one the certificate PDF uses; sessions
+// whose results are held back wait for their release.
+func (r *RecertificationRepositoryImpl) RecordPasses(tx *gorm.DB, now time.Time) ([]models.IssuedCertification, error) {
+	var issued []models.IssuedCertification
+	err := tx.Raw(`
+		INSERT INTO user_certification (assessment_sequence, user_id, session_id, cycle, issued_on, expires_on, created_on)
+		SELECT s.assessment_id, s.user_id, s.session_id::text, rc.current_cycle, s.created_on,
+			s.created_on + make_interval(months => rc.interval_months), ?
+		FROM assessment_user_session s
+		JOIN assessment_recurrence rc ON rc.assessment_sequence = s.assessment_id AND rc.is_active = true
+		JOIN assessment_mst am ON am.assessment_sequence = s.assessment_id AND am.is_deleted = false
+		WHERE s.is_deleted = false
+		  AND NOT EXISTS (SELECT 1 FROM user_certification uc WHERE uc.session_id = s.session_id::text)
+		  AND NOT EXISTS (
+			SELECT 1 FROM assessment_result_release rr
+			WHERE rr.assessment_sequence = s.assessment_id
+			  AND rr.mode <> ? AND rr.released_on IS NULL
+		  )
+		  AND EXISTS (
+			SELECT 1 FROM assessment_result ar
+			WHERE ar.assessment_session_id = s.session_id::text AND ar.is_deleted = false
+		  )
+		  AND (
+			SELECT COALESCE(SUM(ar.point_assigned), 0) FROM assessment_result ar
+			WHERE ar.assessment_session_id = s.session_id::text
+			  AND ar.assessment_sequence = s.assessment_id
+			  AND ar.is_deleted = false
+		  ) * 100.0 / NULLIF(am.marks, 0) >= am.passing_score
+		RETURNING user_id, assessment_sequence, expires_on
+	`, now, constant.ResultReleaseImmediate).Scan(&issued).Error
+	return issued, err
+}
+
+// ReassignLapsed reassigns users who finished the assessment but hold no certification valid
+// at now. Unless includeNeverPassed, only users whose certification expired are picked; users
+// who never passed wait for the next cycle. A due date from the previous cycle is cleared. It
+// returns the reassigned user ids.
+func (r *RecertificationRepositoryImpl) ReassignLapsed(tx *gorm.DB, assessmentSeq string, now time.Time, includeNeverPassed bool, modifiedBy string) ([]string, error) {
+	query := `
+		UPDATE assessment_status st
+		SET assessment_status = @reassigned, due_date = NULL, modified_on = @now, modified_by = @modifiedBy
+		WHERE st.assessment_id = @seq
+		  AND st.is_deleted = false
+		  AND st.assessment_status IN @finished
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_certification uc
+			WHERE uc.assessment_sequence = st.assessment_id AND uc.user_id = st.user_id AND uc.expires_on > @now
+		  )
+	`
+	if !includeNeverPassed {
+		query += `
+		  AND EXISTS (
+			SELECT 1 FROM user_certification uc
+			WHERE uc.assessment_sequence = st.assessment_id AND uc.user_id = st.user_id
+		  )`
+	}
+	query += " RETURNING st.user_id"
+
+	var userIDs []string
+	err := tx.Raw(query, map[string]interface{}{
+		"reassigned": constant.AssignmentReassigned,
+		"finished":   []string{constant.AssignmentCompleted, constant.AssignmentExpired},
+		"seq":        assessmentSeq,
+		"now":        now,
+		"modifiedBy": modifiedBy,
+	}).Scan(&userIDs).Error
+	return userIDs, err
+}
+
+// ReopenForCycle moves the validity window of an assessment so it starts at start, keeping
+// its length, shifts the deadline alike and reopens the assessment if it was closed. Without a
+// ValidFrom the window moves by the time since previousStart.
+func (r *RecertificationRepositoryImpl) ReopenForCycle(tx *gorm.DB, assessmentSeq string, start, previousStart time.Time, modifiedBy string) error {
+	params := map[string]interface{}{
+		"seq":        assessmentSeq,
+		"start":      start,
+		"prev":       previousStart,
+		"closed":     string(constant.Closed),
+		"open":       string(constant.Open),
+		"now":        time.Now(),
+		"modifiedBy": modifiedBy,
+	}
+	// the deadline goes first, while valid_from still holds the old start
+	if err := tx.Exec(`
+		UPDATE dhl_survey_survey_ext sse
+		SET deadline = CASE
+				WHEN sse.deadline > '1900-01-01' THEN sse.deadline + (@start - COALESCE(am.valid_from, @prev))
+				ELSE sse.deadline
+			END,
+			state = CASE WHEN sse.state = @closed THEN @open ELSE sse.state END
+		FROM assessment_mst am
+		WHERE am.assessment_sequence = sse.assessment_sequence AND sse.assessment_sequence = @seq
+	`, params).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE assessment_mst
+		SET valid_to = valid_to + (@start - COALESCE(valid_from, @prev)),
+			valid_from = CASE WHEN valid_from IS NOT NULL THEN @start END,
+			modified_on = @now, modified_by = @modifiedBy
+		WHERE assessment_sequence = @seq AND is_deleted = false
+	`, params).Error
+}
+
+// GetExpiringToNotify returns the latest certifications that run out within their notice
+// period and have not been announced yet.
+func (r *RecertificationRepositoryImpl) GetExpiringToNotify(tx *gorm.DB, now time.Time) ([]models.ExpiringCertification, error) {
+	var certs []models.ExpiringCertification
+	err := tx.Raw(`
+		SELECT uc.id, uc.user_id, uc.assessment_sequence, uc.expires_on
+		FROM user_certification uc
+		JOIN assessment_recurrence r ON r.assessment_sequence = uc.assessment_sequence AND r.is_active = true
+		WHERE uc.expiry_notified_on IS NULL
+		  AND r.notify_days_before > 0
+		  AND uc.expires_on > ?
+		  AND uc.expires_on <= ? + make_interval(days => r.notify_days_before)
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_certification newer
+			WHERE newer.user_id = uc.user_id
+			  AND newer.assessment_sequence = uc.assessment_sequence
+			  AND newer.expires_on > uc.expires_on
+		  )
+		ORDER BY uc.expires_on
+	`, now, now).Scan(&certs).Error
+	return certs, err
+}
+
+func (r *RecertificationRepositoryImpl) MarkExpiryNotified(tx *gorm.DB, certificationIDs []int64, now time.Time) error {
+	if len(certificationIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.UserCertification{}).
+		Where("id IN ?", certificationIDs).
+		Update("expiry_notified_on", now).Error
+}
+
+func (r *RecertificationRepositoryImpl) GetLatestForUsers(userIDs []string, now time.Time) ([]models.CertificationSummary, error) {
+	var summaries []models.CertificationSummary
+	if len(userIDs) == 0 {
+		return summaries, nil
+	}
+	err := r.db.Raw(`
+		SELECT * FROM (`+latestCertificationsSQL+`) c
+		WHERE c.user_id IN @users
+		ORDER BY c.expires_on
+	`, map[string]interface{}{"now": now, "users": userIDs}).Scan(&summaries).Error
+	return summaries, err
+}
+
+// ListCertifications pages through the latest certifications, optionally of one assessment
+// and in one status, soonest expiry first.
+func (r *RecertificationRepositoryImpl) ListCertifications(assessmentSeq, status string, now time.Time, limit, offset int) ([]models.CertificationSummary, int64, error) {
+	query := "FROM (" + latestCertificationsSQL + ") c WHERE 1 = 1"
+	params := map[string]interface{}{"now": now, "limit": limit, "offset": offset}
+	if assessmentSeq != "" {
+		query += " AND c.assessment_sequence = @seq"
+		params["seq"] = assessmentSeq
+	}
+	if status != "" {
+		query += " AND c.status = @status"
+		params["status"] = status
+	}
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) "+query, params).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var summaries []models.CertificationSummary
+	if err := r.db.Raw("SELECT * "+query+" ORDER BY c.expires_on, c.user_id LIMIT @limit OFFSET @offset", params).Scan(&summaries).Error; err != nil {
+		return nil, 0, err
+	}
+	return summaries, total, nil
+}
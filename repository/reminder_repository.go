@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"dhl/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReminderRepository interface {
+	GetPolicy(assessmentSeq string) (*models.ReminderPolicy, error)
+	GetPolicies(tx *gorm.DB) ([]models.ReminderPolicy, error)
+	SavePolicy(tx *gorm.DB, policy *models.ReminderPolicy) error
+	DeletePolicy(assessmentSeq string) error
+
+	GetCandidates(tx *gorm.DB, until time.Time) ([]models.ReminderCandidate, error)
+	GetManagers(tx *gorm.DB, userID string) ([]string, error)
+	HasReminder(tx *gorm.DB, assessmentSeq, userID, recipientID, eventKey string, dueDate time.Time) (bool, error)
+	RecordReminder(tx *gorm.DB, entry *models.ReminderLog) error
+	ListReminders(filter models.ReminderLogFilter, limit, offset int) ([]models.ReminderLog, int64, error)
+}
+
+type ReminderRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &ReminderRepositoryImpl{db: db}
+}
+
+// GetPolicy returns the assessment's own reminder policy, or nil when it follows the default.
+func (r *ReminderRepositoryImpl) GetPolicy(assessmentSeq string) (*models.ReminderPolicy, error) {
+	var policy models.ReminderPolicy
+	if err := r.db.Where("assessment_sequence = ?", assessmentSeq).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *ReminderRepositoryImpl) GetPolicies(tx *gorm.DB) ([]models.ReminderPolicy, error) {
+	var policies []models.ReminderPolicy
+	err := tx.Find(&policies).Error
+	return policies, err
+}
+
+func (r *ReminderRepositoryImpl) SavePolicy(tx *gorm.DB, policy *models.ReminderPolicy) error {
+	return tx.Save(policy).Error
+}
+
+func (r *ReminderRepositoryImpl) DeletePolicy(assessmentSeq string) error {
+	res := r.db.Where("assessment_sequence = ?", assessmentSeq).Delete(&models.ReminderPolicy{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetCandidates returns the assignments nobody finished whose due date is before until, on
+// assessments that are not deleted.
+func (r *ReminderRepositoryImpl) GetCandidates(tx *gorm.DB, until time.Time) ([]models.ReminderCandidate, error) {
+	var candidates []models.ReminderCandidate
+	err := tx.Raw(`
+		SELECT ast.assessment_id AS assessment_sequence, ast.user_id, `+dueDateSQL+` AS due_date
+		FROM assessment_status ast
+		JOIN assessment_mst am ON am.assessment_sequence = ast.assessment_id AND am.is_deleted = false
+		LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = ast.assessment_id
+		WHERE ast.is_deleted = false
+		  AND ast.assessment_status IN ?
+		  AND `+dueDateSQL+` IS NOT NULL
+		  AND `+dueDateSQL+` < ?
+		ORDER BY ast.assessment_id, ast.user_id
+	`, openAssignmentStatuses, until).Scan(&candidates).Error
+	return candidates, err
+}
+
+func (r *ReminderRepositoryImpl) GetManagers(tx *gorm.DB, userID string) ([]string, error) {
+	var managerIDs []string
+	err := tx.Model(&models.UserManagerMapping{}).
+		Where("user_id = ? AND is_active = true", userID).
+		Distinct().
+		Pluck("manager_id", &managerIDs).Error
+	return managerIDs, err
+}
+
+// HasReminder tells whether the reminder was already queued for this due date. A new due
+// date, after an extension, starts the reminders over.
+func (r *ReminderRepositoryImpl) HasReminder(tx *gorm.DB, assessmentSeq, userID, recipientID, eventKey string, dueDate time.Time) (bool, error) {
+	var count int64
+	err := tx.Model(&models.ReminderLog{}).
+		Where("assessment_sequence = ? AND user_id = ? AND recipient_id = ? AND event_key = ? AND due_date = ?",
+			assessmentSeq, userID, recipientID, eventKey, dueDate).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ReminderRepositoryImpl) RecordReminder(tx *gorm.DB, entry *models.ReminderLog) error {
+	return tx.Create(entry).Error
+}
+
+func (r *ReminderRepositoryImpl) ListReminders(filter models.ReminderLogFilter, limit, offset int) ([]models.ReminderLog, int64, error) {
+	query := r.db.Model(&models.ReminderLog{})
+	if filter.AssessmentSequence != "" {
+		query = query.Where("assessment_sequence = ?", filter.AssessmentSequence)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []models.ReminderLog
+	err := query.Order("queued_on DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// releasedResultSQL scores sessions as s, with the same rule the certificate uses, and tells
+// whether the assessment recurs. It expects assessment_mst as am and dhl_survey_survey_ext as sse.
+const releasedResultSQL = `
+	SELECT s.user_id, s.session_id::text AS session_id,
+		COALESCE(SUM(ar.point_assigned), 0) AS marks_obtained,
+		am.marks AS total_marks,
+		COALESCE(ROUND(COALESCE(SUM(ar.point_assigned), 0) * 100.0 / NULLIF(am.marks, 0), 2), 0) AS percentage,
+		COALESCE(COALESCE(SUM(ar.point_assigned), 0) * 100.0 / NULLIF(am.marks, 0) >= am.passing_score, false) AS passed,
+		COALESCE(sse.certificate, false) AS certificate,
+		COALESCE(BOOL_OR(rc.is_active), false) AS recurring
+	FROM assessment_user_session s
+	JOIN assessment_mst am ON am.assessment_sequence = s.assessment_id
+	LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = s.assessment_id
+	LEFT JOIN assessment_recurrence rc ON rc.assessment_sequence = s.assessment_id AND rc.is_active = true
+	LEFT JOIN assessment_result ar ON ar.assessment_session_id = s.session_id::text
+		AND ar.assessment_sequence = s.assessment_id
+		AND ar.is_deleted = false`
+
+type ResultReleaseRepository interface {
+	GetRelease(tx *gorm.DB, assessmentSeq string) (*models.ResultRelease, error)
+	GetShowResult(tx *gorm.DB, assessmentSeq string) (bool, error)
+	SaveRelease(tx *gorm.DB, release *models.ResultRelease) error
+	GetDueReleases(tx *gorm.DB, now time.Time) ([]string, error)
+	MarkReleased(tx *gorm.DB, assessmentSeq string, now time.Time, releasedBy string) (bool, error)
+
+	GetSessionResult(tx *gorm.DB, sessionID string) (*models.ReleasedResult, error)
+	GetLatestResults(tx *gorm.DB, assessmentSeq string) ([]models.ReleasedResult, error)
+}
+
+type ResultReleaseRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewResultReleaseRepository(db *gorm.DB) ResultReleaseRepository {
+	return &ResultReleaseRepositoryImpl{db: db}
+}
+
+// GetRelease returns the release of an assessment, or nil when its results show on submission.
+func (r *ResultReleaseRepositoryImpl) GetRelease(tx *gorm.DB, assessmentSeq string) (*models.ResultRelease, error) {
+	var release models.ResultRelease
+	if err := tx.Where("assessment_sequence = ?", assessmentSeq).First(&release).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &release, nil
+}
+
+// GetShowResult tells whether the assessment shows results to users at all.
+func (r *ResultReleaseRepositoryImpl) GetShowResult(tx *gorm.DB, assessmentSeq string) (bool, error) {
+	var show bool
+	err := tx.Model(&models.DhlSurveySurveyExt{}).
+		Select("COALESCE(show_result, false)").
+		Where("assessment_sequence = ?", assessmentSeq).
+		Scan(&show).Error
+	return show, err
+}
+
+func (r *ResultReleaseRepositoryImpl) SaveRelease(tx *gorm.DB, release *models.ResultRelease) error {
+	return tx.Save(release).Error
+}
+
+// GetDueReleases returns the assessments whose timed release has come: on_close ones that are
+// closed and on_date ones past their date.
+func (r *ResultReleaseRepositoryImpl) GetDueReleases(tx *gorm.DB, now time.Time) ([]string, error) {
+	var seqs []string
+	err := tx.Raw(`
+		SELECT rr.assessment_sequence
+		FROM assessment_result_release rr
+		LEFT JOIN dhl_survey_survey_ext sse ON sse.assessment_sequence = rr.assessment_sequence
+		WHERE rr.released_on IS NULL
+		  AND (
+			(rr.mode = ? AND sse.state = ?)
+			OR (rr.mode = ? AND rr.release_on <= ?)
+		  )
+		ORDER BY rr.assessment_sequence
+	`, constant.ResultReleaseOnClose, string(constant.Closed), constant.ResultReleaseOnDate, now).Scan(&seqs).Error
+	return seqs, err
+}
+
+// MarkReleased releases the results of an assessment, unless they already were. It reports
+// whether this call released them.
+func (r *ResultReleaseRepositoryImpl) MarkReleased(tx *gorm.DB, assessmentSeq string, now time.Time, releasedBy string) (bool, error) {
+	res := tx.Model(&models.ResultRelease{}).
+		Where("assessment_sequence = ? AND released_on IS NULL", assessmentSeq).
+		Updates(map[string]interface{}{
+			"released_on": now,
+			"released_by": releasedBy,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *ResultReleaseRepositoryImpl) GetSessionResult(tx *gorm.DB, sessionID string) (*models.ReleasedResult, error) {
+	var result models.ReleasedResult
+	err := tx.Raw(releasedResultSQL+`
+		WHERE s.session_id::text = ?
+		GROUP BY s.user_id, s.session_id, am.marks, am.passing_score, sse.certificate
+	`, sessionID).Scan(&result).Error
+	return &result, err
+}
+
+// GetLatestResults scores the latest submitted session of every user who completed the
+// assessment.
+func (r *ResultReleaseRepositoryImpl) GetLatestResults(tx *gorm.DB, assessmentSeq string) ([]models.ReleasedResult, error) {
+	var results []models.ReleasedResult
+	err := tx.Raw(`
+		SELECT DISTINCT ON (res.user_id) res.*
+		FROM (`+releasedResultSQL+`
+			WHERE s.assessment_id = ? AND s.is_deleted = false
+			  AND EXISTS (
+				SELECT 1 FROM assessment_status ast
+				WHERE ast.assessment_id = s.assessment_id AND ast.user_id = s.user_id
+				  AND ast.assessment_status = ? AND ast.is_deleted = false
+			  )
+			GROUP BY s.user_id, s.session_id, s.created_on, am.marks, am.passing_score, sse.certificate
+			HAVING COUNT(ar.assessment_session_id) > 0
+		) res
+		JOIN assessment_user_session ls ON ls.session_id::text = res.session_id
+		ORDER BY res.user_id, ls.created_on DESC
+	`, assessmentSeq, constant.AssignmentCompleted).Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	ListTags() ([]models.TagMaster, error)
+	GetTag(tx *gorm.DB, tagID int64) (*models.TagMaster, error)
+	FindSibling(tx *gorm.DB, tagName string, parentTagID *int64, excludeID int64) (*models.TagMaster, error)
+	GetChildren(tx *gorm.DB, parentTagIDs []int64) ([]models.TagMaster, error)
+	CreateTag(tx *gorm.DB, tag *models.TagMaster) error
+	UpdateTag(tx *gorm.DB, tagID int64, updates map[string]interface{}) error
+	GetUsage(tagIDs []int64) ([]models.TagUsage, error)
+	MergeQuestionMappings(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error)
+	MergeAssessmentMappings(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error)
+	AddAncestorMappings(tx *gorm.DB, tagIDs, ancestorIDs []int64, createdBy string) error
+	ReparentChildren(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error)
+	CountTagsAtPath(tx *gorm.DB, parent, tag string, excludeID int64) (int64, error)
+	RenameBlueprintTag(tx *gorm.DB, from, to string) (int64, error)
+}
+
+type TagRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &TagRepositoryImpl{db: db}
+}
+
+func (r *TagRepositoryImpl) ListTags() ([]models.TagMaster, error) {
+	var tags []models.TagMaster
+	err := r.db.Where("is_deleted = false").Order("tag").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepositoryImpl) GetTag(tx *gorm.DB, tagID int64) (*models.TagMaster, error) {
+	var tag models.TagMaster
+	if err := tx.Where("tag_id = ? AND is_deleted = false", tagID).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindSibling looks up a tag by name (case-insensitively) under the same parent.
+func (r *TagRepositoryImpl) FindSibling(tx *gorm.DB, tagName string, parentTagID *int64, excludeID int64) (*models.TagMaster, error) {
+	var tag models.TagMaster
+	query := tx.Where("LOWER(tag) = LOWER(?) AND is_deleted = false AND tag_id <> ?", tagName, excludeID)
+	if parentTagID != nil {
+		query = query.Where("parent_tag_id = ?", *parentTagID)
+	} else {
+		query = query.Where("parent_tag_id IS NULL")
+	}
+	if err := query.First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepositoryImpl) GetChildren(tx *gorm.DB, parentTagIDs []int64) ([]models.TagMaster, error) {
+	var children []models.TagMaster
+	err := tx.Where("parent_tag_id IN ? AND is_deleted = false", parentTagIDs).
+		Order("tag_id").
+		Find(&children).Error
+	return children, err
+}
+
+func (r *TagRepositoryImpl) CreateTag(tx *gorm.DB, tag *models.TagMaster) error {
+	return tx.Create(tag).Error
+}
+
+func (r *TagRepositoryImpl) UpdateTag(tx *gorm.DB, tagID int64, updates map[string]interface{}) error {
+	res := tx.Model(&models.TagMaster{}).
+		Where("tag_id = ? AND is_deleted = false", tagID).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetUsage counts the live mappings, blueprint constraints and children of each tag; all tags
+// when tagIDs is empty. Blueprint constraints are matched as assembly matches them: on the bare
+// tag name, or on a path ending in the tag's own "Parent/Tag".
+func (r *TagRepositoryImpl) GetUsage(tagIDs []int64) ([]models.TagUsage, error) {
+	var usage []models.TagUsage
+
+	query := `
+		SELECT
+			t.tag_id,
+			(SELECT COUNT(DISTINCT tqm.question_id) FROM tag_question_mapping tqm
+				JOIN question_mst q ON q.question_id = tqm.question_id AND q.is_deleted = false
+				WHERE tqm.tag_id = t.tag_id AND tqm.is_deleted = false) AS question_count,
+			(SELECT COUNT(DISTINCT atm.assessment_sequence) FROM assessment_tag_mapping atm
+				WHERE atm.tag_id = t.tag_id AND atm.is_deleted = false) AS assessment_count,
+			(SELECT COUNT(DISTINCT bc.blueprint_id) FROM blueprint_constraint bc
+				JOIN assessment_blueprint b ON b.blueprint_id = bc.blueprint_id AND b.is_deleted = false
+				WHERE LOWER(bc.tag) = LOWER(t.tag)
+				OR (p.tag IS NOT NULL AND (LOWER(bc.tag) = LOWER(p.tag || '/' || t.tag)
+					OR RIGHT(LOWER(bc.tag), LENGTH(p.tag || t.tag) + 2) = LOWER('/' || p.tag || '/' || t.tag)))) AS blueprint_count,
+			(SELECT COUNT(*) FROM tag_mst c
+				WHERE c.parent_tag_id = t.tag_id AND c.is_deleted = false) AS child_count
+		FROM tag_mst t
+		LEFT JOIN tag_mst p ON p.tag_id = t.parent_tag_id AND p.is_deleted = false
+		WHERE t.is_deleted = false
+	`
+	var params []interface{}
+	if len(tagIDs) > 0 {
+		query += " AND t.tag_id IN ?"
+		params = append(params, tagIDs)
+	}
+
+	if err := r.db.Raw(query, params...).Scan(&usage).Error; err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// MergeQuestionMappings moves the question mappings of sourceID to targetID. Questions that
+// already carry the target just lose the source mapping.
+func (r *TagRepositoryImpl) MergeQuestionMappings(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error) {
+	now := time.Now()
+	if err := tx.Model(&models.QuestionTagMapping{}).
+		Where("tag_id = ? AND is_deleted = false", sourceID).
+		Where("question_id IN (?)", tx.Model(&models.QuestionTagMapping{}).
+			Select("question_id").
+			Where("tag_id = ? AND is_deleted = false", targetID)).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"is_active":   false,
+			"modified_on": now,
+			"modified_by": modifiedBy,
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	res := tx.Model(&models.QuestionTagMapping{}).
+		Where("tag_id = ? AND is_deleted = false", sourceID).
+		Updates(map[string]interface{}{
+			"tag_id":      targetID,
+			"modified_on": now,
+			"modified_by": modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// MergeAssessmentMappings does for assessment_tag_mapping what MergeQuestionMappings does for
+// questions.
+func (r *TagRepositoryImpl) MergeAssessmentMappings(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error) {
+	now := time.Now()
+	if err := tx.Model(&models.AssessmentTagMapping{}).
+		Where("tag_id = ? AND is_deleted = false", sourceID).
+		Where("assessment_sequence IN (?)", tx.Model(&models.AssessmentTagMapping{}).
+			Select("assessment_sequence").
+			Where("tag_id = ? AND is_deleted = false", targetID)).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"is_active":   false,
+			"modified_on": now,
+			"modified_by": modifiedBy,
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	res := tx.Model(&models.AssessmentTagMapping{}).
+		Where("tag_id = ? AND is_deleted = false", sourceID).
+		Updates(map[string]interface{}{
+			"tag_id":      targetID,
+			"modified_on": now,
+			"modified_by": modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// AddAncestorMappings maps every question and assessment tagged with any of tagIDs to the
+// ancestorIDs as well, keeping the "tag implies its parents" invariant after a move or merge.
+func (r *TagRepositoryImpl) AddAncestorMappings(tx *gorm.DB, tagIDs, ancestorIDs []int64, createdBy string) error {
+	if len(tagIDs) == 0 || len(ancestorIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+
+	for _, ancestorID := range ancestorIDs {
+		if err := tx.Exec(`
+			INSERT INTO tag_question_mapping (question_id, tag_id, created_on, created_by, is_active, is_deleted, modified_on, modified_by)
+			SELECT DISTINCT tqm.question_id, ?, ?, ?, true, false, ?, ?
+			FROM tag_question_mapping tqm
+			WHERE tqm.tag_id IN ? AND tqm.is_deleted = false
+			  AND NOT EXISTS (
+				SELECT 1 FROM tag_question_mapping e
+				WHERE e.question_id = tqm.question_id AND e.tag_id = ? AND e.is_deleted = false
+			  )
+		`, ancestorID, now, createdBy, now, createdBy, tagIDs, ancestorID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO assessment_tag_mapping (assessment_sequence, tag_id, created_on, created_by, is_active, is_deleted, modified_on, modified_by)
+			SELECT DISTINCT atm.assessment_sequence, ?, ?, ?, true, false, ?, ?
+			FROM assessment_tag_mapping atm
+			WHERE atm.tag_id IN ? AND atm.is_deleted = false
+			  AND NOT EXISTS (
+				SELECT 1 FROM assessment_tag_mapping e
+				WHERE e.assessment_sequence = atm.assessment_sequence AND e.tag_id = ? AND e.is_deleted = false
+			  )
+		`, ancestorID, now, createdBy, now, createdBy, tagIDs, ancestorID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TagRepositoryImpl) ReparentChildren(tx *gorm.DB, sourceID, targetID int64, modifiedBy string) (int64, error) {
+	res := tx.Model(&models.TagMaster{}).
+		Where("parent_tag_id = ? AND is_deleted = false", sourceID).
+		Updates(map[string]interface{}{
+			"parent_tag_id": targetID,
+			"modified_on":   time.Now(),
+			"modified_by":   modifiedBy,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// CountTagsAtPath counts the live tags other than excludeID that a blueprint constraint on
+// "parent/tag" would match, or on the bare tag when parent is empty.
+func (r *TagRepositoryImpl) CountTagsAtPath(tx *gorm.DB, parent, tag string, excludeID int64) (int64, error) {
+	var count int64
+	query := tx.Table("tag_mst t").
+		Where("t.is_deleted = false AND t.tag_id <> ? AND LOWER(t.tag) = LOWER(?)", excludeID, tag)
+	if parent != "" {
+		query = query.
+			Joins("JOIN tag_mst p ON p.tag_id = t.parent_tag_id AND p.is_deleted = false").
+			Where("LOWER(p.tag) = LOWER(?)", parent)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// RenameBlueprintTag rewrites blueprint constraints whose tag path is exactly from.
+func (r *TagRepositoryImpl) RenameBlueprintTag(tx *gorm.DB, from, to string) (int64, error) {
+	res := tx.Model(&models.BlueprintConstraint{}).
+		Where("LOWER(tag) = LOWER(?)", from).
+		Update("tag", to)
+	return res.RowsAffected, res.Error
+}
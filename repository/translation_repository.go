@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"dhl/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository interface {
+	UpsertContentTranslation(tx *gorm.DB, translation *models.ContentTranslation) error
+	UpsertAssessmentTranslation(tx *gorm.DB, translation *models.AssessmentTranslation) error
+	GetAssessmentTranslation(assessmentSeq, locale string) (*models.AssessmentTranslation, error)
+	GetAssessmentTranslations(assessmentSeq string) ([]models.AssessmentTranslation, error)
+	GetAssessmentContentTranslations(assessmentSeq, locale string) (map[int64]string, error)
+	GetAllAssessmentContentTranslations(assessmentSeq string) (map[int64]map[string]string, error)
+	GetAssessmentLocales(assessmentSeq string) ([]string, error)
+}
+
+type TranslationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepository(db *gorm.DB) TranslationRepository {
+	return &TranslationRepositoryImpl{db: db}
+}
+
+// assessmentContentQuery selects the content ids of the questions and options of an assessment.
+const assessmentContentQuery = `
+	SELECT q.content_id
+	FROM assessment_question_mst aq
+	JOIN question_mst q ON q.question_id = aq.question_id
+	WHERE aq.assessment_sequence = @seq AND aq.is_deleted = false
+	UNION
+	SELECT o.content_id
+	FROM assessment_question_mst aq
+	JOIN option_mst o ON o.question_id = aq.question_id
+	WHERE aq.assessment_sequence = @seq AND aq.is_deleted = false`
+
+func (r *TranslationRepositoryImpl) UpsertContentTranslation(tx *gorm.DB, translation *models.ContentTranslation) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "modified_on", "modified_by"}),
+	}).Create(translation).Error
+}
+
+func (r *TranslationRepositoryImpl) UpsertAssessmentTranslation(tx *gorm.DB, translation *models.AssessmentTranslation) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "assessment_sequence"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "instructions", "modified_on", "modified_by"}),
+	}).Create(translation).Error
+}
+
+func (r *TranslationRepositoryImpl) GetAssessmentTranslation(assessmentSeq, locale string) (*models.AssessmentTranslation, error) {
+	var translation models.AssessmentTranslation
+	err := r.db.Where("assessment_sequence = ? AND locale = ?", assessmentSeq, locale).First(&translation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &translation, err
+}
+
+func (r *TranslationRepositoryImpl) GetAssessmentTranslations(assessmentSeq string) ([]models.AssessmentTranslation, error) {
+	var list []models.AssessmentTranslation
+	err := r.db.Where("assessment_sequence = ?", assessmentSeq).Order("locale").Find(&list).Error
+	return list, err
+}
+
+func (r *TranslationRepositoryImpl) GetAssessmentContentTranslations(assessmentSeq, locale string) (map[int64]string, error) {
+	var rows []models.ContentTranslation
+	err := r.db.Raw(`
+	SELECT ct.content_id, ct.locale, ct.value
+	FROM content_translation ct
+	WHERE ct.locale = @locale AND ct.content_id IN (`+assessmentContentQuery+`)`,
+		map[string]interface{}{"seq": assessmentSeq, "locale": locale}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		translations[row.ContentID] = row.Value
+	}
+	return translations, nil
+}
+
+func (r *TranslationRepositoryImpl) GetAllAssessmentContentTranslations(assessmentSeq string) (map[int64]map[string]string, error) {
+	var rows []models.ContentTranslation
+	err := r.db.Raw(`
+	SELECT ct.content_id, ct.locale, ct.value
+	FROM content_translation ct
+	WHERE ct.content_id IN (`+assessmentContentQuery+`)`,
+		map[string]interface{}{"seq": assessmentSeq}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[int64]map[string]string)
+	for _, row := range rows {
+		if translations[row.ContentID] == nil {
+			translations[row.ContentID] = map[string]string{}
+		}
+		translations[row.ContentID][row.Locale] = row.Value
+	}
+	return translations, nil
+}
+
+// GetAssessmentLocales lists the locales that have an assessment translation or at least one
+// translated question or option.
+func (r *TranslationRepositoryImpl) GetAssessmentLocales(assessmentSeq string) ([]string, error) {
+	var locales []string
+	err := r.db.Raw(`
+	SELECT locale FROM assessment_translation WHERE assessment_sequence = @seq
+	UNION
+	SELECT ct.locale FROM content_translation ct WHERE ct.content_id IN (`+assessmentContentQuery+`)
+	ORDER BY locale`,
+		map[string]interface{}{"seq": assessmentSeq}).Scan(&locales).Error
+	return locales, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"dhl/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserNotificationRepository interface {
+	Create(tx *gorm.DB, notifications []models.UserNotification) error
+	List(userID string, unreadOnly bool, limit, offset int) ([]models.UserNotification, int64, error)
+	CountUnread(userID string) (int64, error)
+	MarkRead(userID string, ids []int64, now time.Time) (int64, error)
+	MarkAllRead(userID string, now time.Time) (int64, error)
+}
+
+type UserNotificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserNotificationRepository(db *gorm.DB) UserNotificationRepository {
+	return &UserNotificationRepositoryImpl{db: db}
+}
+
+func (r *UserNotificationRepositoryImpl) Create(tx *gorm.DB, notifications []models.UserNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&notifications, 500).Error
+}
+
+func (r *UserNotificationRepositoryImpl) List(userID string, unreadOnly bool, limit, offset int) ([]models.UserNotification, int64, error) {
+	query := r.db.Model(&models.UserNotification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = false")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []models.UserNotification
+	err := query.Order("created_on DESC, id DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func (r *UserNotificationRepositoryImpl) CountUnread(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserNotification{}).
+		Where("user_id = ? AND is_read = false", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks the user's own notifications among ids as read.
+func (r *UserNotificationRepositoryImpl) MarkRead(userID string, ids []int64, now time.Time) (int64, error) {
+	res := r.db.Model(&models.UserNotification{}).
+		Where("user_id = ? AND id IN ? AND is_read = false", userID, ids).
+		Updates(map[string]interface{}{"is_read": true, "read_on": now})
+	return res.RowsAffected, res.Error
+}
+
+func (r *UserNotificationRepositoryImpl) MarkAllRead(userID string, now time.Time) (int64, error) {
+	res := r.db.Model(&models.UserNotification{}).
+		Where("user_id = ? AND is_read = false", userID).
+		Updates(map[string]interface{}{"is_read": true, "read_on": now})
+	return res.RowsAffected, res.Error
+}
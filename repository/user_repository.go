@@ -15,6 +15,7 @@ import (
 type UserRepository interface {
 	CreateUser(tx *gorm.DB, ctx context.Context, user *models.AssessmentUser, roles []string) error
 	FindUserIdBySub(sub string) (string, error)
+	FindUserIdByEmail(email string) (string, error)
 	FindByUsername(username string) (models.UserWithRoles, error)
 	FindByUserId(userID string) (models.AssessmentUser, error)
 	UpdateUserProfile(userID uuid.UUID, data models.UserProfileUpdate) error
@@ -132,6 +133,17 @@ func (r *UserRepositoryImpl) FindUserIdBySub(sub string) (string, error) {
 	return userId, err
 }
 
+// FindUserIdByEmail returns the id of the active user with the email, or "" when there is none.
+func (r *UserRepositoryImpl) FindUserIdByEmail(email string) (string, error) {
+	var userId string
+	err := r.db.Model(&models.AssessmentUser{}).
+		Select("user_id").
+		Where("LOWER(email) = LOWER(?) AND is_active = true", email).
+		Limit(1).
+		Scan(&userId).Error
+	return userId, err
+}
+
 func (r *UserRepositoryImpl) UpdateUser(userID string, updates map[string]interface{}) error {
 	if len(updates) == 0 {
 		return nil
@@ -170,6 +182,9 @@ func (r *UserRepositoryImpl) UpdateUserProfile(userID uuid.UUID, data models.Use
 	if data.NotifyId != nil {
 		userUpdates["notify_id"] = *data.NotifyId
 	}
+	if data.Locale != nil {
+		userUpdates["locale"] = *data.Locale
+	}
 	if data.UserType != nil {
 		userUpdates["user_type"] = *data.UserType
 	}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	ListEndpoints() ([]models.WebhookEndpoint, error)
+	GetEndpoint(id int64) (*models.WebhookEndpoint, error)
+	GetEndpoints(tx *gorm.DB, ids []int64) ([]models.WebhookEndpoint, error)
+	GetSubscribers(tx *gorm.DB, event string) ([]models.WebhookEndpoint, error)
+	SaveEndpoint(tx *gorm.DB, endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(id int64) error
+
+	CreateDeliveries(tx *gorm.DB, deliveries []models.WebhookDelivery) error
+	ClaimDeliveries(tx *gorm.DB, now, claimedUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	SaveDeliveryAttempt(tx *gorm.DB, delivery *models.WebhookDelivery) error
+	ListDeliveries(filter models.WebhookDeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error)
+
+	GetSessionScore(tx *gorm.DB, sessionID string) (*models.SessionScore, error)
+}
+
+type WebhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+func (r *WebhookRepositoryImpl) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// GetEndpoint returns the endpoint, or nil when there is none.
+func (r *WebhookRepositoryImpl) GetEndpoint(id int64) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.First(&endpoint, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepositoryImpl) GetEndpoints(tx *gorm.DB, ids []int64) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if len(ids) == 0 {
+		return endpoints, nil
+	}
+	err := tx.Where("id IN ?", ids).Find(&endpoints).Error
+	return endpoints, err
+}
+
+// GetSubscribers returns the active endpoints subscribed to the event.
+func (r *WebhookRepositoryImpl) GetSubscribers(tx *gorm.DB, event string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := tx.Where("is_active = true AND ? = ANY(events)", event).Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *WebhookRepositoryImpl) SaveEndpoint(tx *gorm.DB, endpoint *models.WebhookEndpoint) error {
+	return tx.Save(endpoint).Error
+}
+
+func (r *WebhookRepositoryImpl) DeleteEndpoint(id int64) error {
+	res := r.db.Delete(&models.WebhookEndpoint{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryImpl) CreateDeliveries(tx *gorm.DB, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&deliveries, 500).Error
+}
+
+// ClaimDeliveries marks up to limit deliveries due for an attempt as sending until
+// claimedUntil, oldest first, counts the attempt and returns them. Rows another dispatcher is
+// claiming are skipped; sending ones whose claim ran out are due again.
+func (r *WebhookRepositoryImpl) ClaimDeliveries(tx *gorm.DB, now, claimedUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := tx.Raw(`
+		UPDATE webhook_delivery
+		SET status = ?, attempts = attempts + 1, next_attempt_on = ?
+		WHERE id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status IN ? AND next_attempt_on <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, constant.OutboxSending, claimedUntil, []string{constant.OutboxPending, constant.OutboxSending}, now, limit).
+		Scan(&deliveries).Error
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, err
+}
+
+func (r *WebhookRepositoryImpl) SaveDeliveryAttempt(tx *gorm.DB, delivery *models.WebhookDelivery) error {
+	if delivery.ID == 0 {
+		return tx.Create(delivery).Error
+	}
+	return tx.Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_on": delivery.NextAttemptOn,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"delivered_on":    delivery.DeliveredOn,
+		}).Error
+}
+
+func (r *WebhookRepositoryImpl) ListDeliveries(filter models.WebhookDeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	query := r.db.Model(&models.WebhookDelivery{})
+	if filter.EndpointID != 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// GetSessionScore sums the points of a session's answers, with the same rule the certificate
+// uses.
+func (r *WebhookRepositoryImpl) GetSessionScore(tx *gorm.DB, sessionID string) (*models.SessionScore, error) {
+	var score models.SessionScore
+	err := tx.Raw(`
+		SELECT COALESCE(SUM(ar.point_assigned), 0) AS marks_obtained,
+			am.marks AS total_marks, am.passing_score
+		FROM assessment_user_session s
+		JOIN assessment_mst am ON am.assessment_sequence = s.assessment_id
+		LEFT JOIN assessment_result ar ON ar.assessment_session_id = s.session_id::text
+			AND ar.assessment_sequence = s.assessment_id
+			AND ar.is_deleted = false
+		WHERE s.session_id::text = ?
+		GROUP BY am.marks, am.passing_score
+	`, sessionID).Scan(&score).Error
+	return &score, err
+}
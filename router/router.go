@@ -1,17 +1,22 @@
 package router
 
 import (
+	"context"
+	"dhl/config"
 	"dhl/constant"
 	"dhl/controller"
 	"dhl/repository"
 	"dhl/services"
+	"dhl/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func InitializeRoutes(apiGroup *gin.RouterGroup, db *gorm.DB) {
+// InitializeRoutes wires the services and routes. The background scheduler, when enabled, runs
+// until ctx is cancelled; the returned scheduler is waited on at shutdown.
+func InitializeRoutes(ctx context.Context, apiGroup *gin.RouterGroup, db *gorm.DB) *services.Scheduler {
 	var userRepo = repository.NewUserRepository(db)
 	var clientRepo = repository.NewClientRepository(db)
 	var assessmentRepo = repository.NewAssessmentRepository(db)
@@ -29,13 +34,40 @@ func InitializeRoutes(apiGroup *gin.RouterGroup, db *gorm.DB) {
 	var jobRepo = repository.NewJobDescriptionRepository(db)
     var jobService = services.NewJobDescriptionService(jobRepo, db)
 	var questionRepo = repository.NewQuestionRepository(db)
-    var questionService = services.NewQuestionService(questionRepo, db, assessmentRepo)
+	var duplicateService = services.NewDuplicateService(questionRepo)
+    var questionService = services.NewQuestionService(questionRepo, db, assessmentRepo, duplicateService)
+	var questionEditService = services.NewQuestionEditService(repository.NewQuestionEditRepository(db), assessmentRepo, db)
+	var tagService = services.NewTagService(repository.NewTagRepository(db), db)
+	var scheduler = services.NewScheduler(db)
+	var webhookService = services.NewWebhookService(repository.NewWebhookRepository(db), db)
+	scheduler.Register(webhookService.Task())
+	var scheduleService = services.NewAssessmentScheduleService(repository.NewAssessmentScheduleRepository(db), webhookService, scheduler)
+	scheduler.Register(scheduleService.Task())
 
 
 	var userService = services.NewUserService(userRepo, clientRepo, db)
-	var assessmentService = services.NewAssessmentService(assessmentRepo, db)
+	var translationRepo = repository.NewTranslationRepository(db)
+	var outboxRepo = repository.NewNotificationOutboxRepository(db)
+	var notificationTemplateService = services.NewNotificationTemplateService(repository.NewNotificationTemplateRepository(db), db)
+	var inboxRepo = repository.NewUserNotificationRepository(db)
+	var preferenceService = services.NewNotificationPreferenceService(repository.NewNotificationPreferenceRepository(db), db)
+	var notificationService = services.NewNotificationService(userRepo, assessmentRepo, outboxRepo, inboxRepo, notificationTemplateService, preferenceService, services.NewNotificationChannels())
+	var inboxService = services.NewInboxService(inboxRepo, userRepo, notificationTemplateService)
+	var notificationDispatcher = services.NewNotificationDispatcher(outboxRepo, notificationService)
+	scheduler.Register(notificationDispatcher.Task())
+	var releaseService = services.NewResultReleaseService(repository.NewResultReleaseRepository(db), assessmentRepo, notificationService, webhookService, db)
+	scheduler.Register(releaseService.Task())
+	var assessmentService = services.NewAssessmentService(assessmentRepo, translationRepo, duplicateService, notificationService, webhookService, releaseService, scheduleService, db)
+	var translationService = services.NewTranslationService(translationRepo, assessmentRepo, db)
+	var blueprintRepo = repository.NewBlueprintRepository(db)
+	var blueprintService = services.NewBlueprintService(blueprintRepo, assessmentRepo, questionRepo, db)
+	var templateService = services.NewAssessmentTemplateService(repository.NewAssessmentTemplateRepository(db), assessmentRepo, blueprintRepo, questionRepo, dhlCenterRepository, db)
 	var geminiService = services.NewGeminiService()
-	var contactService = services.NewContactService(contactRepo)
+	var jobAssessmentService = services.NewJobAssessmentService(jobRepo, assessmentRepo, questionRepo, geminiService, db)
+	var mediaRepo = repository.NewMediaRepository(db)
+	var mediaService = services.NewMediaService(mediaRepo, questionRepo, utils.NewMediaStorage(), db)
+	var assessmentTransferService = services.NewAssessmentTransferService(assessmentRepo, jobRepo, mediaService, translationRepo, duplicateService, db)
+	var contactService = services.NewContactService(contactRepo, userRepo, notificationService, db)
 	var dhlBusinessPartnerService = services.NewDHLBusinessPartnerService(dhlBusinessPartnerRepository)
 	var dhlCenterService = services.NewDHLCenterService(dhlCenterRepository)
 	var dhlResCompanyService = services.NewDHLResCompanyService(dhlResCompanyRepository)
@@ -46,12 +78,21 @@ func InitializeRoutes(apiGroup *gin.RouterGroup, db *gorm.DB) {
 	var dhlServiceLineService = services.NewDHLServiceLineService(dhlServiceLineRepository)
 	var dhlSubBusinessPartnerService = services.NewDHLSubBusinessPartnerService(dhlSubBusinessPartnerRepository)
 	var dhlSubServiceService = services.NewDHLSubServiceService(dhlSubServiceRepository)
-	var notificationService = services.NewNotificationService(userRepo, assessmentRepo)
 	var authService = services.NewAuthService(userRepo, clientRepo, notificationService, db)
+	var recertService = services.NewRecertificationService(repository.NewRecertificationRepository(db), assessmentRepo, notificationService, webhookService, db)
+	scheduler.Register(recertService.Task())
+	var audienceService = services.NewAudienceService(repository.NewAudienceRepository(db), assessmentRepo, notificationService, webhookService, db)
+	scheduler.Register(audienceService.Task())
+	var deadlineService = services.NewAssessmentDeadlineService(repository.NewAssessmentDeadlineRepository(db), db)
+	var delegationService = services.NewAssessmentDelegationService(repository.NewAssessmentDelegationRepository(db), assessmentRepo, notificationService, webhookService, db)
+	var reminderService = services.NewReminderService(repository.NewReminderRepository(db), assessmentRepo, notificationService, db)
+	scheduler.Register(reminderService.Task())
+	var digestService = services.NewDigestService(repository.NewDigestRepository(db), userRepo, notificationService, notificationTemplateService, db)
+	scheduler.Register(digestService.Task())
 
 	var userController = controller.NewUserController(userService, authService)
-	var adminController = controller.NewAdminController(userService, authService, assessmentService, notificationService, contactService,jobService, questionService)
-	var assessmentController = controller.NewAssessmentController(assessmentService, userService, geminiService)
+	var adminController = controller.NewAdminController(userService, authService, assessmentService, notificationService, contactService,jobService, questionService, duplicateService, questionEditService, tagService, scheduleService, recertService, audienceService, deadlineService, delegationService, reminderService, notificationDispatcher, notificationTemplateService, webhookService, digestService, preferenceService, releaseService)
+	var assessmentController = controller.NewAssessmentController(assessmentService, userService, geminiService, jobAssessmentService, assessmentTransferService, mediaService, translationService, blueprintService, templateService, deadlineService, inboxService, preferenceService)
 	var publicController = controller.NewPublicController(contactService)
 	var mastersController = controller.NewMastersController(dhlBusinessPartnerService, dhlCenterService, dhlResCompanyService,
 		dhlResPartnerIndustryService, dhlServiceService, dhlServiceGroupService, dhlServiceLineService, dhlSubBusinessPartnerService, dhlSubServiceService)
@@ -61,6 +102,11 @@ func InitializeRoutes(apiGroup *gin.RouterGroup, db *gorm.DB) {
 	QuestionAuthorRoutes(apiGroup, adminController, assessmentController, mastersController)
 	AssessmentRoutes(apiGroup, assessmentController)
 	OpenRoutes(apiGroup, publicController, adminController)
+
+	if config.PropConfig.Scheduler.Enabled {
+		scheduler.Start(ctx)
+	}
+	return scheduler
 }
 
 func getAdminRoutes(adminController *controller.AdminController, assessmentController *controller.AssessmentController, mastersController *controller.MastersController,) Routes {
@@ -71,8 +117,28 @@ func getAdminRoutes(adminController *controller.AdminController, assessmentContr
 		Route{"Admin", http.MethodPost, constant.Users, adminController.GetUsers},
 		Route{"Admin", http.MethodPost, constant.UpdateUser, adminController.UpdateUserProfile},
 		Route{"Admin", http.MethodPost, constant.ImportAssessment, assessmentController.UploadAssessment},
+		Route{"Admin", http.MethodPost, constant.ValidateImportAssessment, assessmentController.ValidateAssessmentUpload},
+		Route{"Admin", http.MethodGet, constant.ImportAssessmentTemplate, assessmentController.DownloadImportTemplate},
+		Route{"Admin", http.MethodGet, constant.ExportAssessment, assessmentController.ExportAssessment},
+		Route{"Admin", http.MethodPost, constant.MediaUpload, assessmentController.UploadMedia},
+		Route{"Admin", http.MethodGet, constant.Media, assessmentController.GetMedia},
+		Route{"Admin", http.MethodPut, constant.QuestionContent, assessmentController.UpdateQuestionContent},
+		Route{"Admin", http.MethodPost, constant.AssessmentTranslation, assessmentController.SaveTranslations},
+		Route{"Admin", http.MethodGet, constant.AssessmentLocales, assessmentController.GetAssessmentLocales},
+		Route{"Admin", http.MethodPost, constant.Blueprint, assessmentController.SaveBlueprint},
+		Route{"Admin", http.MethodGet, constant.Blueprints, assessmentController.GetBlueprints},
+		Route{"Admin", http.MethodGet, constant.Blueprint + "/:id", assessmentController.GetBlueprint},
+		Route{"Admin", http.MethodDelete, constant.Blueprint + "/:id", assessmentController.DeleteBlueprint},
+		Route{"Admin", http.MethodPost, constant.AssembleBlueprint, assessmentController.AssembleBlueprint},
+		Route{"Admin", http.MethodPost, constant.AssessmentTemplate, assessmentController.SaveAssessmentTemplate},
+		Route{"Admin", http.MethodGet, constant.AssessmentTemplates, assessmentController.GetAssessmentTemplates},
+		Route{"Admin", http.MethodGet, constant.AssessmentTemplate + "/:id", assessmentController.GetAssessmentTemplate},
+		Route{"Admin", http.MethodDelete, constant.AssessmentTemplate + "/:id", assessmentController.DeleteAssessmentTemplate},
+		Route{"Admin", http.MethodPost, constant.AssessmentFromTemplate, assessmentController.CreateAssessmentFromTemplate},
+		Route{"Admin", http.MethodPost, constant.AssessmentCloneCenters, assessmentController.CloneAssessmentToCenters},
 		Route{"Admin", http.MethodPost, constant.GenerateAssessment, assessmentController.GenerateAssessmentWithAI},
 		Route{"Admin", http.MethodPost, constant.SaveGeneratedAssessment, assessmentController.SaveGeneratedAssessment},
+		Route{"Admin", http.MethodPost, constant.GenerateJobAssessment, assessmentController.GenerateAssessmentFromJob},
 		Route{"Admin", http.MethodPost, constant.Assessment, assessmentController.CreateAssessment},
 		Route{"Admin", http.MethodPost, constant.AssessmentDuplicate, assessmentController.DuplicateAssessment},
 		Route{"Admin", http.MethodPut, constant.AssessmentStatus, adminController.UpdateAssessmentStatusController},
@@ -88,6 +154,62 @@ func getAdminRoutes(adminController *controller.AdminController, assessmentContr
         Route{"Admin", http.MethodDelete, constant.JobDescription + "/:id", adminController.DeleteJobDescription},
         Route{"Admin", http.MethodPost, constant.JobDescriptions, adminController.GetJobDescriptions},
 		Route{"Admin", http.MethodPost, constant.Question, adminController.CreateMultipleQuestions},
+		Route{"Admin", http.MethodGet, constant.QuestionDuplicates, adminController.GetQuestionDuplicates},
+		Route{"Admin", http.MethodPost, constant.QuestionDuplicatesCheck, adminController.CheckQuestionDuplicates},
+		Route{"Admin", http.MethodPost, constant.QuestionBulkRetag, adminController.BulkRetagQuestions},
+		Route{"Admin", http.MethodPost, constant.QuestionBulkDifficulty, adminController.BulkSetQuestionDifficulty},
+		Route{"Admin", http.MethodPost, constant.QuestionBulkPoints, adminController.BulkSetQuestionPoints},
+		Route{"Admin", http.MethodPost, constant.QuestionBulkStatus, adminController.BulkSetQuestionStatus},
+		Route{"Admin", http.MethodPost, constant.QuestionBulkFindReplace, adminController.BulkFindReplaceQuestions},
+		Route{"Admin", http.MethodGet, constant.QuestionEditHistory, adminController.GetQuestionEditHistory},
+		Route{"Admin", http.MethodGet, constant.Tags, adminController.GetTagTree},
+		Route{"Admin", http.MethodGet, constant.TagUsage + "/:id", adminController.GetTagUsage},
+		Route{"Admin", http.MethodPost, constant.Tag, adminController.CreateTag},
+		Route{"Admin", http.MethodPut, constant.Tag + "/:id", adminController.RenameTag},
+		Route{"Admin", http.MethodPost, constant.TagMerge, adminController.MergeTags},
+		Route{"Admin", http.MethodPut, constant.TagMove + "/:id", adminController.MoveTag},
+		Route{"Admin", http.MethodDelete, constant.Tag + "/:id", adminController.DeleteTag},
+		Route{"Admin", http.MethodGet, constant.AssessmentSchedule, adminController.GetAssessmentSchedule},
+		Route{"Admin", http.MethodPut, constant.AssessmentRecurrence, adminController.SaveAssessmentRecurrence},
+		Route{"Admin", http.MethodGet, constant.AssessmentRecurrence + "/:id", adminController.GetAssessmentRecurrence},
+		Route{"Admin", http.MethodDelete, constant.AssessmentRecurrence + "/:id", adminController.DeleteAssessmentRecurrence},
+		Route{"Admin", http.MethodGet, constant.AssessmentCertifications, adminController.GetCertifications},
+		Route{"Admin", http.MethodPost, constant.AudienceRule, adminController.SaveAudienceRule},
+		Route{"Admin", http.MethodGet, constant.AudienceRules, adminController.GetAudienceRules},
+		Route{"Admin", http.MethodGet, constant.AudienceRule + "/:id", adminController.GetAudienceRule},
+		Route{"Admin", http.MethodDelete, constant.AudienceRule + "/:id", adminController.DeleteAudienceRule},
+		Route{"Admin", http.MethodPost, constant.AudiencePreview, adminController.PreviewAudience},
+		Route{"Admin", http.MethodPost, constant.AssessmentAudience, adminController.AssignAssessmentAudience},
+		Route{"Admin", http.MethodGet, constant.AssessmentAudience, adminController.GetAssessmentAudiences},
+		Route{"Admin", http.MethodDelete, constant.AssessmentAudience + "/:id", adminController.RemoveAssessmentAudience},
+		Route{"Admin", http.MethodPut, constant.AssessmentDueDate, adminController.SetAssessmentDueDate},
+		Route{"Admin", http.MethodGet, constant.AssessmentExtensions, adminController.GetExtensionRequests},
+		Route{"Admin", http.MethodPut, constant.AssessmentExtension + "/:id", adminController.ReviewExtensionRequest},
+		Route{"Admin", http.MethodGet, constant.AssessmentTeamProgress, adminController.GetTeamProgress},
+		Route{"Admin", http.MethodGet, constant.AssessmentDelegations, adminController.GetDelegations},
+		Route{"Admin", http.MethodPut, constant.AssessmentReminderPolicy, adminController.SaveReminderPolicy},
+		Route{"Admin", http.MethodGet, constant.AssessmentReminderPolicy + "/:id", adminController.GetReminderPolicy},
+		Route{"Admin", http.MethodDelete, constant.AssessmentReminderPolicy + "/:id", adminController.DeleteReminderPolicy},
+		Route{"Admin", http.MethodGet, constant.AssessmentReminders, adminController.GetReminders},
+		Route{"Admin", http.MethodPut, constant.AssessmentResultRelease, adminController.SaveResultReleasePolicy},
+		Route{"Admin", http.MethodGet, constant.AssessmentResultRelease + "/:id", adminController.GetResultReleasePolicy},
+		Route{"Admin", http.MethodPost, constant.AssessmentResultRelease + "/:id/release", adminController.ReleaseResults},
+		Route{"Admin", http.MethodGet, constant.NotificationOutbox, adminController.GetNotificationOutbox},
+		Route{"Admin", http.MethodPost, constant.NotificationOutboxReplay, adminController.ReplayNotificationOutbox},
+		Route{"Admin", http.MethodGet, constant.NotificationTemplates, adminController.GetNotificationTemplates},
+		Route{"Admin", http.MethodPut, constant.NotificationTemplate, adminController.SaveNotificationTemplate},
+		Route{"Admin", http.MethodDelete, constant.NotificationTemplate + "/:id", adminController.DeleteNotificationTemplate},
+		Route{"Admin", http.MethodPost, constant.NotificationTemplatePreview, adminController.PreviewNotificationTemplate},
+		Route{"Admin", http.MethodGet, constant.NotificationSuppressions, adminController.GetNotificationSuppressions},
+		Route{"Admin", http.MethodGet, constant.Webhooks, adminController.GetWebhooks},
+		Route{"Admin", http.MethodPost, constant.Webhook, adminController.CreateWebhook},
+		Route{"Admin", http.MethodPut, constant.Webhook + "/:id", adminController.UpdateWebhook},
+		Route{"Admin", http.MethodDelete, constant.Webhook + "/:id", adminController.DeleteWebhook},
+		Route{"Admin", http.MethodPost, constant.Webhook + "/:id/ping", adminController.PingWebhook},
+		Route{"Admin", http.MethodGet, constant.WebhookDeliveries, adminController.GetWebhookDeliveries},
+		Route{"Admin", http.MethodGet, constant.DigestSubscription, adminController.GetDigestSubscription},
+		Route{"Admin", http.MethodPut, constant.DigestSubscription, adminController.SaveDigestSubscription},
+		Route{"Admin", http.MethodGet, constant.DigestPreview, adminController.PreviewDigest},
 		Route{"Admin", http.MethodPost, constant.AssessmentUserResult, adminController.GetAssessmentUserResult},
 		Route{"Admin", http.MethodPost, constant.CheckAssessmentAssignment, adminController.CheckAssessmentAssignment},
 		Route{"Admin", http.MethodDelete, constant.DeleteAssessment, assessmentController.DeleteAssessment},
@@ -96,6 +218,7 @@ func getAdminRoutes(adminController *controller.AdminController, assessmentContr
 
 
 		Route{"Contact Form", http.MethodPost, constant.ContactResponse, adminController.ListContactRespController},
+		Route{"Contact Form", http.MethodPost, constant.ContactReply, adminController.ReplyContactResponse},
 		// Master Routes
 		Route{"CreateDHLBusinessPartner", http.MethodPost, "/business-partner", mastersController.CreateDHLBusinessPartner},
 		Route{"ListDHLBusinessPartners", http.MethodGet, "/business-partner", mastersController.ListDHLBusinessPartners},
@@ -149,8 +272,30 @@ func getQuestionAuthorRoutes(adminController *controller.AdminController, assess
 		Route{"Question Author", http.MethodPost, constant.Assessments, adminController.GetAssessments},
 		Route{"Question Author", http.MethodPost, constant.Users, adminController.GetUsers},
 		Route{"Question Author", http.MethodPost, constant.ImportAssessment, assessmentController.UploadAssessment},
+		Route{"Question Author", http.MethodPost, constant.ValidateImportAssessment, assessmentController.ValidateAssessmentUpload},
+		Route{"Question Author", http.MethodGet, constant.ImportAssessmentTemplate, assessmentController.DownloadImportTemplate},
+		Route{"Question Author", http.MethodGet, constant.ExportAssessment, assessmentController.ExportAssessment},
+		Route{"Question Author", http.MethodPost, constant.MediaUpload, assessmentController.UploadMedia},
+		Route{"Question Author", http.MethodGet, constant.Media, assessmentController.GetMedia},
+		Route{"Question Author", http.MethodPut, constant.QuestionContent, assessmentController.UpdateQuestionContent},
+		Route{"Question Author", http.MethodPost, constant.AssessmentTranslation, assessmentController.SaveTranslations},
+		Route{"Question Author", http.MethodGet, constant.AssessmentLocales, assessmentController.GetAssessmentLocales},
+		Route{"Question Author", http.MethodPost, constant.Blueprint, assessmentController.SaveBlueprint},
+		Route{"Question Author", http.MethodGet, constant.Blueprints, assessmentController.GetBlueprints},
+		Route{"Question Author", http.MethodGet, constant.Blueprint + "/:id", assessmentController.GetBlueprint},
+		Route{"Question Author", http.MethodDelete, constant.Blueprint + "/:id", assessmentController.DeleteBlueprint},
+		Route{"Question Author", http.MethodPost, constant.AssembleBlueprint, assessmentController.AssembleBlueprint},
+		Route{"Question Author", http.MethodGet, constant.Tags, adminController.GetTagTree},
+		Route{"Question Author", http.MethodPost, constant.AssessmentTemplate, assessmentController.SaveAssessmentTemplate},
+		Route{"Question Author", http.MethodGet, constant.AssessmentTemplates, assessmentController.GetAssessmentTemplates},
+		Route{"Question Author", http.MethodGet, constant.AssessmentTemplate + "/:id", assessmentController.GetAssessmentTemplate},
+		Route{"Question Author", http.MethodDelete, constant.AssessmentTemplate + "/:id", assessmentController.DeleteAssessmentTemplate},
+		Route{"Question Author", http.MethodPost, constant.AssessmentFromTemplate, assessmentController.CreateAssessmentFromTemplate},
+		Route{"Question Author", http.MethodPost, constant.AssessmentCloneCenters, assessmentController.CloneAssessmentToCenters},
+		Route{"Question Author", http.MethodPost, constant.QuestionDuplicatesCheck, adminController.CheckQuestionDuplicates},
 		Route{"Question Author", http.MethodPost, constant.GenerateAssessment, assessmentController.GenerateAssessmentWithAI},
 		Route{"Question Author", http.MethodPost, constant.SaveGeneratedAssessment, assessmentController.SaveGeneratedAssessment},
+		Route{"Question Author", http.MethodPost, constant.GenerateJobAssessment, assessmentController.GenerateAssessmentFromJob},
 		Route{"Question Author", http.MethodPost, constant.Assessment, assessmentController.CreateAssessment},
 		Route{"Question Author", http.MethodPost, constant.AssessmentDuplicate, assessmentController.DuplicateAssessment},
 		Route{"Question Author", http.MethodPut, constant.Assessment, adminController.UpdateAssessment},
@@ -177,12 +322,23 @@ func getAssessmentRoutes(assessmentController *controller.AssessmentController)
 		Route{"Assessment", http.MethodPost, constant.Assessments, assessmentController.GetUserAssessments},
 		Route{"Assessment", http.MethodPost, constant.Certificate, assessmentController.GetUserAssessmentCerficiate},
 		Route{"Assessment", http.MethodPost, constant.SessionImage, assessmentController.CreateSessionImage},
+		Route{"Assessment", http.MethodGet, constant.Media, assessmentController.GetMedia},
+		Route{"Assessment", http.MethodGet, constant.AssessmentLocales, assessmentController.GetAssessmentLocales},
 		
 
 		Route{"Assessment", http.MethodPost, constant.AssessmentVerificationPhoto, assessmentController.UploadPhoto},
 		Route{"Assessment", http.MethodPost, constant.AssessmentVerificationVoice, assessmentController.UploadVoice},
 		Route{"Assessment", http.MethodPost, constant.AssessmentStart, assessmentController.StartAssessment},
 		Route{"Assessment", http.MethodPost, constant.AssessmentResultView, assessmentController.GetUserAssessmentResult},
+		Route{"Assessment", http.MethodPost, constant.AssessmentExtension, assessmentController.FileExtensionRequest},
+		Route{"Assessment", http.MethodGet, constant.AssessmentExtensions, assessmentController.GetMyExtensionRequests},
+		Route{"Assessment", http.MethodGet, constant.Notifications, assessmentController.GetMyNotifications},
+		Route{"Assessment", http.MethodGet, constant.NotificationsUnread, assessmentController.GetUnreadNotificationCount},
+		Route{"Assessment", http.MethodPut, constant.NotificationsRead, assessmentController.MarkNotificationsRead},
+		Route{"Assessment", http.MethodPut, constant.NotificationsReadAll, assessmentController.MarkAllNotificationsRead},
+		Route{"Assessment", http.MethodGet, constant.NotificationsStream, assessmentController.StreamNotifications},
+		Route{"Assessment", http.MethodGet, constant.NotificationPreferences, assessmentController.GetNotificationPreferences},
+		Route{"Assessment", http.MethodPut, constant.NotificationPreferences, assessmentController.SaveNotificationPreferences},
 
 	}
 }
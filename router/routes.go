@@ -1,16 +1,22 @@
 package router
 
 import (
+	"context"
 	"dhl/auth"
 	"dhl/constant"
 	"dhl/controller"
 	"dhl/database"
 	"dhl/middleware"
 	"dhl/utils"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -93,6 +99,8 @@ func AssessmentRoutes(g *gin.RouterGroup, assessmentController *controller.Asses
 			assessment.POST(assessmentRoute.Path, protectedHandler)
 		case http.MethodGet:
 			assessment.GET(assessmentRoute.Path, protectedHandler)
+		case http.MethodPut:
+			assessment.PUT(assessmentRoute.Path, protectedHandler)
 		}
 	}
 }
@@ -114,7 +122,12 @@ func OpenRoutes(g *gin.RouterGroup, publicController *controller.PublicControlle
 	}
 }
 
+// Routing serves the API until the process gets SIGINT or SIGTERM, then stops accepting
+// requests, lets running ones finish and waits for the background scheduler to stop.
 func Routing(envFile string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	r := routes{
 		router: gin.Default(),
 	}
@@ -134,13 +147,29 @@ func Routing(envFile string) {
 	r.router.GET(constant.Version, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"version": utils.GetBuildVersion()}) })
 	apiGroup := r.router.Group(os.Getenv("ApiVersion"))
 	db := database.GetDBConn()
-	InitializeRoutes(apiGroup, db)
-	if envFile == "dev" {
-		r.router.Run(":" + os.Getenv("GO_SERVER_PORT"))
-	} else {
-		err := r.router.Run(":" + os.Getenv("GO_SERVER_PORT"))
-		if err != nil {
+	scheduler := InitializeRoutes(ctx, apiGroup, db)
+
+	// Requests are not cancelled at shutdown, so in-flight ones can finish; long-lived streams
+	// watch utils.Shutdown instead and end themselves, or Shutdown would wait out its timeout.
+	srv := &http.Server{
+		Addr:    ":" + os.Getenv("GO_SERVER_PORT"),
+		Handler: r.router,
+		BaseContext: func(net.Listener) context.Context {
+			return utils.WithShutdown(context.Background(), ctx.Done())
+		},
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal("Failed to start HTTPS server: ", err)
 		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown failed: ", err)
 	}
+	scheduler.Wait()
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AssessmentDeadlineService manages the due dates of individual assignments and the extension
+// requests users file against them.
+type AssessmentDeadlineService interface {
+	SetDueDates(ctx context.Context, req models.SetDueDateRequest, role, userId string) (int64, error)
+	FileExtension(ctx context.Context, req models.FileExtensionRequest, userId string) (*models.AssessmentExtensionRequest, error)
+	ListExtensions(filter models.ExtensionFilter, role, userId string, limit, offset int) ([]models.AssessmentExtensionRequest, int64, error)
+	ReviewExtension(ctx context.Context, extensionID int64, req models.ReviewExtensionRequest, role, userId string) (*models.AssessmentExtensionRequest, error)
+}
+
+type AssessmentDeadlineServiceImpl struct {
+	deadlineRepo repository.AssessmentDeadlineRepository
+	db           *gorm.DB
+}
+
+func NewAssessmentDeadlineService(deadlineRepo repository.AssessmentDeadlineRepository, db *gorm.DB) AssessmentDeadlineService {
+	return &AssessmentDeadlineServiceImpl{deadlineRepo: deadlineRepo, db: db}
+}
+
+// ErrExtensionPending is returned when the user already has a pending extension request for
+// the assessment.
+var ErrExtensionPending = errors.New("an extension request for this assessment is already pending")
+
+// checkManages refuses managers acting on users who do not report to them. Admins may act on
+// anyone.
+func (s *AssessmentDeadlineServiceImpl) checkManages(role, userId string, userIDs []string) error {
+	if role != string(constant.Manager) {
+		return nil
+	}
+	managed, err := s.deadlineRepo.CountManagedUsers(userId, userIDs)
+	if err != nil {
+		return err
+	}
+	if managed < int64(len(userIDs)) {
+		return errors.New("managers can only change due dates of their own users")
+	}
+	return nil
+}
+
+// SetDueDates sets the users' own due date, or clears it so the assessment deadline applies.
+// It returns how many assignments were changed.
+func (s *AssessmentDeadlineServiceImpl) SetDueDates(ctx context.Context, req models.SetDueDateRequest, role, userId string) (int64, error) {
+	if err := s.checkManages(role, userId, req.UserIDs); err != nil {
+		return 0, err
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	updated, err := s.deadlineRepo.SetDueDates(tx, req.AssessmentSequence, req.UserIDs, req.DueDate, userId)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if updated == 0 {
+		tx.Rollback()
+		return 0, errors.New("none of the users is assigned this assessment")
+	}
+	// Moving the date forward gives expired users their assignment back.
+	if req.DueDate != nil && req.DueDate.After(time.Now()) {
+		for _, uid := range req.UserIDs {
+			if err := s.deadlineRepo.ReopenExpired(tx, req.AssessmentSequence, uid, userId); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// FileExtension asks for a later due date on an unfinished assignment. A user has at most one
+// pending request per assessment.
+func (s *AssessmentDeadlineServiceImpl) FileExtension(ctx context.Context, req models.FileExtensionRequest, userId string) (*models.AssessmentExtensionRequest, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, errors.New("reason is required")
+	}
+	if !req.RequestedDueDate.After(time.Now()) {
+		return nil, errors.New("requested_due_date must be in the future")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	st, err := s.deadlineRepo.GetAssignment(tx, req.AssessmentSequence, userId)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("assessment is not assigned to you")
+		}
+		return nil, err
+	}
+	if st.AssessmentStatus == constant.AssignmentCompleted {
+		tx.Rollback()
+		return nil, errors.New("assessment is already completed")
+	}
+	current, err := s.deadlineRepo.GetDueDate(tx, req.AssessmentSequence, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if current != nil && !req.RequestedDueDate.After(*current) {
+		tx.Rollback()
+		return nil, fmt.Errorf("requested_due_date must be after the current due date %s", current.Format(time.RFC3339))
+	}
+	pending, err := s.deadlineRepo.HasPendingExtension(tx, req.AssessmentSequence, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if pending {
+		tx.Rollback()
+		return nil, ErrExtensionPending
+	}
+
+	ext := &models.AssessmentExtensionRequest{
+		AssessmentSequence: req.AssessmentSequence,
+		UserID:             userId,
+		CurrentDueDate:     current,
+		RequestedDueDate:   req.RequestedDueDate,
+		Reason:             strings.TrimSpace(req.Reason),
+		Status:             constant.ExtensionPending,
+		CreatedOn:          time.Now(),
+	}
+	if err := s.deadlineRepo.CreateExtension(tx, ext); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrExtensionPending
+		}
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
+
+// ListExtensions lists extension requests. Managers only see their own users' requests.
+func (s *AssessmentDeadlineServiceImpl) ListExtensions(filter models.ExtensionFilter, role, userId string, limit, offset int) ([]models.AssessmentExtensionRequest, int64, error) {
+	if filter.Status != "" && filter.Status != constant.ExtensionPending &&
+		filter.Status != constant.ExtensionApproved && filter.Status != constant.ExtensionRejected {
+		return nil, 0, fmt.Errorf("unknown status %q", filter.Status)
+	}
+	if role == string(constant.Manager) {
+		filter.ManagerID = userId
+	}
+	list, total, err := s.deadlineRepo.ListExtensions(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.AssessmentExtensionRequest{}
+	}
+	return list, total, nil
+}
+
+// ReviewExtension approves or rejects a pending request. An approval moves the user's due date
+// to the granted date, the requested one by default, and reopens an expired assignment.
+func (s *AssessmentDeadlineServiceImpl) ReviewExtension(ctx context.Context, extensionID int64, req models.ReviewExtensionRequest, role, userId string) (*models.AssessmentExtensionRequest, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	ext, err := s.deadlineRepo.GetExtension(tx, extensionID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("extension request %d not found: %w", extensionID, err)
+	}
+	if ext.Status != constant.ExtensionPending {
+		tx.Rollback()
+		return nil, fmt.Errorf("extension request is already %s", ext.Status)
+	}
+	if err := s.checkManages(role, userId, []string{ext.UserID}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	ext.Status = constant.ExtensionRejected
+	ext.ReviewComment = req.Comment
+	ext.ReviewedBy = &userId
+	ext.ReviewedOn = &now
+	if req.Approve {
+		granted := ext.RequestedDueDate
+		if req.DueDate != nil {
+			granted = *req.DueDate
+		}
+		if !granted.After(now) {
+			tx.Rollback()
+			return nil, errors.New("the granted due date must be in the future")
+		}
+		ext.Status = constant.ExtensionApproved
+		ext.GrantedDueDate = &granted
+
+		if _, err := s.deadlineRepo.SetDueDates(tx, ext.AssessmentSequence, []string{ext.UserID}, &granted, userId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := s.deadlineRepo.ReopenExpired(tx, ext.AssessmentSequence, ext.UserID, userId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := s.deadlineRepo.SaveExtension(tx, ext); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxDelegationLevel bounds how far a recursive cascade, and the team views, follow reporting
+// lines, which also keeps cycles in the hierarchy from running away.
+const maxDelegationLevel = 10
+
+// AssessmentDelegationService distributes assessments to managers, optionally cascading them
+// to the users under them, and tracks the resulting delegations.
+type AssessmentDelegationService interface {
+	DistributeToManagers(ctx context.Context, req models.DistributeAssessmentRequest, userId string) (*models.DelegationResult, error)
+	RecordManagerDistribution(ctx context.Context, assessmentSeq, managerID string, userIDs []string) error
+	ListDelegations(filter models.DelegationFilter, role, userId string, limit, offset int) ([]models.AssessmentDelegation, int64, error)
+	GetTeamProgress(managerID, assessmentSeq string, limit, offset int) (*models.TeamProgress, int64, error)
+}
+
+type AssessmentDelegationServiceImpl struct {
+	delegationRepo      repository.AssessmentDelegationRepository
+	assessmentRepo      repository.AssessmentRepository
+	notificationService NotificationService
+	webhookService      WebhookService
+	db                  *gorm.DB
+}
+
+func NewAssessmentDelegationService(delegationRepo repository.AssessmentDelegationRepository, assessmentRepo repository.AssessmentRepository, notificationService NotificationService, webhookService WebhookService, db *gorm.DB) AssessmentDelegationService {
+	return &AssessmentDelegationServiceImpl{
+		delegationRepo:      delegationRepo,
+		assessmentRepo:      assessmentRepo,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		db:                  db,
+	}
+}
+
+// DistributeToManagers makes the assessment available to the managers. With Cascade it is
+// also assigned to the users under them, level by level when Recursive.
+func (s *AssessmentDelegationServiceImpl) DistributeToManagers(ctx context.Context, req models.DistributeAssessmentRequest, userId string) (*models.DelegationResult, error) {
+	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	if asmt == nil {
+		return nil, errors.New("assessment not found")
+	}
+	hierarchy := req.Hierarchy
+	if hierarchy == "" {
+		hierarchy = constant.HierarchyMapping
+	}
+
+	now := time.Now()
+	result := &models.DelegationResult{Managers: len(req.UserIds), Assigned: []string{}}
+	tx := s.db.WithContext(ctx).Begin()
+
+	delegations := make([]models.AssessmentDelegation, 0, len(req.UserIds))
+	for _, managerID := range req.UserIds {
+		if _, err := s.assessmentRepo.AddManagerAssessmentMapping(tx, managerID, req.AssessmentSequence); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		delegations = append(delegations, models.AssessmentDelegation{
+			AssessmentSequence: req.AssessmentSequence,
+			DelegatedBy:        userId,
+			DelegatedTo:        managerID,
+			Level:              0,
+			Hierarchy:          hierarchy,
+			CreatedOn:          now,
+		})
+	}
+	if err := s.delegationRepo.RecordDelegations(tx, delegations); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if req.Cascade {
+		seen := make(map[string]bool)
+		for _, managerID := range req.UserIds {
+			seen[managerID] = true
+		}
+		managers := req.UserIds
+		for level := 1; len(managers) > 0 && level <= maxDelegationLevel; level++ {
+			subs, err := s.delegationRepo.GetSubordinates(tx, managers, hierarchy)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			var next []string
+			var levelDelegations []models.AssessmentDelegation
+			for _, sub := range subs {
+				if seen[sub.UserID] {
+					continue
+				}
+				seen[sub.UserID] = true
+				next = append(next, sub.UserID)
+				levelDelegations = append(levelDelegations, models.AssessmentDelegation{
+					AssessmentSequence: req.AssessmentSequence,
+					DelegatedBy:        sub.ManagerID,
+					DelegatedTo:        sub.UserID,
+					Level:              level,
+					Hierarchy:          hierarchy,
+					CreatedOn:          now,
+				})
+			}
+
+			assigned, err := s.delegationRepo.AssignUnassigned(tx, req.AssessmentSequence, next, req.DueDate, userId)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := s.delegationRepo.RecordDelegations(tx, levelDelegations); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			result.Assigned = append(result.Assigned, assigned...)
+			result.AlreadyAssigned += len(next) - len(assigned)
+
+			if !req.Recursive {
+				break
+			}
+			managers = next
+		}
+	}
+
+	if err := s.notificationService.QueueDistributeAssessmentMail(tx, req.UserIds, req.AssessmentSequence, true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := s.notificationService.QueueDistributeAssessmentMail(tx, result.Assigned, req.AssessmentSequence, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := s.webhookService.PublishAssignments(tx, req.AssessmentSequence, result.Assigned); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RecordManagerDistribution notes that a manager handed the assessment to their users by
+// hand, so it shows in their team view.
+func (s *AssessmentDelegationServiceImpl) RecordManagerDistribution(ctx context.Context, assessmentSeq, managerID string, userIDs []string) error {
+	now := time.Now()
+	delegations := make([]models.AssessmentDelegation, len(userIDs))
+	for i, uid := range userIDs {
+		delegations[i] = models.AssessmentDelegation{
+			AssessmentSequence: assessmentSeq,
+			DelegatedBy:        managerID,
+			DelegatedTo:        uid,
+			Level:              1,
+			Hierarchy:          constant.HierarchyMapping,
+			CreatedOn:          now,
+		}
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := s.delegationRepo.RecordDelegations(tx, delegations); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// ListDelegations lists who delegated what to whom. Managers only see their own delegations.
+func (s *AssessmentDelegationServiceImpl) ListDelegations(filter models.DelegationFilter, role, userId string, limit, offset int) ([]models.AssessmentDelegation, int64, error) {
+	if role == string(constant.Manager) {
+		filter.DelegatedBy = userId
+	}
+	list, total, err := s.delegationRepo.ListDelegations(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.AssessmentDelegation{}
+	}
+	return list, total, nil
+}
+
+// GetTeamProgress reports how the users below the manager are doing on the assessment.
+func (s *AssessmentDelegationServiceImpl) GetTeamProgress(managerID, assessmentSeq string, limit, offset int) (*models.TeamProgress, int64, error) {
+	summary, err := s.delegationRepo.GetTeamSummary(managerID, assessmentSeq, maxDelegationLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, total, err := s.delegationRepo.GetTeamProgress(managerID, assessmentSeq, maxDelegationLevel, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if users == nil {
+		users = []models.TeamAssignment{}
+	}
+	return &models.TeamProgress{Summary: *summary, Users: users}, total, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schedulerUser is recorded as the modifier of changes made by background jobs.
+const schedulerUser = "scheduler"
+
+// AssessmentScheduleService opens scheduled assessments at their start and closes them at
+// their deadline, expiring the assignments nobody finished.
+type AssessmentScheduleService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, tx *gorm.DB) error
+	GetOverview(horizon time.Duration, limit, offset int) (*models.ScheduleOverview, int64, error)
+	PublishState(tx *gorm.DB, assessmentSeq, state string) (string, error)
+	CheckStart(tx *gorm.DB, userID, assessmentSeq string) error
+}
+
+type AssessmentScheduleServiceImpl struct {
+	scheduleRepo   repository.AssessmentScheduleRepository
+	webhookService WebhookService
+	scheduler      *Scheduler
+}
+
+func NewAssessmentScheduleService(scheduleRepo repository.AssessmentScheduleRepository, webhookService WebhookService, scheduler *Scheduler) AssessmentScheduleService {
+	return &AssessmentScheduleServiceImpl{scheduleRepo: scheduleRepo, webhookService: webhookService, scheduler: scheduler}
+}
+
+func (s *AssessmentScheduleServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "assessment-schedule",
+		Interval: config.PropConfig.Scheduler.AssessmentInterval,
+		Run:      s.RunDue,
+	}
+}
+
+// RunDue closes before it opens, so an assessment whose whole window has passed while the
+// scheduler was down goes straight to closed.
+func (s *AssessmentScheduleServiceImpl) RunDue(ctx context.Context, tx *gorm.DB) error {
+	now := time.Now()
+
+	toClose, err := s.scheduleRepo.GetDueToClose(tx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find assessments to close: %w", err)
+	}
+	if err := s.scheduleRepo.SetState(tx, toClose, constant.Closed); err != nil {
+		return err
+	}
+	for _, seq := range toClose {
+		expired, err := s.scheduleRepo.ExpireAssignments(tx, seq, schedulerUser)
+		if err != nil {
+			return fmt.Errorf("failed to expire assignments of %s: %w", seq, err)
+		}
+		log.Printf("Closed assessment %s, %d unfinished assignments expired", seq, expired)
+	}
+	expired, err := s.scheduleRepo.ExpireOverdueAssignments(tx, now, schedulerUser)
+	if err != nil {
+		return fmt.Errorf("failed to expire overdue assignments: %w", err)
+	}
+	if expired > 0 {
+		log.Printf("%d assignments expired past their own due date", expired)
+	}
+
+	toOpen, err := s.scheduleRepo.GetDueToOpen(tx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find assessments to open: %w", err)
+	}
+	if err := s.scheduleRepo.SetState(tx, toOpen, constant.Open); err != nil {
+		return err
+	}
+	for _, seq := range toOpen {
+		if err := s.webhookService.Publish(tx, constant.EventAssessmentPublished, map[string]interface{}{
+			"assessment_sequence": seq,
+		}); err != nil {
+			return err
+		}
+		log.Printf("Opened scheduled assessment %s", seq)
+	}
+	return nil
+}
+
+// GetOverview lists the opens and closes due within horizon, together with the state of this
+// instance's scheduler tasks.
+func (s *AssessmentScheduleServiceImpl) GetOverview(horizon time.Duration, limit, offset int) (*models.ScheduleOverview, int64, error) {
+	now := time.Now()
+	jobs, total, err := s.scheduleRepo.GetUpcoming(now, now.Add(horizon), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if jobs == nil {
+		jobs = []models.ScheduledAssessmentJob{}
+	}
+	return &models.ScheduleOverview{
+		Enabled: config.PropConfig.Scheduler.Enabled,
+		Tasks:   s.scheduler.Status(),
+		Jobs:    jobs,
+	}, total, nil
+}
+
+// PublishState returns the state an assessment is stored in when set to state: opening one whose
+// fixed schedule starts later schedules it instead, for the scheduler to open on time. An
+// assessment that opens now, and was not open before, is published to webhooks.
+func (s *AssessmentScheduleServiceImpl) PublishState(tx *gorm.DB, assessmentSeq, state string) (string, error) {
+	if state != string(constant.Open) {
+		return state, nil
+	}
+	openAt, err := s.scheduleRepo.GetOpenAt(tx, assessmentSeq)
+	if err != nil {
+		return "", err
+	}
+	if openAt != nil && openAt.After(time.Now()) {
+		return string(constant.Scheduled), nil
+	}
+
+	current, err := s.scheduleRepo.GetState(tx, assessmentSeq)
+	if err != nil {
+		return "", err
+	}
+	if current != string(constant.Open) {
+		if err := s.webhookService.Publish(tx, constant.EventAssessmentPublished, map[string]interface{}{
+			"assessment_sequence": assessmentSeq,
+		}); err != nil {
+			return "", err
+		}
+	}
+	return state, nil
+}
+
+// CheckStart refuses a user an assessment that is still a draft, has not opened yet, has
+// closed or whose window has passed, and assignments that expired. A due date of the user's
+// own still ahead keeps the assessment open to them past the close of its schedule, as it does
+// for the scheduler, but not past an assessment an admin closed before its schedule ended.
+func (s *AssessmentScheduleServiceImpl) CheckStart(tx *gorm.DB, userID, assessmentSeq string) error {
+	window, err := s.scheduleRepo.GetStartWindow(tx, userID, assessmentSeq)
+	if err != nil || window == nil {
+		return err
+	}
+	now := time.Now()
+	if window.AssignmentStatus != nil && *window.AssignmentStatus == constant.AssignmentExpired {
+		return errors.New("your assignment of this assessment has expired")
+	}
+	switch window.State {
+	case string(constant.Draft):
+		return errors.New("assessment is not published")
+	case string(constant.Scheduled):
+		return errors.New("assessment has not opened yet")
+	}
+
+	windowPassed := window.CloseAt != nil && !window.CloseAt.After(now)
+	if windowPassed && window.DueDate != nil && window.DueDate.After(now) {
+		return nil
+	}
+	if window.State == string(constant.Closed) {
+		return errors.New("assessment is closed")
+	}
+	if windowPassed {
+		return errors.New("assessment window has passed")
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"dhl/repository"
 	"dhl/utils"
 	"errors"
+	"io"
 	"log"
 	"mime/multipart"
 	"time"
@@ -19,22 +20,23 @@ import (
 
 type AssessmentService interface {
 	// GET
-	GetAssessment(assessmentSeq string) (*models.AssessmentResponse, error)
+	GetAssessment(assessmentSeq, locale string) (*models.AssessmentResponse, error)
 	GetUserAssessment(req models.GetAssessmentRequest) (*models.AssessmentResponse, error)
 	GetUserAssessments(userId string, limit, offset int, filters *models.AssessmentFilter) (interface{}, int64, error)
 	GetManagerAssessments(managerID string, limit, offset int, filters *models.AssessmentFilter) (interface{}, int64, error)
 	GetAssessments(limit, offset int, filters *models.AssessmentFilter) (interface{}, int64, error)
 	GetQuestions(limit, offset int) (interface{}, int64, error)
 	GenerateUserAssessmentCerficiate(assessmentSession string) ([]byte, error)
+	GenerateOwnCertificate(assessmentSession, userId string) ([]byte, *models.PendingResultResponse, error)
 	GenerateAssessmentExcel(ctx context.Context, filter models.AssessmentReportFilter) ([]byte, error)
 
 	// CREATE
 	CreateDuplicateAssessment(assessmentSequence, userId string) (interface{}, error)
-	CreateAssessmentViaFileUpload(file multipart.File, filename, userId string, assessment *models.SheetAssessment) (interface{}, error)
+	CreateAssessmentViaFileUpload(file multipart.File, filename, userId string, assessment *models.SheetAssessment, duplicateAction string) (interface{}, error)
+	ValidateAssessmentUpload(file io.Reader, filename string) (*models.ExcelValidationReport, error)
 	CreateAssessmentViaMaual(ctx context.Context, request models.ManualAssessmentRequest, userId string) (interface{}, error)
 	SubmitAssessment(userID string, req models.SubmitUserAssessmentRequest) error
-	DistributeAssessmentUser(assessmentSeq string, userIDs []string) error
-	DistributeAssessmentManager(assessmentSeq string, userIDs []string) error
+	DistributeAssessmentUser(assessmentSeq string, userIDs []string, dueDate *time.Time) error
 	SaveAssessmentTypingRespone(input *models.AssessmentTypingResult, userId string) error
 	CreateSessionImage(userID, sessionID string, imageData []byte) (*models.SessionImageResponse, error)
 
@@ -44,26 +46,107 @@ type AssessmentService interface {
 
 	UploadPhoto(assessmentSeq string, userID string, sessionID string, photoData []byte) error
 	UploadVoice(assessmentSeq string, userID string, sessionID string, voiceData []byte) error
-	StartAssessment(userID, assessmentSequence string) (string, error)
+	StartAssessment(userID, assessmentSequence, locale string) (string, string, error)
 	GetAdminAssessmentUserResult(assessmentSeq string, userId string) (*models.AdminAssessmentUserResultResponse, error)
+	GetUserAssessmentResult(assessmentSeq string, userId string) (interface{}, error)
 	CheckUserAssignment(assessmentSeq string, userIDs []string) ([]models.CheckAssignmentResponse, error)
 	DeleteAssessment(assessmentSeq string) error
 }
 
 type AssessmentServiceImpl struct {
-	assessmentRepo repository.AssessmentRepository
-	db             *gorm.DB
+	assessmentRepo      repository.AssessmentRepository
+	translationRepo     repository.TranslationRepository
+	duplicateService    DuplicateService
+	notificationService NotificationService
+	webhookService      WebhookService
+	releaseService      ResultReleaseService
+	scheduleService     AssessmentScheduleService
+	db                  *gorm.DB
 }
 
-func NewAssessmentService(assessmentRepo repository.AssessmentRepository, db *gorm.DB) AssessmentService {
-	return &AssessmentServiceImpl{assessmentRepo: assessmentRepo, db: db}
+func NewAssessmentService(assessmentRepo repository.AssessmentRepository, translationRepo repository.TranslationRepository, duplicateService DuplicateService, notificationService NotificationService, webhookService WebhookService, releaseService ResultReleaseService, scheduleService AssessmentScheduleService, db *gorm.DB) AssessmentService {
+	return &AssessmentServiceImpl{assessmentRepo: assessmentRepo, translationRepo: translationRepo, duplicateService: duplicateService, notificationService: notificationService, webhookService: webhookService, releaseService: releaseService, scheduleService: scheduleService, db: db}
 }
 
-func (s *AssessmentServiceImpl) GetAssessment(assessmentSeq string) (*models.AssessmentResponse, error) {
+// assessmentTranslations holds the translated text of one assessment in one locale.
+type assessmentTranslations struct {
+	locale     string
+	assessment *models.AssessmentTranslation
+	contents   map[int64]string
+}
+
+func (s *AssessmentServiceImpl) loadTranslations(assessmentSeq, locale string) (*assessmentTranslations, error) {
+	t := &assessmentTranslations{locale: constant.DefaultLocale}
+	if locale == "" || locale == constant.DefaultLocale {
+		return t, nil
+	}
+
+	contents, err := s.translationRepo.GetAssessmentContentTranslations(assessmentSeq, locale)
+	if err != nil {
+		return nil, err
+	}
+	assessment, err := s.translationRepo.GetAssessmentTranslation(assessmentSeq, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	t.locale = locale
+	t.contents = contents
+	t.assessment = assessment
+	return t, nil
+}
+
+// content returns the stored content value localized to the translation locale; untranslated
+// content falls back to the default language.
+func (t *assessmentTranslations) content(contentID int64, contentType, value string) string {
+	if translation, ok := t.contents[contentID]; ok {
+		return utils.LocalizeContent(contentType, value, translation)
+	}
+	return value
+}
+
+func (t *assessmentTranslations) title(value string) string {
+	if t.assessment != nil && t.assessment.Title != "" {
+		return t.assessment.Title
+	}
+	return value
+}
+
+func (t *assessmentTranslations) instructions(value string) string {
+	if t.assessment != nil && t.assessment.Instructions != "" {
+		return t.assessment.Instructions
+	}
+	return value
+}
+
+// resolveLocale normalizes a requested locale and matches it against the languages the
+// assessment has been translated into.
+func (s *AssessmentServiceImpl) resolveLocale(assessmentSeq, requested string) (string, []string, error) {
+	normalized, err := utils.NormalizeLocale(requested)
+	if err != nil {
+		return "", nil, err
+	}
+	available, err := s.translationRepo.GetAssessmentLocales(assessmentSeq)
+	if err != nil {
+		return "", nil, err
+	}
+	return utils.MatchLocale(normalized, available), available, nil
+}
+
+func (s *AssessmentServiceImpl) GetAssessment(assessmentSeq, locale string) (*models.AssessmentResponse, error) {
 	if assessmentSeq == "" {
 		return nil, errors.New("invalid input: assessmentSeq")
 	}
 
+	locale, availableLocales, err := s.resolveLocale(assessmentSeq, locale)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.loadTranslations(assessmentSeq, locale)
+	if err != nil {
+		return nil, err
+	}
+
 	assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
 	if err != nil {
 		return nil, err
@@ -104,25 +187,33 @@ func (s *AssessmentServiceImpl) GetAssessment(assessmentSeq string) (*models.Ass
 		var answers []models.Answer
 		for _, opt := range options {
 			optContents, _ := s.assessmentRepo.GetContentByID(opt.ContentID)
+			optValue := translations.content(optContents.ContentID, optContents.ContentType, optContents.Value)
+			optLabel, optRendered := utils.RenderContent(optContents.ContentType, optValue)
 			answers = append(answers, models.Answer{
 				AnswerID:      int(opt.OptionID),
 				Sequence:      &opt.SequenceID,
-				OptionLabel:   optContents.Value,
+				OptionLabel:   optLabel,
+				Content:       optRendered,
 				CorrectAnswer: opt.IsAnswer,
+				Feedback:      opt.Feedback,
 			})
 		}
 
 		questionTags := questionTagsMap[q.QuestionID]
+		questionValue := translations.content(questionContent.ContentID, questionContent.ContentType, questionContent.Value)
+		title, rendered := utils.RenderContent(questionContent.ContentType, questionValue)
 		questionResponses = append(questionResponses, models.AssessmentQuestion{
 			QuestionID:      int(q.QuestionID),
 			Sequence:        int(q.SequenceID),
-			Title:           questionContent.Value,
+			Title:           title,
+			Content:         rendered,
 			Answers:         answers,
 			AttemptedAnswer: nil,
 			QuestionTime:    int(q.DurationInSeconds),
 			QuestionTypeId:  questionContent.QuestionTypeId,
 			QuestionType:    questionContent.QuestionType,
 			Tags:            questionTags,
+			Feedback:        questionContent.Feedback,
 		})
 	}
 
@@ -131,14 +222,14 @@ func (s *AssessmentServiceImpl) GetAssessment(assessmentSeq string) (*models.Ass
 	resp := &models.AssessmentResponse{
 		AssessmentID:        assessment.AssessmentID,
 		AssessmentSequence:  assessment.AssessmentSequence,
-		AssessmentName:      assessment.AssessmentDesc,
+		AssessmentName:      translations.title(assessment.AssessmentDesc),
 		AssessmentStatus:    assessmentExt.State,
 		NoOfAttempts:        assessmentExt.AttemptsLimit,
 		QuestionsCount:      len(questionResponses),
 		AssessmentType:      assessment.AssessmentType,
 		AssessmentDuration:  &assessment.Duration,
 		Questions:           questionResponses,
-		Instruction:         assessment.Instructions,
+		Instruction:         translations.instructions(assessment.Instructions),
 		TimeLimit:           assessmentExt.TimeLimit,
 		Marks:               assessment.Marks,
 		Certificate:         assessmentExt.Certificate,
@@ -150,6 +241,8 @@ func (s *AssessmentServiceImpl) GetAssessment(assessmentSeq string) (*models.Ass
 		ServiceGroupName:    assessmentExt.ServiceGroupName,
 		ServiceName:         assessmentExt.ServiceName,
 		Tags:                tags,
+		Locale:              translations.locale,
+		AvailableLocales:    availableLocales,
 	}
 
 	return resp, nil
@@ -188,6 +281,12 @@ func (s *AssessmentServiceImpl) GetUserAssessment(req models.GetAssessmentReques
 		return nil, errors.New("session does not match assessment")
 	}
 
+	// content is served in the language chosen when the session was started
+	translations, err := s.loadTranslations(req.AssessmentId, session.Locale)
+	if err != nil {
+		return nil, err
+	}
+
 	attemptedTypingTest := false
 	typingRes, _ := s.assessmentRepo.GetAssessmentTypingResult(req.UserId, req.AssessmentId)
 	if typingRes != nil {
@@ -224,20 +323,26 @@ func (s *AssessmentServiceImpl) GetUserAssessment(req models.GetAssessmentReques
 
 		for _, opt := range options {
 			optContents, _ := s.assessmentRepo.GetContentByID(opt.ContentID)
+			optValue := translations.content(optContents.ContentID, optContents.ContentType, optContents.Value)
+			optLabel, optRendered := utils.RenderContent(optContents.ContentType, optValue)
 
 			answers = append(answers, models.Answer{
 				AnswerID:    int(opt.OptionID),
 				Sequence:    &opt.SequenceID,
-				OptionLabel: optContents.Value,
+				OptionLabel: optLabel,
+				Content:     optRendered,
 			})
 		}
 
 		questionTags := questionTagsMap[q.QuestionID]
+		questionValue := translations.content(questionContent.ContentID, questionContent.ContentType, questionContent.Value)
+		title, rendered := utils.RenderContent(questionContent.ContentType, questionValue)
 
 		questionResponses = append(questionResponses, models.AssessmentQuestion{
 			QuestionID:      int(q.QuestionID),
 			Sequence:        int(q.SequenceID),
-			Title:           questionContent.Value,
+			Title:           title,
+			Content:         rendered,
 			Answers:         answers,
 			AttemptedAnswer: nil,
 			QuestionTime:    int(q.DurationInSeconds),
@@ -253,8 +358,8 @@ func (s *AssessmentServiceImpl) GetUserAssessment(req models.GetAssessmentReques
 	resp := &models.AssessmentResponse{
 		AssessmentID:          assessment.AssessmentID,
 		AssessmentSequence:    assessment.AssessmentSequence,
-		AssessmentName:        assessment.AssessmentDesc,
-		AssessmentUsersStatus: "STARTED",
+		AssessmentName:        translations.title(assessment.AssessmentDesc),
+		AssessmentUsersStatus: constant.AssignmentStarted,
 		AssessmentStatus:      assessmentExt.State,
 		QuestionsCount:        len(questionResponses),
 		AssessmentType:        assessment.AssessmentType,
@@ -264,12 +369,13 @@ func (s *AssessmentServiceImpl) GetUserAssessment(req models.GetAssessmentReques
 		Questions:             questionResponses,
 		SessionID:             req.SessionID,
 		AssessmentReport:      false,
-		Instruction:           assessment.Instructions,
+		Instruction:           translations.instructions(assessment.Instructions),
 		TimeLimit:             assessmentExt.TimeLimit,
 		Marks:                 assessment.Marks,
 		AttemptedTypingTest:   attemptedTypingTest,
 		IsTypingTest:          assessmentExt.IsTypingTest,
 		Tags:                  tags,
+		Locale:                translations.locale,
 	}
 
 	return resp, nil
@@ -281,8 +387,15 @@ func (s *AssessmentServiceImpl) GetUserAssessments(
 	filters *models.AssessmentFilter,
 ) (interface{}, int64, error) {
 
-	// Case 1: Specific session + sequence
+	// Case 1: Specific session + sequence, held back until its results are released
 	if filters.AssessmentSessionId != nil && filters.AssessmentSequence != nil {
+		pending, err := s.releaseService.Pending(*filters.AssessmentSequence)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pending != nil {
+			return pending, 0, nil
+		}
 		asmt, err := s.assessmentRepo.GetUserAssessmentResponse(
 			&userId,
 			*filters.AssessmentSequence,
@@ -409,13 +522,45 @@ func (s *AssessmentServiceImpl) GenerateUserAssessmentCerficiate(assessmentSessi
 	}
 	userScore := (float64(details.MarksObtained) / float64(details.TotalMarks)) * 100
 	passed := userScore >= details.PassingScore
-	pdfBytes, err := utils.GenerateCertificatePDF(details.UserName, details.AssessmentTitle, details.TotalMarks, details.MarksObtained, details.PassingScore, userScore, details.CompletedAt, passed)
+	pdfBytes, err := utils.GenerateCertificatePDF(details.UserName, details.AssessmentTitle, details.TotalMarks, details.MarksObtained, details.PassingScore, userScore, details.CompletedAt, details.ExpiresOn, passed)
 	if err != nil {
 		return nil, err
 	}
 	return pdfBytes, nil
 }
 
+// ErrSessionNotFound is returned for sessions that do not exist or are not the user's.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrNoCertificate is returned for certificates of assessments that do not award any.
+var ErrNoCertificate = errors.New("assessment does not award certificates")
+
+// GenerateOwnCertificate generates the certificate of one of the user's own sessions, or returns
+// its pending release state while the results of the assessment are held back. Assessments hiding
+// results from users still award certificates once released.
+func (s *AssessmentServiceImpl) GenerateOwnCertificate(assessmentSession, userId string) ([]byte, *models.PendingResultResponse, error) {
+	session, err := s.assessmentRepo.GetSessionByID(assessmentSession, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil {
+		return nil, nil, ErrSessionNotFound
+	}
+	ext, err := s.assessmentRepo.GetSurveyExtSettingsByAssmtSeq(session.AssessmentID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if ext == nil || !ext.Certificate {
+		return nil, nil, ErrNoCertificate
+	}
+	pending, err := s.releaseService.PendingCertificate(session.AssessmentID)
+	if err != nil || pending != nil {
+		return nil, pending, err
+	}
+	pdfBytes, err := s.GenerateUserAssessmentCerficiate(assessmentSession)
+	return pdfBytes, nil, err
+}
+
 func (s *AssessmentServiceImpl) CreateDuplicateAssessment(assessmentSequence, userId string) (interface{}, error) {
 	asmtMst, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSequence)
 	if err != nil {
@@ -453,13 +598,15 @@ func (s *AssessmentServiceImpl) CreateDuplicateAssessment(assessmentSequence, us
 				IsCorrect: op.IsAnswer,
 				Score:     op.AnswerScore,
 				Label:     opContent.Value,
+				Feedback:  op.Feedback,
 			})
 		}
 		questionTags := questionTagsMap[assmtQtn.QuestionID]
 		questionsToAdd = append(questionsToAdd, models.SheetQuestion{
-			Title:   question.Value,
-			Options: optionsToAdd,
-			Tags:    questionTags,
+			Title:    question.Value,
+			Options:  optionsToAdd,
+			Tags:     questionTags,
+			Feedback: question.Feedback,
 		})
 	}
 
@@ -486,7 +633,9 @@ func (s *AssessmentServiceImpl) CreateDuplicateAssessment(assessmentSequence, us
 	}
 	return response, nil
 }
-func (s *AssessmentServiceImpl) CreateAssessmentViaFileUpload(file multipart.File, filename, userId string, assessment *models.SheetAssessment) (interface{}, error) {
+// CreateAssessmentViaFileUpload saves a parsed questionnaire (or a generated one) as a new
+// assessment. Questions matching the bank are handled per duplicateAction and reported.
+func (s *AssessmentServiceImpl) CreateAssessmentViaFileUpload(file multipart.File, filename, userId string, assessment *models.SheetAssessment, duplicateAction string) (interface{}, error) {
 	var jsonResponse *models.SheetAssessment
 	var err error
 
@@ -501,17 +650,39 @@ func (s *AssessmentServiceImpl) CreateAssessmentViaFileUpload(file multipart.Fil
 		}
 	}
 
+	if err := screenSheetQuestions(s.duplicateService, jsonResponse, duplicateAction); err != nil {
+		return nil, err
+	}
+
 	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
 	assessmentSequence, err := s.assessmentRepo.SaveAssessmentWithQuestions(context.Background(), tx, *jsonResponse, userId)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
 	jsonResponse.AssessmentSequence = assessmentSequence
 	return jsonResponse, nil
 }
 
+// ValidateAssessmentUpload checks a questionnaire workbook without importing it and reports
+// row and column level problems together with the questions that already exist in the bank.
+func (s *AssessmentServiceImpl) ValidateAssessmentUpload(file io.Reader, filename string) (*models.ExcelValidationReport, error) {
+	assessment, report, err := utils.ValidateQuestionnaireExcel(file, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := reportSheetDuplicates(s.duplicateService, assessment, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 func (s *AssessmentServiceImpl) CreateAssessmentViaMaual(
 	ctx context.Context,
 	req models.ManualAssessmentRequest,
@@ -672,11 +843,19 @@ func (s *AssessmentServiceImpl) SubmitAssessment(userID string, req models.Submi
 		}
 	}
 
-	_, err = s.assessmentRepo.UpdateAssessmentStatus(tx, session.UserID, session.AssessmentID, "COMPLETED")
+	_, err = s.assessmentRepo.UpdateAssessmentStatus(tx, session.UserID, session.AssessmentID, constant.AssignmentCompleted)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
+	if err := s.releaseService.NotifySubmission(tx, session.AssessmentID, session.SessionID.String()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.webhookService.PublishSubmission(tx, session.UserID, session.AssessmentID, session.SessionID.String()); err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	if err := tx.Commit().Error; err != nil {
 		return err
@@ -684,9 +863,12 @@ func (s *AssessmentServiceImpl) SubmitAssessment(userID string, req models.Submi
 	return nil
 }
 
+// DistributeAssessmentUser assigns the assessment to the users and queues their notification. A
+// dueDate gives them their own deadline; without it the assessment deadline applies.
 func (s *AssessmentServiceImpl) DistributeAssessmentUser(
 	assessmentSeq string,
 	userIDs []string,
+	dueDate *time.Time,
 ) error {
 
 	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
@@ -703,6 +885,7 @@ func (s *AssessmentServiceImpl) DistributeAssessmentUser(
 
 		// 🔎 Check if assignment already exists
 		var existing models.AssessmentStatus
+		var st *models.AssessmentStatus
 
 		err := tx.Where(
 			"user_id = ? AND assessment_id = ?",
@@ -713,21 +896,21 @@ func (s *AssessmentServiceImpl) DistributeAssessmentUser(
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 
 			// 🆕 First time assignment
-			_, err = s.assessmentRepo.UpdateAssessmentStatus(
+			st, err = s.assessmentRepo.UpdateAssessmentStatus(
 				tx,
 				uid,
 				assessmentSeq,
-				"ASSIGNED",
+				constant.AssignmentAssigned,
 			)
 
 		} else if err == nil {
 
 			// 🔁 Already assigned before → mark REASSIGNED
-			_, err = s.assessmentRepo.UpdateAssessmentStatus(
+			st, err = s.assessmentRepo.UpdateAssessmentStatus(
 				tx,
 				uid,
 				assessmentSeq,
-				"REASSIGNED",
+				constant.AssignmentReassigned,
 			)
 
 		} else {
@@ -735,38 +918,29 @@ func (s *AssessmentServiceImpl) DistributeAssessmentUser(
 			return err
 		}
 
+		if err == nil {
+			err = tx.Model(st).Update("due_date", dueDate).Error
+		}
+
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	if err := s.notificationService.QueueDistributeAssessmentMail(tx, userIDs, assessmentSeq, false); err != nil {
+		tx.Rollback()
 		return err
 	}
-
-	return nil
-}
-
-func (s *AssessmentServiceImpl) DistributeAssessmentManager(assessmentSeq string, userIDs []string) error {
-	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
-	if err != nil {
-		return nil
-	}
-	if asmt == nil {
-		return errors.New("assessment not found")
-	}
-	tx := s.db.Begin()
-	for _, uid := range userIDs {
-		_, err := s.assessmentRepo.AddManagerAssessmentMapping(tx, uid, assessmentSeq)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
+	if err := s.webhookService.PublishAssignments(tx, assessmentSeq, userIDs); err != nil {
+		tx.Rollback()
+		return err
 	}
+
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
+
 	return nil
 }
 
@@ -857,7 +1031,12 @@ func (s *AssessmentServiceImpl) UpdateAssessmentService(request models.UpdateAss
 		dhlSurveyUpdates.Deadline = *request.AssessmentDetails.Deadline
 	}
 	if request.AssessmentDetails.State != nil {
-		dhlSurveyUpdates.State = *request.AssessmentDetails.State
+		state, err := s.scheduleService.PublishState(tx, assment.AssessmentSequence, *request.AssessmentDetails.State)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		dhlSurveyUpdates.State = state
 	}
 	if request.AssessmentDetails.TimeLimit != nil {
 		dhlSurveyUpdates.TimeLimit = float64(*request.AssessmentDetails.TimeLimit)
@@ -1056,13 +1235,20 @@ func (s *AssessmentServiceImpl) UpdateAssessmentService(request models.UpdateAss
 	return nil
 }
 
+// UpdateAssessmentStatusService sets the state of an assessment. Opening one whose fixed schedule
+// starts later schedules it, and it is published when the scheduler opens it.
 func (s *AssessmentServiceImpl) UpdateAssessmentStatusService(req models.UpdateAssessmentStatusRequest) error {
+	tx := s.db.Begin()
+	state, err := s.scheduleService.PublishState(tx, req.AssessmentSequence, req.AssessmentStatus)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 	updates := &models.DhlSurveySurveyExt{
-		State:              req.AssessmentStatus,
+		State:              state,
 		AssessmentSequence: req.AssessmentSequence,
 	}
-	tx := s.db.Begin()
-	err := s.assessmentRepo.UpdateDHLAssessmentExt(tx, updates)
+	err = s.assessmentRepo.UpdateDHLAssessmentExt(tx, updates)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -1088,7 +1274,7 @@ func (s *AssessmentServiceImpl) GenerateAssessmentExcel(ctx context.Context, fil
 		"SDL", "SLL", "Skill Set", "Date",
 		"Assessment Title", "Status", "Attempts",
 		"Assigned", "Passed", "Failed", "Not Started",
-		"Assessment Sequence",
+		"Assessment Sequence", "Locale",
 	}
 
 	// Write headers
@@ -1118,6 +1304,7 @@ func (s *AssessmentServiceImpl) GenerateAssessmentExcel(ctx context.Context, fil
 			row.TotalNotStarted,
 
 			row.AssessmentSequence,
+			row.Locale,
 		}
 
 		for cIndex, v := range values {
@@ -1233,17 +1420,28 @@ func (s *AssessmentServiceImpl) UploadVoice(
 	)
 }
 
-func (s *AssessmentServiceImpl) StartAssessment(userID, assessmentSequence string) (string, error) {
+// StartAssessment opens a session in the requested language, falling back to the closest
+// available translation or the default language. It returns the session id and the locale used.
+func (s *AssessmentServiceImpl) StartAssessment(userID, assessmentSequence, locale string) (string, string, error) {
 
 	assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSequence)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	if assessment == nil {
-		return "", errors.New("assessment not found")
+		return "", "", errors.New("assessment not found")
+	}
+
+	locale, _, err = s.resolveLocale(assessmentSequence, locale)
+	if err != nil {
+		return "", "", err
 	}
 
 	tx := s.db.Begin()
+	if err := s.scheduleService.CheckStart(tx, userID, assessmentSequence); err != nil {
+		tx.Rollback()
+		return "", "", err
+	}
 
 session, err := s.assessmentRepo.CreateUserSession(
 	tx,
@@ -1251,28 +1449,52 @@ session, err := s.assessmentRepo.CreateUserSession(
 	assessmentSequence,
 	assessment.AssessmentType,
 	assessment.PartnerID,
+	locale,
 )
 	if err != nil {
 		tx.Rollback()
-		return "", err
+		return "", "", err
 	}
 
 	_, err = s.assessmentRepo.UpdateAssessmentStatus(
 		tx,
 		userID,
 		assessmentSequence,
-		"STARTED",
+		constant.AssignmentStarted,
 	)
 	if err != nil {
 		tx.Rollback()
-		return "", err
+		return "", "", err
+	}
+
+	if err := s.webhookService.Publish(tx, constant.EventSessionStarted, map[string]interface{}{
+		"assessment_sequence": assessmentSequence,
+		"user_id":             userID,
+		"session_id":          session.SessionID.String(),
+		"locale":              locale,
+	}); err != nil {
+		tx.Rollback()
+		return "", "", err
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return session.SessionID.String(), nil
+	return session.SessionID.String(), locale, nil
+}
+
+// GetUserAssessmentResult returns the user's result, or its pending release state while the
+// results of the assessment are held back or hidden from users.
+func (s *AssessmentServiceImpl) GetUserAssessmentResult(assessmentSeq string, userId string) (interface{}, error) {
+	pending, err := s.releaseService.Pending(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return pending, nil
+	}
+	return s.GetAdminAssessmentUserResult(assessmentSeq, userId)
 }
 
 func (s *AssessmentServiceImpl) GetAdminAssessmentUserResult(
@@ -1295,6 +1517,7 @@ func (s *AssessmentServiceImpl) GetAdminAssessmentUserResult(
 			attemptMap[sessionID] = &models.AssessmentAttemptResult{
 				SessionID:        sessionID,
 				AttemptTime:      row["session_created_on"],
+				Locale:           attemptLocale(row["locale"]),
 				Questions:        []models.AdminQuestionResult{},
 				CompletionStatus: "Completed",
 			}
@@ -1342,6 +1565,14 @@ func (s *AssessmentServiceImpl) GetAdminAssessmentUserResult(
 	}, nil
 }
 
+// attemptLocale reports sessions started before languages were recorded as the default locale.
+func attemptLocale(val interface{}) string {
+	if locale := safeString(val); locale != "" {
+		return locale
+	}
+	return constant.DefaultLocale
+}
+
 func safeString(val interface{}) string {
 	if val == nil {
 		return ""
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AssessmentTemplateService interface {
+	SaveTemplate(ctx context.Context, req models.SaveTemplateRequest, userId string) (*models.AssessmentTemplate, error)
+	GetTemplate(templateID int64) (*models.AssessmentTemplate, error)
+	ListTemplates(limit, offset int) ([]models.AssessmentTemplate, int64, error)
+	DeleteTemplate(templateID int64, userId string) error
+	CreateFromTemplate(ctx context.Context, req models.CreateFromTemplateRequest, userId string) (*models.CreatedAssessment, error)
+	CloneToCenters(ctx context.Context, req models.CloneToCentersRequest, userId string) (*models.CloneToCentersResponse, error)
+}
+
+type AssessmentTemplateServiceImpl struct {
+	templateRepo   repository.AssessmentTemplateRepository
+	assessmentRepo repository.AssessmentRepository
+	blueprintRepo  repository.BlueprintRepository
+	questionRepo   repository.QuestionRepository
+	centerRepo     repository.DHLCenterRepository
+	db             *gorm.DB
+}
+
+func NewAssessmentTemplateService(templateRepo repository.AssessmentTemplateRepository, assessmentRepo repository.AssessmentRepository, blueprintRepo repository.BlueprintRepository, questionRepo repository.QuestionRepository, centerRepo repository.DHLCenterRepository, db *gorm.DB) AssessmentTemplateService {
+	return &AssessmentTemplateServiceImpl{
+		templateRepo:   templateRepo,
+		assessmentRepo: assessmentRepo,
+		blueprintRepo:  blueprintRepo,
+		questionRepo:   questionRepo,
+		centerRepo:     centerRepo,
+		db:             db,
+	}
+}
+
+func (s *AssessmentTemplateServiceImpl) SaveTemplate(ctx context.Context, req models.SaveTemplateRequest, userId string) (*models.AssessmentTemplate, error) {
+	settings := models.DefaultAssessmentSettings()
+	if req.FromAssessmentSequence != "" {
+		_, sourceSettings, err := s.loadAssessmentSettings(req.FromAssessmentSequence)
+		if err != nil {
+			return nil, err
+		}
+		settings = sourceSettings
+	}
+	applySettingsOverride(&settings, req.Settings)
+
+	if req.BlueprintID != nil {
+		if _, err := s.blueprintRepo.GetByID(*req.BlueprintID); err != nil {
+			return nil, fmt.Errorf("blueprint %d not found: %w", *req.BlueprintID, err)
+		}
+	}
+
+	now := time.Now()
+	template := &models.AssessmentTemplate{
+		Name:                     strings.TrimSpace(req.Name),
+		Description:              req.Description,
+		SourceAssessmentSequence: req.FromAssessmentSequence,
+		BlueprintID:              req.BlueprintID,
+		Settings:                 settings,
+		CreatedOn:                now,
+		CreatedBy:                userId,
+		ModifiedOn:               now,
+		ModifiedBy:               userId,
+		IsActive:                 true,
+		IsDeleted:                false,
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if req.TemplateID != nil {
+		existing, err := s.templateRepo.GetByID(*req.TemplateID)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("template %d not found: %w", *req.TemplateID, err)
+		}
+		template.TemplateID = existing.TemplateID
+		template.CreatedOn = existing.CreatedOn
+		template.CreatedBy = existing.CreatedBy
+		if err := s.templateRepo.Update(tx, template); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if err := s.templateRepo.Create(tx, template); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return s.templateRepo.GetByID(template.TemplateID)
+}
+
+func (s *AssessmentTemplateServiceImpl) GetTemplate(templateID int64) (*models.AssessmentTemplate, error) {
+	return s.templateRepo.GetByID(templateID)
+}
+
+func (s *AssessmentTemplateServiceImpl) ListTemplates(limit, offset int) ([]models.AssessmentTemplate, int64, error) {
+	return s.templateRepo.List(limit, offset)
+}
+
+func (s *AssessmentTemplateServiceImpl) DeleteTemplate(templateID int64, userId string) error {
+	return s.templateRepo.Delete(templateID, userId)
+}
+
+// CreateFromTemplate creates a draft assessment with the template's settings and the request
+// overrides. Questions are linked from the template's source assessment, or drawn afresh from
+// its blueprint when RegenerateQuestions is set; a template with neither gives an empty draft.
+func (s *AssessmentTemplateServiceImpl) CreateFromTemplate(ctx context.Context, req models.CreateFromTemplateRequest, userId string) (*models.CreatedAssessment, error) {
+	template, err := s.templateRepo.GetByID(req.TemplateID)
+	if err != nil {
+		return nil, fmt.Errorf("template %d not found: %w", req.TemplateID, err)
+	}
+
+	settings := template.Settings
+	applySettingsOverride(&settings, req.Overrides)
+
+	name := strings.TrimSpace(req.AssessmentName)
+	if name == "" {
+		name = template.Name
+	}
+
+	var picks []blueprintPick
+	var source *models.AssessmentMst
+	var marks int64
+	switch {
+	case req.RegenerateQuestions:
+		if template.BlueprintID == nil {
+			return nil, errors.New("template has no blueprint to regenerate questions from")
+		}
+		blueprint, err := s.blueprintRepo.GetByID(*template.BlueprintID)
+		if err != nil {
+			return nil, fmt.Errorf("blueprint %d not found: %w", *template.BlueprintID, err)
+		}
+		var fulfilment []models.ConstraintFulfilment
+		var satisfied bool
+		picks, fulfilment, satisfied, err = selectBlueprintQuestions(s.blueprintRepo, s.questionRepo, blueprint)
+		if err != nil {
+			return nil, err
+		}
+		if !satisfied {
+			return nil, fmt.Errorf("question bank cannot satisfy blueprint %q: %s", blueprint.Name, describeShortfall(fulfilment))
+		}
+		for _, p := range picks {
+			marks += blueprintPoints(p.constraint)
+		}
+	case template.SourceAssessmentSequence != "":
+		source, err = s.assessmentRepo.GetAssessmentMstByAssmtSeq(template.SourceAssessmentSequence)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, fmt.Errorf("source assessment %s of the template no longer exists", template.SourceAssessmentSequence)
+		}
+		marks = source.Marks
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	assessment, err := createDraftAssessment(ctx, tx, s.assessmentRepo, draftAssessment{
+		Name:        name,
+		Settings:    settings,
+		Marks:       marks,
+		ValidFrom:   req.ValidFrom,
+		ValidTo:     req.ValidTo,
+		BlueprintID: template.BlueprintID,
+	}, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	count := len(picks)
+	if req.RegenerateQuestions {
+		if err := mapBlueprintPicks(ctx, tx, assessment, picks, userId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if source != nil {
+		if count, err = s.copyAssessmentQuestions(ctx, tx, source.AssessmentSequence, assessment, userId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAssessment{
+		AssessmentSequence: assessment.AssessmentSequence,
+		AssessmentName:     name,
+		CenterID:           settings.CenterID,
+		QuestionsCount:     count,
+	}, nil
+}
+
+// CloneToCenters copies an assessment once per center, all in one transaction: either every
+// center gets its copy or none does. Copies share the source questions and are named after
+// their center.
+func (s *AssessmentTemplateServiceImpl) CloneToCenters(ctx context.Context, req models.CloneToCentersRequest, userId string) (*models.CloneToCentersResponse, error) {
+	source, settings, err := s.loadAssessmentSettings(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	applySettingsOverride(&settings, req.Overrides)
+
+	centers, err := s.centerRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	centerNames := make(map[int]string, len(centers))
+	for _, c := range centers {
+		centerNames[int(c.CenterID)] = c.CenterName
+	}
+
+	var centerIDs []int
+	seen := map[int]bool{}
+	for _, id := range req.CenterIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, ok := centerNames[id]; !ok {
+			return nil, fmt.Errorf("center %d not found or inactive", id)
+		}
+		centerIDs = append(centerIDs, id)
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	resp := &models.CloneToCentersResponse{SourceAssessmentSequence: source.AssessmentSequence}
+	for _, centerID := range centerIDs {
+		centerSettings := settings
+		centerSettings.CenterID = centerID
+		name := fmt.Sprintf("%s - %s", source.AssessmentDesc, centerNames[centerID])
+
+		assessment, err := createDraftAssessment(ctx, tx, s.assessmentRepo, draftAssessment{
+			Name:        name,
+			Settings:    centerSettings,
+			Marks:       source.Marks,
+			ValidFrom:   source.ValidFrom,
+			ValidTo:     source.ValidTo,
+			BlueprintID: source.BlueprintID,
+		}, userId)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("center %d: %w", centerID, err)
+		}
+		count, err := s.copyAssessmentQuestions(ctx, tx, source.AssessmentSequence, assessment, userId)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("center %d: %w", centerID, err)
+		}
+
+		resp.Assessments = append(resp.Assessments, models.CreatedAssessment{
+			AssessmentSequence: assessment.AssessmentSequence,
+			AssessmentName:     name,
+			CenterID:           centerID,
+			QuestionsCount:     count,
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *AssessmentTemplateServiceImpl) loadAssessmentSettings(assessmentSeq string) (*models.AssessmentMst, models.AssessmentSettings, error) {
+	assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
+	if err != nil {
+		return nil, models.AssessmentSettings{}, err
+	}
+	if assessment == nil {
+		return nil, models.AssessmentSettings{}, fmt.Errorf("assessment %s not found", assessmentSeq)
+	}
+	ext, err := s.assessmentRepo.GetSurveyExtSettingsByAssmtSeq(assessmentSeq)
+	if err != nil {
+		return nil, models.AssessmentSettings{}, fmt.Errorf("settings of assessment %s not found: %w", assessmentSeq, err)
+	}
+
+	return assessment, models.AssessmentSettings{
+		AssessmentType:       assessment.AssessmentType,
+		Duration:             assessment.Duration,
+		Instructions:         assessment.Instructions,
+		IsTimeLimited:        ext.IsTimeLimited,
+		TimeLimit:            ext.TimeLimit,
+		IsAttemptsLimited:    ext.IsAttemptsLimited,
+		AttemptsLimit:        ext.AttemptsLimit,
+		Certificate:          ext.Certificate,
+		ShowResult:           ext.ShowResult,
+		UsersLoginRequired:   ext.UsersLoginRequired,
+		UsersCanGoBack:       ext.UsersCanGoBack,
+		ScoringType:          ext.ScoringType,
+		QuestionsLayout:      ext.QuestionsLayout,
+		QuestionsSelection:   ext.QuestionsSelection,
+		NoOfRandomQuestions:  ext.NoOfRandomQuestions,
+		IsTypingTest:         ext.IsTypingTest,
+		TypingTestTime:       ext.TypingTestTime,
+		ThankYouMessage:      ext.ThankYouMessage,
+		CenterID:             ext.CenterID,
+		ServiceLineID:        ext.ServiceLineID,
+		BusinessPartnerID:    ext.BusinessPartnerID,
+		SubBusinessPartnerID: ext.SubBusinessPartnerID,
+		ServiceGroupID:       ext.ServiceGroupID,
+		ServiceID:            ext.ServiceID,
+	}, nil
+}
+
+// draftAssessment describes a new draft assessment.
+type draftAssessment struct {
+	Name        string
+	Settings    models.AssessmentSettings
+	Marks       int64
+	ValidFrom   *time.Time
+	ValidTo     *time.Time
+	BlueprintID *int64
+	JobID       *int64
+}
+
+// createDraftAssessment inserts a draft assessment and its survey settings. Every service that
+// creates assessments outside of the upload path goes through here, starting from
+// models.DefaultAssessmentSettings when it has no settings of its own.
+func createDraftAssessment(ctx context.Context, tx *gorm.DB, assessmentRepo repository.AssessmentRepository, draft draftAssessment, userId string) (*models.AssessmentMst, error) {
+	now := time.Now()
+	assessmentType := draft.Settings.AssessmentType
+	if assessmentType == "" {
+		assessmentType = "survey"
+	}
+
+	assessment, err := assessmentRepo.CreateAssessment(ctx, tx, &models.AssessmentMst{
+		AssessmentDesc: draft.Name,
+		CreatedOn:      now,
+		CreatedBy:      userId,
+		IsActive:       true,
+		IsDeleted:      false,
+		ModifiedOn:     now,
+		ModifiedBy:     userId,
+		Duration:       draft.Settings.Duration,
+		Marks:          draft.Marks,
+		ValidFrom:      draft.ValidFrom,
+		ValidTo:        draft.ValidTo,
+		Instructions:   draft.Settings.Instructions,
+		AssessmentType: assessmentType,
+		BlueprintID:    draft.BlueprintID,
+		JobID:          draft.JobID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ext := draft.Settings.DraftSurveyExt(assessment.AssessmentID, assessment.AssessmentSequence)
+	if _, err := assessmentRepo.CreateAssessmentExt(ctx, tx, &ext); err != nil {
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// copyAssessmentQuestions links the questions and tags of the source assessment to the target
+// with the same points, timing and order. Question content is shared, not duplicated.
+func (s *AssessmentTemplateServiceImpl) copyAssessmentQuestions(ctx context.Context, tx *gorm.DB, sourceSeq string, target *models.AssessmentMst, userId string) (int, error) {
+	questions, err := s.assessmentRepo.GetAssessmentQuestions(sourceSeq)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, q := range questions {
+		mapping := models.AssessmentQuestionMst{
+			CreatedOn:          now,
+			CreatedBy:          userId,
+			IsActive:           q.IsActive,
+			IsDeleted:          false,
+			ModifiedOn:         now,
+			ModifiedBy:         userId,
+			AssessmentSequence: target.AssessmentSequence,
+			AssessmentID:       int(target.AssessmentID),
+			QuestionID:         q.QuestionID,
+			SequenceID:         q.SequenceID,
+			CorrectPoints:      q.CorrectPoints,
+			NegativePoints:     q.NegativePoints,
+			DurationInSeconds:  q.DurationInSeconds,
+			SkippingAllowed:    q.SkippingAllowed,
+			DifficultyLevel:    q.DifficultyLevel,
+		}
+		if err := tx.WithContext(ctx).Create(&mapping).Error; err != nil {
+			return 0, fmt.Errorf("failed to copy question %d: %w", q.QuestionID, err)
+		}
+	}
+
+	tags, err := s.assessmentRepo.GetTagRequestsByAssessmentSequence(sourceSeq)
+	if err != nil {
+		return 0, err
+	}
+	for _, tagReq := range tags {
+		tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, userId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to process tag request for assessment: %w", err)
+		}
+		for _, tagID := range tagIDs {
+			if err := s.assessmentRepo.CreateAssessmentTagMappingWithParents(tx, target.AssessmentSequence, tagID, userId); err != nil {
+				return 0, fmt.Errorf("failed to create assessment tag mapping: %w", err)
+			}
+		}
+	}
+
+	return len(questions), nil
+}
+
+func applySettingsOverride(settings *models.AssessmentSettings, o models.AssessmentSettingsOverride) {
+	if o.AssessmentType != nil {
+		settings.AssessmentType = *o.AssessmentType
+	}
+	if o.Duration != nil {
+		settings.Duration = *o.Duration
+	}
+	if o.Instructions != nil {
+		settings.Instructions = *o.Instructions
+	}
+	if o.IsTimeLimited != nil {
+		settings.IsTimeLimited = *o.IsTimeLimited
+	}
+	if o.TimeLimit != nil {
+		settings.TimeLimit = *o.TimeLimit
+	}
+	if o.IsAttemptsLimited != nil {
+		settings.IsAttemptsLimited = *o.IsAttemptsLimited
+	}
+	if o.AttemptsLimit != nil {
+		settings.AttemptsLimit = *o.AttemptsLimit
+	}
+	if o.Certificate != nil {
+		settings.Certificate = *o.Certificate
+	}
+	if o.ShowResult != nil {
+		settings.ShowResult = *o.ShowResult
+	}
+	if o.UsersLoginRequired != nil {
+		settings.UsersLoginRequired = *o.UsersLoginRequired
+	}
+	if o.UsersCanGoBack != nil {
+		settings.UsersCanGoBack = *o.UsersCanGoBack
+	}
+	if o.ScoringType != nil {
+		settings.ScoringType = *o.ScoringType
+	}
+	if o.QuestionsLayout != nil {
+		settings.QuestionsLayout = *o.QuestionsLayout
+	}
+	if o.QuestionsSelection != nil {
+		settings.QuestionsSelection = *o.QuestionsSelection
+	}
+	if o.NoOfRandomQuestions != nil {
+		settings.NoOfRandomQuestions = *o.NoOfRandomQuestions
+	}
+	if o.IsTypingTest != nil {
+		settings.IsTypingTest = *o.IsTypingTest
+	}
+	if o.TypingTestTime != nil {
+		settings.TypingTestTime = *o.TypingTestTime
+	}
+	if o.ThankYouMessage != nil {
+		settings.ThankYouMessage = *o.ThankYouMessage
+	}
+	if o.CenterID != nil {
+		settings.CenterID = *o.CenterID
+	}
+	if o.ServiceLineID != nil {
+		settings.ServiceLineID = *o.ServiceLineID
+	}
+	if o.BusinessPartnerID != nil {
+		settings.BusinessPartnerID = *o.BusinessPartnerID
+	}
+	if o.SubBusinessPartnerID != nil {
+		settings.SubBusinessPartnerID = *o.SubBusinessPartnerID
+	}
+	if o.ServiceGroupID != nil {
+		settings.ServiceGroupID = *o.ServiceGroupID
+	}
+	if o.ServiceID != nil {
+		settings.ServiceID = *o.ServiceID
+	}
+}
+
+func describeShortfall(fulfilment []models.ConstraintFulfilment) string {
+	var parts []string
+	for _, f := range fulfilment {
+		if f.Shortfall <= 0 {
+			continue
+		}
+		label := f.Tag
+		if f.DifficultyLevel != "" {
+			label = strings.TrimSpace(label + " " + f.DifficultyLevel)
+		}
+		parts = append(parts, fmt.Sprintf("constraint %d (%s) is short by %d", f.Sequence, label, f.Shortfall))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,654 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AssessmentTransferService interface {
+	ExportAssessmentBundle(assessmentSeq string) (*models.AssessmentBundle, error)
+	ExportAssessmentExcel(assessmentSeq string) ([]byte, error)
+	ImportAssessmentBundle(ctx context.Context, bundle models.AssessmentBundle, userId string) (*models.ImportBundleResponse, error)
+	ExportQTIPackage(assessmentSeq string) ([]byte, []models.ImportItemIssue, error)
+	ImportItemFile(ctx context.Context, format constant.ImportFormat, data []byte, filename, userId string) (*models.ItemImportResponse, error)
+	ImportExcelZip(ctx context.Context, data []byte, filename, userId, duplicateAction string) (*models.SheetAssessment, error)
+	ValidateExcelZip(data []byte, filename string) (*models.ExcelValidationReport, error)
+}
+
+type AssessmentTransferServiceImpl struct {
+	assessmentRepo   repository.AssessmentRepository
+	jobRepo          repository.JobDescriptionRepository
+	mediaService     MediaService
+	translationRepo  repository.TranslationRepository
+	duplicateService DuplicateService
+	db               *gorm.DB
+}
+
+func NewAssessmentTransferService(assessmentRepo repository.AssessmentRepository, jobRepo repository.JobDescriptionRepository, mediaService MediaService, translationRepo repository.TranslationRepository, duplicateService DuplicateService, db *gorm.DB) AssessmentTransferService {
+	return &AssessmentTransferServiceImpl{
+		assessmentRepo:   assessmentRepo,
+		jobRepo:          jobRepo,
+		mediaService:     mediaService,
+		translationRepo:  translationRepo,
+		duplicateService: duplicateService,
+		db:               db,
+	}
+}
+
+func (s *AssessmentTransferServiceImpl) ExportAssessmentBundle(assessmentSeq string) (*models.AssessmentBundle, error) {
+	if assessmentSeq == "" {
+		return nil, errors.New("invalid input: assessmentSeq")
+	}
+
+	assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if assessment == nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	settings, err := s.assessmentRepo.GetSurveyExtSettingsByAssmtSeq(assessmentSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch assessment settings: %w", err)
+	}
+
+	questions, err := s.assessmentRepo.GetAssessmentQuestions(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	questionIDs := make([]int64, len(questions))
+	for i, q := range questions {
+		questionIDs[i] = q.QuestionID
+	}
+	questionTagsMap, err := s.assessmentRepo.GetTagRequestsByQuestionIDs(questionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.AssessmentBundle{
+		Version:        constant.AssessmentBundleVersion,
+		ExportedOn:     time.Now(),
+		SourceSequence: assessment.AssessmentSequence,
+		Assessment: models.BundleAssessment{
+			Name:            assessment.AssessmentDesc,
+			Duration:        assessment.Duration,
+			Marks:           assessment.Marks,
+			StartTime:       assessment.StartTime,
+			PartnerID:       assessment.PartnerID,
+			ValidFrom:       assessment.ValidFrom,
+			ValidTo:         assessment.ValidTo,
+			NoFixedSchedule: assessment.NoFixedSchedule,
+			Instructions:    assessment.Instructions,
+			AssessmentType:  assessment.AssessmentType,
+		},
+		Settings:  *settings,
+		Questions: make([]models.BundleQuestion, 0, len(questions)),
+	}
+	clearEnvironmentSettings(&bundle.Settings)
+
+	if assessment.JobID != nil {
+		job, err := s.jobRepo.GetByID(*assessment.JobID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if job != nil {
+			bundle.Job = &models.BundleJob{
+				Title:          job.Title,
+				Description:    job.Description,
+				RequiredSkills: job.RequiredSkills,
+				Level:          job.Level,
+			}
+		}
+	}
+
+	bundle.Tags, err = s.assessmentRepo.GetTagRequestsByAssessmentSequence(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	assessmentTranslations, err := s.translationRepo.GetAssessmentTranslations(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range assessmentTranslations {
+		if bundle.Assessment.Translations == nil {
+			bundle.Assessment.Translations = map[string]models.BundleTranslation{}
+		}
+		bundle.Assessment.Translations[t.Locale] = models.BundleTranslation{Title: t.Title, Instructions: t.Instructions}
+	}
+	contentTranslations, err := s.translationRepo.GetAllAssessmentContentTranslations(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range questions {
+		questionContent, err := s.assessmentRepo.GetContentByQuestionID(q.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+
+		options, err := s.assessmentRepo.GetOptionsByQuestionID(q.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(options, func(i, j int) bool {
+			if options[i].SequenceID != options[j].SequenceID {
+				return options[i].SequenceID < options[j].SequenceID
+			}
+			return options[i].OptionID < options[j].OptionID
+		})
+
+		bundleOptions := make([]models.BundleOption, 0, len(options))
+		for idx, opt := range options {
+			optContent, err := s.assessmentRepo.GetContentByID(opt.ContentID)
+			if err != nil {
+				return nil, err
+			}
+			// rich content is exported as its plain-text fallback
+			label, _ := utils.RenderContent(optContent.ContentType, optContent.Value)
+			bundleOptions = append(bundleOptions, models.BundleOption{
+				Sequence:     int64(idx + 1),
+				Label:        label,
+				IsCorrect:    opt.IsAnswer,
+				Score:        opt.AnswerScore,
+				Translations: contentTranslations[opt.ContentID],
+				Feedback:     opt.Feedback,
+			})
+		}
+
+		title, _ := utils.RenderContent(questionContent.ContentType, questionContent.Value)
+		bundle.Questions = append(bundle.Questions, models.BundleQuestion{
+			Sequence:          q.SequenceID,
+			Title:             title,
+			QuestionType:      questionContent.QuestionType,
+			MandatoryToAnswer: !q.SkippingAllowed,
+			CorrectPoints:     q.CorrectPoints,
+			NegativePoints:    q.NegativePoints,
+			DurationInSeconds: q.DurationInSeconds,
+			DifficultyLevel:   q.DifficultyLevel,
+			Options:           bundleOptions,
+			Tags:              questionTagsMap[q.QuestionID],
+			Translations:      contentTranslations[questionContent.ContentID],
+			Feedback:          questionContent.Feedback,
+		})
+	}
+
+	return bundle, nil
+}
+
+// clearEnvironmentSettings drops the settings that only mean something in the environment they
+// were made in: the ids, the access token and the organisation the assessment belongs to.
+func clearEnvironmentSettings(settings *models.DhlSurveySurveyExt) {
+	settings.SurveyID = 0
+	settings.AssessmentSequence = ""
+	settings.AccessToken = ""
+	settings.CenterID = 0
+	settings.ServiceLineID = 0
+	settings.BusinessPartnerID = 0
+	settings.SubBusinessPartnerID = 0
+	settings.ServiceGroupID = 0
+	settings.ServiceID = 0
+}
+
+func (s *AssessmentTransferServiceImpl) ExportAssessmentExcel(assessmentSeq string) ([]byte, error) {
+	bundle, err := s.ExportAssessmentBundle(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := models.SheetAssessment{
+		AssessmentName:     bundle.Assessment.Name,
+		AssessmentSequence: bundle.SourceSequence,
+		Questions:          bundleToSheetQuestions(bundle.Questions),
+		Tags:               bundle.Tags,
+	}
+	for locale, t := range bundle.Assessment.Translations {
+		if t.Title == "" {
+			continue
+		}
+		if sheet.NameTranslations == nil {
+			sheet.NameTranslations = map[string]string{}
+		}
+		sheet.NameTranslations[locale] = t.Title
+	}
+	return utils.BuildQuestionnaireExcel(sheet)
+}
+
+func (s *AssessmentTransferServiceImpl) ExportQTIPackage(assessmentSeq string) ([]byte, []models.ImportItemIssue, error) {
+	bundle, err := s.ExportAssessmentBundle(assessmentSeq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sheet := models.SheetAssessment{
+		AssessmentName:     bundle.Assessment.Name,
+		AssessmentSequence: bundle.SourceSequence,
+		Questions:          bundleToSheetQuestions(bundle.Questions),
+		Tags:               bundle.Tags,
+	}
+	return utils.BuildQTIPackage(sheet)
+}
+
+// ImportItemFile imports question-interchange files (QTI, Moodle XML, GIFT) and reports the
+// items that could not be converted.
+func (s *AssessmentTransferServiceImpl) ImportItemFile(ctx context.Context, format constant.ImportFormat, data []byte, filename, userId string) (*models.ItemImportResponse, error) {
+	var assessment *models.SheetAssessment
+	var issues []models.ImportItemIssue
+	var err error
+
+	switch format {
+	case constant.ImportFormatQTI:
+		assessment, issues, err = utils.ParseQTIPackage(data, filename)
+	case constant.ImportFormatMoodleXML:
+		assessment, issues, err = utils.ParseMoodleXMLToJSON(data, filename)
+	case constant.ImportFormatGIFT:
+		assessment, issues, err = utils.ParseGIFTToJSON(data, filename)
+	default:
+		return nil, fmt.Errorf("format %s is not an item file format", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(assessment.Questions) == 0 {
+		return nil, fmt.Errorf("%s file has no supported questions (%d unsupported)", format, len(issues))
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	assessmentSequence, err := s.assessmentRepo.SaveAssessmentWithQuestions(ctx, tx, *assessment, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	assessment.AssessmentSequence = assessmentSequence
+
+	return &models.ItemImportResponse{
+		Format:      string(format),
+		Assessment:  assessment,
+		Imported:    len(assessment.Questions),
+		Unsupported: issues,
+	}, nil
+}
+
+// readExcelZip opens a questionnaire workbook bundled with its media files and validates it,
+// reporting media files the workbook refers to but the zip does not contain.
+func readExcelZip(data []byte, filename string) (*models.SheetAssessment, *models.ExcelValidationReport, map[string]*zip.File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot open zip file %s: %w", filename, err)
+	}
+
+	var workbook *zip.File
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		base := path.Base(f.Name)
+		if strings.HasPrefix(base, "~$") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if strings.EqualFold(path.Ext(base), ".xlsx") {
+			if workbook == nil {
+				workbook = f
+			}
+			continue
+		}
+		entries[strings.ToLower(base)] = f
+	}
+	if workbook == nil {
+		return nil, nil, nil, errors.New("zip file has no .xlsx questionnaire")
+	}
+
+	rc, err := workbook.Open()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assessment, report, err := utils.ValidateQuestionnaireExcel(rc, workbook.Name)
+	rc.Close()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	missing := func(media *models.SheetMedia, field string) {
+		if media == nil {
+			return
+		}
+		if _, ok := entries[strings.ToLower(path.Base(media.FileName))]; !ok {
+			report.Errors = append(report.Errors, models.ExcelValidationIssue{
+				Sheet:    report.Sheet,
+				Field:    field,
+				Severity: constant.IssueError,
+				Message:  fmt.Sprintf("media file %s not found in zip", media.FileName),
+			})
+		}
+	}
+	for _, q := range assessment.Questions {
+		missing(q.Media, "Question Media")
+		for _, opt := range q.Options {
+			missing(opt.Media, "Option Media")
+		}
+	}
+	report.Valid = len(report.Errors) == 0
+
+	return assessment, report, entries, nil
+}
+
+func (s *AssessmentTransferServiceImpl) ValidateExcelZip(data []byte, filename string) (*models.ExcelValidationReport, error) {
+	assessment, report, _, err := readExcelZip(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := reportSheetDuplicates(s.duplicateService, assessment, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ImportExcelZip imports a zip holding a questionnaire workbook and the image/audio files its
+// "Question Media" and "Option Media" columns refer to.
+func (s *AssessmentTransferServiceImpl) ImportExcelZip(ctx context.Context, data []byte, filename, userId, duplicateAction string) (*models.SheetAssessment, error) {
+	assessment, report, entries, err := readExcelZip(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Valid {
+		return nil, &utils.ExcelValidationError{Report: report}
+	}
+	if err := screenSheetQuestions(s.duplicateService, assessment, duplicateAction); err != nil {
+		return nil, err
+	}
+
+	// merged questions keep the bank question's content, so their files are not needed
+	var refs []*models.SheetMedia
+	for qi := range assessment.Questions {
+		q := &assessment.Questions[qi]
+		if q.ExistingQuestionID > 0 {
+			continue
+		}
+		if q.Media != nil {
+			refs = append(refs, q.Media)
+		}
+		for oi := range q.Options {
+			if q.Options[oi].Media != nil {
+				refs = append(refs, q.Options[oi].Media)
+			}
+		}
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var storedKeys []string
+	rollback := func() {
+		tx.Rollback()
+		for _, key := range storedKeys {
+			s.mediaService.Discard(key)
+		}
+	}
+
+	// the same file may be referenced by several rows; store it once
+	stored := map[string]*models.MediaMst{}
+	for _, ref := range refs {
+		name := strings.ToLower(path.Base(ref.FileName))
+		media, ok := stored[name]
+		if !ok {
+			content, err := readZipEntry(entries[name])
+			if err != nil {
+				rollback()
+				return nil, err
+			}
+			media, err = s.mediaService.Store(tx, ref.FileName, content, userId)
+			if err != nil {
+				rollback()
+				return nil, err
+			}
+			storedKeys = append(storedKeys, media.StorageKey)
+			stored[name] = media
+		}
+		ref.MediaID = media.MediaID
+		ref.Key = media.StorageKey
+		ref.MimeType = media.MimeType
+	}
+
+	assessmentSequence, err := s.assessmentRepo.SaveAssessmentWithQuestions(ctx, tx, *assessment, userId)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		for _, key := range storedKeys {
+			s.mediaService.Discard(key)
+		}
+		return nil, err
+	}
+	assessment.AssessmentSequence = assessmentSequence
+
+	return assessment, nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (s *AssessmentTransferServiceImpl) ImportAssessmentBundle(ctx context.Context, bundle models.AssessmentBundle, userId string) (*models.ImportBundleResponse, error) {
+	if bundle.Version <= 0 || bundle.Version > constant.AssessmentBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	if bundle.Assessment.Name == "" {
+		return nil, errors.New("bundle assessment name is required")
+	}
+	if len(bundle.Questions) == 0 {
+		return nil, errors.New("bundle has no questions")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var jobID *int64
+	if bundle.Job != nil && bundle.Job.Title != "" {
+		id, err := s.resolveBundleJob(tx, *bundle.Job, userId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		jobID = &id
+	}
+
+	now := time.Now()
+	assessment, err := s.assessmentRepo.CreateAssessment(ctx, tx, &models.AssessmentMst{
+		AssessmentDesc:  bundle.Assessment.Name,
+		CreatedOn:       now,
+		CreatedBy:       userId,
+		IsActive:        true,
+		IsDeleted:       false,
+		ModifiedOn:      now,
+		ModifiedBy:      userId,
+		Duration:        bundle.Assessment.Duration,
+		Marks:           bundle.Assessment.Marks,
+		StartTime:       bundle.Assessment.StartTime,
+		PartnerID:       bundle.Assessment.PartnerID,
+		ValidFrom:       bundle.Assessment.ValidFrom,
+		ValidTo:         bundle.Assessment.ValidTo,
+		NoFixedSchedule: bundle.Assessment.NoFixedSchedule,
+		Instructions:    bundle.Assessment.Instructions,
+		AssessmentType:  bundle.Assessment.AssessmentType,
+		JobID:           jobID,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// imported assessments start as drafts, whatever state they were exported in
+	settings := bundle.Settings
+	clearEnvironmentSettings(&settings)
+	settings.SurveyID = assessment.AssessmentID
+	settings.AssessmentSequence = assessment.AssessmentSequence
+	settings.State = string(constant.Draft)
+	if _, err := s.assessmentRepo.CreateAssessmentExt(ctx, tx, &settings); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for locale, t := range bundle.Assessment.Translations {
+		locale, err := utils.NormalizeLocale(locale)
+		if err != nil || locale == constant.DefaultLocale {
+			continue
+		}
+		if err := s.translationRepo.UpsertAssessmentTranslation(tx, &models.AssessmentTranslation{
+			AssessmentSequence: assessment.AssessmentSequence,
+			Locale:             locale,
+			Title:              t.Title,
+			Instructions:       t.Instructions,
+			CreatedOn:          now,
+			CreatedBy:          userId,
+			ModifiedOn:         now,
+			ModifiedBy:         userId,
+		}); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to save assessment translation: %w", err)
+		}
+	}
+
+	questions := append([]models.BundleQuestion(nil), bundle.Questions...)
+	sort.SliceStable(questions, func(i, j int) bool {
+		return questions[i].Sequence < questions[j].Sequence
+	})
+
+	if _, err := s.assessmentRepo.SaveSheetQuestions(ctx, tx, assessment.AssessmentID, assessment.AssessmentSequence, bundleToSheetQuestions(questions), userId, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, tagReq := range bundle.Tags {
+		tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, userId)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to process tag request for assessment: %w", err)
+		}
+		for _, tagID := range tagIDs {
+			if err := s.assessmentRepo.CreateAssessmentTagMappingWithParents(tx, assessment.AssessmentSequence, tagID, userId); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to create assessment tag mapping: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &models.ImportBundleResponse{
+		AssessmentSequence: assessment.AssessmentSequence,
+		AssessmentName:     assessment.AssessmentDesc,
+		QuestionsCount:     len(questions),
+		JobID:              jobID,
+	}, nil
+}
+
+// resolveBundleJob links the imported assessment to a job description with the same title,
+// creating one when the target environment does not have it yet.
+func (s *AssessmentTransferServiceImpl) resolveBundleJob(tx *gorm.DB, bundleJob models.BundleJob, userId string) (int64, error) {
+	job, err := s.jobRepo.GetByTitle(bundleJob.Title)
+	if err == nil {
+		return job.JobID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	now := time.Now()
+	newJob := &models.JobDescription{
+		CreatedOn:      now,
+		CreatedBy:      userId,
+		IsActive:       true,
+		IsDeleted:      false,
+		ModifiedOn:     now,
+		ModifiedBy:     userId,
+		Title:          bundleJob.Title,
+		Description:    bundleJob.Description,
+		RequiredSkills: bundleJob.RequiredSkills,
+		Level:          bundleJob.Level,
+	}
+	if err := s.jobRepo.Create(tx, newJob); err != nil {
+		return 0, fmt.Errorf("failed to create job description: %w", err)
+	}
+	return newJob.JobID, nil
+}
+
+func bundleToSheetQuestions(questions []models.BundleQuestion) []models.SheetQuestion {
+	sheetQuestions := make([]models.SheetQuestion, 0, len(questions))
+	for _, q := range questions {
+		options := make([]models.SheetOption, 0, len(q.Options))
+		for _, opt := range q.Options {
+			options = append(options, models.SheetOption{
+				Label:        opt.Label,
+				IsCorrect:    opt.IsCorrect,
+				Score:        opt.Score,
+				Translations: normalizeTranslations(opt.Translations),
+				Feedback:     opt.Feedback,
+			})
+		}
+		sheetQuestions = append(sheetQuestions, models.SheetQuestion{
+			Title:             q.Title,
+			MandatoryToAnswer: q.MandatoryToAnswer,
+			QuestionType:      q.QuestionType,
+			Options:           options,
+			Tags:              q.Tags,
+			DifficultyLevel:   q.DifficultyLevel,
+			Points:            q.CorrectPoints,
+			NegativePoints:    q.NegativePoints,
+			DurationInSeconds: q.DurationInSeconds,
+			Translations:      normalizeTranslations(q.Translations),
+			Feedback:          q.Feedback,
+		})
+	}
+	return sheetQuestions
+}
+
+// normalizeTranslations drops translations with an invalid or default locale from imported files.
+func normalizeTranslations(translations map[string]string) map[string]string {
+	var normalized map[string]string
+	for locale, value := range translations {
+		locale, err := utils.NormalizeLocale(locale)
+		if err != nil || locale == constant.DefaultLocale || strings.TrimSpace(value) == "" {
+			continue
+		}
+		if normalized == nil {
+			normalized = map[string]string{}
+		}
+		normalized[locale] = value
+	}
+	return normalized
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AudienceService manages saved audience rules and assigns assessments to the users they match.
+type AudienceService interface {
+	SaveRule(ctx context.Context, req models.SaveAudienceRuleRequest, userId string) (*models.AudienceRule, error)
+	GetRule(ruleID int64) (*models.AudienceRule, error)
+	ListRules(limit, offset int) ([]models.AudienceRule, int64, error)
+	DeleteRule(ruleID int64, userId string) error
+	Preview(req models.AudiencePreviewRequest, limit, offset int) ([]models.AudienceMember, int64, error)
+	AssignAudience(ctx context.Context, req models.AssignAudienceRequest, userId string) (*models.AssessmentAudience, []string, error)
+	ListAudiences(assessmentSeq string) ([]models.AssessmentAudience, error)
+	RemoveAudience(audienceID int64, userId string) error
+	Task() ScheduledTask
+	SyncAudiences(ctx context.Context, tx *gorm.DB) error
+}
+
+type AudienceServiceImpl struct {
+	audienceRepo        repository.AudienceRepository
+	assessmentRepo      repository.AssessmentRepository
+	notificationService NotificationService
+	webhookService      WebhookService
+	db                  *gorm.DB
+}
+
+func NewAudienceService(audienceRepo repository.AudienceRepository, assessmentRepo repository.AssessmentRepository, notificationService NotificationService, webhookService WebhookService, db *gorm.DB) AudienceService {
+	return &AudienceServiceImpl{
+		audienceRepo:        audienceRepo,
+		assessmentRepo:      assessmentRepo,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		db:                  db,
+	}
+}
+
+func (s *AudienceServiceImpl) SaveRule(ctx context.Context, req models.SaveAudienceRuleRequest, userId string) (*models.AudienceRule, error) {
+	conditions, err := buildAudienceConditions(req.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	rule := &models.AudienceRule{
+		CreatedOn:   now,
+		CreatedBy:   userId,
+		IsActive:    true,
+		ModifiedOn:  now,
+		ModifiedBy:  userId,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Conditions:  conditions,
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if req.RuleID != nil {
+		if _, err := s.audienceRepo.GetRule(*req.RuleID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("audience rule %d not found: %w", *req.RuleID, err)
+		}
+		rule.RuleID = *req.RuleID
+		if err := s.audienceRepo.UpdateRule(tx, rule); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if err := s.audienceRepo.CreateRule(tx, rule); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return s.audienceRepo.GetRule(rule.RuleID)
+}
+
+func (s *AudienceServiceImpl) GetRule(ruleID int64) (*models.AudienceRule, error) {
+	return s.audienceRepo.GetRule(ruleID)
+}
+
+func (s *AudienceServiceImpl) ListRules(limit, offset int) ([]models.AudienceRule, int64, error) {
+	return s.audienceRepo.ListRules(limit, offset)
+}
+
+// DeleteRule refuses rules still assigning an assessment; remove those audiences first.
+func (s *AudienceServiceImpl) DeleteRule(ruleID int64, userId string) error {
+	inUse, err := s.audienceRepo.CountActiveAudiences(ruleID)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return fmt.Errorf("audience rule is assigned to %d assessments", inUse)
+	}
+	return s.audienceRepo.DeleteRule(ruleID, userId)
+}
+
+// Preview lists who a saved rule, or the unsaved conditions, match right now.
+func (s *AudienceServiceImpl) Preview(req models.AudiencePreviewRequest, limit, offset int) ([]models.AudienceMember, int64, error) {
+	var conditions []models.AudienceCondition
+	if req.RuleID != nil {
+		rule, err := s.audienceRepo.GetRule(*req.RuleID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("audience rule %d not found: %w", *req.RuleID, err)
+		}
+		conditions = rule.Conditions
+	} else {
+		var err error
+		if conditions, err = buildAudienceConditions(req.Conditions); err != nil {
+			return nil, 0, err
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, 0, errors.New("a rule_id or at least one condition is required")
+	}
+
+	members, total, err := s.audienceRepo.MatchUsers(conditions, req.AssessmentSequence, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if members == nil {
+		members = []models.AudienceMember{}
+	}
+	return members, total, nil
+}
+
+// AssignAudience assigns the assessment to every matching user who does not have it yet,
+// notifies them, and keeps the rule on the assessment. It returns the newly assigned users.
+func (s *AudienceServiceImpl) AssignAudience(ctx context.Context, req models.AssignAudienceRequest, userId string) (*models.AssessmentAudience, []string, error) {
+	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, nil, err
+	}
+	if asmt == nil {
+		return nil, nil, errors.New("assessment not found")
+	}
+	rule, err := s.audienceRepo.GetRule(req.RuleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("audience rule %d not found: %w", req.RuleID, err)
+	}
+
+	now := time.Now()
+	tx := s.db.WithContext(ctx).Begin()
+	audience, err := s.audienceRepo.GetAudience(tx, req.AssessmentSequence, req.RuleID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if audience == nil {
+		audience = &models.AssessmentAudience{
+			AssessmentSequence: req.AssessmentSequence,
+			RuleID:             req.RuleID,
+			CreatedOn:          now,
+			CreatedBy:          userId,
+		}
+	}
+	audience.AutoAssign = req.AutoAssign
+	audience.IsActive = true
+	audience.LastSyncedOn = &now
+	audience.ModifiedOn = now
+	audience.ModifiedBy = userId
+
+	userIDs, err := s.audienceRepo.GetUnassignedMatches(tx, rule.Conditions, req.AssessmentSequence)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := s.audienceRepo.AssignUsers(tx, req.AssessmentSequence, userIDs, userId); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := s.audienceRepo.SaveAudience(tx, audience); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := s.notificationService.QueueDistributeAssessmentMail(tx, userIDs, req.AssessmentSequence, false); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := s.webhookService.PublishAssignments(tx, req.AssessmentSequence, userIDs); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	audience.RuleName = rule.Name
+	return audience, userIDs, nil
+}
+
+func (s *AudienceServiceImpl) ListAudiences(assessmentSeq string) ([]models.AssessmentAudience, error) {
+	audiences, err := s.audienceRepo.ListAudiences(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if audiences == nil {
+		audiences = []models.AssessmentAudience{}
+	}
+	return audiences, nil
+}
+
+// RemoveAudience stops auto-assignment; users already assigned keep the assessment.
+func (s *AudienceServiceImpl) RemoveAudience(audienceID int64, userId string) error {
+	return s.audienceRepo.DeactivateAudience(audienceID, userId)
+}
+
+func (s *AudienceServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "audience-sync",
+		Interval: config.PropConfig.Scheduler.AudienceInterval,
+		Run:      s.SyncAudiences,
+	}
+}
+
+// SyncAudiences assigns auto-assigning audiences to the users who started matching their rule
+// since the last run, new users included.
+func (s *AudienceServiceImpl) SyncAudiences(ctx context.Context, tx *gorm.DB) error {
+	audiences, err := s.audienceRepo.GetAutoAssignAudiences(tx)
+	if err != nil {
+		return fmt.Errorf("failed to load audiences: %w", err)
+	}
+
+	now := time.Now()
+	for i := range audiences {
+		audience := &audiences[i]
+		rule, err := s.audienceRepo.GetRule(audience.RuleID)
+		if err != nil {
+			return fmt.Errorf("audience rule %d: %w", audience.RuleID, err)
+		}
+		userIDs, err := s.audienceRepo.GetUnassignedMatches(tx, rule.Conditions, audience.AssessmentSequence)
+		if err != nil {
+			return err
+		}
+		if err := s.audienceRepo.AssignUsers(tx, audience.AssessmentSequence, userIDs, schedulerUser); err != nil {
+			return err
+		}
+		audience.LastSyncedOn = &now
+		if err := s.audienceRepo.SaveAudience(tx, audience); err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			continue
+		}
+
+		log.Printf("Assigned assessment %s to %d users matching rule %q", audience.AssessmentSequence, len(userIDs), rule.Name)
+		if err := s.notificationService.QueueDistributeAssessmentMail(tx, userIDs, audience.AssessmentSequence, false); err != nil {
+			return err
+		}
+		if err := s.webhookService.PublishAssignments(tx, audience.AssessmentSequence, userIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildAudienceConditions validates the requested conditions against the known fields.
+func buildAudienceConditions(reqs []models.AudienceConditionRequest) ([]models.AudienceCondition, error) {
+	known := make(map[string]bool)
+	for _, field := range repository.AudienceFields() {
+		known[field] = true
+	}
+
+	conditions := make([]models.AudienceCondition, 0, len(reqs))
+	for idx, c := range reqs {
+		field := strings.ToLower(strings.TrimSpace(c.Field))
+		if !known[field] {
+			return nil, fmt.Errorf("condition %d: unknown field %q, expected one of %s", idx+1, c.Field, strings.Join(repository.AudienceFields(), ", "))
+		}
+		if (c.Operator == "eq" || c.Operator == "neq") && len(c.Values) != 1 {
+			return nil, fmt.Errorf("condition %d: %s takes exactly one value", idx+1, c.Operator)
+		}
+		conditions = append(conditions, models.AudienceCondition{
+			SequenceID: int64(idx + 1),
+			Field:      field,
+			Operator:   c.Operator,
+			Values:     c.Values,
+		})
+	}
+	return conditions, nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BlueprintService interface {
+	SaveBlueprint(ctx context.Context, req models.SaveBlueprintRequest, userId string) (*models.AssessmentBlueprint, error)
+	GetBlueprint(blueprintID int64) (*models.AssessmentBlueprint, error)
+	ListBlueprints(limit, offset int) ([]models.AssessmentBlueprint, int64, error)
+	DeleteBlueprint(blueprintID int64, userId string) error
+	AssembleAssessment(ctx context.Context, req models.AssembleBlueprintRequest, userId string) (*models.BlueprintAssemblyResponse, error)
+}
+
+type BlueprintServiceImpl struct {
+	blueprintRepo  repository.BlueprintRepository
+	assessmentRepo repository.AssessmentRepository
+	questionRepo   repository.QuestionRepository
+	db             *gorm.DB
+}
+
+func NewBlueprintService(blueprintRepo repository.BlueprintRepository, assessmentRepo repository.AssessmentRepository, questionRepo repository.QuestionRepository, db *gorm.DB) BlueprintService {
+	return &BlueprintServiceImpl{
+		blueprintRepo:  blueprintRepo,
+		assessmentRepo: assessmentRepo,
+		questionRepo:   questionRepo,
+		db:             db,
+	}
+}
+
+func (s *BlueprintServiceImpl) SaveBlueprint(ctx context.Context, req models.SaveBlueprintRequest, userId string) (*models.AssessmentBlueprint, error) {
+	now := time.Now()
+	blueprint := &models.AssessmentBlueprint{
+		CreatedOn:      now,
+		CreatedBy:      userId,
+		IsActive:       true,
+		IsDeleted:      false,
+		ModifiedOn:     now,
+		ModifiedBy:     userId,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		AssessmentType: req.AssessmentType,
+		Duration:       req.Duration,
+	}
+
+	for idx, c := range req.Constraints {
+		tag := normalizeTagPath(c.Tag)
+		if tag == "" && c.DifficultyLevel == "" {
+			return nil, fmt.Errorf("constraint %d needs a tag or a difficulty level", idx+1)
+		}
+		blueprint.Constraints = append(blueprint.Constraints, models.BlueprintConstraint{
+			SequenceID:        int64(idx + 1),
+			Tag:               tag,
+			DifficultyLevel:   strings.ToLower(c.DifficultyLevel),
+			QuestionCount:     c.QuestionCount,
+			Points:            c.Points,
+			NegativePoints:    c.NegativePoints,
+			DurationInSeconds: c.DurationInSeconds,
+		})
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if req.BlueprintID != nil {
+		if _, err := s.blueprintRepo.GetByID(*req.BlueprintID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("blueprint %d not found: %w", *req.BlueprintID, err)
+		}
+		blueprint.BlueprintID = *req.BlueprintID
+		if err := s.blueprintRepo.Update(tx, blueprint); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if err := s.blueprintRepo.Create(tx, blueprint); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return s.blueprintRepo.GetByID(blueprint.BlueprintID)
+}
+
+func (s *BlueprintServiceImpl) GetBlueprint(blueprintID int64) (*models.AssessmentBlueprint, error) {
+	return s.blueprintRepo.GetByID(blueprintID)
+}
+
+func (s *BlueprintServiceImpl) ListBlueprints(limit, offset int) ([]models.AssessmentBlueprint, int64, error) {
+	return s.blueprintRepo.List(limit, offset)
+}
+
+func (s *BlueprintServiceImpl) DeleteBlueprint(blueprintID int64, userId string) error {
+	return s.blueprintRepo.Delete(blueprintID, userId)
+}
+
+// AssembleAssessment fills every constraint of a blueprint from the question bank and saves
+// the result as a draft assessment. Questions used by earlier forms of the same blueprint are
+// picked last, so each run gives a fresh but equivalent form. When the bank cannot satisfy the
+// blueprint nothing is saved unless AllowPartial is set; the shortfall is reported per constraint.
+func (s *BlueprintServiceImpl) AssembleAssessment(ctx context.Context, req models.AssembleBlueprintRequest, userId string) (*models.BlueprintAssemblyResponse, error) {
+	blueprint, err := s.blueprintRepo.GetByID(req.BlueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("blueprint %d not found: %w", req.BlueprintID, err)
+	}
+
+	picks, fulfilment, satisfied, err := selectBlueprintQuestions(s.blueprintRepo, s.questionRepo, blueprint)
+	if err != nil {
+		return nil, err
+	}
+
+	assessmentName := strings.TrimSpace(req.AssessmentName)
+	if assessmentName == "" {
+		now := time.Now()
+		assessmentName = fmt.Sprintf("%s - Q%d %d", blueprint.Name, (int(now.Month())-1)/3+1, now.Year())
+	}
+
+	var marks int64
+	for _, p := range picks {
+		marks += blueprintPoints(p.constraint)
+	}
+
+	resp := &models.BlueprintAssemblyResponse{
+		BlueprintID:    blueprint.BlueprintID,
+		AssessmentName: assessmentName,
+		QuestionsCount: len(picks),
+		Marks:          marks,
+		Satisfied:      satisfied,
+		Constraints:    fulfilment,
+	}
+	if req.DryRun || (!satisfied && !req.AllowPartial) {
+		return resp, nil
+	}
+	if len(picks) == 0 {
+		return nil, errors.New("no questions in the bank match this blueprint")
+	}
+
+	settings := models.DefaultAssessmentSettings()
+	settings.Duration = blueprint.Duration
+	if blueprint.AssessmentType != "" {
+		settings.AssessmentType = blueprint.AssessmentType
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	blueprintID := blueprint.BlueprintID
+	assessment, err := createDraftAssessment(ctx, tx, s.assessmentRepo, draftAssessment{
+		Name:        assessmentName,
+		Settings:    settings,
+		Marks:       marks,
+		BlueprintID: &blueprintID,
+	}, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := mapBlueprintPicks(ctx, tx, assessment, picks, userId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, f := range fulfilment {
+		if f.Tag == "" || f.Selected == 0 {
+			continue
+		}
+		parent, tag := splitTagPath(f.Tag)
+		tagReq := models.TagRequest{ParentTag: parent, ChildTags: []string{tag}}
+		tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, userId)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to process blueprint tag %q: %w", f.Tag, err)
+		}
+		for _, tagID := range tagIDs {
+			if err := s.assessmentRepo.CreateAssessmentTagMappingWithParents(tx, assessment.AssessmentSequence, tagID, userId); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to create assessment tag mapping: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	resp.AssessmentSequence = assessment.AssessmentSequence
+	return resp, nil
+}
+
+// blueprintPick is a bank question chosen for one blueprint constraint.
+type blueprintPick struct {
+	question   models.BankQuestion
+	constraint models.BlueprintConstraint
+}
+
+// selectBlueprintQuestions fills every constraint of a blueprint from the bank without saving anything.
+func selectBlueprintQuestions(blueprintRepo repository.BlueprintRepository, questionRepo repository.QuestionRepository, blueprint *models.AssessmentBlueprint) ([]blueprintPick, []models.ConstraintFulfilment, bool, error) {
+	previousForms, err := blueprintRepo.GetFormQuestionIDs(blueprint.BlueprintID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var picks []blueprintPick
+	var selectedIDs []int64
+	satisfied := true
+	fulfilment := make([]models.ConstraintFulfilment, 0, len(blueprint.Constraints))
+
+	for _, c := range blueprint.Constraints {
+		parent, tag := splitTagPath(c.Tag)
+		rows, err := questionRepo.FindBlueprintQuestions(parent, tag, c.DifficultyLevel, c.QuestionCount, selectedIDs, previousForms)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to fetch questions for constraint %d: %w", c.SequenceID, err)
+		}
+		for _, row := range rows {
+			picks = append(picks, blueprintPick{question: row, constraint: c})
+			selectedIDs = append(selectedIDs, row.QuestionID)
+		}
+
+		shortfall := c.QuestionCount - len(rows)
+		if shortfall > 0 {
+			satisfied = false
+		}
+		fulfilment = append(fulfilment, models.ConstraintFulfilment{
+			Sequence:        c.SequenceID,
+			Tag:             c.Tag,
+			DifficultyLevel: c.DifficultyLevel,
+			Requested:       c.QuestionCount,
+			Selected:        len(rows),
+			Shortfall:       shortfall,
+		})
+	}
+	return picks, fulfilment, satisfied, nil
+}
+
+// mapBlueprintPicks links the picked bank questions to an assessment in pick order.
+func mapBlueprintPicks(ctx context.Context, tx *gorm.DB, assessment *models.AssessmentMst, picks []blueprintPick, userId string) error {
+	now := time.Now()
+	for idx, p := range picks {
+		difficulty := p.constraint.DifficultyLevel
+		if difficulty == "" {
+			difficulty = p.question.DifficultyLevel
+		}
+		mapping := models.AssessmentQuestionMst{
+			CreatedOn:          now,
+			CreatedBy:          userId,
+			IsActive:           true,
+			IsDeleted:          false,
+			ModifiedOn:         now,
+			ModifiedBy:         userId,
+			AssessmentSequence: assessment.AssessmentSequence,
+			AssessmentID:       int(assessment.AssessmentID),
+			QuestionID:         p.question.QuestionID,
+			SequenceID:         int64(idx + 1),
+			CorrectPoints:      blueprintPoints(p.constraint),
+			NegativePoints:     p.constraint.NegativePoints,
+			DurationInSeconds:  p.constraint.DurationInSeconds,
+			DifficultyLevel:    difficulty,
+		}
+		if err := tx.WithContext(ctx).Create(&mapping).Error; err != nil {
+			return fmt.Errorf("failed to map bank question %d: %w", p.question.QuestionID, err)
+		}
+	}
+	return nil
+}
+
+// blueprintPoints defaults constraints without explicit points to one point per question.
+func blueprintPoints(c models.BlueprintConstraint) int64 {
+	if c.Points > 0 {
+		return c.Points
+	}
+	return 1
+}
+
+// normalizeTagPath trims the segments of a "Parent/Child" tag path.
+func normalizeTagPath(tag string) string {
+	var segments []string
+	for _, seg := range strings.Split(tag, "/") {
+		if seg = strings.TrimSpace(seg); seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// splitTagPath returns the parent and tag of a path; deeper paths use their last two segments.
+func splitTagPath(path string) (string, string) {
+	segments := strings.Split(normalizeTagPath(path), "/")
+	switch len(segments) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", segments[0]
+	default:
+		return segments[len(segments)-2], segments[len(segments)-1]
+	}
+}
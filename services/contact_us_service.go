@@ -1,21 +1,35 @@
 package services
 
 import (
+	"context"
 	"dhl/models"
 	"dhl/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
+// ErrContactNotUser is returned when a contact form message came from an address no user has,
+// so there is nobody to send a reply to.
+var ErrContactNotUser = errors.New("the sender has no user account to reply to")
+
 type ContactService interface {
 	Submit(response *models.ContactUsResponse) error
 	List() ([]models.ContactUsResponse, error)
+	Reply(ctx context.Context, id uint, req models.ContactReplyRequest) error
 }
 
 type ContactServiceImpl struct {
-	repo repository.ContactRepository
+	repo                repository.ContactRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	db                  *gorm.DB
 }
 
-func NewContactService(repo repository.ContactRepository) ContactService {
-	return &ContactServiceImpl{repo}
+func NewContactService(repo repository.ContactRepository, userRepo repository.UserRepository, notificationService NotificationService, db *gorm.DB) ContactService {
+	return &ContactServiceImpl{repo: repo, userRepo: userRepo, notificationService: notificationService, db: db}
 }
 
 func (s *ContactServiceImpl) Submit(response *models.ContactUsResponse) error {
@@ -25,3 +39,24 @@ func (s *ContactServiceImpl) Submit(response *models.ContactUsResponse) error {
 func (s *ContactServiceImpl) List() ([]models.ContactUsResponse, error) {
 	return s.repo.GetAll()
 }
+
+// Reply mails an answer to the user who sent a contact form message. Replies are marketing
+// notifications, so they follow the user's preferences for that category.
+func (s *ContactServiceImpl) Reply(ctx context.Context, id uint, req models.ContactReplyRequest) error {
+	reply := strings.TrimSpace(req.Reply)
+	if reply == "" {
+		return errors.New("reply is required")
+	}
+	contact, err := s.repo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("contact message %d not found: %w", id, err)
+	}
+	userId, err := s.userRepo.FindUserIdByEmail(contact.Email)
+	if err != nil {
+		return err
+	}
+	if userId == "" {
+		return ErrContactNotUser
+	}
+	return s.notificationService.QueueContactReplyMail(s.db.WithContext(ctx), userId, *contact, reply)
+}
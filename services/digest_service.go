@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DigestService sends managers and admins a daily or weekly digest of their team's
+// completions, failures, overdue assignments and coming deadlines, instead of a notification
+// per event.
+type DigestService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, tx *gorm.DB) error
+	GetSubscription(userID string) (*models.DigestSubscription, error)
+	SaveSubscription(ctx context.Context, userID, role string, req models.SaveDigestSubscriptionRequest) (*models.DigestSubscription, error)
+	Build(managerID string, from, to time.Time) (*models.Digest, error)
+	Preview(recipientID, managerID string, from, to time.Time) (*models.RenderedNotification, error)
+}
+
+type DigestServiceImpl struct {
+	digestRepo          repository.DigestRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	templateService     NotificationTemplateService
+	db                  *gorm.DB
+}
+
+func NewDigestService(digestRepo repository.DigestRepository, userRepo repository.UserRepository, notificationService NotificationService, templateService NotificationTemplateService, db *gorm.DB) DigestService {
+	return &DigestServiceImpl{
+		digestRepo:          digestRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		templateService:     templateService,
+		db:                  db,
+	}
+}
+
+func (s *DigestServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "digests",
+		Interval: config.PropConfig.Scheduler.DigestInterval,
+		Run:      s.RunDue,
+	}
+}
+
+// digestDue tells whether a subscriber's digest goes out today, the local date of now in loc.
+func digestDue(sub models.DigestSubscription, now time.Time, loc *time.Location) bool {
+	if sub.LastSentOn != nil && daysBetween(*sub.LastSentOn, now, loc) == 0 {
+		return false
+	}
+	switch sub.Frequency {
+	case constant.DigestDaily:
+		return true
+	case constant.DigestWeekly:
+		return int(now.In(loc).Weekday()) == sub.Weekday
+	}
+	return false
+}
+
+// digestPeriodStart is where a digest sent at now starts: the last digest, so runs at other
+// hours neither skip nor repeat anything, unless that is over two periods ago, as after a
+// subscription was paused. Then it covers one period.
+func digestPeriodStart(sub models.DigestSubscription, now time.Time) time.Time {
+	days := 1
+	if sub.Frequency == constant.DigestWeekly {
+		days = 7
+	}
+	if sub.LastSentOn != nil && sub.LastSentOn.After(now.AddDate(0, 0, -2*days)) {
+		return *sub.LastSentOn
+	}
+	return now.AddDate(0, 0, -days)
+}
+
+// RunDue queues the digests due today, from the configured send hour on. Subscribers with
+// nothing to report get no digest, but their period still moves on.
+func (s *DigestServiceImpl) RunDue(ctx context.Context, tx *gorm.DB) error {
+	loc := reminderLocation()
+	now := time.Now()
+	if now.In(loc).Hour() < config.PropConfig.Digests.SendHour {
+		return nil
+	}
+
+	subs, err := s.digestRepo.GetActiveSubscriptions(tx)
+	if err != nil {
+		return fmt.Errorf("failed to load digest subscriptions: %w", err)
+	}
+	sent := 0
+	for _, sub := range subs {
+		if !digestDue(sub, now, loc) {
+			continue
+		}
+		managerID := sub.UserID
+		if sub.Scope == constant.DigestScopeAll {
+			managerID = ""
+		}
+		digest, err := s.Build(managerID, digestPeriodStart(sub, now), now)
+		if err != nil {
+			log.Printf("[ERROR] failed to build the digest of %s: %v", sub.UserID, err)
+			continue
+		}
+		if !digest.IsEmpty() {
+			if err := s.notificationService.QueueDigestMail(tx, sub.UserID, digestTemplateData(digest)); err != nil {
+				return fmt.Errorf("failed to queue the digest of %s: %w", sub.UserID, err)
+			}
+			sent++
+		}
+		if err := s.digestRepo.MarkSent(tx, sub.UserID, now); err != nil {
+			return err
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("Queued %d digests", sent)
+	}
+	return nil
+}
+
+// GetSubscription returns the user's subscription, switched off for users who never
+// subscribed.
+func (s *DigestServiceImpl) GetSubscription(userID string) (*models.DigestSubscription, error) {
+	sub, err := s.digestRepo.GetSubscription(userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		sub = &models.DigestSubscription{UserID: userID, Frequency: constant.DigestOff, Weekday: int(time.Monday), Scope: constant.DigestScopeTeam}
+	}
+	return sub, nil
+}
+
+// SaveSubscription sets how often the user gets their digest. Admins default to the digest of
+// every user; everyone else only gets their team's.
+func (s *DigestServiceImpl) SaveSubscription(ctx context.Context, userID, role string, req models.SaveDigestSubscriptionRequest) (*models.DigestSubscription, error) {
+	isAdmin := role == string(constant.Admin)
+	if req.Scope == constant.DigestScopeAll && !isAdmin {
+		return nil, errors.New("only admins can subscribe to the digest of every user")
+	}
+
+	sub, err := s.digestRepo.GetSubscription(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if sub == nil {
+		sub = &models.DigestSubscription{UserID: userID, Weekday: int(time.Monday), CreatedOn: now}
+		if isAdmin {
+			sub.Scope = constant.DigestScopeAll
+		} else {
+			sub.Scope = constant.DigestScopeTeam
+		}
+	}
+	sub.Frequency = req.Frequency
+	if req.Weekday != nil {
+		sub.Weekday = *req.Weekday
+	}
+	if req.Scope != "" {
+		sub.Scope = req.Scope
+	}
+	sub.ModifiedOn = now
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := s.digestRepo.SaveSubscription(tx, sub); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Build sums up from until to for a manager's team, or for every user when managerID is empty.
+// Overdue and coming assignments are those unfinished now, due before to and within the
+// configured days after it.
+func (s *DigestServiceImpl) Build(managerID string, from, to time.Time) (*models.Digest, error) {
+	if !from.Before(to) {
+		return nil, errors.New("the digest period must end after it starts")
+	}
+	cfg := config.PropConfig.Digests
+	digest := &models.Digest{
+		ManagerID:   managerID,
+		From:        from,
+		To:          to,
+		Completions: []models.DigestEntry{},
+		Failures:    []models.DigestEntry{},
+		Overdue:     []models.DigestEntry{},
+		Upcoming:    []models.DigestEntry{},
+	}
+
+	completions, err := s.digestRepo.GetCompletions(managerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range completions {
+		if e.Passed {
+			digest.CompletionCount++
+			if len(digest.Completions) < cfg.MaxEntries {
+				digest.Completions = append(digest.Completions, e)
+			}
+		} else {
+			digest.FailureCount++
+			if len(digest.Failures) < cfg.MaxEntries {
+				digest.Failures = append(digest.Failures, e)
+			}
+		}
+	}
+
+	open, err := s.digestRepo.GetOpenAssignments(managerID, to.AddDate(0, 0, cfg.UpcomingDays))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range open {
+		if e.Date.Before(to) {
+			digest.OverdueCount++
+			if len(digest.Overdue) < cfg.MaxEntries {
+				digest.Overdue = append(digest.Overdue, e)
+			}
+		} else {
+			digest.UpcomingCount++
+			if len(digest.Upcoming) < cfg.MaxEntries {
+				digest.Upcoming = append(digest.Upcoming, e)
+			}
+		}
+	}
+	return digest, nil
+}
+
+// digestTemplateData is the data the digest template is executed with.
+func digestTemplateData(d *models.Digest) map[string]interface{} {
+	entries := func(list []models.DigestEntry) []map[string]interface{} {
+		out := make([]map[string]interface{}, len(list))
+		for i, e := range list {
+			score := ""
+			if e.Percentage != nil {
+				score = fmt.Sprintf("%.0f%%", *e.Percentage)
+			}
+			out[i] = map[string]interface{}{
+				"userName":           e.UserName,
+				"assessmentSequence": e.AssessmentSequence,
+				"assessmentName":     e.AssessmentName,
+				"date":               e.Date,
+				"score":              score,
+			}
+		}
+		return out
+	}
+	return map[string]interface{}{
+		"from":            d.From,
+		"to":              d.To,
+		"teamOnly":        d.ManagerID != "",
+		"completions":     entries(d.Completions),
+		"failures":        entries(d.Failures),
+		"overdue":         entries(d.Overdue),
+		"upcoming":        entries(d.Upcoming),
+		"completionCount": d.CompletionCount,
+		"failureCount":    d.FailureCount,
+		"overdueCount":    d.OverdueCount,
+		"upcomingCount":   d.UpcomingCount,
+	}
+}
+
+// Preview renders the digest of managerID's team, or of every user without one, as the
+// recipient would receive it.
+func (s *DigestServiceImpl) Preview(recipientID, managerID string, from, to time.Time) (*models.RenderedNotification, error) {
+	recipient, err := s.userRepo.FindByUserId(recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("recipient %s: %w", recipientID, err)
+	}
+	digest, err := s.Build(managerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	data := digestTemplateData(digest)
+	data["userName"] = fmt.Sprintf("%s %s", recipient.FirstName, recipient.LastName)
+	data["assessmentLink"] = config.PropConfig.Notify.AssessmentLink
+	return s.templateService.Render(constant.TemplateDigest, recipient.Locale, data)
+}
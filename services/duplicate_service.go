@@ -0,0 +1,329 @@
+package services
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DuplicateCandidate is an incoming question screened against the bank before import.
+type DuplicateCandidate struct {
+	Title              string
+	Options            []string
+	DuplicateAction    string
+	ExistingQuestionID int64
+}
+
+type DuplicateService interface {
+	Screen(candidates []DuplicateCandidate, defaultAction string, threshold float64) ([]models.DuplicateWarning, error)
+	ListClusters(threshold float64, limit, offset int) ([]models.DuplicateCluster, int64, error)
+}
+
+type DuplicateServiceImpl struct {
+	questionRepo repository.QuestionRepository
+
+	mu   sync.Mutex
+	bank *bankSnapshot
+}
+
+func NewDuplicateService(questionRepo repository.QuestionRepository) DuplicateService {
+	return &DuplicateServiceImpl{questionRepo: questionRepo}
+}
+
+type bankEntry struct {
+	question    models.BankQuestionText
+	title       string
+	options     []string
+	fingerprint utils.QuestionFingerprint
+}
+
+// bankSnapshot is the indexed bank at one version. It is never changed once built, so
+// concurrent screens share it without locking.
+type bankSnapshot struct {
+	version models.BankVersion
+	entries map[int64]*bankEntry
+	index   *utils.SimilarityIndex
+}
+
+// loadBank returns the indexed bank, rebuilding it only when the bank version moved since
+// the last build. The version is read from the database, so writes made by other replicas
+// are picked up as well.
+func (s *DuplicateServiceImpl) loadBank() (map[int64]*bankEntry, *utils.SimilarityIndex, error) {
+	version, err := s.questionRepo.GetBankVersion()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read question bank version: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bank != nil && s.bank.version.Equal(version) {
+		return s.bank.entries, s.bank.index, nil
+	}
+
+	rows, err := s.questionRepo.GetBankQuestionTexts()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load question bank: %w", err)
+	}
+
+	bank := make(map[int64]*bankEntry, len(rows))
+	index := utils.NewSimilarityIndex()
+	for _, row := range rows {
+		title, _ := utils.RenderContent(row.ContentType, row.Value)
+		entry := &bankEntry{question: row, title: title}
+		if row.Options != "" {
+			for _, opt := range strings.Split(row.Options, "\x1f") {
+				contentType, value, _ := strings.Cut(opt, "\x1e")
+				label, _ := utils.RenderContent(contentType, value)
+				entry.options = append(entry.options, label)
+			}
+		}
+		entry.fingerprint = utils.NewQuestionFingerprint(entry.title, entry.options)
+		bank[row.QuestionID] = entry
+		index.Add(row.QuestionID, entry.fingerprint)
+	}
+	s.bank = &bankSnapshot{version: version, entries: bank, index: index}
+	return bank, index, nil
+}
+
+// Screen compares incoming questions with the bank and with each other. Only questions with
+// a match get a warning; its action is the question's own choice or defaultAction. Merging
+// needs a bank match (or an explicit ExistingQuestionID) and falls back to warn otherwise.
+func (s *DuplicateServiceImpl) Screen(candidates []DuplicateCandidate, defaultAction string, threshold float64) ([]models.DuplicateWarning, error) {
+	importAction, err := parseDuplicateAction(defaultAction, constant.DuplicateWarn)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 || threshold > 1 {
+		threshold = constant.DuplicateThreshold
+	}
+
+	bank, index, err := s.loadBank()
+	if err != nil {
+		return nil, err
+	}
+	batch := utils.NewSimilarityIndex()
+
+	var warnings []models.DuplicateWarning
+	for i, c := range candidates {
+		action, err := parseDuplicateAction(c.DuplicateAction, importAction)
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", i+1, err)
+		}
+
+		fp := utils.NewQuestionFingerprint(c.Title, c.Options)
+		warning := models.DuplicateWarning{Index: i, Title: c.Title, Action: string(action)}
+		for _, m := range index.Query(fp, threshold) {
+			warning.Matches = append(warning.Matches, duplicateMatch(bank[m.ID], m))
+		}
+		for _, m := range batch.Query(fp, threshold) {
+			warning.BatchMatches = append(warning.BatchMatches, int(m.ID))
+		}
+		sort.Ints(warning.BatchMatches)
+		batch.Add(int64(i), fp)
+
+		if c.ExistingQuestionID > 0 && action == constant.DuplicateMerge {
+			entry, ok := bank[c.ExistingQuestionID]
+			if !ok {
+				return nil, fmt.Errorf("question %d: bank question %d not found", i+1, c.ExistingQuestionID)
+			}
+			if !hasDuplicateMatch(warning.Matches, c.ExistingQuestionID) {
+				score, text, options := fp.Similarity(entry.fingerprint)
+				warning.Matches = append(warning.Matches, duplicateMatch(entry, utils.SimilarityMatch{
+					ID: c.ExistingQuestionID, Score: score, TextSimilarity: text, OptionSimilarity: options,
+				}))
+			}
+			warning.QuestionID = c.ExistingQuestionID
+		}
+
+		if len(warning.Matches) == 0 && len(warning.BatchMatches) == 0 {
+			continue
+		}
+		if action == constant.DuplicateMerge && warning.QuestionID == 0 {
+			if len(warning.Matches) > 0 {
+				warning.QuestionID = warning.Matches[0].QuestionID
+			} else {
+				warning.Action = string(constant.DuplicateWarn)
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
+
+// ListClusters groups the bank into near-duplicate clusters, largest first.
+func (s *DuplicateServiceImpl) ListClusters(threshold float64, limit, offset int) ([]models.DuplicateCluster, int64, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = constant.DuplicateThreshold
+	}
+
+	bank, index, err := s.loadBank()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	groups, scores := index.Clusters(threshold)
+	clusters := make([]models.DuplicateCluster, 0, len(groups))
+	for i, ids := range groups {
+		cluster := models.DuplicateCluster{Score: scores[i]}
+		for _, id := range ids {
+			entry := bank[id]
+			cluster.Questions = append(cluster.Questions, models.DuplicateClusterQuestion{
+				QuestionID: id,
+				Title:      entry.title,
+				Options:    entry.options,
+			})
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Questions) != len(clusters[j].Questions) {
+			return len(clusters[i].Questions) > len(clusters[j].Questions)
+		}
+		return clusters[i].Score > clusters[j].Score
+	})
+
+	total := int64(len(clusters))
+	if offset >= len(clusters) {
+		return []models.DuplicateCluster{}, total, nil
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(clusters) {
+		end = len(clusters)
+	}
+	page := clusters[offset:end]
+
+	// usage changes without touching the questions, so it is counted for the page only
+	var ids []int64
+	for _, cluster := range page {
+		for _, q := range cluster.Questions {
+			ids = append(ids, q.QuestionID)
+		}
+	}
+	counts, err := s.questionRepo.GetQuestionAssessmentCounts(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byQuestion := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		byQuestion[c.QuestionID] = c.AssessmentCount
+	}
+	for i := range page {
+		for j := range page[i].Questions {
+			page[i].Questions[j].AssessmentCount = byQuestion[page[i].Questions[j].QuestionID]
+		}
+	}
+	return page, total, nil
+}
+
+func duplicateMatch(entry *bankEntry, m utils.SimilarityMatch) models.DuplicateMatch {
+	return models.DuplicateMatch{
+		QuestionID:       m.ID,
+		Title:            entry.title,
+		Score:            m.Score,
+		TextSimilarity:   m.TextSimilarity,
+		OptionSimilarity: m.OptionSimilarity,
+	}
+}
+
+func hasDuplicateMatch(matches []models.DuplicateMatch, questionID int64) bool {
+	for _, m := range matches {
+		if m.QuestionID == questionID {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDuplicateAction(value string, fallback constant.DuplicateAction) (constant.DuplicateAction, error) {
+	switch action := constant.DuplicateAction(strings.ToLower(strings.TrimSpace(value))); action {
+	case "":
+		return fallback, nil
+	case constant.DuplicateWarn, constant.DuplicateSkip, constant.DuplicateMerge:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unsupported duplicate action %q: use warn, skip or merge", value)
+	}
+}
+
+// duplicateWarningsByIndex indexes screening results by the position of the question.
+func duplicateWarningsByIndex(warnings []models.DuplicateWarning) map[int]models.DuplicateWarning {
+	byIndex := make(map[int]models.DuplicateWarning, len(warnings))
+	for _, w := range warnings {
+		byIndex[w.Index] = w
+	}
+	return byIndex
+}
+
+// screenSheetQuestions applies duplicate handling to an imported sheet before it is saved:
+// skipped questions are dropped, merged ones point at their bank question, and every match
+// is reported on the sheet. Warning indexes refer to the questions as they were in the file.
+func screenSheetQuestions(duplicateService DuplicateService, sheet *models.SheetAssessment, duplicateAction string) error {
+	warnings, err := duplicateService.Screen(sheetDuplicateCandidates(sheet), duplicateAction, 0)
+	if err != nil {
+		return err
+	}
+	duplicates := duplicateWarningsByIndex(warnings)
+
+	// a bank question can only be linked once per assessment
+	merged := map[int64]bool{}
+	kept := sheet.Questions[:0]
+	for idx, q := range sheet.Questions {
+		q.ExistingQuestionID = 0
+		if dup, ok := duplicates[idx]; ok {
+			switch constant.DuplicateAction(dup.Action) {
+			case constant.DuplicateSkip:
+				continue
+			case constant.DuplicateMerge:
+				if merged[dup.QuestionID] {
+					continue
+				}
+				merged[dup.QuestionID] = true
+				q.ExistingQuestionID = dup.QuestionID
+			}
+		}
+		kept = append(kept, q)
+	}
+	if len(kept) == 0 {
+		return errors.New("every question in the file was skipped as a duplicate")
+	}
+
+	sheet.Questions = kept
+	sheet.Duplicates = warnings
+	return nil
+}
+
+func sheetDuplicateCandidates(sheet *models.SheetAssessment) []DuplicateCandidate {
+	candidates := make([]DuplicateCandidate, 0, len(sheet.Questions))
+	for _, q := range sheet.Questions {
+		candidate := DuplicateCandidate{
+			Title:              q.Title,
+			DuplicateAction:    q.DuplicateAction,
+			ExistingQuestionID: q.ExistingQuestionID,
+		}
+		for _, opt := range q.Options {
+			candidate.Options = append(candidate.Options, opt.Label)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// reportSheetDuplicates adds the parsed sheet and its bank matches to a validation report.
+func reportSheetDuplicates(duplicateService DuplicateService, sheet *models.SheetAssessment, report *models.ExcelValidationReport) error {
+	report.Assessment = sheet
+	if len(sheet.Questions) == 0 {
+		return nil
+	}
+	warnings, err := duplicateService.Screen(sheetDuplicateCandidates(sheet), string(constant.DuplicateWarn), 0)
+	if err != nil {
+		return err
+	}
+	report.Duplicates = warnings
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"dhl/config"
+	"dhl/models"
+	"dhl/repository"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// InboxService serves the users' in-app notifications.
+type InboxService interface {
+	List(userId string, unreadOnly bool, limit, offset int) ([]models.UserNotification, int64, error)
+	UnreadCount(userId string) (int64, error)
+	MarkRead(userId string, ids []int64) (int64, error)
+	MarkAllRead(userId string) (int64, error)
+}
+
+type InboxServiceImpl struct {
+	inboxRepo       repository.UserNotificationRepository
+	userRepo        repository.UserRepository
+	templateService NotificationTemplateService
+}
+
+func NewInboxService(inboxRepo repository.UserNotificationRepository, userRepo repository.UserRepository, templateService NotificationTemplateService) InboxService {
+	return &InboxServiceImpl{inboxRepo: inboxRepo, userRepo: userRepo, templateService: templateService}
+}
+
+// List returns the user's notifications, newest first, with their title and body rendered in
+// the user's locale. A notification that fails to render is listed without them.
+func (s *InboxServiceImpl) List(userId string, unreadOnly bool, limit, offset int) ([]models.UserNotification, int64, error) {
+	list, total, err := s.inboxRepo.List(userId, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(list) == 0 {
+		return []models.UserNotification{}, total, nil
+	}
+
+	user, err := s.userRepo.FindByUserId(userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range list {
+		data := map[string]interface{}{}
+		if len(list[i].Payload) > 0 {
+			if err := json.Unmarshal(list[i].Payload, &data); err != nil {
+				log.Printf("[ERROR] invalid payload of notification %d: %v", list[i].ID, err)
+				continue
+			}
+		}
+		data["userName"] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
+		data["assessmentLink"] = config.PropConfig.Notify.AssessmentLink
+
+		rendered, err := s.templateService.Render(list[i].TemplateKey, user.Locale, data)
+		if err != nil {
+			log.Printf("[ERROR] failed to render notification %d: %v", list[i].ID, err)
+			continue
+		}
+		list[i].Title = rendered.Subject
+		list[i].Body = rendered.Body
+	}
+	return list, total, nil
+}
+
+func (s *InboxServiceImpl) UnreadCount(userId string) (int64, error) {
+	return s.inboxRepo.CountUnread(userId)
+}
+
+func (s *InboxServiceImpl) MarkRead(userId string, ids []int64) (int64, error) {
+	return s.inboxRepo.MarkRead(userId, ids, time.Now())
+}
+
+func (s *InboxServiceImpl) MarkAllRead(userId string) (int64, error) {
+	return s.inboxRepo.MarkAllRead(userId, time.Now())
+}
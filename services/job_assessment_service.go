@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultQuestionsPerSkill = 5
+
+type JobAssessmentService interface {
+	GenerateAssessmentFromJob(ctx context.Context, req models.GenerateJobAssessmentRequest, userId string) (*models.JobAssessmentResponse, error)
+}
+
+type JobAssessmentServiceImpl struct {
+	jobRepo        repository.JobDescriptionRepository
+	assessmentRepo repository.AssessmentRepository
+	questionRepo   repository.QuestionRepository
+	geminiService  GeminiService
+	db             *gorm.DB
+}
+
+func NewJobAssessmentService(jobRepo repository.JobDescriptionRepository, assessmentRepo repository.AssessmentRepository, questionRepo repository.QuestionRepository, geminiService GeminiService, db *gorm.DB) JobAssessmentService {
+	return &JobAssessmentServiceImpl{
+		jobRepo:        jobRepo,
+		assessmentRepo: assessmentRepo,
+		questionRepo:   questionRepo,
+		geminiService:  geminiService,
+		db:             db,
+	}
+}
+
+// GenerateAssessmentFromJob builds a per-skill blueprint from the job's required skills,
+// fills it from the question bank and/or AI generation, and saves it as a draft assessment.
+func (s *JobAssessmentServiceImpl) GenerateAssessmentFromJob(ctx context.Context, req models.GenerateJobAssessmentRequest, userId string) (*models.JobAssessmentResponse, error) {
+	job, err := s.jobRepo.GetByID(req.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("job description not found: %w", err)
+	}
+
+	skills := parseRequiredSkills(job.RequiredSkills)
+	if len(skills) == 0 {
+		return nil, fmt.Errorf("job description %d has no required skills", job.JobID)
+	}
+
+	difficulty := jobLevelToDifficulty(job.Level)
+	difficultyLabel := string(difficultyToLabel(difficulty))
+	counts := distributeQuestions(len(skills), req.NumberOfQuestions, req.QuestionsPerSkill)
+
+	blueprint := make([]models.SkillBlueprint, 0, len(skills))
+	var bankIDs []int64
+	var generated []models.SheetQuestion
+
+	for i, skill := range skills {
+		entry := models.SkillBlueprint{
+			Skill:           skill,
+			DifficultyLevel: difficultyLabel,
+			Requested:       counts[i],
+		}
+
+		if req.UseExistingQuestions {
+			ids, err := s.questionRepo.FindQuestionIDsByTag(skill, difficultyLabel, counts[i], bankIDs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch bank questions for skill %q: %w", skill, err)
+			}
+			bankIDs = append(bankIDs, ids...)
+			entry.FromBank = len(ids)
+		}
+
+		shortfall := counts[i] - entry.FromBank
+		if shortfall > 0 {
+			sheet, err := s.geminiService.GenerateAssessment(models.GenerateAssessmentRequest{
+				Topic:             fmt.Sprintf("%s for the role of %s", skill, job.Title),
+				NumberOfQuestions: shortfall,
+				DifficultyLevel:   difficulty,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate questions for skill %q: %w", skill, err)
+			}
+			if len(sheet.Questions) > shortfall {
+				sheet.Questions = sheet.Questions[:shortfall]
+			}
+			for _, q := range sheet.Questions {
+				q.DifficultyLevel = difficultyLabel
+				q.Points = 1
+				q.Tags = append(q.Tags, models.TagRequest{ChildTags: []string{skill}})
+				generated = append(generated, q)
+			}
+			entry.Generated = len(sheet.Questions)
+		}
+
+		blueprint = append(blueprint, entry)
+	}
+
+	total := len(bankIDs) + len(generated)
+	if total == 0 {
+		return nil, fmt.Errorf("no questions could be selected or generated for job %d", job.JobID)
+	}
+
+	assessmentName := req.AssessmentName
+	if assessmentName == "" {
+		assessmentName = job.Title + " Assessment"
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	jobID := job.JobID
+	assessment, err := createDraftAssessment(ctx, tx, s.assessmentRepo, draftAssessment{
+		Name:     assessmentName,
+		Settings: models.DefaultAssessmentSettings(),
+		Marks:    int64(total),
+		JobID:    &jobID,
+	}, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	for idx, qID := range bankIDs {
+		mapping := models.AssessmentQuestionMst{
+			CreatedOn:          now,
+			CreatedBy:          userId,
+			IsActive:           true,
+			IsDeleted:          false,
+			ModifiedOn:         now,
+			ModifiedBy:         userId,
+			AssessmentSequence: assessment.AssessmentSequence,
+			AssessmentID:       int(assessment.AssessmentID),
+			QuestionID:         qID,
+			SequenceID:         int64(idx + 1),
+			CorrectPoints:      1,
+			DifficultyLevel:    difficultyLabel,
+		}
+		if err := tx.WithContext(ctx).Create(&mapping).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to map bank question %d: %w", qID, err)
+		}
+	}
+
+	if len(generated) > 0 {
+		if _, err := s.assessmentRepo.SaveSheetQuestions(ctx, tx, assessment.AssessmentID, assessment.AssessmentSequence, generated, userId, int64(len(bankIDs))); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, models.TagRequest{ChildTags: skills}, userId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to process skill tags: %w", err)
+	}
+	for _, tagID := range tagIDs {
+		if err := s.assessmentRepo.CreateAssessmentTagMappingWithParents(tx, assessment.AssessmentSequence, tagID, userId); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to create assessment tag mapping: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &models.JobAssessmentResponse{
+		AssessmentSequence: assessment.AssessmentSequence,
+		AssessmentName:     assessmentName,
+		JobID:              job.JobID,
+		AssessmentStatus:   string(constant.Draft),
+		QuestionsCount:     total,
+		Blueprint:          blueprint,
+	}, nil
+}
+
+// parseRequiredSkills splits a free-text skills list on commas, semicolons and new lines.
+func parseRequiredSkills(raw string) []string {
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '|'
+	})
+
+	seen := make(map[string]bool)
+	var skills []string
+	for _, p := range parts {
+		skill := strings.TrimSpace(p)
+		key := strings.ToLower(skill)
+		if skill == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		skills = append(skills, skill)
+	}
+	return skills
+}
+
+// jobLevelToDifficulty maps a job level ("junior", "senior", "3", ...) onto the 1-5 scale used for generation.
+func jobLevelToDifficulty(level string) int {
+	l := strings.ToLower(strings.TrimSpace(level))
+	if n, err := strconv.Atoi(l); err == nil {
+		if n < 1 {
+			return 1
+		}
+		if n > 5 {
+			return 5
+		}
+		return n
+	}
+
+	switch {
+	case strings.Contains(l, "intern"), strings.Contains(l, "trainee"):
+		return 1
+	case strings.Contains(l, "junior"), strings.Contains(l, "entry"), strings.Contains(l, "associate"):
+		return 2
+	case strings.Contains(l, "lead"), strings.Contains(l, "expert"), strings.Contains(l, "principal"), strings.Contains(l, "head"):
+		return 5
+	case strings.Contains(l, "senior"):
+		return 4
+	default:
+		return 3
+	}
+}
+
+func difficultyToLabel(difficulty int) constant.DifficultyLevel {
+	switch {
+	case difficulty <= 2:
+		return constant.Easy
+	case difficulty == 3:
+		return constant.Medium
+	default:
+		return constant.Hard
+	}
+}
+
+// distributeQuestions splits the requested total evenly across skills, giving the remainder
+// to the first skills. questionsPerSkill wins when set.
+func distributeQuestions(skillCount, total, questionsPerSkill int) []int {
+	counts := make([]int, skillCount)
+	if questionsPerSkill <= 0 && total <= 0 {
+		questionsPerSkill = defaultQuestionsPerSkill
+	}
+
+	if questionsPerSkill > 0 {
+		for i := range counts {
+			counts[i] = questionsPerSkill
+		}
+		return counts
+	}
+
+	base := total / skillCount
+	remainder := total % skillCount
+	for i := range counts {
+		counts[i] = base
+		if i < remainder {
+			counts[i]++
+		}
+	}
+	return counts
+}
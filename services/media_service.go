@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImageBytes = 5 << 20
+	maxAudioBytes = 20 << 20
+)
+
+// allowedMediaTypes maps accepted mime types to their content kind and storage extension.
+var allowedMediaTypes = map[string]struct {
+	kind string
+	ext  string
+}{
+	"image/png":  {constant.ContentImage, ".png"},
+	"image/jpeg": {constant.ContentImage, ".jpg"},
+	"image/gif":  {constant.ContentImage, ".gif"},
+	"image/webp": {constant.ContentImage, ".webp"},
+	"audio/mpeg": {constant.ContentAudio, ".mp3"},
+	"audio/wav":  {constant.ContentAudio, ".wav"},
+	"audio/ogg":  {constant.ContentAudio, ".ogg"},
+	"audio/mp4":  {constant.ContentAudio, ".m4a"},
+}
+
+type MediaService interface {
+	Upload(ctx context.Context, fileName string, data []byte, userId string) (*models.MediaUploadResponse, error)
+	Store(tx *gorm.DB, fileName string, data []byte, userId string) (*models.MediaMst, error)
+	Discard(key string)
+	Open(key string) (io.ReadCloser, *models.MediaMst, error)
+	UpdateContent(ctx context.Context, req models.UpdateContentRequest) (*models.RenderedContent, error)
+}
+
+type MediaServiceImpl struct {
+	mediaRepo    repository.MediaRepository
+	questionRepo repository.QuestionRepository
+	storage      utils.MediaStorage
+	db           *gorm.DB
+}
+
+func NewMediaService(mediaRepo repository.MediaRepository, questionRepo repository.QuestionRepository, storage utils.MediaStorage, db *gorm.DB) MediaService {
+	return &MediaServiceImpl{
+		mediaRepo:    mediaRepo,
+		questionRepo: questionRepo,
+		storage:      storage,
+		db:           db,
+	}
+}
+
+func (s *MediaServiceImpl) Upload(ctx context.Context, fileName string, data []byte, userId string) (*models.MediaUploadResponse, error) {
+	media, err := s.Store(s.db.WithContext(ctx), fileName, data, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MediaUploadResponse{
+		MediaID:     media.MediaID,
+		StorageKey:  media.StorageKey,
+		URL:         utils.MediaURL(media.StorageKey),
+		MimeType:    media.MimeType,
+		ContentType: allowedMediaTypes[media.MimeType].kind,
+		SizeBytes:   media.SizeBytes,
+	}, nil
+}
+
+// Store validates the file, writes it to the storage backend and records it in media_mst
+// using the given transaction.
+func (s *MediaServiceImpl) Store(tx *gorm.DB, fileName string, data []byte, userId string) (*models.MediaMst, error) {
+	mimeType, err := detectMediaType(fileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(maxImageBytes)
+	if allowedMediaTypes[mimeType].kind == constant.ContentAudio {
+		limit = maxAudioBytes
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s exceeds the %d MB limit for %s files", fileName, limit>>20, allowedMediaTypes[mimeType].kind)
+	}
+
+	key := fmt.Sprintf("%s/%s%s", time.Now().Format("2006/01"), uuid.New().String(), allowedMediaTypes[mimeType].ext)
+	if err := s.storage.Save(key, data); err != nil {
+		return nil, fmt.Errorf("failed to store %s: %w", fileName, err)
+	}
+
+	sum := sha256.Sum256(data)
+	media := &models.MediaMst{
+		StorageKey: key,
+		FileName:   filepath.Base(fileName),
+		MimeType:   mimeType,
+		SizeBytes:  int64(len(data)),
+		Checksum:   hex.EncodeToString(sum[:]),
+		CreatedOn:  time.Now(),
+		CreatedBy:  userId,
+		IsDeleted:  false,
+	}
+	if err := s.mediaRepo.Create(tx, media); err != nil {
+		s.Discard(key)
+		return nil, fmt.Errorf("failed to save media record: %w", err)
+	}
+	return media, nil
+}
+
+// Discard removes a stored file whose database record was rolled back.
+func (s *MediaServiceImpl) Discard(key string) {
+	if err := s.storage.Delete(key); err != nil {
+		log.Printf("[ERROR] failed to discard media %s: %v", key, err)
+	}
+}
+
+func (s *MediaServiceImpl) Open(key string) (io.ReadCloser, *models.MediaMst, error) {
+	media, err := s.mediaRepo.GetByKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := s.storage.Open(media.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rc, media, nil
+}
+
+// UpdateContent replaces the stem of a question or the label of an option with text,
+// sanitized HTML/Markdown or an uploaded image/audio file.
+func (s *MediaServiceImpl) UpdateContent(ctx context.Context, req models.UpdateContentRequest) (*models.RenderedContent, error) {
+	if (req.QuestionID == nil) == (req.OptionID == nil) {
+		return nil, errors.New("exactly one of question_id or option_id is required")
+	}
+
+	var contentID int64
+	var err error
+	if req.QuestionID != nil {
+		contentID, err = s.questionRepo.GetQuestionContentID(*req.QuestionID)
+	} else {
+		contentID, err = s.questionRepo.GetOptionContentID(*req.OptionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("content not found: %w", err)
+	}
+
+	content := &models.ContentMst{ContentID: contentID}
+	switch req.ContentType {
+	case constant.ContentText:
+		content.ContentTypeID = constant.ContentTypeText
+		content.Value = strings.TrimSpace(req.Value)
+	case constant.ContentHTML:
+		content.ContentTypeID = constant.ContentTypeHTML
+		content.Value = utils.SanitizeHTML(req.Value)
+	case constant.ContentMarkdown:
+		content.ContentTypeID = constant.ContentTypeMarkdown
+		content.Value = utils.SanitizeMarkdown(req.Value)
+	case constant.ContentImage, constant.ContentAudio:
+		media, err := s.mediaRepo.GetByID(req.MediaID)
+		if err != nil {
+			return nil, fmt.Errorf("media %d not found: %w", req.MediaID, err)
+		}
+		if allowedMediaTypes[media.MimeType].kind != req.ContentType {
+			return nil, fmt.Errorf("media %d is %s, not %s", media.MediaID, media.MimeType, req.ContentType)
+		}
+		content.ContentTypeID = constant.ContentTypeImage
+		if req.ContentType == constant.ContentAudio {
+			content.ContentTypeID = constant.ContentTypeAudio
+		}
+		value, _ := json.Marshal(models.MediaContentValue{
+			MediaID:  media.MediaID,
+			Key:      media.StorageKey,
+			MimeType: media.MimeType,
+			Alt:      strings.TrimSpace(req.Alt),
+		})
+		content.Value = string(value)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", req.ContentType)
+	}
+	if content.Value == "" {
+		return nil, errors.New("content value is empty")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if err := s.questionRepo.UpdateContent(tx, content); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	text, rendered := utils.RenderContent(req.ContentType, content.Value)
+	if rendered == nil {
+		rendered = &models.RenderedContent{Type: constant.ContentText, Text: text}
+	}
+	return rendered, nil
+}
+
+// detectMediaType sniffs the file content; the extension is only trusted for audio formats
+// that have no reliable signature.
+func detectMediaType(fileName string, data []byte) (string, error) {
+	sniffed := http.DetectContentType(data)
+	if i := strings.Index(sniffed, ";"); i >= 0 {
+		sniffed = sniffed[:i]
+	}
+
+	switch sniffed {
+	case "application/ogg":
+		sniffed = "audio/ogg"
+	case "audio/wave", "audio/x-wav":
+		sniffed = "audio/wav"
+	case "video/mp4":
+		sniffed = "audio/mp4"
+	case "application/octet-stream":
+		if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName))); strings.HasPrefix(byExt, "audio/") {
+			sniffed = byExt
+		}
+	}
+
+	if _, ok := allowedMediaTypes[sniffed]; !ok {
+		return "", fmt.Errorf("%s has unsupported media type %s", fileName, sniffed)
+	}
+	return sniffed, nil
+}
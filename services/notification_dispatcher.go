@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationDispatcher delivers the notifications waiting in the outbox. Failed deliveries
+// are retried with exponential backoff until they run out of attempts and are dead-lettered.
+type NotificationDispatcher interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, db *gorm.DB) error
+	List(filter models.OutboxFilter, limit, offset int) ([]models.NotificationOutbox, int64, error)
+	Replay(req models.ReplayOutboxRequest) (int64, error)
+}
+
+type NotificationDispatcherImpl struct {
+	outboxRepo          repository.NotificationOutboxRepository
+	notificationService NotificationService
+}
+
+func NewNotificationDispatcher(outboxRepo repository.NotificationOutboxRepository, notificationService NotificationService) NotificationDispatcher {
+	return &NotificationDispatcherImpl{outboxRepo: outboxRepo, notificationService: notificationService}
+}
+
+func (s *NotificationDispatcherImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "notification-outbox",
+		Interval: config.PropConfig.Scheduler.OutboxInterval,
+		Run:      s.RunDue,
+		Unlocked: true,
+	}
+}
+
+// outboxBackoff is the wait before the next attempt of a notification that failed attempts
+// times: the base doubled each time, up to the maximum.
+func outboxBackoff(attempts int) time.Duration {
+	cfg := config.PropConfig.Outbox
+	wait := cfg.BackoffBase
+	for i := 1; i < attempts && wait < cfg.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, cfg.BackoffMax)
+}
+
+// RunDue delivers one batch of due notifications. The batch is claimed first, in a statement
+// of its own, so concurrent instances never deliver the same notification twice and nothing is
+// locked while the channels are called. Each outcome is recorded as soon as it is known; a
+// batch cut short by shutdown is claimed again once its claim runs out.
+func (s *NotificationDispatcherImpl) RunDue(ctx context.Context, db *gorm.DB) error {
+	now := time.Now()
+	messages, err := s.outboxRepo.Claim(db, now, now.Add(config.PropConfig.Outbox.ClaimTimeout), config.PropConfig.Outbox.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim due notifications: %w", err)
+	}
+
+	// outcomes are recorded even when shutdown interrupts the batch
+	record := db.WithContext(context.WithoutCancel(ctx))
+	sent, failed := 0, 0
+	for i := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		msg := &messages[i]
+		if err := s.notificationService.Deliver(msg); err != nil {
+			failed++
+			msg.LastError = err.Error()
+			if msg.Attempts >= config.PropConfig.Outbox.MaxAttempts {
+				msg.Status = constant.OutboxDead
+				log.Printf("[ERROR] notification %d to %s dead after %d attempts: %v", msg.ID, msg.RecipientID, msg.Attempts, err)
+			} else {
+				msg.Status = constant.OutboxPending
+				msg.NextAttemptOn = time.Now().Add(outboxBackoff(msg.Attempts))
+			}
+		} else {
+			sent++
+			sentOn := time.Now()
+			msg.Status = constant.OutboxSent
+			msg.SentOn = &sentOn
+			msg.LastError = ""
+		}
+		if err := s.outboxRepo.SaveAttempt(record, msg); err != nil {
+			return err
+		}
+	}
+
+	if sent > 0 || failed > 0 {
+		log.Printf("Notification outbox: %d sent, %d failed", sent, failed)
+	}
+	return nil
+}
+
+func (s *NotificationDispatcherImpl) List(filter models.OutboxFilter, limit, offset int) ([]models.NotificationOutbox, int64, error) {
+	list, total, err := s.outboxRepo.List(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.NotificationOutbox{}
+	}
+	return list, total, nil
+}
+
+// Replay queues dead notifications for delivery again.
+func (s *NotificationDispatcherImpl) Replay(req models.ReplayOutboxRequest) (int64, error) {
+	if !req.AllDead && len(req.IDs) == 0 {
+		return 0, errors.New("ids or all_dead is required")
+	}
+	return s.outboxRepo.Replay(req.IDs, req.AllDead, time.Now())
+}
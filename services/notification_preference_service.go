@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// notificationCategories is the preference category of each kind of notification. Kinds
+// missing here are treated as compliance and always sent.
+var notificationCategories = map[string]string{
+	constant.NotificationDistribution:      constant.CategoryAssignments,
+	constant.NotificationCertificateExpiry: constant.CategoryCompliance,
+	constant.NotificationReminder:          constant.CategoryReminders,
+	constant.NotificationEscalation:        constant.CategoryReminders,
+	constant.NotificationDigest:            constant.CategoryReminders,
+	constant.NotificationResult:            constant.CategoryResults,
+	constant.NotificationCertificate:       constant.CategoryResults,
+	constant.NotificationContactReply:      constant.CategoryMarketing,
+}
+
+// preferenceCategories are the categories in the order users see them.
+var preferenceCategories = []string{
+	constant.CategoryAssignments,
+	constant.CategoryReminders,
+	constant.CategoryResults,
+	constant.CategoryCompliance,
+	constant.CategoryMarketing,
+}
+
+func notificationCategory(kind string) string {
+	if category, ok := notificationCategories[kind]; ok {
+		return category
+	}
+	return constant.CategoryCompliance
+}
+
+func isMandatoryCategory(category string) bool {
+	return slices.Contains(config.PropConfig.Notify.MandatoryCategories, category)
+}
+
+// channelsOf reads a notification type as the channels it allows. Unset or unknown types
+// allow every channel, as before preferences were consulted.
+func channelsOf(notificationType string) models.NotificationChannels {
+	switch strings.ToLower(strings.TrimSpace(notificationType)) {
+	case constant.PreferenceEmail:
+		return models.NotificationChannels{Email: true}
+	case constant.PreferenceInApp:
+		return models.NotificationChannels{InApp: true}
+	case constant.PreferenceNone:
+		return models.NotificationChannels{}
+	}
+	return models.NotificationChannels{Email: true, InApp: true}
+}
+
+// NotificationPreferenceService decides which channels users receive each category of
+// notifications through, and keeps the log of what it held back.
+type NotificationPreferenceService interface {
+	Resolve(tx *gorm.DB, kind string, userIDs []string) (map[string]models.NotificationChannels, error)
+	LogSuppressions(tx *gorm.DB, suppressions []models.NotificationSuppression) error
+	Get(userID string) (*models.NotificationPreferences, error)
+	Save(ctx context.Context, userID string, req models.SaveNotificationPreferencesRequest) (*models.NotificationPreferences, error)
+	ListSuppressions(filter models.SuppressionFilter, limit, offset int) ([]models.NotificationSuppression, int64, error)
+}
+
+type NotificationPreferenceServiceImpl struct {
+	preferenceRepo repository.NotificationPreferenceRepository
+	db             *gorm.DB
+}
+
+func NewNotificationPreferenceService(preferenceRepo repository.NotificationPreferenceRepository, db *gorm.DB) NotificationPreferenceService {
+	return &NotificationPreferenceServiceImpl{preferenceRepo: preferenceRepo, db: db}
+}
+
+// Resolve returns the channels each user gets a kind of notification through: every channel
+// for a mandatory category, else the user's preference for the category, else the
+// notification type of their profile.
+func (s *NotificationPreferenceServiceImpl) Resolve(tx *gorm.DB, kind string, userIDs []string) (map[string]models.NotificationChannels, error) {
+	category := notificationCategory(kind)
+	channels := make(map[string]models.NotificationChannels, len(userIDs))
+	if isMandatoryCategory(category) {
+		for _, id := range userIDs {
+			channels[id] = models.NotificationChannels{Email: true, InApp: true}
+		}
+		return channels, nil
+	}
+
+	prefs, err := s.preferenceRepo.GetPreferences(tx, userIDs, category)
+	if err != nil {
+		return nil, err
+	}
+	types, err := s.preferenceRepo.GetNotificationTypes(tx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range userIDs {
+		if pref, ok := prefs[id]; ok {
+			channels[id] = models.NotificationChannels{Email: pref.Email, InApp: pref.InApp}
+		} else {
+			channels[id] = channelsOf(types[id])
+		}
+	}
+	return channels, nil
+}
+
+func (s *NotificationPreferenceServiceImpl) LogSuppressions(tx *gorm.DB, suppressions []models.NotificationSuppression) error {
+	return s.preferenceRepo.LogSuppressions(tx, suppressions)
+}
+
+// Get returns the user's default and the channels of every category.
+func (s *NotificationPreferenceServiceImpl) Get(userID string) (*models.NotificationPreferences, error) {
+	types, err := s.preferenceRepo.GetNotificationTypes(s.db, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := s.preferenceRepo.ListPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]models.NotificationPreference, len(prefs))
+	for _, p := range prefs {
+		byCategory[p.Category] = p
+	}
+
+	def := strings.ToLower(strings.TrimSpace(types[userID]))
+	if def != constant.PreferenceEmail && def != constant.PreferenceInApp && def != constant.PreferenceNone {
+		def = constant.PreferenceAll
+	}
+	result := &models.NotificationPreferences{Default: def, Categories: make([]models.CategoryPreference, len(preferenceCategories))}
+	for i, category := range preferenceCategories {
+		cp := models.CategoryPreference{Category: category, Mandatory: isMandatoryCategory(category)}
+		pref, custom := byCategory[category]
+		switch {
+		case cp.Mandatory:
+			cp.Email, cp.InApp = true, true
+		case custom:
+			cp.Email, cp.InApp, cp.Custom = pref.Email, pref.InApp, true
+		default:
+			ch := channelsOf(def)
+			cp.Email, cp.InApp = ch.Email, ch.InApp
+		}
+		result.Categories[i] = cp
+	}
+	return result, nil
+}
+
+// Save changes the user's default and the categories given. Mandatory categories cannot be
+// switched off on any channel.
+func (s *NotificationPreferenceServiceImpl) Save(ctx context.Context, userID string, req models.SaveNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	now := time.Now()
+	prefs := make([]models.NotificationPreference, 0, len(req.Categories))
+	for _, c := range req.Categories {
+		if isMandatoryCategory(c.Category) && (!c.Email || !c.InApp) {
+			return nil, fmt.Errorf("%s notifications are mandatory and cannot be switched off", c.Category)
+		}
+		prefs = append(prefs, models.NotificationPreference{
+			UserID:     userID,
+			Category:   c.Category,
+			Email:      c.Email,
+			InApp:      c.InApp,
+			ModifiedOn: now,
+		})
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if req.Default != nil {
+		if err := s.preferenceRepo.SetNotificationType(tx, userID, *req.Default); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := s.preferenceRepo.SavePreferences(tx, prefs); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return s.Get(userID)
+}
+
+func (s *NotificationPreferenceServiceImpl) ListSuppressions(filter models.SuppressionFilter, limit, offset int) ([]models.NotificationSuppression, int64, error) {
+	list, total, err := s.preferenceRepo.ListSuppressions(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.NotificationSuppression{}
+	}
+	return list, total, nil
+}
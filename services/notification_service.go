@@ -1,69 +1,301 @@
 package services
 
 import (
+	"dhl/config"
+	"dhl/constant"
 	"dhl/models"
 	"dhl/repository"
 	"dhl/utils"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// NotificationService queues notifications in the outbox and the recipients' in-app inbox,
+// within the caller's transaction, and delivers them when the outbox dispatcher asks.
 type NotificationService interface {
-	SendDistributeAssessmentMail(userIds []string, assessementSeq string, isManager bool) error
+	QueueDistributeAssessmentMail(tx *gorm.DB, userIds []string, assessementSeq string, isManager bool) error
+	QueueCertificateExpiryMail(tx *gorm.DB, userId, assessmentSeq string, expiresOn time.Time) error
+	QueueReminderMail(tx *gorm.DB, userId, assessmentSeq, event string, dueDate time.Time) error
+	QueueEscalationMail(tx *gorm.DB, managerId, userId, assessmentSeq string, dueDate time.Time, daysOverdue int) error
+	QueueResultMail(tx *gorm.DB, assessmentSeq string, result models.ReleasedResult) error
+	QueueCertificateIssuedMail(tx *gorm.DB, userId, assessmentSeq string, expiresOn time.Time) error
+	QueueDigestMail(tx *gorm.DB, userId string, data map[string]interface{}) error
+	QueueContactReplyMail(tx *gorm.DB, userId string, contact models.ContactUsResponse, reply string) error
+	Deliver(message *models.NotificationOutbox) error
 	AddUsersToNotify(userIds []*string) error
 
 	RegisterUserInNotify(fcmToken, phone *string, email string) (uuid.UUID, error)
 }
 
 type NotificationServiceImpl struct {
-	userRepo       repository.UserRepository
-	assessmentRepo repository.AssessmentRepository
+	userRepo          repository.UserRepository
+	assessmentRepo    repository.AssessmentRepository
+	outboxRepo        repository.NotificationOutboxRepository
+	inboxRepo         repository.UserNotificationRepository
+	templateService   NotificationTemplateService
+	preferenceService NotificationPreferenceService
+	channels          map[string]NotificationChannel
 }
 
-func NewNotificationService(userRepo repository.UserRepository, assessmentRepo repository.AssessmentRepository) NotificationService {
-	return &NotificationServiceImpl{userRepo: userRepo, assessmentRepo: assessmentRepo}
+func NewNotificationService(userRepo repository.UserRepository, assessmentRepo repository.AssessmentRepository, outboxRepo repository.NotificationOutboxRepository, inboxRepo repository.UserNotificationRepository, templateService NotificationTemplateService, preferenceService NotificationPreferenceService, channels map[string]NotificationChannel) NotificationService {
+	return &NotificationServiceImpl{userRepo: userRepo, assessmentRepo: assessmentRepo, outboxRepo: outboxRepo, inboxRepo: inboxRepo, templateService: templateService, preferenceService: preferenceService, channels: channels}
 }
 
-func (e *NotificationServiceImpl) SendDistributeAssessmentMail(userIds []string, assessementSeq string, isManager bool) error {
-	asmt, err := e.assessmentRepo.GetAssessmentMstByAssmtSeq(assessementSeq)
+// queue writes an outbox notification and an inbox entry for each recipient who receives the
+// kind through email and in the app. What the recipients opted out of is logged instead. Kinds
+// delivered through the inbox channel have nothing beyond the inbox to send.
+func (e *NotificationServiceImpl) queue(tx *gorm.DB, kind string, recipientIds []string, assessmentSeq, templateKey string, data map[string]interface{}) error {
+	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
+	allowed, err := e.preferenceService.Resolve(tx, kind, recipientIds)
+	if err != nil {
+		return err
+	}
+	inboxOnly := channelFor(kind) == constant.ChannelInbox
 
-	header := map[string]string{
-		"X-API-Key": os.Getenv("NOTIFY_API_KEY"),
+	now := time.Now()
+	var messages []models.NotificationOutbox
+	var inbox []models.UserNotification
+	var suppressed []models.NotificationSuppression
+	suppress := func(rid, channel string) {
+		suppressed = append(suppressed, models.NotificationSuppression{
+			UserID:             rid,
+			Kind:               kind,
+			Category:           notificationCategory(kind),
+			Channel:            channel,
+			TemplateKey:        templateKey,
+			AssessmentSequence: assessmentSeq,
+			CreatedOn:          now,
+		})
 	}
-	var errorList []error
-	for _, uid := range userIds {
-		user, _ := e.userRepo.FindByUserId(uid)
-		if user.NotifyId == "" {
-			log.Println("[Error] Distribution Mail User not registred on notify :", user.UserID, " : ", err)
-			errorList = append(errorList, err)
+	for _, rid := range recipientIds {
+		channels := allowed[rid]
+		if channels.InApp {
+			inbox = append(inbox, models.UserNotification{
+				UserID:             rid,
+				Kind:               kind,
+				TemplateKey:        templateKey,
+				AssessmentSequence: assessmentSeq,
+				Payload:            payload,
+				CreatedOn:          now,
+			})
+		} else {
+			suppress(rid, constant.PreferenceInApp)
+		}
+
+		// the inbox row is the whole of an inbox-only notification
+		if inboxOnly {
 			continue
 		}
-		sendBody := map[string]interface{}{
-			"target_type":   "recipient_id",
-			"target_value":  user.NotifyId,
-			"template_code": 13,
-			"channels":      []string{"email"},
-			"data": map[string]interface{}{
-				"endDate":        asmt.ValidTo,
-				"userName":       fmt.Sprintf("%s %s", user.FirstName, user.LastName),
-				"assessmentLink": "https://dhl.catseye.cloud/",
-				"isManager":      isManager,
-			},
+		if !channels.Email {
+			suppress(rid, constant.PreferenceEmail)
+			continue
 		}
-		_, _, sendErr := utils.MakeRESTRequest(http.MethodPost, os.Getenv("NOTIFY_SERVER_URL")+"/api/v1/notifications/send", sendBody, header)
-		errorList = append(errorList, sendErr)
+		messages = append(messages, models.NotificationOutbox{
+			Kind:               kind,
+			RecipientID:        rid,
+			AssessmentSequence: assessmentSeq,
+			TemplateKey:        templateKey,
+			Payload:            payload,
+			Status:             constant.OutboxPending,
+			NextAttemptOn:      now,
+			CreatedOn:          now,
+		})
 	}
-	finalErr := errors.Join(errorList...)
-	return finalErr
+
+	if len(suppressed) > 0 {
+		log.Printf("Suppressed %d %s notifications by user preference", len(suppressed), kind)
+		if err := e.preferenceService.LogSuppressions(tx, suppressed); err != nil {
+			return err
+		}
+	}
+	if err := e.inboxRepo.Create(tx, inbox); err != nil {
+		return err
+	}
+	return e.outboxRepo.Enqueue(tx, messages)
+}
+
+func (e *NotificationServiceImpl) getAssessment(assessmentSeq string) (*models.AssessmentMst, error) {
+	asmt, err := e.assessmentRepo.GetAssessmentMstByAssmtSeq(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if asmt == nil {
+		return nil, errors.New("assessment not found")
+	}
+	return asmt, nil
+}
+
+func (e *NotificationServiceImpl) QueueDistributeAssessmentMail(tx *gorm.DB, userIds []string, assessementSeq string, isManager bool) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	asmt, err := e.getAssessment(assessementSeq)
+	if err != nil {
+		return err
+	}
+	templateKey := constant.TemplateDistribution
+	if isManager {
+		templateKey = constant.TemplateDistributionManager
+	}
+	return e.queue(tx, constant.NotificationDistribution, userIds, assessementSeq, templateKey, map[string]interface{}{
+		"endDate":        asmt.ValidTo,
+		"assessmentName": asmt.AssessmentDesc,
+		"isManager":      isManager,
+	})
+}
+
+// QueueCertificateExpiryMail tells a user their certification of a recurring assessment runs
+// out on expiresOn and has to be renewed.
+func (e *NotificationServiceImpl) QueueCertificateExpiryMail(tx *gorm.DB, userId, assessmentSeq string, expiresOn time.Time) error {
+	asmt, err := e.getAssessment(assessmentSeq)
+	if err != nil {
+		return err
+	}
+	return e.queue(tx, constant.NotificationCertificateExpiry, []string{userId}, assessmentSeq, constant.TemplateCertificateExpiry, map[string]interface{}{
+		"endDate":        expiresOn,
+		"expiresOn":      expiresOn,
+		"assessmentName": asmt.AssessmentDesc,
+		"isManager":      false,
+	})
+}
+
+// QueueReminderMail reminds a user of an assessment due on dueDate, with the template of the
+// reminder event.
+func (e *NotificationServiceImpl) QueueReminderMail(tx *gorm.DB, userId, assessmentSeq, event string, dueDate time.Time) error {
+	asmt, err := e.getAssessment(assessmentSeq)
+	if err != nil {
+		return err
+	}
+	templateKey := constant.TemplateReminderBeforeDue
+	switch event {
+	case constant.ReminderDueToday:
+		templateKey = constant.TemplateReminderDueToday
+	case constant.ReminderOverdue:
+		templateKey = constant.TemplateReminderOverdue
+	}
+	return e.queue(tx, constant.NotificationReminder, []string{userId}, assessmentSeq, templateKey, map[string]interface{}{
+		"endDate":        dueDate,
+		"dueDate":        dueDate,
+		"event":          event,
+		"assessmentName": asmt.AssessmentDesc,
+		"isManager":      false,
+	})
+}
+
+// QueueEscalationMail tells a manager that one of their users is daysOverdue days late on an
+// assessment.
+func (e *NotificationServiceImpl) QueueEscalationMail(tx *gorm.DB, managerId, userId, assessmentSeq string, dueDate time.Time, daysOverdue int) error {
+	asmt, err := e.getAssessment(assessmentSeq)
+	if err != nil {
+		return err
+	}
+	user, err := e.userRepo.FindByUserId(userId)
+	if err != nil {
+		return err
+	}
+	return e.queue(tx, constant.NotificationEscalation, []string{managerId}, assessmentSeq, constant.TemplateEscalation, map[string]interface{}{
+		"endDate":        dueDate,
+		"dueDate":        dueDate,
+		"daysOverdue":    daysOverdue,
+		"employeeName":   fmt.Sprintf("%s %s", user.FirstName, user.LastName),
+		"assessmentName": asmt.AssessmentDesc,
+		"isManager":      true,
+	})
+}
+
+// QueueResultMail tells a user their released result of an assessment, with a link to their
+// certificate when they passed one that grants it.
+func (e *NotificationServiceImpl) QueueResultMail(tx *gorm.DB, assessmentSeq string, result models.ReleasedResult) error {
+	asmt, err := e.getAssessment(assessmentSeq)
+	if err != nil {
+		return err
+	}
+	certificateLink := ""
+	if result.Passed && result.Certificate {
+		certificateLink = config.PropConfig.Notify.CertificateLink
+	}
+	return e.queue(tx, constant.NotificationResult, []string{result.UserID}, assessmentSeq, constant.TemplateResultPublished, map[string]interface{}{
+		"assessmentName":  asmt.AssessmentDesc,
+		"marksObtained":   result.MarksObtained,
+		"totalMarks":      result.TotalMarks,
+		"score":           fmt.Sprintf("%.0f%%", result.Percentage),
+		"passed":          result.Passed,
+		"certificateLink": certificateLink,
+		"isManager":       false,
+	})
+}
+
+// QueueCertificateIssuedMail tells a user they were certified until expiresOn.
+func (e *NotificationServiceImpl) QueueCertificateIssuedMail(tx *gorm.DB, userId, assessmentSeq string, expiresOn time.Time) error {
+	asmt, err := e.getAssessment(assessmentSeq)
+	if err != nil {
+		return err
+	}
+	return e.queue(tx, constant.NotificationCertificate, []string{userId}, assessmentSeq, constant.TemplateCertificateIssued, map[string]interface{}{
+		"expiresOn":      expiresOn,
+		"assessmentName": asmt.AssessmentDesc,
+		"isManager":      false,
+	})
+}
+
+// QueueDigestMail sends a manager or admin their digest, with the data of digestTemplateData.
+func (e *NotificationServiceImpl) QueueDigestMail(tx *gorm.DB, userId string, data map[string]interface{}) error {
+	return e.queue(tx, constant.NotificationDigest, []string{userId}, "", constant.TemplateDigest, data)
+}
+
+// QueueContactReplyMail answers a user's contact form message.
+func (e *NotificationServiceImpl) QueueContactReplyMail(tx *gorm.DB, userId string, contact models.ContactUsResponse, reply string) error {
+	return e.queue(tx, constant.NotificationContactReply, []string{userId}, "", constant.TemplateContactReply, map[string]interface{}{
+		"subject":   contact.Subject,
+		"question":  contact.Question,
+		"reply":     reply,
+		"isManager": false,
+	})
+}
+
+// Deliver renders an outbox notification in its recipient's locale and sends it through the
+// channel configured for its kind.
+func (e *NotificationServiceImpl) Deliver(message *models.NotificationOutbox) error {
+	name := channelFor(message.Kind)
+	channel, ok := e.channels[name]
+	if !ok {
+		return fmt.Errorf("unknown notification channel %q", name)
+	}
+	user, err := e.userRepo.FindByUserId(message.RecipientID)
+	if err != nil {
+		return fmt.Errorf("recipient %s: %w", message.RecipientID, err)
+	}
+	data := map[string]interface{}{}
+	if len(message.Payload) > 0 {
+		if err := json.Unmarshal(message.Payload, &data); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+	}
+	data["userName"] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
+	data["assessmentLink"] = config.PropConfig.Notify.AssessmentLink
+
+	rendered, err := e.templateService.Render(message.TemplateKey, user.Locale, data)
+	if err != nil {
+		return err
+	}
+	return channel.Send(OutboundNotification{
+		Message:   message,
+		Recipient: user,
+		Rendered:  rendered,
+		Data:      data,
+	})
 }
 
 func (s *NotificationServiceImpl) RegisterUserInNotify(fcmToken, phone *string, email string) (uuid.UUID, error) {
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationTemplateService keeps the notification templates and renders them in the
+// recipient's locale.
+type NotificationTemplateService interface {
+	Render(key, locale string, data map[string]interface{}) (*models.RenderedNotification, error)
+	List(key string) ([]models.NotificationTemplate, error)
+	Save(ctx context.Context, req models.SaveNotificationTemplateRequest, userId string) (*models.NotificationTemplate, error)
+	Delete(id int64) error
+	Preview(req models.PreviewNotificationTemplateRequest) (*models.RenderedNotification, error)
+}
+
+type NotificationTemplateServiceImpl struct {
+	templateRepo repository.NotificationTemplateRepository
+	db           *gorm.DB
+}
+
+func NewNotificationTemplateService(templateRepo repository.NotificationTemplateRepository, db *gorm.DB) NotificationTemplateService {
+	return &NotificationTemplateServiceImpl{templateRepo: templateRepo, db: db}
+}
+
+// defaultNotificationTemplates are used for keys without any template in the database.
+var defaultNotificationTemplates = map[string]models.NotificationTemplate{
+	constant.TemplateDistribution: {
+		Subject: "New assessment: {{.assessmentName}}",
+		Body:    `<p>Hello {{.userName}},</p><p>You have been assigned the assessment <b>{{.assessmentName}}</b>, open until {{date .endDate}}.</p><p><a href="{{.assessmentLink}}">Take the assessment</a></p>`,
+	},
+	constant.TemplateDistributionManager: {
+		Subject: "Assessment for your team: {{.assessmentName}}",
+		Body:    `<p>Hello {{.userName}},</p><p>The assessment <b>{{.assessmentName}}</b> is available to distribute to your team until {{date .endDate}}.</p><p><a href="{{.assessmentLink}}">Open the portal</a></p>`,
+	},
+	constant.TemplateCertificateExpiry: {
+		Subject: "Your certification for {{.assessmentName}} expires soon",
+		Body:    `<p>Hello {{.userName}},</p><p>Your certification for <b>{{.assessmentName}}</b> expires on {{date .expiresOn}}. Please take the assessment again to renew it.</p><p><a href="{{.assessmentLink}}">Take the assessment</a></p>`,
+	},
+	constant.TemplateReminderBeforeDue: {
+		Subject: "Reminder: {{.assessmentName}} is due on {{date .dueDate}}",
+		Body:    `<p>Hello {{.userName}},</p><p>The assessment <b>{{.assessmentName}}</b> is due on {{date .dueDate}}.</p><p><a href="{{.assessmentLink}}">Take the assessment</a></p>`,
+	},
+	constant.TemplateReminderDueToday: {
+		Subject: "{{.assessmentName}} is due today",
+		Body:    `<p>Hello {{.userName}},</p><p>The assessment <b>{{.assessmentName}}</b> is due today.</p><p><a href="{{.assessmentLink}}">Take the assessment</a></p>`,
+	},
+	constant.TemplateReminderOverdue: {
+		Subject: "{{.assessmentName}} is overdue",
+		Body:    `<p>Hello {{.userName}},</p><p>The assessment <b>{{.assessmentName}}</b> was due on {{date .dueDate}} and is still not completed.</p><p><a href="{{.assessmentLink}}">Take the assessment</a></p>`,
+	},
+	constant.TemplateResultPublished: {
+		Subject: "Your result for {{.assessmentName}} is available",
+		Body:    `<p>Hello {{.userName}},</p><p>Your result for the assessment <b>{{.assessmentName}}</b> is available: you scored {{.score}} and {{if .passed}}passed{{else}}did not pass{{end}}.</p><p><a href="{{.assessmentLink}}">View your result</a></p>{{if .certificateLink}}<p><a href="{{.certificateLink}}">Download your certificate</a></p>{{end}}`,
+	},
+	constant.TemplateCertificateIssued: {
+		Subject: "You are certified for {{.assessmentName}}",
+		Body:    `<p>Hello {{.userName}},</p><p>You passed <b>{{.assessmentName}}</b>. Your certification is valid until {{date .expiresOn}}.</p><p><a href="{{.assessmentLink}}">View your certificate</a></p>`,
+	},
+	constant.TemplateDigest: {
+		Subject: "Assessment digest, {{date .from}} to {{date .to}}",
+		Body: `<p>Hello {{.userName}},</p><p>Here is what happened {{if .teamOnly}}in your team {{end}}from {{date .from}} to {{date .to}}.</p>` +
+			`<h3>Completed ({{.completionCount}})</h3>{{if .completions}}<ul>{{range .completions}}<li>{{.userName}}: {{.assessmentName}}, {{.score}} on {{date .date}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}` +
+			`<h3>Failed ({{.failureCount}})</h3>{{if .failures}}<ul>{{range .failures}}<li>{{.userName}}: {{.assessmentName}}, {{.score}} on {{date .date}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}` +
+			`<h3>Overdue ({{.overdueCount}})</h3>{{if .overdue}}<ul>{{range .overdue}}<li>{{.userName}}: {{.assessmentName}}, due {{date .date}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}` +
+			`<h3>Coming up ({{.upcomingCount}})</h3>{{if .upcoming}}<ul>{{range .upcoming}}<li>{{.userName}}: {{.assessmentName}}, due {{date .date}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}` +
+			`<p><a href="{{.assessmentLink}}">Open the portal</a></p>`,
+	},
+	constant.TemplateContactReply: {
+		Subject: "Re: {{.subject}}",
+		Body:    `<p>Hello {{.userName}},</p><p>{{.reply}}</p><p>You wrote:</p><blockquote>{{.question}}</blockquote>`,
+	},
+	constant.TemplateEscalation: {
+		Subject: "{{.employeeName}} is {{.daysOverdue}} days late on {{.assessmentName}}",
+		Body:    `<p>Hello {{.userName}},</p><p>{{.employeeName}} has not completed the assessment <b>{{.assessmentName}}</b>, due on {{date .dueDate}}, {{.daysOverdue}} days ago.</p>`,
+	},
+}
+
+// templateDate formats a date of the template data, a time or the RFC 3339 text it becomes
+// once stored in the outbox.
+func templateDate(v interface{}) string {
+	switch d := v.(type) {
+	case time.Time:
+		return d.Format("02 Jan 2006")
+	case *time.Time:
+		if d != nil {
+			return d.Format("02 Jan 2006")
+		}
+	case string:
+		if t, err := time.Parse(time.RFC3339, d); err == nil {
+			return t.Format("02 Jan 2006")
+		}
+		return d
+	}
+	return ""
+}
+
+// renderTemplate executes a template's subject and body with data.
+func renderTemplate(tmpl models.NotificationTemplate, data map[string]interface{}) (*models.RenderedNotification, error) {
+	subject, err := template.New("subject").Funcs(template.FuncMap{"date": templateDate}).Parse(tmpl.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	body, err := htmltemplate.New("body").Funcs(htmltemplate.FuncMap{"date": templateDate}).Parse(tmpl.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	var subjectOut, bodyOut bytes.Buffer
+	if err := subject.Execute(&subjectOut, data); err != nil {
+		return nil, fmt.Errorf("subject template: %w", err)
+	}
+	if err := body.Execute(&bodyOut, data); err != nil {
+		return nil, fmt.Errorf("body template: %w", err)
+	}
+	return &models.RenderedNotification{
+		Key:        tmpl.Key,
+		Locale:     tmpl.Locale,
+		Subject:    subjectOut.String(),
+		Body:       bodyOut.String(),
+		NotifyCode: tmpl.NotifyCode,
+	}, nil
+}
+
+// Render executes the best variant of the template for the locale: the locale itself, its
+// bare language, then the default locale, then the built-in template.
+func (s *NotificationTemplateServiceImpl) Render(key, locale string, data map[string]interface{}) (*models.RenderedNotification, error) {
+	variants, err := s.templateRepo.GetVariants(key)
+	if err != nil {
+		return nil, err
+	}
+	locales := make([]string, len(variants))
+	for i, v := range variants {
+		locales[i] = v.Locale
+	}
+	chosen := utils.MatchLocale(locale, locales)
+	for _, v := range variants {
+		if v.Locale == chosen {
+			return renderTemplate(v, data)
+		}
+	}
+
+	tmpl, ok := defaultNotificationTemplates[key]
+	if !ok {
+		return nil, fmt.Errorf("no notification template %q", key)
+	}
+	tmpl.Key = key
+	tmpl.Locale = constant.DefaultLocale
+	return renderTemplate(tmpl, data)
+}
+
+func (s *NotificationTemplateServiceImpl) List(key string) ([]models.NotificationTemplate, error) {
+	list, err := s.templateRepo.List(key)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.NotificationTemplate{}
+	}
+	return list, nil
+}
+
+// Save creates or replaces the template of a key in a locale, after checking it renders.
+func (s *NotificationTemplateServiceImpl) Save(ctx context.Context, req models.SaveNotificationTemplateRequest, userId string) (*models.NotificationTemplate, error) {
+	if _, ok := defaultNotificationTemplates[req.Key]; !ok {
+		return nil, fmt.Errorf("unknown notification template %q", req.Key)
+	}
+	locale, err := utils.NormalizeLocale(req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := renderTemplate(models.NotificationTemplate{Subject: req.Subject, Body: req.Body}, map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := s.templateRepo.Find(req.Key, locale)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if tmpl == nil {
+		tmpl = &models.NotificationTemplate{
+			Key:       req.Key,
+			Locale:    locale,
+			CreatedOn: now,
+			CreatedBy: userId,
+		}
+	}
+	tmpl.Subject = req.Subject
+	tmpl.Body = req.Body
+	tmpl.NotifyCode = req.NotifyCode
+	tmpl.ModifiedOn = now
+	tmpl.ModifiedBy = userId
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := s.templateRepo.Save(tx, tmpl); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (s *NotificationTemplateServiceImpl) Delete(id int64) error {
+	return s.templateRepo.Delete(id)
+}
+
+// Preview renders a template as a recipient in the locale would receive it.
+func (s *NotificationTemplateServiceImpl) Preview(req models.PreviewNotificationTemplateRequest) (*models.RenderedNotification, error) {
+	locale, err := utils.NormalizeLocale(req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	if req.Data == nil {
+		req.Data = map[string]interface{}{}
+	}
+	return s.Render(req.Key, locale, req.Data)
+}
@@ -0,0 +1,519 @@
+package services
+
+import (
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuestionEditService applies admin edits to many questions at once. Every operation except a
+// find/replace preview runs in one transaction and records each changed value in the edit
+// history under a shared batch id.
+type QuestionEditService interface {
+	Retag(ctx context.Context, req models.BulkRetagRequest, userId string) (*models.BulkEditResult, error)
+	SetDifficulty(ctx context.Context, req models.BulkDifficultyRequest, userId string) (*models.BulkEditResult, error)
+	SetPoints(ctx context.Context, req models.BulkPointsRequest, userId string) (*models.BulkEditResult, error)
+	SetStatus(ctx context.Context, req models.BulkStatusRequest, userId string) (*models.BulkEditResult, error)
+	FindReplace(ctx context.Context, req models.FindReplaceRequest, userId string) (*models.BulkEditResult, error)
+	ListHistory(questionID int64, batchID string, limit, offset int) ([]models.QuestionEditHistory, int64, error)
+}
+
+type QuestionEditServiceImpl struct {
+	editRepo       repository.QuestionEditRepository
+	assessmentRepo repository.AssessmentRepository
+	db             *gorm.DB
+}
+
+func NewQuestionEditService(editRepo repository.QuestionEditRepository, assessmentRepo repository.AssessmentRepository, db *gorm.DB) QuestionEditService {
+	return &QuestionEditServiceImpl{
+		editRepo:       editRepo,
+		assessmentRepo: assessmentRepo,
+		db:             db,
+	}
+}
+
+// runBatch runs edit in a transaction and stores the changes it returns as one history batch.
+func (s *QuestionEditServiceImpl) runBatch(ctx context.Context, operation constant.BulkEditOperation, userId string, edit func(tx *gorm.DB) ([]models.QuestionEditChange, error)) (*models.BulkEditResult, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	changes, err := edit(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := &models.BulkEditResult{
+		Operation: string(operation),
+		Affected:  countEditedQuestions(changes),
+		Changes:   changes,
+	}
+	if len(changes) == 0 {
+		// nothing changed, so there is nothing to record
+		return result, tx.Commit().Error
+	}
+	result.BatchID = uuid.NewString()
+
+	now := time.Now()
+	entries := make([]models.QuestionEditHistory, 0, len(changes))
+	for _, c := range changes {
+		entries = append(entries, models.QuestionEditHistory{
+			BatchID:            result.BatchID,
+			Operation:          result.Operation,
+			QuestionID:         c.QuestionID,
+			OptionID:           c.OptionID,
+			AssessmentSequence: c.AssessmentSequence,
+			Field:              c.Field,
+			OldValue:           c.Before,
+			NewValue:           c.After,
+			EditedOn:           now,
+			EditedBy:           userId,
+		})
+	}
+	if err := s.editRepo.CreateHistory(tx, entries); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to record edit history: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Retag adds and removes tags on the questions. Added tags are mapped with their parents, as
+// when a question is created.
+func (s *QuestionEditServiceImpl) Retag(ctx context.Context, req models.BulkRetagRequest, userId string) (*models.BulkEditResult, error) {
+	if len(req.AddTags) == 0 && len(req.RemoveTagIDs) == 0 && !req.ReplaceTags {
+		return nil, errors.New("nothing to change: give add_tags, remove_tag_ids or replace_tags")
+	}
+
+	return s.runBatch(ctx, constant.BulkRetag, userId, func(tx *gorm.DB) ([]models.QuestionEditChange, error) {
+		questionIDs, err := s.existingQuestionIDs(tx, req.QuestionIDs)
+		if err != nil {
+			return nil, err
+		}
+		before, err := s.tagNamesByQuestion(tx, questionIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.ReplaceTags {
+			if err := s.editRepo.RemoveQuestionTags(tx, questionIDs, nil, userId); err != nil {
+				return nil, err
+			}
+		} else if len(req.RemoveTagIDs) > 0 {
+			if err := s.editRepo.RemoveQuestionTags(tx, questionIDs, req.RemoveTagIDs, userId); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, tagReq := range req.AddTags {
+			tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, userId)
+			if err != nil {
+				return nil, err
+			}
+			for _, questionID := range questionIDs {
+				for _, tagID := range tagIDs {
+					if err := s.assessmentRepo.CreateQuestionTagMappingWithParents(tx, questionID, tagID, userId); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		after, err := s.tagNamesByQuestion(tx, questionIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		var changes []models.QuestionEditChange
+		var changed []int64
+		for _, questionID := range questionIDs {
+			if before[questionID] == after[questionID] {
+				continue
+			}
+			changed = append(changed, questionID)
+			changes = append(changes, models.QuestionEditChange{
+				QuestionID: questionID,
+				Field:      "tags",
+				Before:     before[questionID],
+				After:      after[questionID],
+			})
+		}
+		if len(changed) > 0 {
+			if err := s.editRepo.TouchQuestions(tx, changed, userId); err != nil {
+				return nil, err
+			}
+		}
+		return changes, nil
+	})
+}
+
+// SetDifficulty updates the difficulty of the questions' assessment mappings, which is where
+// the bank keeps difficulty.
+func (s *QuestionEditServiceImpl) SetDifficulty(ctx context.Context, req models.BulkDifficultyRequest, userId string) (*models.BulkEditResult, error) {
+	level := strings.ToLower(strings.TrimSpace(req.DifficultyLevel))
+	switch constant.DifficultyLevel(level) {
+	case constant.Easy, constant.Medium, constant.Hard:
+	default:
+		return nil, fmt.Errorf("unsupported difficulty level %q: use easy, medium or hard", req.DifficultyLevel)
+	}
+
+	return s.runBatch(ctx, constant.BulkDifficulty, userId, func(tx *gorm.DB) ([]models.QuestionEditChange, error) {
+		mappings, err := s.editRepo.GetAssessmentQuestionMappings(tx, req.AssessmentSequence, req.QuestionIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(mappings) == 0 {
+			return nil, errors.New("the questions are not used in any matching assessment")
+		}
+
+		var mappingIDs []int64
+		var changes []models.QuestionEditChange
+		for _, m := range mappings {
+			if m.DifficultyLevel == level {
+				continue
+			}
+			mappingIDs = append(mappingIDs, m.AssessmentQuestionID)
+			changes = append(changes, models.QuestionEditChange{
+				QuestionID:         m.QuestionID,
+				AssessmentSequence: m.AssessmentSequence,
+				Field:              "difficulty_level",
+				Before:             m.DifficultyLevel,
+				After:              level,
+			})
+		}
+		if len(mappingIDs) == 0 {
+			return changes, nil
+		}
+
+		err = s.editRepo.UpdateAssessmentQuestions(tx, mappingIDs, map[string]interface{}{
+			"difficulty_level": level,
+			"modified_on":      time.Now(),
+			"modified_by":      userId,
+		})
+		return changes, err
+	})
+}
+
+// SetPoints sets correct and/or negative points for the questions of an assessment and keeps
+// the assessment's total marks in step. Results already recorded keep the scores they were
+// given; only attempts scored afterwards use the new points.
+func (s *QuestionEditServiceImpl) SetPoints(ctx context.Context, req models.BulkPointsRequest, userId string) (*models.BulkEditResult, error) {
+	if req.CorrectPoints == nil && req.NegativePoints == nil {
+		return nil, errors.New("nothing to change: give correct_points and/or negative_points")
+	}
+	if (req.CorrectPoints != nil && *req.CorrectPoints < 0) || (req.NegativePoints != nil && *req.NegativePoints < 0) {
+		return nil, errors.New("points cannot be negative")
+	}
+
+	return s.runBatch(ctx, constant.BulkPoints, userId, func(tx *gorm.DB) ([]models.QuestionEditChange, error) {
+		assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+		if err != nil {
+			return nil, err
+		}
+		if assessment == nil {
+			return nil, fmt.Errorf("assessment %s not found", req.AssessmentSequence)
+		}
+
+		mappings, err := s.editRepo.GetAssessmentQuestionMappings(tx, req.AssessmentSequence, req.QuestionIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(mappings) == 0 {
+			return nil, fmt.Errorf("no matching questions in assessment %s", req.AssessmentSequence)
+		}
+
+		var mappingIDs []int64
+		var changes []models.QuestionEditChange
+		for _, m := range mappings {
+			touched := false
+			if req.CorrectPoints != nil && m.CorrectPoints != *req.CorrectPoints {
+				changes = append(changes, pointsChange(m, "correct_points", m.CorrectPoints, *req.CorrectPoints))
+				touched = true
+			}
+			if req.NegativePoints != nil && m.NegativePoints != *req.NegativePoints {
+				changes = append(changes, pointsChange(m, "negative_points", m.NegativePoints, *req.NegativePoints))
+				touched = true
+			}
+			if touched {
+				mappingIDs = append(mappingIDs, m.AssessmentQuestionID)
+			}
+		}
+		if len(mappingIDs) == 0 {
+			return changes, nil
+		}
+
+		updates := map[string]interface{}{
+			"modified_on": time.Now(),
+			"modified_by": userId,
+		}
+		if req.CorrectPoints != nil {
+			updates["correct_points"] = *req.CorrectPoints
+		}
+		if req.NegativePoints != nil {
+			updates["negative_points"] = *req.NegativePoints
+		}
+		if err := s.editRepo.UpdateAssessmentQuestions(tx, mappingIDs, updates); err != nil {
+			return nil, err
+		}
+
+		if req.CorrectPoints != nil {
+			all, err := s.editRepo.GetAssessmentQuestionMappings(tx, req.AssessmentSequence, nil)
+			if err != nil {
+				return nil, err
+			}
+			var marks int64
+			for _, m := range all {
+				marks += m.CorrectPoints
+			}
+			if marks != assessment.Marks {
+				if err := s.editRepo.UpdateAssessmentMarks(tx, req.AssessmentSequence, marks, userId); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return changes, nil
+	})
+}
+
+func (s *QuestionEditServiceImpl) SetStatus(ctx context.Context, req models.BulkStatusRequest, userId string) (*models.BulkEditResult, error) {
+	return s.runBatch(ctx, constant.BulkStatus, userId, func(tx *gorm.DB) ([]models.QuestionEditChange, error) {
+		questions, err := s.editRepo.GetQuestions(tx, req.QuestionIDs)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAllQuestionsFound(req.QuestionIDs, questions); err != nil {
+			return nil, err
+		}
+
+		var changed []int64
+		var changes []models.QuestionEditChange
+		for _, q := range questions {
+			if q.IsActive == *req.IsActive {
+				continue
+			}
+			changed = append(changed, q.QuestionID)
+			changes = append(changes, models.QuestionEditChange{
+				QuestionID: q.QuestionID,
+				Field:      "is_active",
+				Before:     strconv.FormatBool(q.IsActive),
+				After:      strconv.FormatBool(*req.IsActive),
+			})
+		}
+		if len(changed) == 0 {
+			return changes, nil
+		}
+		return changes, s.editRepo.SetQuestionsActive(tx, changed, *req.IsActive, userId)
+	})
+}
+
+// FindReplace replaces text in question stems and options. Only text, HTML and Markdown
+// content is searched; media content is left alone. With Preview the changes are only
+// computed, outside any transaction, and nothing is saved or recorded.
+//
+// Question content is shared by every assessment that uses the bank question, so selecting
+// by assessment_sequence edits those questions in all of their assessments, not just a copy.
+func (s *QuestionEditServiceImpl) FindReplace(ctx context.Context, req models.FindReplaceRequest, userId string) (*models.BulkEditResult, error) {
+	if len(req.QuestionIDs) == 0 && strings.TrimSpace(req.AssessmentSequence) == "" {
+		return nil, errors.New("select questions by question_ids or assessment_sequence")
+	}
+	scope := strings.ToLower(strings.TrimSpace(req.Scope))
+	switch scope {
+	case "":
+		scope = constant.FindReplaceAll
+	case constant.FindReplaceAll, constant.FindReplaceStems, constant.FindReplaceOptions:
+	default:
+		return nil, fmt.Errorf("unsupported scope %q: use stems, options or all", req.Scope)
+	}
+
+	pattern := regexp.QuoteMeta(req.Find)
+	if !req.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	matcher := regexp.MustCompile(pattern)
+
+	findChanges := func(tx *gorm.DB) ([]models.QuestionEditChange, []models.EditableContent, error) {
+		contents, err := s.editRepo.GetEditableContents(tx, strings.TrimSpace(req.AssessmentSequence), req.QuestionIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var changes []models.QuestionEditChange
+		var edited []models.EditableContent
+		for _, c := range contents {
+			isOption := c.OptionID != nil
+			if (isOption && scope == constant.FindReplaceStems) || (!isOption && scope == constant.FindReplaceOptions) {
+				continue
+			}
+			if !isSearchableContent(c.ContentTypeID) || !matcher.MatchString(c.Value) {
+				continue
+			}
+
+			replaced := matcher.ReplaceAllLiteralString(c.Value, req.Replace)
+			if replaced == c.Value {
+				continue
+			}
+			field := "stem"
+			if isOption {
+				field = "option"
+			}
+			changes = append(changes, models.QuestionEditChange{
+				QuestionID: c.QuestionID,
+				OptionID:   c.OptionID,
+				Field:      field,
+				Before:     c.Value,
+				After:      replaced,
+			})
+			c.Value = replaced
+			edited = append(edited, c)
+		}
+		return changes, edited, nil
+	}
+
+	if req.Preview {
+		changes, _, err := findChanges(s.db.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return &models.BulkEditResult{
+			Operation: string(constant.BulkFindReplace),
+			Preview:   true,
+			Affected:  countEditedQuestions(changes),
+			Changes:   changes,
+		}, nil
+	}
+
+	return s.runBatch(ctx, constant.BulkFindReplace, userId, func(tx *gorm.DB) ([]models.QuestionEditChange, error) {
+		changes, edited, err := findChanges(tx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range edited {
+			if err := s.editRepo.UpdateContentValue(tx, c.ContentID, c.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		questionIDs := editedQuestionIDs(changes)
+		if len(questionIDs) > 0 {
+			if err := s.editRepo.TouchQuestions(tx, questionIDs, userId); err != nil {
+				return nil, err
+			}
+		}
+		return changes, nil
+	})
+}
+
+func (s *QuestionEditServiceImpl) ListHistory(questionID int64, batchID string, limit, offset int) ([]models.QuestionEditHistory, int64, error) {
+	return s.editRepo.ListHistory(questionID, batchID, limit, offset)
+}
+
+// existingQuestionIDs returns the distinct requested ids, failing if any is unknown.
+func (s *QuestionEditServiceImpl) existingQuestionIDs(tx *gorm.DB, questionIDs []int64) ([]int64, error) {
+	questions, err := s.editRepo.GetQuestions(tx, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAllQuestionsFound(questionIDs, questions); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.QuestionID)
+	}
+	return ids, nil
+}
+
+// tagNamesByQuestion renders each question's current tags as a sorted, comma separated list.
+func (s *QuestionEditServiceImpl) tagNamesByQuestion(tx *gorm.DB, questionIDs []int64) (map[int64]string, error) {
+	tags, err := s.editRepo.GetQuestionTags(tx, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int64][]string{}
+	for _, t := range tags {
+		names[t.QuestionID] = append(names[t.QuestionID], t.Tag)
+	}
+	joined := make(map[int64]string, len(names))
+	for id, list := range names {
+		sort.Strings(list)
+		joined[id] = strings.Join(dedupeStrings(list), ", ")
+	}
+	return joined, nil
+}
+
+func checkAllQuestionsFound(requested []int64, found []models.QuestionMst) error {
+	known := make(map[int64]bool, len(found))
+	for _, q := range found {
+		known[q.QuestionID] = true
+	}
+	var missing []string
+	for _, id := range requested {
+		if !known[id] {
+			missing = append(missing, strconv.FormatInt(id, 10))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("questions not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func pointsChange(m models.AssessmentQuestionMst, field string, before, after int64) models.QuestionEditChange {
+	return models.QuestionEditChange{
+		QuestionID:         m.QuestionID,
+		AssessmentSequence: m.AssessmentSequence,
+		Field:              field,
+		Before:             strconv.FormatInt(before, 10),
+		After:              strconv.FormatInt(after, 10),
+	}
+}
+
+func isSearchableContent(contentTypeID int64) bool {
+	switch contentTypeID {
+	case constant.ContentTypeText, constant.ContentTypeHTML, constant.ContentTypeMarkdown:
+		return true
+	}
+	return false
+}
+
+func editedQuestionIDs(changes []models.QuestionEditChange) []int64 {
+	seen := map[int64]bool{}
+	var ids []int64
+	for _, c := range changes {
+		if !seen[c.QuestionID] {
+			seen[c.QuestionID] = true
+			ids = append(ids, c.QuestionID)
+		}
+	}
+	return ids
+}
+
+func countEditedQuestions(changes []models.QuestionEditChange) int {
+	return len(editedQuestionIDs(changes))
+}
+
+func dedupeStrings(sorted []string) []string {
+	out := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"dhl/constant"
 	"dhl/models"
 	"dhl/repository"
 	"time"
@@ -13,21 +14,24 @@ type QuestionService interface {
 
 	CreateMultipleQuestions(
 		requests []models.CreateQuestionRequest,
+		duplicateAction string,
 		createdBy string,
-	) ([]int64, error)
+	) (*models.CreateQuestionsResponse, error)
 }
 
 type QuestionServiceImpl struct {
-	repo           repository.QuestionRepository
-	db             *gorm.DB
-	assessmentRepo repository.AssessmentRepository
+	repo             repository.QuestionRepository
+	db               *gorm.DB
+	assessmentRepo   repository.AssessmentRepository
+	duplicateService DuplicateService
 }
 
-func NewQuestionService(repo repository.QuestionRepository, db *gorm.DB, assessmentRepo repository.AssessmentRepository) QuestionService {
+func NewQuestionService(repo repository.QuestionRepository, db *gorm.DB, assessmentRepo repository.AssessmentRepository, duplicateService DuplicateService) QuestionService {
 	return &QuestionServiceImpl{
-		repo:           repo,
-		db:             db,
-		assessmentRepo: assessmentRepo,
+		repo:             repo,
+		db:               db,
+		assessmentRepo:   assessmentRepo,
+		duplicateService: duplicateService,
 	}
 }
 
@@ -122,17 +126,54 @@ func (s *QuestionServiceImpl) CreateQuestion(
 	return question.QuestionID, nil
 }
 
+// CreateMultipleQuestions adds questions to the bank. Questions resembling existing ones are
+// reported and, depending on duplicateAction or their own choice, created anyway, skipped, or
+// merged: the existing question id is returned and it receives the new question's tags.
 func (s *QuestionServiceImpl) CreateMultipleQuestions(
 	requests []models.CreateQuestionRequest,
+	duplicateAction string,
 	createdBy string,
-) ([]int64, error) {
+) (*models.CreateQuestionsResponse, error) {
+
+	candidates := make([]DuplicateCandidate, 0, len(requests))
+	for _, req := range requests {
+		candidate := DuplicateCandidate{
+			Title:              req.Title,
+			DuplicateAction:    req.DuplicateAction,
+			ExistingQuestionID: req.ExistingQuestionID,
+		}
+		for _, opt := range req.Options {
+			candidate.Options = append(candidate.Options, opt.Label)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	warnings, err := s.duplicateService.Screen(candidates, duplicateAction, 0)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := duplicateWarningsByIndex(warnings)
 
 	tx := s.db.Begin()
 	now := time.Now()
 
-	var questionIDs []int64
+	questionIDs := []int64{}
 
-	for _, req := range requests {
+	for idx, req := range requests {
+
+		if dup, ok := duplicates[idx]; ok {
+			switch constant.DuplicateAction(dup.Action) {
+			case constant.DuplicateSkip:
+				continue
+			case constant.DuplicateMerge:
+				if err := s.tagQuestion(tx, dup.QuestionID, req.Tags, createdBy); err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+				questionIDs = append(questionIDs, dup.QuestionID)
+				continue
+			}
+		}
 
 		content := models.ContentMst{
 			ContentTypeID: 1,
@@ -189,24 +230,9 @@ func (s *QuestionServiceImpl) CreateMultipleQuestions(
 			}
 		}
 
-		for _, tagReq := range req.Tags {
-			tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, createdBy)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
-			}
-
-			for _, tagID := range tagIDs {
-				if err := s.assessmentRepo.CreateQuestionTagMappingWithParents(
-					tx,
-					question.QuestionID,
-					tagID,
-					createdBy,
-				); err != nil {
-					tx.Rollback()
-					return nil, err
-				}
-			}
+		if err := s.tagQuestion(tx, question.QuestionID, req.Tags, createdBy); err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 
 		questionIDs = append(questionIDs, question.QuestionID)
@@ -216,5 +242,29 @@ func (s *QuestionServiceImpl) CreateMultipleQuestions(
 		return nil, err
 	}
 
-	return questionIDs, nil
+	return &models.CreateQuestionsResponse{
+		QuestionIDs: questionIDs,
+		Duplicates:  warnings,
+	}, nil
+}
+
+func (s *QuestionServiceImpl) tagQuestion(tx *gorm.DB, questionID int64, tags []models.TagRequest, createdBy string) error {
+	for _, tagReq := range tags {
+		tagIDs, err := s.assessmentRepo.ProcessTagRequest(tx, tagReq, createdBy)
+		if err != nil {
+			return err
+		}
+
+		for _, tagID := range tagIDs {
+			if err := s.assessmentRepo.CreateQuestionTagMappingWithParents(
+				tx,
+				questionID,
+				tagID,
+				createdBy,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
\ No newline at end of file
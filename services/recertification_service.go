@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecertificationService runs recurring assessments: it certifies passes, starts a new cycle
+// every interval, reassigns users whose certification lapsed and warns them ahead of expiry.
+type RecertificationService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, tx *gorm.DB) error
+	SaveRecurrence(ctx context.Context, req models.SaveRecurrenceRequest, userId string) (*models.AssessmentRecurrence, error)
+	GetRecurrence(assessmentSeq string) (*models.AssessmentRecurrence, error)
+	DeleteRecurrence(ctx context.Context, assessmentSeq, userId string) error
+	ListCertifications(assessmentSeq, status string, limit, offset int) ([]models.CertificationSummary, int64, error)
+	AttachCertificationStatus(users []models.UserFullData) error
+}
+
+type RecertificationServiceImpl struct {
+	recertRepo          repository.RecertificationRepository
+	assessmentRepo      repository.AssessmentRepository
+	notificationService NotificationService
+	webhookService      WebhookService
+	db                  *gorm.DB
+}
+
+func NewRecertificationService(recertRepo repository.RecertificationRepository, assessmentRepo repository.AssessmentRepository, notificationService NotificationService, webhookService WebhookService, db *gorm.DB) RecertificationService {
+	return &RecertificationServiceImpl{
+		recertRepo:          recertRepo,
+		assessmentRepo:      assessmentRepo,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		db:                  db,
+	}
+}
+
+func (s *RecertificationServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "recertification",
+		Interval: config.PropConfig.Scheduler.RecertificationInterval,
+		Run:      s.RunDue,
+	}
+}
+
+// RunDue records new passes first, so a user who passed just before a cycle starts is not
+// reassigned. Notices and events are queued in the same transaction, so a failed run sends none.
+func (s *RecertificationServiceImpl) RunDue(ctx context.Context, tx *gorm.DB) error {
+	now := time.Now()
+
+	issued, err := s.recertRepo.RecordPasses(tx, now)
+	if err != nil {
+		return fmt.Errorf("failed to record passes: %w", err)
+	}
+	for _, cert := range issued {
+		if err := s.notificationService.QueueCertificateIssuedMail(tx, cert.UserID, cert.AssessmentSequence, cert.ExpiresOn); err != nil {
+			return fmt.Errorf("failed to queue certificate notice to %s for %s: %w", cert.UserID, cert.AssessmentSequence, err)
+		}
+		if err := s.webhookService.Publish(tx, constant.EventCertificateIssued, map[string]interface{}{
+			"assessment_sequence": cert.AssessmentSequence,
+			"user_id":             cert.UserID,
+			"expires_on":          cert.ExpiresOn,
+		}); err != nil {
+			return err
+		}
+	}
+	if len(issued) > 0 {
+		log.Printf("Issued %d certifications", len(issued))
+	}
+
+	recurrences, err := s.recertRepo.GetActiveRecurrences(tx)
+	if err != nil {
+		return err
+	}
+	for i := range recurrences {
+		if err := s.runRecurrence(tx, &recurrences[i], now); err != nil {
+			return fmt.Errorf("recurrence of %s: %w", recurrences[i].AssessmentSequence, err)
+		}
+	}
+
+	return s.notifyExpiring(tx, now)
+}
+
+// runRecurrence starts the cycles that are due and reassigns users who need to certify again.
+// Outside a cycle start only expired certifications are reassigned; at a cycle start users who
+// never passed are reassigned too.
+func (s *RecertificationServiceImpl) runRecurrence(tx *gorm.DB, rec *models.AssessmentRecurrence, now time.Time) error {
+	newCycle := false
+	if !rec.NextCycleOn.After(now) {
+		start := rec.NextCycleOn
+		// skip the cycles missed while the scheduler was down
+		for next := start.AddDate(0, rec.IntervalMonths, 0); !next.After(now); next = next.AddDate(0, rec.IntervalMonths, 0) {
+			start = next
+		}
+		if err := s.recertRepo.ReopenForCycle(tx, rec.AssessmentSequence, start, rec.CycleStartedOn, schedulerUser); err != nil {
+			return err
+		}
+		rec.CurrentCycle++
+		rec.CycleStartedOn = start
+		rec.NextCycleOn = start.AddDate(0, rec.IntervalMonths, 0)
+		rec.ModifiedOn = now
+		rec.ModifiedBy = schedulerUser
+		if err := s.recertRepo.SaveRecurrence(tx, rec); err != nil {
+			return err
+		}
+		newCycle = true
+		log.Printf("Started cycle %d of assessment %s", rec.CurrentCycle, rec.AssessmentSequence)
+	}
+
+	userIDs, err := s.recertRepo.ReassignLapsed(tx, rec.AssessmentSequence, now, newCycle, schedulerUser)
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	log.Printf("Reassigned %d users to assessment %s", len(userIDs), rec.AssessmentSequence)
+	if err := s.notificationService.QueueDistributeAssessmentMail(tx, userIDs, rec.AssessmentSequence, false); err != nil {
+		return err
+	}
+	return s.webhookService.PublishAssignments(tx, rec.AssessmentSequence, userIDs)
+}
+
+// notifyExpiring queues one notice per certification.
+func (s *RecertificationServiceImpl) notifyExpiring(tx *gorm.DB, now time.Time) error {
+	certs, err := s.recertRepo.GetExpiringToNotify(tx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find expiring certifications: %w", err)
+	}
+
+	notified := make([]int64, 0, len(certs))
+	for _, cert := range certs {
+		if err := s.notificationService.QueueCertificateExpiryMail(tx, cert.UserID, cert.AssessmentSequence, cert.ExpiresOn); err != nil {
+			return fmt.Errorf("failed to queue expiry notice to %s for %s: %w", cert.UserID, cert.AssessmentSequence, err)
+		}
+		notified = append(notified, cert.ID)
+	}
+	return s.recertRepo.MarkExpiryNotified(tx, notified, now)
+}
+
+// SaveRecurrence creates or updates the rule of an assessment. A new rule starts at cycle 1;
+// its next cycle begins at FirstCycleOn, or one interval from now.
+func (s *RecertificationServiceImpl) SaveRecurrence(ctx context.Context, req models.SaveRecurrenceRequest, userId string) (*models.AssessmentRecurrence, error) {
+	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	if asmt == nil {
+		return nil, errors.New("assessment not found")
+	}
+	now := time.Now()
+	if req.FirstCycleOn != nil && !req.FirstCycleOn.After(now) {
+		return nil, errors.New("first_cycle_on must be in the future")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	rec, err := s.recertRepo.GetRecurrence(tx, req.AssessmentSequence)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+	if rec == nil {
+		rec = &models.AssessmentRecurrence{
+			AssessmentSequence: req.AssessmentSequence,
+			CurrentCycle:       1,
+			CycleStartedOn:     now,
+			CreatedOn:          now,
+			CreatedBy:          userId,
+		}
+	}
+	if req.FirstCycleOn != nil {
+		rec.NextCycleOn = *req.FirstCycleOn
+	} else if rec.ID == 0 || !rec.IsActive || rec.IntervalMonths != req.IntervalMonths {
+		// a cycle already overdue under the new interval starts on the next run
+		rec.NextCycleOn = rec.CycleStartedOn.AddDate(0, req.IntervalMonths, 0)
+	}
+	rec.IntervalMonths = req.IntervalMonths
+	rec.NotifyDaysBefore = req.NotifyDaysBefore
+	rec.IsActive = true
+	rec.ModifiedOn = now
+	rec.ModifiedBy = userId
+
+	if err := s.recertRepo.SaveRecurrence(tx, rec); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (s *RecertificationServiceImpl) GetRecurrence(assessmentSeq string) (*models.AssessmentRecurrence, error) {
+	rec, err := s.recertRepo.GetRecurrence(s.db, assessmentSeq)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("assessment has no recurrence")
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// DeleteRecurrence stops the cycles; issued certifications are kept.
+func (s *RecertificationServiceImpl) DeleteRecurrence(ctx context.Context, assessmentSeq, userId string) error {
+	tx := s.db.WithContext(ctx).Begin()
+	rec, err := s.recertRepo.GetRecurrence(tx, assessmentSeq)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("assessment has no recurrence")
+		}
+		return err
+	}
+	rec.IsActive = false
+	rec.ModifiedOn = time.Now()
+	rec.ModifiedBy = userId
+	if err := s.recertRepo.SaveRecurrence(tx, rec); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (s *RecertificationServiceImpl) ListCertifications(assessmentSeq, status string, limit, offset int) ([]models.CertificationSummary, int64, error) {
+	switch status {
+	case "", constant.CertificationCurrent, constant.CertificationExpiring, constant.CertificationExpired:
+	default:
+		return nil, 0, fmt.Errorf("unknown certification status %q", status)
+	}
+	certs, total, err := s.recertRepo.ListCertifications(assessmentSeq, status, time.Now(), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if certs == nil {
+		certs = []models.CertificationSummary{}
+	}
+	return certs, total, nil
+}
+
+// AttachCertificationStatus fills the certifications of each user and sums them up in the
+// worst status among them.
+func (s *RecertificationServiceImpl) AttachCertificationStatus(users []models.UserFullData) error {
+	userIDs := make([]string, len(users))
+	for i, u := range users {
+		userIDs[i] = u.UserID.String()
+	}
+	certs, err := s.recertRepo.GetLatestForUsers(userIDs, time.Now())
+	if err != nil {
+		return err
+	}
+
+	byUser := make(map[string][]models.CertificationSummary)
+	for _, c := range certs {
+		c.UserName = ""
+		byUser[c.UserID] = append(byUser[c.UserID], c)
+	}
+	rank := map[string]int{
+		constant.CertificationCurrent:  1,
+		constant.CertificationExpiring: 2,
+		constant.CertificationExpired:  3,
+	}
+	for i := range users {
+		users[i].Certifications = byUser[userIDs[i]]
+		for _, c := range users[i].Certifications {
+			if rank[c.Status] > rank[users[i].CertificationStatus] {
+				users[i].CertificationStatus = c.Status
+			}
+		}
+	}
+	return nil
+}
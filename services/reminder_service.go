@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReminderService reminds users of assessments coming due or overdue, and escalates to their
+// managers once they are late long enough.
+type ReminderService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, tx *gorm.DB) error
+	SavePolicy(ctx context.Context, req models.SaveReminderPolicyRequest, userId string) (*models.ReminderPolicy, error)
+	GetPolicy(assessmentSeq string) (*models.ReminderPolicy, error)
+	DeletePolicy(assessmentSeq string) error
+	ListReminders(filter models.ReminderLogFilter, limit, offset int) ([]models.ReminderLog, int64, error)
+}
+
+type ReminderServiceImpl struct {
+	reminderRepo        repository.ReminderRepository
+	assessmentRepo      repository.AssessmentRepository
+	notificationService NotificationService
+	db                  *gorm.DB
+}
+
+func NewReminderService(reminderRepo repository.ReminderRepository, assessmentRepo repository.AssessmentRepository, notificationService NotificationService, db *gorm.DB) ReminderService {
+	return &ReminderServiceImpl{
+		reminderRepo:        reminderRepo,
+		assessmentRepo:      assessmentRepo,
+		notificationService: notificationService,
+		db:                  db,
+	}
+}
+
+func (s *ReminderServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "reminders",
+		Interval: config.PropConfig.Scheduler.ReminderInterval,
+		Run:      s.RunDue,
+	}
+}
+
+// defaultReminderPolicy is the configured policy of assessments without one of their own.
+func defaultReminderPolicy(assessmentSeq string) *models.ReminderPolicy {
+	cfg := config.PropConfig.Reminders
+	days := make([]int64, len(cfg.DaysBefore))
+	for i, d := range cfg.DaysBefore {
+		days[i] = int64(d)
+	}
+	return &models.ReminderPolicy{
+		AssessmentSequence: assessmentSeq,
+		Enabled:            true,
+		DaysBefore:         days,
+		OnDueDate:          cfg.OnDueDate,
+		OverdueEveryDays:   cfg.OverdueEveryDays,
+		EscalateAfterDays:  cfg.EscalateAfterDays,
+	}
+}
+
+// reminderLocation is the time zone reminder days and quiet hours are counted in.
+func reminderLocation() *time.Location {
+	if tz := config.PropConfig.Reminders.Timezone; tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+		log.Printf("[ERROR] unknown reminder timezone %q, using UTC", tz)
+	}
+	return time.UTC
+}
+
+func inQuietHours(now time.Time) bool {
+	start, end := config.PropConfig.Reminders.QuietHoursStart, config.PropConfig.Reminders.QuietHoursEnd
+	hour := now.Hour()
+	if start == end {
+		return false
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// daysBetween counts the calendar days from a to b in loc.
+func daysBetween(a, b time.Time, loc *time.Location) int {
+	ay, am, ad := a.In(loc).Date()
+	by, bm, bd := b.In(loc).Date()
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+// reminderEvent picks the reminder due for an assignment now, if any. Before the due date only
+// the closest cadence step not yet passed counts, so a late assignment or a missed run does not
+// send the earlier steps as well.
+func reminderEvent(policy *models.ReminderPolicy, dueDate, now time.Time, loc *time.Location) (event, key string) {
+	daysLeft := daysBetween(now, dueDate, loc)
+	switch {
+	case dueDate.Before(now):
+		period := 0
+		if policy.OverdueEveryDays > 0 {
+			period = -daysLeft / policy.OverdueEveryDays
+		}
+		return constant.ReminderOverdue, fmt.Sprintf("%s:%d", constant.ReminderOverdue, period)
+	case daysLeft == 0:
+		if policy.OnDueDate {
+			return constant.ReminderDueToday, constant.ReminderDueToday
+		}
+	default:
+		steps := append([]int64(nil), policy.DaysBefore...)
+		sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+		for _, step := range steps {
+			if int64(daysLeft) <= step {
+				return constant.ReminderBeforeDue, fmt.Sprintf("%s:%d", constant.ReminderBeforeDue, step)
+			}
+		}
+	}
+	return "", ""
+}
+
+// RunDue queues the reminders and escalations that have come due since the last run. Nothing
+// goes out during quiet hours; the next run after them catches up.
+func (s *ReminderServiceImpl) RunDue(ctx context.Context, tx *gorm.DB) error {
+	loc := reminderLocation()
+	now := time.Now()
+	if inQuietHours(now.In(loc)) {
+		return nil
+	}
+
+	policies, err := s.reminderRepo.GetPolicies(tx)
+	if err != nil {
+		return fmt.Errorf("failed to load reminder policies: %w", err)
+	}
+	bySeq := make(map[string]*models.ReminderPolicy, len(policies))
+	horizon := 0
+	for _, d := range config.PropConfig.Reminders.DaysBefore {
+		horizon = max(horizon, d)
+	}
+	for i := range policies {
+		bySeq[policies[i].AssessmentSequence] = &policies[i]
+		for _, d := range policies[i].DaysBefore {
+			horizon = max(horizon, int(d))
+		}
+	}
+
+	candidates, err := s.reminderRepo.GetCandidates(tx, now.AddDate(0, 0, horizon+1))
+	if err != nil {
+		return fmt.Errorf("failed to load assignments to remind: %w", err)
+	}
+
+	sent := 0
+	for _, c := range candidates {
+		policy, ok := bySeq[c.AssessmentSequence]
+		if !ok {
+			policy = defaultReminderPolicy(c.AssessmentSequence)
+		}
+		if !policy.Enabled {
+			continue
+		}
+
+		if event, key := reminderEvent(policy, c.DueDate, now, loc); event != "" {
+			ok, err := s.send(tx, c, c.UserID, event, key, func() error {
+				return s.notificationService.QueueReminderMail(tx, c.UserID, c.AssessmentSequence, event, c.DueDate)
+			})
+			if err != nil {
+				return err
+			}
+			if ok {
+				sent++
+			}
+		}
+
+		daysOverdue := -daysBetween(now, c.DueDate, loc)
+		if policy.EscalateAfterDays <= 0 || !c.DueDate.Before(now) || daysOverdue < policy.EscalateAfterDays {
+			continue
+		}
+		managerIDs, err := s.reminderRepo.GetManagers(tx, c.UserID)
+		if err != nil {
+			return err
+		}
+		for _, managerID := range managerIDs {
+			ok, err := s.send(tx, c, managerID, constant.ReminderEscalation, constant.ReminderEscalation, func() error {
+				return s.notificationService.QueueEscalationMail(tx, managerID, c.UserID, c.AssessmentSequence, c.DueDate, daysOverdue)
+			})
+			if err != nil {
+				return err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("Queued %d due date reminders and escalations", sent)
+	}
+	return nil
+}
+
+// send queues a reminder unless it was already queued, and records it. Delivery and its retries
+// are left to the notification outbox. It reports whether the reminder was queued.
+func (s *ReminderServiceImpl) send(tx *gorm.DB, c models.ReminderCandidate, recipientID, event, key string, queue func() error) (bool, error) {
+	done, err := s.reminderRepo.HasReminder(tx, c.AssessmentSequence, c.UserID, recipientID, key, c.DueDate)
+	if err != nil || done {
+		return false, err
+	}
+
+	entry := &models.ReminderLog{
+		AssessmentSequence: c.AssessmentSequence,
+		UserID:             c.UserID,
+		RecipientID:        recipientID,
+		Event:              event,
+		EventKey:           key,
+		DueDate:            c.DueDate,
+		QueuedOn:           time.Now(),
+	}
+	if err := queue(); err != nil {
+		return false, err
+	}
+	if err := s.reminderRepo.RecordReminder(tx, entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *ReminderServiceImpl) SavePolicy(ctx context.Context, req models.SaveReminderPolicyRequest, userId string) (*models.ReminderPolicy, error) {
+	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	if asmt == nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	policy, err := s.reminderRepo.GetPolicy(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if policy == nil {
+		policy = &models.ReminderPolicy{
+			AssessmentSequence: req.AssessmentSequence,
+			CreatedOn:          now,
+			CreatedBy:          userId,
+		}
+	}
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+	policy.DaysBefore = req.DaysBefore
+	policy.OnDueDate = req.OnDueDate
+	policy.OverdueEveryDays = req.OverdueEveryDays
+	policy.EscalateAfterDays = req.EscalateAfterDays
+	policy.ModifiedOn = now
+	policy.ModifiedBy = userId
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := s.reminderRepo.SavePolicy(tx, policy); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy returns the policy the assessment's reminders follow, the default one included.
+func (s *ReminderServiceImpl) GetPolicy(assessmentSeq string) (*models.ReminderPolicy, error) {
+	policy, err := s.reminderRepo.GetPolicy(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return defaultReminderPolicy(assessmentSeq), nil
+	}
+	return policy, nil
+}
+
+// DeletePolicy puts the assessment back on the default policy.
+func (s *ReminderServiceImpl) DeletePolicy(assessmentSeq string) error {
+	return s.reminderRepo.DeletePolicy(assessmentSeq)
+}
+
+func (s *ReminderServiceImpl) ListReminders(filter models.ReminderLogFilter, limit, offset int) ([]models.ReminderLog, int64, error) {
+	list, total, err := s.reminderRepo.ListReminders(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.ReminderLog{}
+	}
+	return list, total, nil
+}
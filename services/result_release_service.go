@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ResultReleaseService holds back the results of assessments until they are released: when the
+// assessment closes, on a date, or by an admin. Releasing tells every candidate their result and
+// issues the certificates earned. Assessments that do not show results to users are released
+// for their certificates only; nobody is told a result.
+type ResultReleaseService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, tx *gorm.DB) error
+	SavePolicy(ctx context.Context, req models.SaveResultReleaseRequest, userId string) (*models.ResultRelease, error)
+	GetPolicy(assessmentSeq string) (*models.ResultRelease, error)
+	Release(ctx context.Context, assessmentSeq, userId string) (int, error)
+	Pending(assessmentSeq string) (*models.PendingResultResponse, error)
+	PendingCertificate(assessmentSeq string) (*models.PendingResultResponse, error)
+	NotifySubmission(tx *gorm.DB, assessmentSeq, sessionID string) error
+}
+
+type ResultReleaseServiceImpl struct {
+	releaseRepo         repository.ResultReleaseRepository
+	assessmentRepo      repository.AssessmentRepository
+	notificationService NotificationService
+	webhookService      WebhookService
+	db                  *gorm.DB
+}
+
+func NewResultReleaseService(releaseRepo repository.ResultReleaseRepository, assessmentRepo repository.AssessmentRepository, notificationService NotificationService, webhookService WebhookService, db *gorm.DB) ResultReleaseService {
+	return &ResultReleaseServiceImpl{
+		releaseRepo:         releaseRepo,
+		assessmentRepo:      assessmentRepo,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		db:                  db,
+	}
+}
+
+func (s *ResultReleaseServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "result-release",
+		Interval: config.PropConfig.Scheduler.ResultReleaseInterval,
+		Run:      s.RunDue,
+	}
+}
+
+// resultsReleased tells whether users may see the results of an assessment with the release.
+func resultsReleased(release *models.ResultRelease) bool {
+	return release == nil || release.Mode == constant.ResultReleaseImmediate || release.ReleasedOn != nil
+}
+
+// RunDue releases the results of closed on_close assessments and of on_date ones past their
+// date.
+func (s *ResultReleaseServiceImpl) RunDue(ctx context.Context, tx *gorm.DB) error {
+	now := time.Now()
+	due, err := s.releaseRepo.GetDueReleases(tx, now)
+	if err != nil {
+		return fmt.Errorf("failed to find result releases due: %w", err)
+	}
+	for _, seq := range due {
+		notified, err := s.release(tx, seq, now, schedulerUser)
+		if err != nil {
+			return fmt.Errorf("failed to release results of %s: %w", seq, err)
+		}
+		log.Printf("Released results of %s to %d candidates", seq, notified)
+	}
+	return nil
+}
+
+// release marks the results of an assessment released, queues every candidate's result unless
+// the assessment hides them, and issues the certificates earned. It returns how many candidates
+// were told.
+func (s *ResultReleaseServiceImpl) release(tx *gorm.DB, assessmentSeq string, now time.Time, releasedBy string) (int, error) {
+	released, err := s.releaseRepo.MarkReleased(tx, assessmentSeq, now, releasedBy)
+	if err != nil {
+		return 0, err
+	}
+	if !released {
+		return 0, errors.New("results already released")
+	}
+	show, err := s.releaseRepo.GetShowResult(tx, assessmentSeq)
+	if err != nil {
+		return 0, err
+	}
+	results, err := s.releaseRepo.GetLatestResults(tx, assessmentSeq)
+	if err != nil {
+		return 0, err
+	}
+	told := 0
+	for _, result := range results {
+		if show {
+			if err := s.notificationService.QueueResultMail(tx, assessmentSeq, result); err != nil {
+				return 0, err
+			}
+			told++
+		}
+		if err := s.issueCertificate(tx, assessmentSeq, result); err != nil {
+			return 0, err
+		}
+	}
+	return told, nil
+}
+
+// issueCertificate publishes the certificate a released result earns: a pass of an assessment
+// awarding certificates. Recurring assessments are certified by the recertification job, with
+// an expiry, instead.
+func (s *ResultReleaseServiceImpl) issueCertificate(tx *gorm.DB, assessmentSeq string, result models.ReleasedResult) error {
+	if !result.Passed || !result.Certificate || result.Recurring {
+		return nil
+	}
+	return s.webhookService.Publish(tx, constant.EventCertificateIssued, map[string]interface{}{
+		"assessment_sequence": assessmentSeq,
+		"user_id":             result.UserID,
+		"session_id":          result.SessionID,
+	})
+}
+
+// SavePolicy sets when the results of an assessment are released. Results already released
+// stay released unless the policy goes back to immediate and then to a timed one.
+func (s *ResultReleaseServiceImpl) SavePolicy(ctx context.Context, req models.SaveResultReleaseRequest, userId string) (*models.ResultRelease, error) {
+	asmt, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	if asmt == nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	release, err := s.releaseRepo.GetRelease(tx, req.AssessmentSequence)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	now := time.Now()
+	if release == nil {
+		release = &models.ResultRelease{
+			AssessmentSequence: req.AssessmentSequence,
+			CreatedOn:          now,
+			CreatedBy:          userId,
+		}
+	}
+	if req.Mode == constant.ResultReleaseImmediate {
+		release.ReleasedOn = nil
+		release.ReleasedBy = ""
+	}
+	release.Mode = req.Mode
+	release.ReleaseOn = nil
+	if req.Mode == constant.ResultReleaseOnDate {
+		release.ReleaseOn = req.ReleaseOn
+	}
+	release.ModifiedOn = now
+	release.ModifiedBy = userId
+
+	if err := s.releaseRepo.SaveRelease(tx, release); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// GetPolicy returns the release of an assessment, immediate for assessments without one.
+func (s *ResultReleaseServiceImpl) GetPolicy(assessmentSeq string) (*models.ResultRelease, error) {
+	release, err := s.releaseRepo.GetRelease(s.db, assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		release = &models.ResultRelease{AssessmentSequence: assessmentSeq, Mode: constant.ResultReleaseImmediate}
+	}
+	return release, nil
+}
+
+// Release releases the results of an assessment now, whatever its policy, and returns how many
+// candidates were told.
+func (s *ResultReleaseServiceImpl) Release(ctx context.Context, assessmentSeq, userId string) (int, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	release, err := s.releaseRepo.GetRelease(tx, assessmentSeq)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if release == nil || release.Mode == constant.ResultReleaseImmediate {
+		tx.Rollback()
+		return 0, errors.New("results of this assessment are shown on submission")
+	}
+	notified, err := s.release(tx, assessmentSeq, time.Now(), userId)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return notified, nil
+}
+
+// Pending returns what users get instead of their result while the results of an assessment
+// are held back, or hidden from users altogether, or nil once they may see it.
+func (s *ResultReleaseServiceImpl) Pending(assessmentSeq string) (*models.PendingResultResponse, error) {
+	show, err := s.releaseRepo.GetShowResult(s.db, assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if !show {
+		return &models.PendingResultResponse{
+			AssessmentSequence: assessmentSeq,
+			ResultStatus:       constant.ResultHidden,
+		}, nil
+	}
+	return s.PendingCertificate(assessmentSeq)
+}
+
+// PendingCertificate returns what users get instead of their certificate while the results of
+// an assessment are held back, or nil once released. Whether the results are shown to users
+// does not matter.
+func (s *ResultReleaseServiceImpl) PendingCertificate(assessmentSeq string) (*models.PendingResultResponse, error) {
+	release, err := s.releaseRepo.GetRelease(s.db, assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+	if resultsReleased(release) {
+		return nil, nil
+	}
+	return &models.PendingResultResponse{
+		AssessmentSequence: assessmentSeq,
+		ResultStatus:       constant.ResultPendingRelease,
+		ReleaseMode:        release.Mode,
+		ReleaseOn:          release.ReleaseOn,
+	}, nil
+}
+
+// NotifySubmission tells the user their result of a submitted session, unless the assessment
+// hides results from users, and issues the certificate it earns. Both wait for the release of
+// results held back.
+func (s *ResultReleaseServiceImpl) NotifySubmission(tx *gorm.DB, assessmentSeq, sessionID string) error {
+	release, err := s.releaseRepo.GetRelease(tx, assessmentSeq)
+	if err != nil {
+		return err
+	}
+	if !resultsReleased(release) {
+		return nil
+	}
+	show, err := s.releaseRepo.GetShowResult(tx, assessmentSeq)
+	if err != nil {
+		return err
+	}
+	result, err := s.releaseRepo.GetSessionResult(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if show {
+		if err := s.notificationService.QueueResultMail(tx, assessmentSeq, *result); err != nil {
+			return err
+		}
+	}
+	return s.issueCertificate(tx, assessmentSeq, *result)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"dhl/models"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledTask is a job the Scheduler runs every Interval. Run is called inside a
+// transaction holding the task's advisory lock, so across replicas only one runs it at a time.
+// Unlocked tasks are given the database outside any transaction instead, for jobs calling
+// other systems; they open their own transactions and keep concurrent runs apart themselves.
+type ScheduledTask struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, tx *gorm.DB) error
+	Unlocked bool
+}
+
+// Scheduler runs background tasks inside the server process.
+type Scheduler struct {
+	db     *gorm.DB
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	tasks  []ScheduledTask
+	status map[string]*models.SchedulerTaskStatus
+}
+
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{db: db, status: map[string]*models.SchedulerTaskStatus{}}
+}
+
+// Register adds a task; tasks registered after Start are not run.
+func (s *Scheduler) Register(task ScheduledTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, task)
+	s.status[task.Name] = &models.SchedulerTaskStatus{Name: task.Name, Interval: task.Interval.String()}
+}
+
+// Start runs every registered task in its own goroutine until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	tasks := append([]ScheduledTask(nil), s.tasks...)
+	s.mu.Unlock()
+
+	for _, task := range tasks {
+		s.wg.Add(1)
+		go s.loop(ctx, task)
+	}
+}
+
+// Wait blocks until every task started by Start has returned after its context was cancelled.
+// A run in progress is rolled back when its context goes.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, task ScheduledTask) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx, task)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the task if no other replica is running it right now. The advisory lock is
+// transaction scoped, so it is released on commit, rollback or a dropped connection.
+func (s *Scheduler) RunOnce(ctx context.Context, task ScheduledTask) {
+	started := time.Now()
+	ran, err := s.runLocked(ctx, task)
+	if err != nil {
+		log.Printf("[ERROR] scheduled task %s failed: %v", task.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.status[task.Name]
+	if st == nil {
+		return
+	}
+	next := started.Add(task.Interval)
+	st.LastCheckedAt = &started
+	st.NextCheckAt = &next
+	if ran || err != nil {
+		st.LastRunAt = &started
+		st.LastError = ""
+		if err != nil {
+			st.LastError = err.Error()
+		}
+	}
+}
+
+func (s *Scheduler) runLocked(ctx context.Context, task ScheduledTask) (ran bool, err error) {
+	if task.Unlocked {
+		return s.runUnlocked(ctx, task)
+	}
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			ran, err = true, fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", schedulerLockKey(task.Name)).Scan(&locked).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !locked {
+		// another replica is running this task
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := task.Run(ctx, tx); err != nil {
+		tx.Rollback()
+		return true, err
+	}
+	return true, tx.Commit().Error
+}
+
+func (s *Scheduler) runUnlocked(ctx context.Context, task ScheduledTask) (ran bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			ran, err = true, fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return true, task.Run(ctx, s.db.WithContext(ctx))
+}
+
+// Status reports the tasks of this instance, sorted by name.
+func (s *Scheduler) Status() []models.SchedulerTaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.SchedulerTaskStatus, 0, len(s.status))
+	for _, st := range s.status {
+		list = append(list, *st)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// schedulerLockKey derives a stable advisory lock key from the task name.
+func schedulerLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("dhl.scheduler." + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,543 @@
+package services
+
+import (
+	"context"
+	"dhl/config"
+	"dhl/models"
+	"dhl/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TagService manages the tag taxonomy explicitly, alongside the implicit creation done by
+// ProcessTagRequest when questions and assessments are tagged.
+type TagService interface {
+	GetTree() (*models.TagTreeResponse, error)
+	GetUsage(tagID int64) (*models.TagUsage, error)
+	CreateTag(ctx context.Context, req models.CreateTagRequest, userId string) (*models.TagMaster, error)
+	RenameTag(ctx context.Context, tagID int64, req models.RenameTagRequest, userId string) error
+	MergeTags(ctx context.Context, req models.MergeTagRequest, userId string) (*models.MergeTagResult, error)
+	MoveTag(ctx context.Context, tagID int64, req models.MoveTagRequest, userId string) error
+	DeleteTag(ctx context.Context, tagID int64, userId string) error
+}
+
+type TagServiceImpl struct {
+	tagRepo repository.TagRepository
+	db      *gorm.DB
+}
+
+func NewTagService(tagRepo repository.TagRepository, db *gorm.DB) TagService {
+	return &TagServiceImpl{tagRepo: tagRepo, db: db}
+}
+
+// GetTree returns the live tags as a forest sorted by name, each with its usage counts.
+func (s *TagServiceImpl) GetTree() (*models.TagTreeResponse, error) {
+	tags, err := s.tagRepo.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.tagRepo.GetUsage(nil)
+	if err != nil {
+		return nil, err
+	}
+	usageByID := make(map[int64]models.TagUsage, len(usage))
+	for _, u := range usage {
+		usageByID[u.TagID] = u
+	}
+
+	nodes := make(map[int64]*models.TagNode, len(tags))
+	for _, t := range tags {
+		nodes[t.TagID] = &models.TagNode{
+			TagID:       t.TagID,
+			Tag:         t.TagName,
+			ParentTagID: t.ParentTagID,
+			Usage:       usageByID[t.TagID],
+			Children:    []*models.TagNode{},
+		}
+	}
+
+	roots := []*models.TagNode{}
+	for _, t := range tags {
+		node := nodes[t.TagID]
+		if t.ParentTagID != nil {
+			if parent, ok := nodes[*t.ParentTagID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		// tags whose parent was deleted surface as roots rather than disappearing
+		roots = append(roots, node)
+	}
+	setTagPaths(roots, "")
+
+	return &models.TagTreeResponse{
+		StrictMode: config.PropConfig.Tags.StrictMode,
+		Tags:       roots,
+	}, nil
+}
+
+func (s *TagServiceImpl) GetUsage(tagID int64) (*models.TagUsage, error) {
+	if _, err := s.tagRepo.GetTag(s.db, tagID); err != nil {
+		return nil, err
+	}
+	usage, err := s.tagRepo.GetUsage([]int64{tagID})
+	if err != nil {
+		return nil, err
+	}
+	if len(usage) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &usage[0], nil
+}
+
+// CreateTag adds a tag to the curated vocabulary; in strict mode this is the only way new
+// tags come into existence.
+func (s *TagServiceImpl) CreateTag(ctx context.Context, req models.CreateTagRequest, userId string) (*models.TagMaster, error) {
+	name, err := cleanTagName(req.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if req.ParentTagID != nil {
+		if _, err := s.tagRepo.GetTag(tx, *req.ParentTagID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("parent tag %d not found: %w", *req.ParentTagID, err)
+		}
+	}
+	if err := s.checkNameFree(tx, name, req.ParentTagID, 0); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	tag := &models.TagMaster{
+		TagName:     name,
+		ParentTagID: req.ParentTagID,
+		CreatedOn:   now,
+		CreatedBy:   userId,
+		IsActive:    true,
+		IsDeleted:   false,
+		ModifiedOn:  now,
+		ModifiedBy:  userId,
+	}
+	if err := s.tagRepo.CreateTag(tx, tag); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// RenameTag renames a tag in place, so every mapping follows. Blueprint constraints, which
+// refer to tags by name, are rewritten too.
+func (s *TagServiceImpl) RenameTag(ctx context.Context, tagID int64, req models.RenameTagRequest, userId string) error {
+	name, err := cleanTagName(req.Tag)
+	if err != nil {
+		return err
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	tag, err := s.tagRepo.GetTag(tx, tagID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.checkNameFree(tx, name, tag.ParentTagID, tag.TagID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := s.tagRepo.UpdateTag(tx, tagID, map[string]interface{}{
+		"tag":         name,
+		"modified_on": time.Now(),
+		"modified_by": userId,
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	parentName := ""
+	if tag.ParentTagID != nil {
+		parent, err := s.tagRepo.GetTag(tx, *tag.ParentTagID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		parentName = parent.TagName
+	}
+	children, err := s.tagRepo.GetChildren(tx, []int64{tagID})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := s.renameBlueprintPaths(tx, tagID, tag.TagName, name, parentName, parentName, children); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// MergeTags folds the source tag into the target. Children of the source move under the
+// target; a child whose name the target already uses is merged into that child in turn.
+func (s *TagServiceImpl) MergeTags(ctx context.Context, req models.MergeTagRequest, userId string) (*models.MergeTagResult, error) {
+	if req.SourceTagID == req.TargetTagID {
+		return nil, errors.New("a tag cannot be merged into itself")
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	result := &models.MergeTagResult{SourceTagID: req.SourceTagID, TargetTagID: req.TargetTagID}
+	if err := s.mergeTag(tx, req.SourceTagID, req.TargetTagID, userId, result); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *TagServiceImpl) mergeTag(tx *gorm.DB, sourceID, targetID int64, userId string, result *models.MergeTagResult) error {
+	source, err := s.tagRepo.GetTag(tx, sourceID)
+	if err != nil {
+		return fmt.Errorf("source tag %d not found: %w", sourceID, err)
+	}
+	target, err := s.tagRepo.GetTag(tx, targetID)
+	if err != nil {
+		return fmt.Errorf("target tag %d not found: %w", targetID, err)
+	}
+	// merging a tag into its own descendant would leave the descendant parented by itself
+	targetAncestors, err := s.ancestorIDs(tx, target)
+	if err != nil {
+		return err
+	}
+	for _, id := range targetAncestors {
+		if id == sourceID {
+			return fmt.Errorf("tag %q cannot be merged into its descendant %q", source.TagName, target.TagName)
+		}
+	}
+
+	questions, err := s.tagRepo.MergeQuestionMappings(tx, sourceID, targetID, userId)
+	if err != nil {
+		return err
+	}
+	assessments, err := s.tagRepo.MergeAssessmentMappings(tx, sourceID, targetID, userId)
+	if err != nil {
+		return err
+	}
+	result.QuestionMappings += questions
+	result.AssessmentMappings += assessments
+
+	// children whose name already exists under the target are merged, the rest move across
+	sourceChildren, err := s.tagRepo.GetChildren(tx, []int64{sourceID})
+	if err != nil {
+		return err
+	}
+	var movedChildren []models.TagMaster
+	for _, child := range sourceChildren {
+		existing, err := s.tagRepo.FindSibling(tx, child.TagName, &targetID, child.TagID)
+		if err == nil {
+			if err := s.mergeTag(tx, child.TagID, existing.TagID, userId, result); err != nil {
+				return err
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		movedChildren = append(movedChildren, child)
+	}
+	moved, err := s.tagRepo.ReparentChildren(tx, sourceID, targetID, userId)
+	if err != nil {
+		return err
+	}
+	result.ChildrenMoved += moved
+
+	// everything now under the target must also carry the target's ancestors
+	subtree, err := s.subtreeIDs(tx, targetID)
+	if err != nil {
+		return err
+	}
+	if err := s.tagRepo.AddAncestorMappings(tx, subtree, append([]int64{targetID}, targetAncestors...), userId); err != nil {
+		return err
+	}
+
+	sourceParent, err := s.tagName(tx, source.ParentTagID)
+	if err != nil {
+		return err
+	}
+	targetParent, err := s.tagName(tx, target.ParentTagID)
+	if err != nil {
+		return err
+	}
+	updated, err := s.renameBlueprintPaths(tx, sourceID, source.TagName, target.TagName, sourceParent, targetParent, movedChildren)
+	if err != nil {
+		return err
+	}
+	result.BlueprintsUpdated += updated
+
+	return s.tagRepo.UpdateTag(tx, sourceID, map[string]interface{}{
+		"is_deleted":  true,
+		"is_active":   false,
+		"modified_on": time.Now(),
+		"modified_by": userId,
+	})
+}
+
+// MoveTag re-parents a tag with its subtree. Moving a tag under itself or one of its
+// descendants is refused. Questions and assessments in the subtree gain mappings to the new
+// ancestors; mappings to the old ones are kept, since they may have been set explicitly.
+func (s *TagServiceImpl) MoveTag(ctx context.Context, tagID int64, req models.MoveTagRequest, userId string) error {
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	tag, err := s.tagRepo.GetTag(tx, tagID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var newAncestors []int64
+	if req.ParentTagID != nil {
+		parent, err := s.tagRepo.GetTag(tx, *req.ParentTagID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("parent tag %d not found: %w", *req.ParentTagID, err)
+		}
+		ancestors, err := s.ancestorIDs(tx, parent)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		newAncestors = append([]int64{parent.TagID}, ancestors...)
+		for _, id := range newAncestors {
+			if id == tagID {
+				tx.Rollback()
+				return fmt.Errorf("moving tag %q under %q would create a cycle", tag.TagName, parent.TagName)
+			}
+		}
+	}
+	if err := s.checkNameFree(tx, tag.TagName, req.ParentTagID, tag.TagID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := s.tagRepo.UpdateTag(tx, tagID, map[string]interface{}{
+		"parent_tag_id": req.ParentTagID,
+		"modified_on":   time.Now(),
+		"modified_by":   userId,
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	subtree, err := s.subtreeIDs(tx, tagID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.tagRepo.AddAncestorMappings(tx, subtree, newAncestors, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	oldParent, err := s.tagName(tx, tag.ParentTagID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	newParent, err := s.tagName(tx, req.ParentTagID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := s.renameBlueprintPaths(tx, tagID, tag.TagName, tag.TagName, oldParent, newParent, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteTag soft-deletes a tag nothing refers to: no questions, assessments, blueprints or
+// child tags. Used tags have to be merged instead.
+func (s *TagServiceImpl) DeleteTag(ctx context.Context, tagID int64, userId string) error {
+	usage, err := s.GetUsage(tagID)
+	if err != nil {
+		return err
+	}
+	if usage.QuestionCount > 0 || usage.AssessmentCount > 0 || usage.BlueprintCount > 0 || usage.ChildCount > 0 {
+		return fmt.Errorf("tag is in use (%d questions, %d assessments, %d blueprints, %d child tags); merge it into another tag instead",
+			usage.QuestionCount, usage.AssessmentCount, usage.BlueprintCount, usage.ChildCount)
+	}
+
+	return s.tagRepo.UpdateTag(s.db.WithContext(ctx), tagID, map[string]interface{}{
+		"is_deleted":  true,
+		"is_active":   false,
+		"modified_on": time.Now(),
+		"modified_by": userId,
+	})
+}
+
+func (s *TagServiceImpl) checkNameFree(tx *gorm.DB, name string, parentTagID *int64, excludeID int64) error {
+	existing, err := s.tagRepo.FindSibling(tx, name, parentTagID, excludeID)
+	if err == nil {
+		return fmt.Errorf("tag %q already exists at this level (id %d); merge instead", existing.TagName, existing.TagID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ancestorIDs walks up from tag, nearest parent first. The walk is bounded so that a cycle
+// already in the data cannot loop forever.
+func (s *TagServiceImpl) ancestorIDs(tx *gorm.DB, tag *models.TagMaster) ([]int64, error) {
+	var ids []int64
+	seen := map[int64]bool{tag.TagID: true}
+	current := tag
+	for current.ParentTagID != nil {
+		parentID := *current.ParentTagID
+		if seen[parentID] {
+			return nil, fmt.Errorf("tag %d is part of a parent cycle", parentID)
+		}
+		seen[parentID] = true
+
+		parent, err := s.tagRepo.GetTag(tx, parentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, parentID)
+		current = parent
+	}
+	return ids, nil
+}
+
+// subtreeIDs returns tagID and all its live descendants.
+func (s *TagServiceImpl) subtreeIDs(tx *gorm.DB, tagID int64) ([]int64, error) {
+	ids := []int64{tagID}
+	seen := map[int64]bool{tagID: true}
+	frontier := []int64{tagID}
+	for len(frontier) > 0 {
+		children, err := s.tagRepo.GetChildren(tx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for _, child := range children {
+			if id := child.TagID; !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (s *TagServiceImpl) tagName(tx *gorm.DB, tagID *int64) (string, error) {
+	if tagID == nil {
+		return "", nil
+	}
+	tag, err := s.tagRepo.GetTag(tx, *tagID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return tag.TagName, nil
+}
+
+// renameBlueprintPaths rewrites blueprint constraints for a tag that changed name and/or
+// parent: its "Parent/Tag" path, its bare name and the "Tag/Child" paths of the given children.
+// A constraint is only rewritten when the tag is the only one it matches, since a bare name or
+// a path shared with another tag has to keep selecting that tag's questions.
+func (s *TagServiceImpl) renameBlueprintPaths(tx *gorm.DB, tagID int64, oldName, newName, oldParent, newParent string, children []models.TagMaster) (int64, error) {
+	var total int64
+	rename := func(parent, tag string, excludeID int64, to string) error {
+		from := joinTagPath(parent, tag)
+		if from == to {
+			return nil
+		}
+		shared, err := s.tagRepo.CountTagsAtPath(tx, parent, tag, excludeID)
+		if err != nil || shared > 0 {
+			return err
+		}
+		n, err := s.tagRepo.RenameBlueprintTag(tx, from, to)
+		total += n
+		return err
+	}
+
+	if oldParent != "" {
+		if err := rename(oldParent, oldName, tagID, joinTagPath(newParent, newName)); err != nil {
+			return 0, err
+		}
+	}
+	if err := rename("", oldName, tagID, newName); err != nil {
+		return 0, err
+	}
+	if oldName != newName {
+		for _, child := range children {
+			if err := rename(oldName, child.TagName, child.TagID, newName+"/"+child.TagName); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return total, nil
+}
+
+func setTagPaths(nodes []*models.TagNode, prefix string) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Tag) < strings.ToLower(nodes[j].Tag)
+	})
+	for _, n := range nodes {
+		n.Path = joinTagPath(prefix, n.Tag)
+		setTagPaths(n.Children, n.Path)
+	}
+}
+
+func joinTagPath(parent, tag string) string {
+	if parent == "" {
+		return tag
+	}
+	return parent + "/" + tag
+}
+
+func cleanTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("tag name is required")
+	}
+	if strings.Contains(name, "/") {
+		return "", errors.New("tag names cannot contain '/', which separates parent and child in tag paths")
+	}
+	return name, nil
+}
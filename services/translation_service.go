@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TranslationService interface {
+	SaveTranslations(ctx context.Context, req models.SaveTranslationsRequest, userId string) (*models.SaveTranslationsResponse, error)
+	GetAssessmentLocales(assessmentSeq string) (*models.AssessmentLocalesResponse, error)
+}
+
+type TranslationServiceImpl struct {
+	translationRepo repository.TranslationRepository
+	assessmentRepo  repository.AssessmentRepository
+	db              *gorm.DB
+}
+
+func NewTranslationService(translationRepo repository.TranslationRepository, assessmentRepo repository.AssessmentRepository, db *gorm.DB) TranslationService {
+	return &TranslationServiceImpl{
+		translationRepo: translationRepo,
+		assessmentRepo:  assessmentRepo,
+		db:              db,
+	}
+}
+
+// SaveTranslations stores one locale of an assessment's title, instructions, questions and
+// options. Existing translations for the same locale are replaced; empty values are left as they are.
+func (s *TranslationServiceImpl) SaveTranslations(ctx context.Context, req models.SaveTranslationsRequest, userId string) (*models.SaveTranslationsResponse, error) {
+	locale, err := utils.NormalizeLocale(req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	if locale == constant.DefaultLocale {
+		return nil, fmt.Errorf("%s is the default language; edit the assessment content instead", locale)
+	}
+
+	assessment, err := s.assessmentRepo.GetAssessmentMstByAssmtSeq(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	if assessment == nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	questions, err := s.assessmentRepo.GetAssessmentQuestions(req.AssessmentSequence)
+	if err != nil {
+		return nil, err
+	}
+	inAssessment := make(map[int64]bool, len(questions))
+	for _, q := range questions {
+		inAssessment[q.QuestionID] = true
+	}
+
+	now := time.Now()
+	translated := 0
+
+	tx := s.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	saveContent := func(contentID int64, contentType, value string) error {
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(contentType) {
+		case constant.ContentHTML:
+			value = utils.SanitizeHTML(value)
+		case constant.ContentMarkdown:
+			value = utils.SanitizeMarkdown(value)
+		}
+		if value == "" {
+			return nil
+		}
+		translated++
+		return s.translationRepo.UpsertContentTranslation(tx, &models.ContentTranslation{
+			ContentID:  contentID,
+			Locale:     locale,
+			Value:      value,
+			CreatedOn:  now,
+			CreatedBy:  userId,
+			ModifiedOn: now,
+			ModifiedBy: userId,
+		})
+	}
+
+	if strings.TrimSpace(req.Title) != "" || strings.TrimSpace(req.Instructions) != "" {
+		if err := s.translationRepo.UpsertAssessmentTranslation(tx, &models.AssessmentTranslation{
+			AssessmentSequence: req.AssessmentSequence,
+			Locale:             locale,
+			Title:              strings.TrimSpace(req.Title),
+			Instructions:       strings.TrimSpace(req.Instructions),
+			CreatedOn:          now,
+			CreatedBy:          userId,
+			ModifiedOn:         now,
+			ModifiedBy:         userId,
+		}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		translated++
+	}
+
+	for _, qt := range req.Questions {
+		if !inAssessment[qt.QuestionID] {
+			tx.Rollback()
+			return nil, fmt.Errorf("question %d is not part of assessment %s", qt.QuestionID, req.AssessmentSequence)
+		}
+
+		questionContent, err := s.assessmentRepo.GetContentByQuestionID(qt.QuestionID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := saveContent(questionContent.ContentID, questionContent.ContentType, qt.Title); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if len(qt.Options) == 0 {
+			continue
+		}
+		options, err := s.assessmentRepo.GetOptionsByQuestionID(qt.QuestionID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		optionContent := make(map[int64]int64, len(options))
+		for _, opt := range options {
+			optionContent[opt.OptionID] = opt.ContentID
+		}
+
+		for _, ot := range qt.Options {
+			contentID, ok := optionContent[ot.OptionID]
+			if !ok {
+				tx.Rollback()
+				return nil, fmt.Errorf("option %d does not belong to question %d", ot.OptionID, qt.QuestionID)
+			}
+			content, err := s.assessmentRepo.GetContentByID(contentID)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := saveContent(contentID, content.ContentType, ot.Label); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &models.SaveTranslationsResponse{
+		AssessmentSequence: req.AssessmentSequence,
+		Locale:             locale,
+		Translated:         translated,
+	}, nil
+}
+
+func (s *TranslationServiceImpl) GetAssessmentLocales(assessmentSeq string) (*models.AssessmentLocalesResponse, error) {
+	if assessmentSeq == "" {
+		return nil, errors.New("invalid input: assessmentSeq")
+	}
+
+	locales, err := s.translationRepo.GetAssessmentLocales(assessmentSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AssessmentLocalesResponse{
+		AssessmentSequence: assessmentSeq,
+		DefaultLocale:      constant.DefaultLocale,
+		Locales:            append([]string{constant.DefaultLocale}, locales...),
+	}, nil
+}
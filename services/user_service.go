@@ -4,6 +4,7 @@ import (
 	"context"
 	"dhl/models"
 	"dhl/repository"
+	"dhl/utils"
 	"errors"
 	"fmt"
 	"log"
@@ -49,6 +50,13 @@ func (us *UserServiceImpl) GetUserProfile(userID string) (*models.AssessmentUser
 }
 
 func (us *UserServiceImpl) UpdateUserProfile(userID uuid.UUID, data models.UserProfileUpdate) error {
+	if data.Locale != nil {
+		locale, err := utils.NormalizeLocale(*data.Locale)
+		if err != nil {
+			return err
+		}
+		data.Locale = &locale
+	}
 	err := us.userRepo.UpdateUserProfile(userID, data)
 	if err != nil {
 		return err
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"dhl/config"
+	"dhl/constant"
+	"dhl/models"
+	"dhl/repository"
+	"dhl/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookService tells registered endpoints about lifecycle events. Events are written in the
+// transaction of the change they report and delivered at least once by the scheduled task,
+// with the notification outbox's retries and backoff.
+type WebhookService interface {
+	Task() ScheduledTask
+	RunDue(ctx context.Context, db *gorm.DB) error
+	Publish(tx *gorm.DB, event string, data map[string]interface{}) error
+	PublishAssignments(tx *gorm.DB, assessmentSeq string, userIDs []string) error
+	PublishSubmission(tx *gorm.DB, userID, assessmentSeq, sessionID string) error
+
+	ListEndpoints() ([]models.WebhookEndpoint, error)
+	SaveEndpoint(ctx context.Context, id int64, req models.SaveWebhookRequest, userId string) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(id int64) error
+	Ping(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	ListDeliveries(filter models.WebhookDeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error)
+}
+
+type WebhookServiceImpl struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	db          *gorm.DB
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, db *gorm.DB) WebhookService {
+	return &WebhookServiceImpl{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: config.PropConfig.Webhooks.Timeout},
+		db:          db,
+	}
+}
+
+func (s *WebhookServiceImpl) Task() ScheduledTask {
+	return ScheduledTask{
+		Name:     "webhook-deliveries",
+		Interval: config.PropConfig.Scheduler.WebhookInterval,
+		Run:      s.RunDue,
+		Unlocked: true,
+	}
+}
+
+// newWebhookPayload builds the body of a new event and returns it with the event's id.
+func newWebhookPayload(event string, data map[string]interface{}) (string, json.RawMessage, error) {
+	id := uuid.NewString()
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:         id,
+		Event:      event,
+		OccurredOn: time.Now().UTC(),
+		Data:       data,
+	})
+	return id, payload, err
+}
+
+// Publish queues the event for every active endpoint subscribed to it.
+func (s *WebhookServiceImpl) Publish(tx *gorm.DB, event string, data map[string]interface{}) error {
+	endpoints, err := s.webhookRepo.GetSubscribers(tx, event)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+	eventID, payload, err := newWebhookPayload(event, data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, len(endpoints))
+	for i, endpoint := range endpoints {
+		deliveries[i] = models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       payload,
+			Status:        constant.OutboxPending,
+			NextAttemptOn: now,
+			CreatedOn:     now,
+		}
+	}
+	return s.webhookRepo.CreateDeliveries(tx, deliveries)
+}
+
+// PublishAssignments publishes one assignment.created event per user.
+func (s *WebhookServiceImpl) PublishAssignments(tx *gorm.DB, assessmentSeq string, userIDs []string) error {
+	for _, userID := range userIDs {
+		if err := s.Publish(tx, constant.EventAssignmentCreated, map[string]interface{}{
+			"assessment_sequence": assessmentSeq,
+			"user_id":             userID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishSubmission publishes a submitted session and its graded result.
+func (s *WebhookServiceImpl) PublishSubmission(tx *gorm.DB, userID, assessmentSeq, sessionID string) error {
+	data := map[string]interface{}{
+		"assessment_sequence": assessmentSeq,
+		"user_id":             userID,
+		"session_id":          sessionID,
+	}
+	if err := s.Publish(tx, constant.EventSessionSubmitted, data); err != nil {
+		return err
+	}
+
+	score, err := s.webhookRepo.GetSessionScore(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	percentage := 0.0
+	if score.TotalMarks > 0 {
+		percentage = score.MarksObtained * 100 / score.TotalMarks
+	}
+	data["marks_obtained"] = score.MarksObtained
+	data["total_marks"] = score.TotalMarks
+	data["percentage"] = percentage
+	data["passed"] = percentage >= score.PassingScore
+	return s.Publish(tx, constant.EventResultGraded, data)
+}
+
+// deliver posts a delivery's payload to the endpoint, signed with its secret, and returns the
+// response status.
+func (s *WebhookServiceImpl) deliver(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dhl-assessment-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Id", delivery.EventID)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", utils.SignWebhook(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// RunDue sends one batch of due deliveries. The batch is claimed first, in a statement of its
+// own, so no rows stay locked while endpoints are called, and the outcomes are recorded together
+// afterwards. Deliveries of endpoints removed or disabled since are dead-lettered; ones not
+// attempted because the server is shutting down go back to pending.
+func (s *WebhookServiceImpl) RunDue(ctx context.Context, db *gorm.DB) error {
+	now := time.Now()
+	deliveries, err := s.webhookRepo.ClaimDeliveries(db, now, now.Add(config.PropConfig.Outbox.ClaimTimeout), config.PropConfig.Outbox.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.EndpointID)
+	}
+	endpoints, err := s.webhookRepo.GetEndpoints(db, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*models.WebhookEndpoint, len(endpoints))
+	for i := range endpoints {
+		byID[endpoints[i].ID] = &endpoints[i]
+	}
+
+	sent, failed := 0, 0
+	for i := range deliveries {
+		d := &deliveries[i]
+		endpoint, ok := byID[d.EndpointID]
+		switch {
+		case !ok:
+			d.Status = constant.OutboxDead
+			d.LastError = "endpoint removed"
+		case !endpoint.IsActive:
+			d.Status = constant.OutboxDead
+			d.LastError = "endpoint disabled"
+		case ctx.Err() != nil:
+			d.Status = constant.OutboxPending
+			d.Attempts--
+			d.NextAttemptOn = now
+		default:
+			status, err := s.deliver(ctx, endpoint, d)
+			d.ResponseStatus = status
+			if err != nil {
+				failed++
+				d.LastError = err.Error()
+				if d.Attempts >= config.PropConfig.Outbox.MaxAttempts {
+					d.Status = constant.OutboxDead
+					log.Printf("[ERROR] webhook delivery %d to %s dead after %d attempts: %v", d.ID, endpoint.URL, d.Attempts, err)
+				} else {
+					d.Status = constant.OutboxPending
+					d.NextAttemptOn = time.Now().Add(outboxBackoff(d.Attempts))
+				}
+			} else {
+				sent++
+				deliveredOn := time.Now()
+				d.Status = constant.OutboxSent
+				d.DeliveredOn = &deliveredOn
+				d.LastError = ""
+			}
+		}
+	}
+
+	// the outcomes are recorded even when shutdown interrupted the batch
+	err = db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		for i := range deliveries {
+			if err := s.webhookRepo.SaveDeliveryAttempt(tx, &deliveries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record webhook deliveries: %w", err)
+	}
+
+	if sent > 0 || failed > 0 {
+		log.Printf("Webhooks: %d delivered, %d failed", sent, failed)
+	}
+	return nil
+}
+
+func (s *WebhookServiceImpl) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.webhookRepo.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	if endpoints == nil {
+		endpoints = []models.WebhookEndpoint{}
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+// SaveEndpoint registers an endpoint, or updates endpoint id when not 0. The secret is kept
+// unless a new one is given, and only returned when it was set by this call.
+func (s *WebhookServiceImpl) SaveEndpoint(ctx context.Context, id int64, req models.SaveWebhookRequest, userId string) (*models.WebhookEndpoint, error) {
+	now := time.Now()
+	endpoint := &models.WebhookEndpoint{CreatedOn: now, CreatedBy: userId}
+	if id != 0 {
+		existing, err := s.webhookRepo.GetEndpoint(id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, errors.New("webhook not found")
+		}
+		endpoint = existing
+	}
+
+	secretSet := req.Secret != "" || endpoint.Secret == ""
+	if req.Secret != "" {
+		endpoint.Secret = req.Secret
+	} else if endpoint.Secret == "" {
+		secret, err := utils.NewWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = secret
+	}
+	endpoint.Name = req.Name
+	endpoint.URL = req.URL
+	endpoint.Events = req.Events
+	endpoint.IsActive = req.IsActive == nil || *req.IsActive
+	endpoint.ModifiedOn = now
+	endpoint.ModifiedBy = userId
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := s.webhookRepo.SaveEndpoint(tx, endpoint); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if !secretSet {
+		endpoint.Secret = ""
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookServiceImpl) DeleteEndpoint(id int64) error {
+	return s.webhookRepo.DeleteEndpoint(id)
+}
+
+// Ping sends a webhook.ping event to the endpoint right away, active or not, and logs it
+// without retries.
+func (s *WebhookServiceImpl) Ping(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, errors.New("webhook not found")
+	}
+	eventID, payload, err := newWebhookPayload(constant.EventPing, map[string]interface{}{
+		"webhook_id": endpoint.ID,
+		"name":       endpoint.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		EndpointID:    endpoint.ID,
+		EventID:       eventID,
+		Event:         constant.EventPing,
+		Payload:       payload,
+		Status:        constant.OutboxSent,
+		Attempts:      1,
+		NextAttemptOn: now,
+		CreatedOn:     now,
+	}
+	status, sendErr := s.deliver(ctx, endpoint, delivery)
+	delivery.ResponseStatus = status
+	if sendErr != nil {
+		delivery.Status = constant.OutboxDead
+		delivery.LastError = sendErr.Error()
+	} else {
+		deliveredOn := time.Now()
+		delivery.DeliveredOn = &deliveredOn
+	}
+	if err := s.webhookRepo.SaveDeliveryAttempt(s.db.WithContext(ctx), delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *WebhookServiceImpl) ListDeliveries(filter models.WebhookDeliveryFilter, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	list, total, err := s.webhookRepo.ListDeliveries(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		list = []models.WebhookDelivery{}
+	}
+	return list, total, nil
+}
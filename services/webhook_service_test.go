@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"dhl/models"
+	"dhl/utils"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookDeliverSignsRequest(t *testing.T) {
+	payload := []byte(`{"event":"result.released","data":{"score":7}}`)
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := &WebhookServiceImpl{client: srv.Client()}
+	endpoint := &models.WebhookEndpoint{URL: srv.URL, Secret: "s3cret"}
+	delivery := &models.WebhookDelivery{Event: "result.released", EventID: "evt-1", Payload: payload}
+
+	before := time.Now().Unix()
+	status, err := s.deliver(context.Background(), endpoint, delivery)
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	if h := got.Header.Get("X-Webhook-Event"); h != "result.released" {
+		t.Errorf("X-Webhook-Event = %q", h)
+	}
+	if h := got.Header.Get("X-Webhook-Id"); h != "evt-1" {
+		t.Errorf("X-Webhook-Id = %q", h)
+	}
+	timestamp, err := strconv.ParseInt(got.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-Webhook-Timestamp: %v", err)
+	}
+	if timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("X-Webhook-Timestamp = %d, not the time of sending", timestamp)
+	}
+	want := utils.SignWebhook("s3cret", timestamp, payload)
+	if h := got.Header.Get("X-Webhook-Signature"); h != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", h, want)
+	}
+}
+
+func TestWebhookDeliverFailsOnNon2xx(t *testing.T) {
+	for _, code := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no", code)
+		}))
+		s := &WebhookServiceImpl{client: srv.Client()}
+		endpoint := &models.WebhookEndpoint{URL: srv.URL, Secret: "s3cret"}
+		status, err := s.deliver(context.Background(), endpoint, &models.WebhookDelivery{Payload: []byte(`{}`)})
+		srv.Close()
+		if err == nil {
+			t.Errorf("status %d: deliver succeeded, want a failure", code)
+		}
+		if status != code {
+			t.Errorf("status = %d, want %d", status, code)
+		}
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if sig := utils.SignWebhook("key", 1700000000, body); sig != want {
+		t.Errorf("SignWebhook = %q, want %q", sig, want)
+	}
+	if utils.SignWebhook("other", 1700000000, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if utils.SignWebhook("key", 1700000001, body) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/jung-kurt/gofpdf"
 )
 
-func GenerateCertificatePDF(userFullName, assessmentTitle string, totalMarks, marksObtained int, passingScore, userScore float64, certificateDate time.Time, passed bool) ([]byte, error) {
+func GenerateCertificatePDF(userFullName, assessmentTitle string, totalMarks, marksObtained int, passingScore, userScore float64, certificateDate time.Time, expiresOn *time.Time, passed bool) ([]byte, error) {
 
 	templatePath := os.Getenv("CERTIFICATE_TEMPLATE_PATH")
 
@@ -49,6 +49,10 @@ func GenerateCertificatePDF(userFullName, assessmentTitle string, totalMarks, ma
 		dateText := "Date of Certification: " + certificateDate.Format("01/02/2006 15:04:05")
 		center(135, "", 14, dateText)
 
+		if expiresOn != nil {
+			center(145, "", 14, "Valid Until: "+expiresOn.Format("01/02/2006"))
+		}
+
 	} else {
 		// ---------------- FAIL CERTIFICATE / RESULT CARD ----------------
 		center(40, "B", 36, "DHL RESULT REPORT")
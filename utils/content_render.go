@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"dhl/constant"
+	"dhl/models"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var allowedHTMLTags = map[string]map[string]bool{
+	"p": {}, "br": {}, "hr": {}, "b": {}, "strong": {}, "i": {}, "em": {}, "u": {}, "s": {},
+	"sub": {}, "sup": {}, "small": {}, "span": {}, "code": {}, "pre": {}, "blockquote": {},
+	"ul": {}, "ol": {}, "li": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"table": {}, "thead": {}, "tbody": {}, "tr": {},
+	"th":  {"colspan": true, "rowspan": true},
+	"td":  {"colspan": true, "rowspan": true},
+	"a":   {"href": true, "title": true},
+	"img": {"src": true, "alt": true, "width": true, "height": true},
+}
+
+// droppedHTMLTags are removed together with everything inside them.
+var droppedHTMLTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "svg": true, "math": true, "form": true,
+}
+
+// SanitizeHTML keeps a small allow-list of formatting tags and attributes. Links and image
+// sources must be http(s) or relative; everything else is stripped.
+func SanitizeHTML(input string) string {
+	return sanitizeMarkup(input, true)
+}
+
+// SanitizeMarkdown removes raw HTML embedded in Markdown while leaving the Markdown text as is.
+// Link and image targets, inline or in reference definitions, are held to the same schemes as
+// HTML links and images; other targets are emptied.
+func SanitizeMarkdown(input string) string {
+	out := sanitizeMarkup(input, false)
+	out = markdownInlineTarget.ReplaceAllStringFunc(out, func(m string) string {
+		parts := markdownInlineTarget.FindStringSubmatch(m)
+		attr := "href"
+		if strings.HasPrefix(parts[1], "!") {
+			attr = "src"
+		}
+		if safeMarkdownTarget(attr, parts[2]) {
+			return m
+		}
+		return parts[1]
+	})
+	out = markdownReferenceTarget.ReplaceAllStringFunc(out, func(m string) string {
+		parts := markdownReferenceTarget.FindStringSubmatch(m)
+		// a definition may serve links and images alike, so it has to be safe for both
+		if safeMarkdownTarget("src", parts[2]) {
+			return m
+		}
+		return parts[1] + "#"
+	})
+	return strings.TrimSpace(out)
+}
+
+var (
+	// markdownInlineTarget matches the start of an inline link or image up to its target.
+	markdownInlineTarget = regexp.MustCompile(`(!?\[[^\]]*\]\(\s*)(<[^>]*>|[^)\s]*)`)
+	// markdownReferenceTarget matches a reference definition up to its target.
+	markdownReferenceTarget = regexp.MustCompile(`(?m)^( {0,3}\[[^\]]+\]:[ \t]*)(<[^>]*>|\S+)`)
+)
+
+// safeMarkdownTarget checks a Markdown link target as renderers read it: entities decoded and
+// the whitespace and control characters browsers ignore in URLs taken out.
+func safeMarkdownTarget(attr, target string) bool {
+	target = html.UnescapeString(strings.Trim(target, "<>"))
+	target = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, target)
+	return safeAttrValue(attr, target)
+}
+
+func sanitizeMarkup(input string, keepTags bool) string {
+	z := html.NewTokenizer(strings.NewReader(input))
+	var sb strings.Builder
+	dropDepth := 0
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return sb.String()
+
+		case html.TextToken:
+			if dropDepth > 0 {
+				continue
+			}
+			if keepTags {
+				sb.WriteString(html.EscapeString(string(z.Text())))
+			} else {
+				sb.Write(z.Raw())
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if droppedHTMLTags[tag] {
+				if tt == html.StartTagToken {
+					dropDepth++
+				}
+				continue
+			}
+			allowedAttrs, ok := allowedHTMLTags[tag]
+			if dropDepth > 0 || !keepTags || !ok {
+				continue
+			}
+			sb.WriteString("<" + tag)
+			for {
+				key, val, more := z.TagAttr()
+				k := string(key)
+				if allowedAttrs[k] && safeAttrValue(k, string(val)) {
+					sb.WriteString(" " + k + `="` + html.EscapeString(string(val)) + `"`)
+				}
+				if !more {
+					break
+				}
+			}
+			if tt == html.SelfClosingTagToken {
+				sb.WriteString(" /")
+			}
+			sb.WriteString(">")
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if droppedHTMLTags[tag] {
+				if dropDepth > 0 {
+					dropDepth--
+				}
+				continue
+			}
+			if _, ok := allowedHTMLTags[tag]; ok && keepTags && dropDepth == 0 {
+				sb.WriteString("</" + tag + ">")
+			}
+		}
+	}
+}
+
+func safeAttrValue(attr, val string) bool {
+	if attr != "href" && attr != "src" {
+		return true
+	}
+	v := strings.ToLower(strings.TrimSpace(val))
+	if i := strings.IndexAny(v, ":/?#"); i >= 0 && v[i] == ':' {
+		return strings.HasPrefix(v, "http:") || strings.HasPrefix(v, "https:") || (attr == "href" && strings.HasPrefix(v, "mailto:"))
+	}
+	return true
+}
+
+// RenderContent turns a stored content_mst value into what the client needs to display it and
+// a plain-text fallback used for titles, labels and reports.
+func RenderContent(contentType, value string) (string, *models.RenderedContent) {
+	switch strings.ToLower(contentType) {
+	case constant.ContentImage, constant.ContentAudio:
+		var media models.MediaContentValue
+		if err := json.Unmarshal([]byte(value), &media); err != nil || media.Key == "" {
+			return value, nil
+		}
+		return media.Alt, &models.RenderedContent{
+			Type:     strings.ToLower(contentType),
+			URL:      MediaURL(media.Key),
+			MimeType: media.MimeType,
+			Alt:      media.Alt,
+		}
+
+	case constant.ContentHTML:
+		return stripHTML(value), &models.RenderedContent{
+			Type: constant.ContentHTML,
+			HTML: SanitizeHTML(value),
+		}
+
+	case constant.ContentMarkdown:
+		return value, &models.RenderedContent{
+			Type:     constant.ContentMarkdown,
+			Markdown: SanitizeMarkdown(value),
+		}
+	}
+
+	return value, nil
+}
+
+// LocalizeContent applies a translation to a stored content value. Media content keeps its
+// file and takes the translation as alt text; HTML and Markdown translations are sanitized.
+func LocalizeContent(contentType, value, translation string) string {
+	switch strings.ToLower(contentType) {
+	case constant.ContentImage, constant.ContentAudio:
+		var media models.MediaContentValue
+		if err := json.Unmarshal([]byte(value), &media); err != nil || media.Key == "" {
+			return translation
+		}
+		media.Alt = translation
+		localized, _ := json.Marshal(media)
+		return string(localized)
+
+	case constant.ContentHTML:
+		return SanitizeHTML(translation)
+
+	case constant.ContentMarkdown:
+		return SanitizeMarkdown(translation)
+	}
+
+	return translation
+}
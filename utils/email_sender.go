@@ -28,7 +28,11 @@ func SendEmail(cfg EmailConfig, to, subject, templateFile string, data interface
 	if err := t.Execute(&body, data); err != nil {
 		return fmt.Errorf("template execute error: %w", err)
 	}
+	return SendHTMLEmail(cfg, to, subject, body.String())
+}
 
+// SendHTMLEmail sends an already rendered HTML body.
+func SendHTMLEmail(cfg EmailConfig, to, subject, body string) error {
 	// SMTP AUTH
 	auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
 
@@ -39,7 +43,7 @@ func SendEmail(cfg EmailConfig, to, subject, templateFile string, data interface
 			"Subject: " + subject + "\r\n" +
 			"MIME-version: 1.0;\r\n" +
 			"Content-Type: text/html; charset=\"UTF-8\";\r\n\r\n" +
-			body.String(),
+			body,
 	)
 
 	address := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
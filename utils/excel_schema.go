@@ -0,0 +1,553 @@
+package utils
+
+import (
+	"bytes"
+	"dhl/constant"
+	"dhl/models"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Keys of the fields an import workbook can carry.
+const (
+	fieldSerial         = "serial"
+	fieldQuestionType   = "question_type"
+	fieldAssessmentName = "assessment_name"
+	fieldQuestion       = "question"
+	fieldOption         = "option"
+	fieldScore          = "score"
+	fieldCorrect        = "correct"
+	fieldMandatory      = "mandatory"
+	fieldQuestionMedia  = "question_media"
+	fieldOptionMedia    = "option_media"
+)
+
+// ExcelColumn describes one column of an import workbook. Aliases are other header texts
+// accepted for the column; Position is used when a workbook has no recognisable header row.
+type ExcelColumn struct {
+	Field       string
+	Header      string
+	Aliases     []string
+	Position    int
+	Required    bool
+	Description string
+}
+
+// ExcelSchema is the layout of an import workbook: one row per option, with the question
+// columns filled on the first row of each question.
+type ExcelSchema struct {
+	Sheet   string
+	Columns []ExcelColumn
+}
+
+// QuestionnaireSchema is the layout read by ParseQuestionnaireExcelToJSON and written by
+// BuildQuestionnaireExcel and the import template.
+var QuestionnaireSchema = ExcelSchema{
+	Sheet: "Questions",
+	Columns: []ExcelColumn{
+		{Field: fieldSerial, Header: "S.No", Aliases: []string{"sno", "s no", "#"}, Position: 0, Description: "Question number, for reference only."},
+		{Field: fieldQuestionType, Header: "Question Type", Aliases: []string{"type"}, Position: 1, Required: true, Description: "One of the supported question types, on the first row of each question."},
+		{Field: fieldAssessmentName, Header: "Assessment Name", Aliases: []string{"assessment"}, Position: 2, Description: "Name of the assessment; only the first non-empty value is used. Defaults to the file name."},
+		{Field: fieldQuestion, Header: "Question", Aliases: []string{"question title", "title"}, Position: 3, Required: true, Description: "Question text. A filled cell starts a new question."},
+		{Field: fieldOption, Header: "Option", Aliases: []string{"option label", "answer"}, Position: 4, Required: true, Description: "Option text; one option per row."},
+		{Field: fieldScore, Header: "Score", Aliases: []string{"points"}, Position: 5, Description: "Whole number awarded for choosing the option."},
+		{Field: fieldCorrect, Header: "Correct", Aliases: []string{"is correct", "is_correct"}, Position: 6, Description: "TRUE for correct options, FALSE or empty otherwise."},
+		{Field: fieldMandatory, Header: "Mandatory", Aliases: []string{"mandatory to answer", "mandatory answer"}, Position: 7, Description: "TRUE when the question must be answered."},
+		{Field: fieldQuestionMedia, Header: "Question Media", Position: 8, Description: "Image or audio file name for the question, when uploading a zip with media."},
+		{Field: fieldOptionMedia, Header: "Option Media", Position: 9, Description: "Image or audio file name for the option, when uploading a zip with media."},
+	},
+}
+
+// legacyQuestionSchema is the older layout read by ParseExcelToJSON, without an assessment name.
+var legacyQuestionSchema = ExcelSchema{
+	Sheet: "Questions",
+	Columns: []ExcelColumn{
+		{Field: fieldQuestion, Header: "Question", Aliases: []string{"question title", "title"}, Position: 0, Required: true},
+		{Field: fieldCorrect, Header: "Is Correct", Aliases: []string{"correct", "is_correct"}, Position: 1},
+		{Field: fieldScore, Header: "Score", Aliases: []string{"points"}, Position: 2},
+		{Field: fieldOption, Header: "Option", Aliases: []string{"option label", "answer"}, Position: 3, Required: true},
+		{Field: fieldQuestionType, Header: "Question Type", Aliases: []string{"type"}, Position: 4, Required: true},
+		{Field: fieldMandatory, Header: "Mandatory", Aliases: []string{"mandatory to answer", "mandatory answer"}, Position: 5},
+	},
+}
+
+// Headers returns the column headers in order.
+func (s ExcelSchema) Headers() []string {
+	headers := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+func normalizeHeader(h string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimSpace(h))), " ")
+}
+
+// resolveColumns maps each field to its column index using the header row. It returns nil
+// when none of the required headers are present.
+func (s ExcelSchema) resolveColumns(header []string) map[string]int {
+	byHeader := map[string]int{}
+	for idx, h := range header {
+		if n := normalizeHeader(h); n != "" {
+			if _, ok := byHeader[n]; !ok {
+				byHeader[n] = idx
+			}
+		}
+	}
+
+	cols := map[string]int{}
+	found := 0
+	for _, c := range s.Columns {
+		cols[c.Field] = -1
+		for _, name := range append([]string{c.Header}, c.Aliases...) {
+			if idx, ok := byHeader[normalizeHeader(name)]; ok {
+				cols[c.Field] = idx
+				if c.Required {
+					found++
+				}
+				break
+			}
+		}
+	}
+	if found == 0 {
+		return nil
+	}
+	return cols
+}
+
+func (s ExcelSchema) positionalColumns() map[string]int {
+	cols := map[string]int{}
+	for _, c := range s.Columns {
+		cols[c.Field] = c.Position
+	}
+	return cols
+}
+
+// locateSheet picks the first sheet whose header row matches the schema, falling back to the
+// first sheet of the workbook.
+func (s ExcelSchema) locateSheet(f *excelize.File) (string, [][]string, map[string]int, error) {
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return "", nil, nil, fmt.Errorf("workbook has no sheets")
+	}
+	for _, name := range sheets {
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("cannot read sheet %s: %w", name, err)
+		}
+		if len(rows) > 0 {
+			if cols := s.resolveColumns(rows[0]); cols != nil {
+				return name, rows, cols, nil
+			}
+		}
+	}
+
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("cannot read sheet %s: %w", sheets[0], err)
+	}
+	return sheets[0], rows, nil, nil
+}
+
+// sheetValidator collects the issues found while reading a sheet.
+type sheetValidator struct {
+	report *models.ExcelValidationReport
+	schema ExcelSchema
+	cols   map[string]int
+}
+
+func (v *sheetValidator) add(severity string, row int, field, format string, args ...interface{}) {
+	issue := models.ExcelValidationIssue{
+		Sheet:    v.report.Sheet,
+		Row:      row,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if field != "" {
+		for _, c := range v.schema.Columns {
+			if c.Field == field {
+				issue.Field = c.Header
+			}
+		}
+		if idx := v.cols[field]; idx >= 0 {
+			issue.Column, _ = excelize.ColumnNumberToName(idx + 1)
+		}
+	}
+	if severity == constant.IssueError {
+		v.report.Errors = append(v.report.Errors, issue)
+	} else {
+		v.report.Warnings = append(v.report.Warnings, issue)
+	}
+}
+
+func (v *sheetValidator) errorf(row int, field, format string, args ...interface{}) {
+	v.add(constant.IssueError, row, field, format, args...)
+}
+
+func (v *sheetValidator) warnf(row int, field, format string, args ...interface{}) {
+	v.add(constant.IssueWarning, row, field, format, args...)
+}
+
+func (v *sheetValidator) cell(row []string, field string) string {
+	idx, ok := v.cols[field]
+	if !ok || idx < 0 {
+		return ""
+	}
+	return cellAt(row, idx)
+}
+
+// choiceQuestionTypes need options and at least one correct answer.
+var choiceQuestionTypes = map[string]bool{"simple_choice": true, "multiple_choice": true}
+
+// optionQuestionTypes use the options of a question; other types ignore them.
+var optionQuestionTypes = map[string]bool{"simple_choice": true, "multiple_choice": true, "matrix": true}
+
+func normalizeQuestionType(t string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(t, "-", " "))), "_")
+}
+
+func parseSheetBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return false, true
+	case "true", "1", "yes", "y":
+		return true, true
+	case "false", "0", "no", "n":
+		return false, true
+	}
+	return false, false
+}
+
+func parseSheetScore(s string) (int, bool) {
+	if s == "" {
+		return 0, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func questionTypeNames() string {
+	names := make([]string, 0, len(QuestionTypeMap))
+	for name := range QuestionTypeMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ValidateQuestionnaireExcel reads a questionnaire workbook and reports every problem by row
+// and column without saving anything. The error is only set when the file cannot be read.
+func ValidateQuestionnaireExcel(file io.Reader, filename string) (*models.SheetAssessment, *models.ExcelValidationReport, error) {
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open excel: %w", err)
+	}
+	defer f.Close()
+	return parseQuestionWorkbook(f, filename, QuestionnaireSchema)
+}
+
+func parseQuestionWorkbook(f *excelize.File, filename string, schema ExcelSchema) (*models.SheetAssessment, *models.ExcelValidationReport, error) {
+	sheet, rows, cols, err := schema.locateSheet(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &models.ExcelValidationReport{
+		Sheet:    sheet,
+		Errors:   []models.ExcelValidationIssue{},
+		Warnings: []models.ExcelValidationIssue{},
+	}
+	v := &sheetValidator{report: report, schema: schema, cols: cols}
+	if cols == nil {
+		v.cols = schema.positionalColumns()
+		v.warnf(1, "", "header row not recognised; columns are read by position (%s)", strings.Join(schema.Headers(), ", "))
+	} else {
+		for _, c := range schema.Columns {
+			if c.Required && cols[c.Field] < 0 {
+				v.errorf(1, "", "missing required column %q", c.Header)
+			}
+		}
+	}
+
+	assessment := &models.SheetAssessment{Questions: []models.SheetQuestion{}}
+
+	var translationCols []translationColumn
+	if len(rows) > 0 && cols != nil {
+		translationCols = parseTranslationHeaders(rows[0])
+	}
+
+	var currentQ *models.SheetQuestion
+	questionRow := 0
+	finishQuestion := func() {
+		if currentQ == nil {
+			return
+		}
+		validateSheetQuestion(v, questionRow, currentQ)
+		assessment.Questions = append(assessment.Questions, *currentQ)
+	}
+
+	otherNames := map[string]bool{}
+	for i, row := range rows {
+		if i == 0 && cols != nil {
+			continue // header
+		}
+		rowNum := i + 1
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		questionType := v.cell(row, fieldQuestionType)
+		assessmentName := v.cell(row, fieldAssessmentName)
+		title := v.cell(row, fieldQuestion)
+		optLabel := v.cell(row, fieldOption)
+		scoreStr := v.cell(row, fieldScore)
+		correctStr := v.cell(row, fieldCorrect)
+		mandatoryStr := v.cell(row, fieldMandatory)
+		questionMedia := v.cell(row, fieldQuestionMedia)
+		optionMedia := v.cell(row, fieldOptionMedia)
+
+		// Set assessment name (once)
+		if assessmentName != "" {
+			if assessment.AssessmentName == "" {
+				assessment.AssessmentName = assessmentName
+			} else if assessmentName != assessment.AssessmentName && !otherNames[assessmentName] {
+				otherNames[assessmentName] = true
+				v.warnf(rowNum, fieldAssessmentName, "assessment name %q differs from %q and is ignored", assessmentName, assessment.AssessmentName)
+			}
+		}
+		for _, col := range translationCols {
+			if value := cellAt(row, col.index); col.field == "assessment name" && value != "" {
+				if assessment.NameTranslations == nil {
+					assessment.NameTranslations = map[string]string{}
+				}
+				if _, ok := assessment.NameTranslations[col.locale]; !ok {
+					assessment.NameTranslations[col.locale] = value
+				}
+			}
+		}
+
+		// A filled question cell starts a new question
+		if title != "" {
+			finishQuestion()
+
+			qType := normalizeQuestionType(questionType)
+			switch {
+			case qType == "":
+				v.errorf(rowNum, fieldQuestionType, "question type is required")
+			case QuestionTypeMap[qType] == 0:
+				v.errorf(rowNum, fieldQuestionType, "unknown question type %q; expected one of %s", questionType, questionTypeNames())
+			}
+
+			mandatory, ok := parseSheetBool(mandatoryStr)
+			if !ok {
+				v.errorf(rowNum, fieldMandatory, "%q is not TRUE or FALSE", mandatoryStr)
+			}
+
+			currentQ = &models.SheetQuestion{
+				Title:             title,
+				MandatoryToAnswer: mandatory,
+				QuestionType:      qType,
+				Options:           []models.SheetOption{},
+			}
+			if questionMedia != "" {
+				currentQ.Media = &models.SheetMedia{FileName: questionMedia}
+			}
+			currentQ.Translations = rowTranslations(row, translationCols, "question")
+			questionRow = rowNum
+		} else {
+			if questionType != "" && (currentQ == nil || normalizeQuestionType(questionType) != currentQ.QuestionType) {
+				v.warnf(rowNum, fieldQuestionType, "question type on a row without a question is ignored")
+			}
+			if questionMedia != "" {
+				v.warnf(rowNum, fieldQuestionMedia, "question media on a row without a question is ignored")
+			}
+		}
+
+		if optLabel == "" && optionMedia == "" {
+			if scoreStr != "" || correctStr != "" {
+				v.warnf(rowNum, fieldOption, "score and correct flag on a row without an option are ignored")
+			}
+			continue
+		}
+		if currentQ == nil {
+			v.errorf(rowNum, fieldOption, "option appears before the first question")
+			continue
+		}
+
+		score, ok := parseSheetScore(scoreStr)
+		if !ok {
+			v.errorf(rowNum, fieldScore, "score %q is not a whole number", scoreStr)
+		}
+		isCorrect, ok := parseSheetBool(correctStr)
+		if !ok {
+			v.errorf(rowNum, fieldCorrect, "%q is not TRUE or FALSE", correctStr)
+		}
+
+		opt := models.SheetOption{
+			Label:     optLabel,
+			IsCorrect: isCorrect,
+			Score:     score,
+		}
+		if optionMedia != "" {
+			opt.Media = &models.SheetMedia{FileName: optionMedia}
+		}
+		opt.Translations = rowTranslations(row, translationCols, "option")
+		currentQ.Options = append(currentQ.Options, opt)
+		report.OptionsCount++
+	}
+	finishQuestion()
+
+	if len(assessment.Questions) == 0 {
+		v.errorf(0, fieldQuestion, "the sheet has no questions")
+	}
+	if assessment.AssessmentName == "" {
+		assessment.AssessmentName = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		if schema.hasField(fieldAssessmentName) {
+			v.warnf(0, fieldAssessmentName, "no assessment name given; using %q", assessment.AssessmentName)
+		}
+	}
+
+	byRow := func(issues []models.ExcelValidationIssue) func(i, j int) bool {
+		return func(i, j int) bool { return issues[i].Row < issues[j].Row }
+	}
+	sort.SliceStable(report.Errors, byRow(report.Errors))
+	sort.SliceStable(report.Warnings, byRow(report.Warnings))
+
+	report.QuestionsCount = len(assessment.Questions)
+	report.Valid = len(report.Errors) == 0
+	return assessment, report, nil
+}
+
+func (s ExcelSchema) hasField(field string) bool {
+	for _, c := range s.Columns {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSheetQuestion checks a question once all of its option rows are read.
+func validateSheetQuestion(v *sheetValidator, row int, q *models.SheetQuestion) {
+	correct := 0
+	labels := map[string]bool{}
+	for _, opt := range q.Options {
+		if opt.IsCorrect {
+			correct++
+		}
+		if label := strings.ToLower(opt.Label); label != "" {
+			if labels[label] {
+				v.warnf(row, fieldOption, "option %q is listed more than once", opt.Label)
+			}
+			labels[label] = true
+		}
+	}
+
+	switch {
+	case choiceQuestionTypes[q.QuestionType]:
+		if len(q.Options) < 2 {
+			v.errorf(row, fieldOption, "%s question needs at least 2 options, found %d", q.QuestionType, len(q.Options))
+		}
+		if correct == 0 {
+			v.errorf(row, fieldCorrect, "%s question has no correct option", q.QuestionType)
+		}
+		if q.QuestionType == "simple_choice" && correct > 1 {
+			v.warnf(row, fieldCorrect, "simple_choice question has %d correct options; consider multiple_choice", correct)
+		}
+	case q.QuestionType != "" && !optionQuestionTypes[q.QuestionType] && len(q.Options) > 0:
+		v.warnf(row, fieldOption, "options are not used by %s questions", q.QuestionType)
+	}
+}
+
+// ExcelValidationError is returned when a workbook is rejected; nothing from it is imported.
+type ExcelValidationError struct {
+	Report *models.ExcelValidationReport
+}
+
+func (e *ExcelValidationError) Error() string {
+	if len(e.Report.Errors) == 0 {
+		return "excel validation failed"
+	}
+	first := e.Report.Errors[0]
+	location := ""
+	if first.Row > 0 {
+		location = fmt.Sprintf("row %d", first.Row)
+		if first.Column != "" {
+			location += ", column " + first.Column
+		}
+		location += ": "
+	}
+	return fmt.Sprintf("excel validation failed with %d error(s); %s%s", len(e.Report.Errors), location, first.Message)
+}
+
+// BuildQuestionnaireTemplate creates an empty import workbook from QuestionnaireSchema with a
+// few example rows and a sheet describing every column and the supported question types.
+func BuildQuestionnaireTemplate() ([]byte, error) {
+	example := models.SheetAssessment{
+		AssessmentName: "Example Assessment",
+		Questions: []models.SheetQuestion{
+			{
+				Title:             "Which colour is the DHL logo?",
+				QuestionType:      "simple_choice",
+				MandatoryToAnswer: true,
+				Options: []models.SheetOption{
+					{Label: "Red on yellow", IsCorrect: true, Score: 1},
+					{Label: "Blue on white"},
+					{Label: "Green on black"},
+				},
+			},
+			{
+				Title:        "Describe how you would handle a damaged parcel.",
+				QuestionType: "free_text",
+			},
+		},
+	}
+
+	data, err := BuildQuestionnaireExcel(example)
+	if err != nil {
+		return nil, err
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	const help = "Instructions"
+	if _, err := f.NewSheet(help); err != nil {
+		return nil, err
+	}
+	rows := [][]interface{}{{"Column", "Required", "Description"}}
+	for _, c := range QuestionnaireSchema.Columns {
+		required := "No"
+		if c.Required {
+			required = "Yes"
+		}
+		rows = append(rows, []interface{}{c.Header, required, c.Description})
+	}
+	rows = append(rows, []interface{}{}, []interface{}{"Question types", "", questionTypeNames()})
+	rows = append(rows, []interface{}{"Translations", "", `Add columns such as "Question (fr)", "Option (fr)" and "Assessment Name (fr)" for other languages.`})
+	for i, r := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(help, cell, &r); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,372 @@
+package utils
+
+import (
+	"dhl/models"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// giftAnswer is one "=" or "~" entry inside a GIFT answer block.
+type giftAnswer struct {
+	correct  bool
+	weight   *float64
+	text     string
+	feedback string
+}
+
+// ParseGIFTToJSON converts a Moodle GIFT text file into a SheetAssessment. Weighted answers
+// (~%50%...) become option scores, $CATEGORY lines become tags and "#" feedback is kept on the
+// options. Matching and other unsupported questions are reported as issues.
+func ParseGIFTToJSON(data []byte, filename string) (*models.SheetAssessment, []models.ImportItemIssue, error) {
+	assessment := &models.SheetAssessment{
+		AssessmentName: strings.TrimSuffix(path.Base(filename), path.Ext(filename)),
+		Questions:      []models.SheetQuestion{},
+	}
+
+	var issues []models.ImportItemIssue
+	var categoryTag *models.TagRequest
+
+	for idx, block := range splitGIFTBlocks(string(data)) {
+		if strings.HasPrefix(block, "$CATEGORY:") {
+			categoryTag = categoryToTag(strings.TrimSpace(strings.TrimPrefix(block, "$CATEGORY:")))
+			continue
+		}
+
+		q, issue := parseGIFTQuestion(block)
+		if issue != nil {
+			if issue.Identifier == "" {
+				issue.Identifier = fmt.Sprintf("question %d", idx+1)
+			}
+			issues = append(issues, *issue)
+			continue
+		}
+		if q == nil {
+			continue
+		}
+		if categoryTag != nil {
+			q.Tags = append(q.Tags, *categoryTag)
+		}
+		assessment.Questions = append(assessment.Questions, *q)
+	}
+
+	return assessment, issues, nil
+}
+
+// splitGIFTBlocks drops comments and splits the file on blank lines that are not inside an
+// answer block.
+func splitGIFTBlocks(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+
+	var blocks []string
+	var current []string
+	depth := 0
+
+	flush := func() {
+		block := strings.TrimSpace(strings.Join(current, "\n"))
+		if block != "" {
+			blocks = append(blocks, block)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if trimmed == "" && depth == 0 {
+			flush()
+			continue
+		}
+		if depth == 0 && strings.HasPrefix(trimmed, "$CATEGORY:") {
+			flush()
+			blocks = append(blocks, trimmed)
+			continue
+		}
+		current = append(current, line)
+		depth += giftBraceDelta(line)
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func giftBraceDelta(line string) int {
+	delta := 0
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '{':
+			delta++
+		case r == '}':
+			delta--
+		}
+	}
+	return delta
+}
+
+// indexUnescaped returns the byte index of the first unescaped occurrence of c at or after from.
+func indexUnescaped(s string, c byte, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeGIFT(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				sb.WriteByte('\n')
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// cleanGIFTText removes the optional [html]/[markdown]/[plain]/[moodle] format marker and escapes.
+func cleanGIFTText(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		if end := strings.Index(s, "]"); end > 0 {
+			switch strings.ToLower(s[1:end]) {
+			case "html":
+				return stripHTML(unescapeGIFT(s[end+1:]))
+			case "markdown", "plain", "moodle":
+				s = s[end+1:]
+			}
+		}
+	}
+	return unescapeGIFT(s)
+}
+
+func parseGIFTQuestion(block string) (*models.SheetQuestion, *models.ImportItemIssue) {
+	name := ""
+	if strings.HasPrefix(block, "::") {
+		if end := strings.Index(block[2:], "::"); end >= 0 {
+			name = unescapeGIFT(block[2 : end+2])
+			block = block[end+4:]
+		}
+	}
+
+	open := indexUnescaped(block, '{', 0)
+	if open < 0 {
+		// a block without answers is a description, which is not a question
+		return nil, nil
+	}
+	closing := indexUnescaped(block, '}', open+1)
+	if closing < 0 {
+		return nil, &models.ImportItemIssue{Identifier: name, Title: cleanGIFTText(block), Reason: "answer block is not closed"}
+	}
+
+	title := cleanGIFTText(block[:open])
+	if after := cleanGIFTText(block[closing+1:]); after != "" {
+		// fill-in-the-blank: the answer block sits inside the sentence
+		title = strings.TrimSpace(title + " _____ " + after)
+	}
+	if title == "" {
+		title = name
+	}
+
+	body := strings.TrimSpace(block[open+1 : closing])
+	q := &models.SheetQuestion{
+		Title:             title,
+		MandatoryToAnswer: true,
+		Options:           []models.SheetOption{},
+	}
+
+	if gf := strings.Index(body, "####"); gf >= 0 {
+		q.Feedback = cleanGIFTText(body[gf+4:])
+		body = strings.TrimSpace(body[:gf])
+	}
+
+	switch {
+	case body == "":
+		q.QuestionType = "free_text"
+		return q, nil
+
+	case isGIFTTrueFalse(body):
+		q.QuestionType = "simple_choice"
+		value, feedback := splitGIFTFeedback(body)
+		answer := strings.HasPrefix(strings.ToUpper(strings.TrimSpace(value)), "T")
+		q.Options = []models.SheetOption{
+			{Label: "True", IsCorrect: answer, Score: giftBoolScore(answer)},
+			{Label: "False", IsCorrect: !answer, Score: giftBoolScore(!answer)},
+		}
+		if feedback != "" {
+			q.Options[0].Feedback = feedback
+		}
+		return q, nil
+
+	case strings.HasPrefix(body, "#"):
+		q.QuestionType = "numerical_box"
+		numeric := strings.TrimSpace(body[1:])
+		answers := parseGIFTAnswers(numeric)
+		if len(answers) == 0 {
+			value, feedback := splitGIFTFeedback(numeric)
+			answers = []giftAnswer{{correct: true, text: value, feedback: feedback}}
+		}
+		for _, a := range answers {
+			// "value:tolerance" keeps only the value; ranges ("1..5") are kept as written
+			if i := strings.Index(a.text, ":"); i > 0 {
+				a.text = strings.TrimSpace(a.text[:i])
+			}
+			q.Options = append(q.Options, giftOption(a, true))
+		}
+		return q, nil
+
+	case strings.Contains(body, "->"):
+		return nil, &models.ImportItemIssue{Identifier: name, Title: title, Interaction: "matching", Reason: "unsupported gift question type"}
+	}
+
+	answers := parseGIFTAnswers(body)
+	if len(answers) == 0 {
+		return nil, &models.ImportItemIssue{Identifier: name, Title: title, Reason: "no answers found"}
+	}
+
+	hasWrong := false
+	equalsCount := 0
+	weightedCount := 0
+	for _, a := range answers {
+		if a.correct {
+			equalsCount++
+		} else {
+			hasWrong = true
+		}
+		if !a.correct && a.weight != nil && *a.weight > 0 {
+			weightedCount++
+		}
+	}
+
+	switch {
+	case !hasWrong:
+		// only "=" answers: short answer with accepted variants
+		q.QuestionType = "free_text"
+	case equalsCount == 0 && weightedCount > 1:
+		// multiple-answer questions use only weighted "~" answers
+		q.QuestionType = "multiple_choice"
+	default:
+		q.QuestionType = "simple_choice"
+	}
+
+	for _, a := range answers {
+		q.Options = append(q.Options, giftOption(a, q.QuestionType != "simple_choice"))
+	}
+	return q, nil
+}
+
+func isGIFTTrueFalse(body string) bool {
+	value, _ := splitGIFTFeedback(body)
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	}
+	return false
+}
+
+func giftBoolScore(correct bool) int {
+	if correct {
+		return percentScore(100, 1)
+	}
+	return 0
+}
+
+// percentScore converts a GIFT or Moodle answer weight, a percentage of the question's points,
+// to the points options score elsewhere, as QTI and sheet imports do: 1 for the correct answer
+// of a one point question.
+func percentScore(percent float64, points int64) int {
+	if points <= 0 {
+		points = 1
+	}
+	return int(math.Round(percent * float64(points) / 100))
+}
+
+// splitGIFTFeedback splits "text#feedback" on the first unescaped '#'.
+func splitGIFTFeedback(s string) (string, string) {
+	if i := indexUnescaped(s, '#', 0); i >= 0 {
+		return cleanGIFTText(s[:i]), cleanGIFTText(s[i+1:])
+	}
+	return cleanGIFTText(s), ""
+}
+
+// parseGIFTAnswers splits an answer block on unescaped '=' and '~' markers.
+func parseGIFTAnswers(body string) []giftAnswer {
+	var answers []giftAnswer
+	start := -1
+	correct := false
+
+	emit := func(end int) {
+		if start < 0 {
+			return
+		}
+		raw := strings.TrimSpace(body[start:end])
+		a := giftAnswer{correct: correct}
+		if strings.HasPrefix(raw, "%") {
+			if end := strings.Index(raw[1:], "%"); end >= 0 {
+				if w, err := strconv.ParseFloat(raw[1:end+1], 64); err == nil {
+					a.weight = &w
+				}
+				raw = raw[end+2:]
+			}
+		}
+		a.text, a.feedback = splitGIFTFeedback(raw)
+		answers = append(answers, a)
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			emit(i)
+			start = i + 1
+			correct = body[i] == '='
+		}
+	}
+	emit(len(body))
+
+	return answers
+}
+
+func giftOption(a giftAnswer, partialIsCorrect bool) models.SheetOption {
+	score := 0
+	switch {
+	case a.weight != nil:
+		score = percentScore(*a.weight, 1)
+	case a.correct:
+		score = percentScore(100, 1)
+	}
+
+	isCorrect := a.correct
+	if a.weight != nil {
+		isCorrect = *a.weight >= 100 || (partialIsCorrect && *a.weight > 0)
+	}
+
+	return models.SheetOption{
+		Label:     a.text,
+		IsCorrect: isCorrect,
+		Score:     score,
+		Feedback:  a.feedback,
+	}
+}
@@ -1,13 +1,22 @@
 package utils
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"dhl/constant"
 	"dhl/models"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"mime/multipart"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,6 +24,20 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+type shutdownKey struct{}
+
+// WithShutdown attaches a channel that is closed when the server starts shutting down, for
+// handlers that would otherwise hold their connection open until the client leaves.
+func WithShutdown(ctx context.Context, shutdown <-chan struct{}) context.Context {
+	return context.WithValue(ctx, shutdownKey{}, shutdown)
+}
+
+// Shutdown returns the channel attached by WithShutdown, or nil, which is never ready.
+func Shutdown(ctx context.Context) <-chan struct{} {
+	shutdown, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return shutdown
+}
+
 func GetBuildVersion() string {
 	buildVersion := os.Getenv("BUILD_VERSION")
 	return buildVersion
@@ -71,6 +94,8 @@ func GetUserIDFromContext(ctx *gin.Context, getUserIdBySubFunc func(string) (str
 	return highestRole, userID, false, nil
 }
 
+// ParseExcelToJSON reads the older question layout (question, correct, score, option, type,
+// mandatory). The workbook is rejected with an *ExcelValidationError when any row is invalid.
 func ParseExcelToJSON(file multipart.File, filename string) (*models.SheetAssessment, error) {
 	f, err := excelize.OpenReader(file)
 	if err != nil {
@@ -78,68 +103,14 @@ func ParseExcelToJSON(file multipart.File, filename string) (*models.SheetAssess
 	}
 	defer f.Close()
 
-	rows, err := f.GetRows(f.GetSheetName(0))
+	assessment, report, err := parseQuestionWorkbook(f, filename, legacyQuestionSchema)
 	if err != nil {
 		return nil, err
 	}
-
-	var assessment models.SheetAssessment
-	assessment.AssessmentName = filename
-
-	var questions []models.SheetQuestion
-	var currentQuestion *models.SheetQuestion
-
-	for i, row := range rows {
-		if i == 0 {
-			// Skip header row
-			continue
-		}
-
-		var (
-			questionTitle = strings.TrimSpace(getCell(row, 0)) // A
-			isCorrectStr  = strings.TrimSpace(getCell(row, 1)) // B
-			scoreStr      = strings.TrimSpace(getCell(row, 2)) // C
-			optionLabel   = strings.TrimSpace(getCell(row, 3)) // D
-			questionType  = strings.TrimSpace(getCell(row, 4)) // E
-			mandatoryStr  = strings.TrimSpace(getCell(row, 5)) // F
-		)
-
-		// Detect new question
-		if questionTitle != "" {
-			if currentQuestion != nil {
-				questions = append(questions, *currentQuestion)
-			}
-			currentQuestion = &models.SheetQuestion{
-				Title:             questionTitle,
-				MandatoryToAnswer: strings.EqualFold(mandatoryStr, "true"),
-				QuestionType:      questionType,
-				Options:           []models.SheetOption{},
-			}
-		}
-
-		if currentQuestion == nil {
-			continue
-		}
-
-		isCorrect := strings.EqualFold(isCorrectStr, "true") || isCorrectStr == "1"
-		score := 0
-		fmt.Sscanf(scoreStr, "%d", &score)
-
-		if optionLabel != "" {
-			currentQuestion.Options = append(currentQuestion.Options, models.SheetOption{
-				Label:     optionLabel,
-				IsCorrect: isCorrect,
-				Score:     score,
-			})
-		}
-	}
-
-	if currentQuestion != nil {
-		questions = append(questions, *currentQuestion)
+	if !report.Valid {
+		return nil, &ExcelValidationError{Report: report}
 	}
-
-	assessment.Questions = questions
-	return &assessment, nil
+	return assessment, nil
 }
 
 func getCell(row []string, index int) string {
@@ -205,88 +176,249 @@ func splitGormTag(tag string) []string {
 	return parts
 }
 
-func ParseQuestionnaireExcelToJSON(file multipart.File, filename string) (*models.SheetAssessment, error) {
-	// Load Excel file
-	f, err := excelize.OpenReader(file)
+// ParseQuestionnaireExcelToJSON reads a questionnaire workbook laid out as QuestionnaireSchema.
+// The workbook is rejected as a whole with an *ExcelValidationError when any row is invalid;
+// use ValidateQuestionnaireExcel to get the full report without importing.
+func ParseQuestionnaireExcelToJSON(file io.Reader, filename string) (*models.SheetAssessment, error) {
+	assessment, report, err := ValidateQuestionnaireExcel(file, filename)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open excel: %w", err)
+		return nil, err
+	}
+	if !report.Valid {
+		return nil, &ExcelValidationError{Report: report}
 	}
+	return assessment, nil
+}
 
-	rows, err := f.GetRows("Sheet1") // change if your sheet name differs
-	if err != nil {
-		return nil, fmt.Errorf("cannot read sheet: %w", err)
+// translationColumn is an extra questionnaire column such as "Question (fr)" holding the
+// assessment name, question or option in another language.
+type translationColumn struct {
+	field  string
+	locale string
+	index  int
+}
+
+var translationHeaderPattern = regexp.MustCompile(`(?i)^\s*(assessment name|question|option)\s*[(\[]\s*([a-z]{2,3}(?:[-_][a-z0-9]{2,8})*)\s*[)\]]\s*$`)
+
+func parseTranslationHeaders(header []string) []translationColumn {
+	var cols []translationColumn
+	for idx, h := range header {
+		m := translationHeaderPattern.FindStringSubmatch(h)
+		if m == nil {
+			continue
+		}
+		locale, err := NormalizeLocale(m[2])
+		if err != nil || locale == constant.DefaultLocale {
+			continue
+		}
+		cols = append(cols, translationColumn{field: strings.ToLower(m[1]), locale: locale, index: idx})
 	}
+	return cols
+}
 
-	assessment := &models.SheetAssessment{
-		AssessmentName: "",
-		Questions:      []models.SheetQuestion{},
+func cellAt(row []string, idx int) string {
+	if idx < len(row) {
+		return strings.TrimSpace(row[idx])
 	}
+	return ""
+}
 
-	var currentQ *models.SheetQuestion
+func rowTranslations(row []string, cols []translationColumn, field string) map[string]string {
+	var translations map[string]string
+	for _, col := range cols {
+		if col.field != field {
+			continue
+		}
+		if value := cellAt(row, col.index); value != "" {
+			if translations == nil {
+				translations = map[string]string{}
+			}
+			translations[col.locale] = value
+		}
+	}
+	return translations
+}
 
-	for i, row := range rows {
-		if i == 0 {
-			continue // skip header
+// sheetLocales lists the translation locales used anywhere in a sheet assessment.
+func sheetLocales(assessment models.SheetAssessment) []string {
+	seen := map[string]bool{}
+	for locale := range assessment.NameTranslations {
+		seen[locale] = true
+	}
+	for _, q := range assessment.Questions {
+		for locale := range q.Translations {
+			seen[locale] = true
 		}
+		for _, opt := range q.Options {
+			for locale := range opt.Translations {
+				seen[locale] = true
+			}
+		}
+	}
+
+	locales := make([]string, 0, len(seen))
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
 
-		// Ensure minimum columns exist
-		for len(row) < 9 {
-			row = append(row, "")
+// DetectImportFormat works out which importer handles an uploaded file, using the extension
+// first and the content to settle ambiguous cases (.xml, .txt, .zip or no extension).
+func DetectImportFormat(filename string, data []byte) (constant.ImportFormat, error) {
+	head := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	isZip := bytes.HasPrefix(data, []byte("PK\x03\x04"))
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx", ".xlsm", ".xls":
+		return constant.ImportFormatExcel, nil
+	case ".json":
+		return constant.ImportFormatBundle, nil
+	case ".gift":
+		return constant.ImportFormatGIFT, nil
+	}
+
+	if isZip {
+		return detectZipImportFormat(filename, data)
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("{")):
+		return constant.ImportFormatBundle, nil
+	case bytes.HasPrefix(head, []byte("<")):
+		if bytes.Contains(head, []byte("<quiz")) {
+			return constant.ImportFormatMoodleXML, nil
 		}
+		return "", fmt.Errorf("unrecognised xml file %s: expected a moodle <quiz> export", filename)
+	case bytes.Contains(head, []byte("{")) && bytes.Contains(head, []byte("}")):
+		return constant.ImportFormatGIFT, nil
+	}
+
+	return "", fmt.Errorf("unsupported import file %s", filename)
+}
+
+// detectZipImportFormat tells QTI packages, bare xlsx workbooks and questionnaire workbooks
+// bundled with their media files apart by the entries in the archive.
+func detectZipImportFormat(filename string, data []byte) (constant.ImportFormat, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("cannot open zip file %s: %w", filename, err)
+	}
 
-		questionType := strings.TrimSpace(row[1])
-		assessmentName := strings.TrimSpace(row[2])
-		title := strings.TrimSpace(row[3])
-		optLabel := strings.TrimSpace(row[4])
-		scoreStr := strings.TrimSpace(row[5])
-		correctStr := strings.TrimSpace(row[6])
-		mandatoryStr := strings.TrimSpace(row[7]) // mandatory answer
-
-		// Set assessment name (once)
-		if assessment.AssessmentName == "" && assessmentName != "" {
-			assessment.AssessmentName = assessmentName
+	hasWorkbook := false
+	for _, f := range zr.File {
+		name := strings.ToLower(path.Base(f.Name))
+		switch {
+		case name == "imsmanifest.xml":
+			return constant.ImportFormatQTI, nil
+		case f.Name == "[Content_Types].xml":
+			return constant.ImportFormatExcel, nil
+		case strings.HasSuffix(name, ".xlsx") && !strings.HasPrefix(name, "~$"):
+			hasWorkbook = true
 		}
+	}
+	if hasWorkbook {
+		return constant.ImportFormatExcelZip, nil
+	}
+	return "", fmt.Errorf("zip file %s has neither an imsmanifest.xml nor an xlsx questionnaire", filename)
+}
 
-		// If title cell contains question → start new question
-		if title != "" {
-			if currentQ != nil {
-				assessment.Questions = append(assessment.Questions, *currentQ)
-			}
+// QuestionnaireHeaders are the columns read by ParseQuestionnaireExcelToJSON, in order.
+var QuestionnaireHeaders = QuestionnaireSchema.Headers()
 
-			mandatory := false
-			if mandatoryStr == "1" || strings.ToUpper(mandatoryStr) == "TRUE" {
-				mandatory = true
-			}
+// BuildQuestionnaireExcel writes an assessment in the layout read by ParseQuestionnaireExcelToJSON:
+// one row per option, with the question columns filled only on the first option row.
+func BuildQuestionnaireExcel(assessment models.SheetAssessment) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := QuestionnaireSchema.Sheet
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return nil, err
+	}
 
-			currentQ = &models.SheetQuestion{
-				Title:             title,
-				MandatoryToAnswer: mandatory,
-				QuestionType:      questionType,
-				Options:           []models.SheetOption{},
-			}
+	// each translated locale adds an assessment name, question and option column
+	locales := sheetLocales(assessment)
+	headers := append([]string{}, QuestionnaireHeaders...)
+	for _, locale := range locales {
+		headers = append(headers,
+			fmt.Sprintf("Assessment Name (%s)", locale),
+			fmt.Sprintf("Question (%s)", locale),
+			fmt.Sprintf("Option (%s)", locale))
+	}
+
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+
+	row := 2
+	for qIdx, q := range assessment.Questions {
+		mandatory := "FALSE"
+		if q.MandatoryToAnswer {
+			mandatory = "TRUE"
 		}
 
-		// Create option only if label exists
-		if optLabel != "" && currentQ != nil {
-			score, _ := strconv.Atoi(scoreStr)
-			isCorrect := strings.ToUpper(correctStr) == "TRUE"
+		options := q.Options
+		if len(options) == 0 {
+			// free text style questions still need a row for the title
+			options = []models.SheetOption{{}}
+		}
 
-			opt := models.SheetOption{
-				Label:     optLabel,
-				IsCorrect: isCorrect,
-				Score:     score,
+		for optIdx, opt := range options {
+			values := make([]interface{}, len(headers))
+			if optIdx == 0 {
+				values[0] = qIdx + 1
+				values[1] = q.QuestionType
+				values[2] = assessment.AssessmentName
+				values[3] = q.Title
+				values[7] = mandatory
+				if q.Media != nil && q.Media.FileName != "" {
+					values[8] = q.Media.FileName
+				}
+				for lIdx, locale := range locales {
+					base := len(QuestionnaireHeaders) + lIdx*3
+					if name := assessment.NameTranslations[locale]; name != "" {
+						values[base] = name
+					}
+					if title := q.Translations[locale]; title != "" {
+						values[base+1] = title
+					}
+				}
+			}
+			if opt.Label != "" || opt.Media != nil {
+				values[4] = opt.Label
+				values[5] = opt.Score
+				values[6] = strings.ToUpper(strconv.FormatBool(opt.IsCorrect))
+				if opt.Media != nil && opt.Media.FileName != "" {
+					values[9] = opt.Media.FileName
+				}
+				for lIdx, locale := range locales {
+					if label := opt.Translations[locale]; label != "" {
+						values[len(QuestionnaireHeaders)+lIdx*3+2] = label
+					}
+				}
 			}
 
-			currentQ.Options = append(currentQ.Options, opt)
+			for cIdx, v := range values {
+				if v == nil {
+					continue
+				}
+				cell, _ := excelize.CoordinatesToCellName(cIdx+1, row)
+				f.SetCellValue(sheet, cell, v)
+			}
+			row++
 		}
 	}
 
-	// Append the final question
-	if currentQ != nil {
-		assessment.Questions = append(assessment.Questions, *currentQ)
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
 	}
-
-	return assessment, nil
+	return buf.Bytes(), nil
 }
 
 var QuestionTypeMap = map[string]int{
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"dhl/constant"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// NormalizeLocale turns "fr_ca", "FR-CA" or "fr-CA" into "fr-CA". An empty locale is returned
+// as the default locale.
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if locale == "" {
+		return constant.DefaultLocale, nil
+	}
+	if !localePattern.MatchString(locale) {
+		return "", fmt.Errorf("invalid locale %q", locale)
+	}
+
+	parts := strings.Split(locale, "-")
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			// region subtags are upper case, script subtags title case
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// MatchLocale picks the best available locale for a requested one: an exact match, then the
+// bare language ("fr-CA" -> "fr"), then the default locale.
+func MatchLocale(requested string, available []string) string {
+	if requested == "" || requested == constant.DefaultLocale {
+		return constant.DefaultLocale
+	}
+	language := strings.SplitN(requested, "-", 2)[0]
+	fallback := ""
+	for _, l := range available {
+		if l == requested {
+			return l
+		}
+		if l == language {
+			fallback = l
+		}
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return constant.DefaultLocale
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaStorage stores uploaded question media. Keys are relative, slash-separated paths.
+type MediaStorage interface {
+	Save(key string, data []byte) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalMediaStorage keeps media files on the local disk under Root.
+type LocalMediaStorage struct {
+	Root string
+}
+
+// NewMediaStorage returns the storage backend selected by MEDIA_STORAGE_DRIVER. Only "local"
+// is available today; MEDIA_STORAGE_PATH sets its root directory.
+func NewMediaStorage() MediaStorage {
+	driver := strings.ToLower(os.Getenv("MEDIA_STORAGE_DRIVER"))
+	if driver != "" && driver != "local" {
+		log.Printf("unknown MEDIA_STORAGE_DRIVER %q, falling back to local storage", driver)
+	}
+
+	root := os.Getenv("MEDIA_STORAGE_PATH")
+	if root == "" {
+		root = filepath.Join("assets", "media")
+	}
+	return &LocalMediaStorage{Root: root}
+}
+
+func (s *LocalMediaStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid media key %q", key)
+	}
+	return filepath.Join(s.Root, clean), nil
+}
+
+func (s *LocalMediaStorage) Save(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func (s *LocalMediaStorage) Open(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s *LocalMediaStorage) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// MediaURL is the URL clients use to fetch stored media through the media endpoint.
+// MEDIA_BASE_URL overrides the default of the assessment media route.
+func MediaURL(key string) string {
+	base := os.Getenv("MEDIA_BASE_URL")
+	if base == "" {
+		base = os.Getenv("ApiVersion") + "/assessment/media"
+	}
+	return base + "?key=" + url.QueryEscape(key)
+}
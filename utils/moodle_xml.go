@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"dhl/models"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleQuestion struct {
+	Type            string         `xml:"type,attr"`
+	Name            string         `xml:"name>text"`
+	QuestionText    string         `xml:"questiontext>text"`
+	GeneralFeedback string         `xml:"generalfeedback>text"`
+	DefaultGrade    string         `xml:"defaultgrade"`
+	Single          string         `xml:"single"`
+	Category        string         `xml:"category>text"`
+	Answers         []moodleAnswer `xml:"answer"`
+	Tags            []string       `xml:"tags>tag>text"`
+}
+
+type moodleAnswer struct {
+	Fraction string `xml:"fraction,attr"`
+	Text     string `xml:"text"`
+	Feedback string `xml:"feedback>text"`
+}
+
+var htmlTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// stripHTML turns an HTML fragment from an imported question into plain text.
+func stripHTML(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// ParseMoodleXMLToJSON converts a Moodle XML question export into a SheetAssessment.
+// Answer fractions become option scores, categories become tags and feedback is kept on the
+// question and its options. Question types without an equivalent are reported as issues.
+func ParseMoodleXMLToJSON(data []byte, filename string) (*models.SheetAssessment, []models.ImportItemIssue, error) {
+	var quiz moodleQuiz
+	if err := xml.Unmarshal(data, &quiz); err != nil {
+		return nil, nil, fmt.Errorf("cannot read moodle xml: %w", err)
+	}
+
+	assessment := &models.SheetAssessment{
+		AssessmentName: strings.TrimSuffix(path.Base(filename), path.Ext(filename)),
+		Questions:      []models.SheetQuestion{},
+	}
+
+	var issues []models.ImportItemIssue
+	var categoryTag *models.TagRequest
+
+	for idx, mq := range quiz.Questions {
+		identifier := mq.Name
+		if identifier == "" {
+			identifier = fmt.Sprintf("question %d", idx+1)
+		}
+
+		switch mq.Type {
+		case "category":
+			categoryTag = categoryToTag(mq.Category)
+			continue
+		case "description":
+			// descriptions are informational text, not questions
+			continue
+		}
+
+		q := models.SheetQuestion{
+			Title:             stripHTML(mq.QuestionText),
+			MandatoryToAnswer: true,
+			Options:           []models.SheetOption{},
+			Feedback:          stripHTML(mq.GeneralFeedback),
+		}
+		if q.Title == "" {
+			q.Title = mq.Name
+		}
+		if grade, err := strconv.ParseFloat(strings.TrimSpace(mq.DefaultGrade), 64); err == nil && grade > 0 {
+			q.Points = int64(math.Round(grade))
+		}
+
+		switch mq.Type {
+		case "multichoice":
+			single := !strings.EqualFold(strings.TrimSpace(mq.Single), "false") && strings.TrimSpace(mq.Single) != "0"
+			q.QuestionType = "multiple_choice"
+			if single {
+				q.QuestionType = "simple_choice"
+			}
+			for _, a := range mq.Answers {
+				fraction := parseFraction(a.Fraction)
+				isCorrect := fraction > 0
+				if single {
+					isCorrect = fraction >= 100
+				}
+				q.Options = append(q.Options, models.SheetOption{
+					Label:     stripHTML(a.Text),
+					IsCorrect: isCorrect,
+					Score:     percentScore(fraction, q.Points),
+					Feedback:  stripHTML(a.Feedback),
+				})
+			}
+
+		case "truefalse":
+			q.QuestionType = "simple_choice"
+			for _, a := range mq.Answers {
+				fraction := parseFraction(a.Fraction)
+				label := stripHTML(a.Text)
+				if strings.EqualFold(label, "true") {
+					label = "True"
+				} else if strings.EqualFold(label, "false") {
+					label = "False"
+				}
+				q.Options = append(q.Options, models.SheetOption{
+					Label:     label,
+					IsCorrect: fraction >= 100,
+					Score:     percentScore(fraction, q.Points),
+					Feedback:  stripHTML(a.Feedback),
+				})
+			}
+
+		case "shortanswer", "numerical":
+			q.QuestionType = "free_text"
+			if mq.Type == "numerical" {
+				q.QuestionType = "numerical_box"
+			}
+			for _, a := range mq.Answers {
+				fraction := parseFraction(a.Fraction)
+				label := stripHTML(a.Text)
+				if label == "*" {
+					// wildcard answers only carry feedback for everything else
+					if q.Feedback == "" {
+						q.Feedback = stripHTML(a.Feedback)
+					}
+					continue
+				}
+				q.Options = append(q.Options, models.SheetOption{
+					Label:     label,
+					IsCorrect: fraction > 0,
+					Score:     percentScore(fraction, q.Points),
+					Feedback:  stripHTML(a.Feedback),
+				})
+			}
+
+		case "essay":
+			q.QuestionType = "free_text"
+
+		default:
+			issues = append(issues, models.ImportItemIssue{
+				Identifier:  identifier,
+				Title:       q.Title,
+				Interaction: mq.Type,
+				Reason:      "unsupported moodle question type",
+			})
+			continue
+		}
+
+		if categoryTag != nil {
+			q.Tags = append(q.Tags, *categoryTag)
+		}
+		if len(mq.Tags) > 0 {
+			var tagNames []string
+			for _, t := range mq.Tags {
+				if t = strings.TrimSpace(t); t != "" {
+					tagNames = append(tagNames, t)
+				}
+			}
+			if len(tagNames) > 0 {
+				q.Tags = append(q.Tags, models.TagRequest{ChildTags: tagNames})
+			}
+		}
+
+		assessment.Questions = append(assessment.Questions, q)
+	}
+
+	return assessment, issues, nil
+}
+
+func parseFraction(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// categoryToTag maps a category path such as "$course$/top/Customs/Import" onto a parent/child
+// tag, using the first and last meaningful segments.
+func categoryToTag(category string) *models.TagRequest {
+	var segments []string
+	for _, seg := range strings.Split(category, "/") {
+		seg = strings.TrimSpace(seg)
+		if seg == "" || strings.EqualFold(seg, "top") || (strings.HasPrefix(seg, "$") && strings.HasSuffix(seg, "$")) {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+
+	switch len(segments) {
+	case 0:
+		return nil
+	case 1:
+		return &models.TagRequest{ChildTags: segments}
+	default:
+		return &models.TagRequest{ParentTag: segments[0], ChildTags: []string{segments[len(segments)-1]}}
+	}
+}
@@ -0,0 +1,790 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"dhl/models"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is a generic element tree. QTI 2.1 (camelCase) and QTI 3.0 (qti-kebab-case)
+// element names are compared through qtiName so one parser handles both versions.
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Inner    string     `xml:",innerxml"`
+	Children []xmlNode  `xml:",any"`
+}
+
+func qtiName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.TrimPrefix(name, "qti-")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+func (n *xmlNode) is(name string) bool {
+	return qtiName(n.XMLName.Local) == qtiName(name)
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if qtiName(a.Name.Local) == qtiName(name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) find(name string) *xmlNode {
+	for i := range n.Children {
+		c := &n.Children[i]
+		if c.is(name) {
+			return c
+		}
+		if found := c.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) findAll(name string) []*xmlNode {
+	var out []*xmlNode
+	for i := range n.Children {
+		c := &n.Children[i]
+		if c.is(name) {
+			out = append(out, c)
+		}
+		out = append(out, c.findAll(name)...)
+	}
+	return out
+}
+
+// text returns the whitespace-collapsed text of the node in document order, optionally
+// leaving out the content of interaction elements.
+func (n *xmlNode) text(skipInteractions bool) string {
+	dec := xml.NewDecoder(strings.NewReader(n.Inner))
+	dec.Strict = false
+
+	var sb strings.Builder
+	skipDepth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 || (skipInteractions && strings.HasSuffix(qtiName(t.Name.Local), "interaction")) {
+				skipDepth++
+			}
+			if !inlineElements[t.Name.Local] {
+				sb.WriteString(" ")
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+			}
+			if !inlineElements[t.Name.Local] {
+				sb.WriteString(" ")
+			}
+		case xml.CharData:
+			if skipDepth == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// inlineElements are XHTML elements that do not break words when their markup is stripped.
+var inlineElements = map[string]bool{
+	"b": true, "i": true, "u": true, "em": true, "strong": true, "span": true,
+	"sub": true, "sup": true, "code": true, "a": true, "small": true, "big": true,
+}
+
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+func isInteraction(n *xmlNode) bool {
+	return strings.HasSuffix(qtiName(n.XMLName.Local), "interaction")
+}
+
+// ParseQTIPackage reads an IMS content package (zip with imsmanifest.xml) holding QTI 2.1 or 3.0
+// items. Choice, multiple-response, text-entry and numeric items are converted; every other item
+// is returned as an issue instead of being dropped silently.
+func ParseQTIPackage(data []byte, filename string) (*models.SheetAssessment, []models.ImportItemIssue, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open qti package: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	manifestPath := ""
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+		if strings.EqualFold(path.Base(f.Name), "imsmanifest.xml") {
+			if manifestPath == "" || len(f.Name) < len(manifestPath) {
+				manifestPath = path.Clean(f.Name)
+			}
+		}
+	}
+	if manifestPath == "" {
+		return nil, nil, fmt.Errorf("imsmanifest.xml not found in %s", filename)
+	}
+
+	manifest, err := readZipXML(files, manifestPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read imsmanifest.xml: %w", err)
+	}
+	baseDir := path.Dir(manifestPath)
+
+	assessment := &models.SheetAssessment{
+		AssessmentName: strings.TrimSuffix(path.Base(filename), path.Ext(filename)),
+		Questions:      []models.SheetQuestion{},
+	}
+	if org := manifest.find("organization"); org != nil {
+		if title := org.find("title"); title != nil && title.text(false) != "" {
+			assessment.AssessmentName = title.text(false)
+		}
+	}
+
+	var itemOrder, testOrder []string
+	itemTags := make(map[string][]models.TagRequest)
+	for _, res := range manifest.findAll("resource") {
+		resType := strings.ToLower(res.attr("type"))
+		href := path.Join(baseDir, res.attr("href"))
+
+		switch {
+		case strings.Contains(resType, "imsqti_item"):
+			itemOrder = append(itemOrder, href)
+			itemTags[href] = qtiMetadataTags(res)
+		case strings.Contains(resType, "imsqti_test"):
+			test, err := readZipXML(files, href)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot read assessment test %s: %w", href, err)
+			}
+			if title := test.attr("title"); title != "" {
+				assessment.AssessmentName = title
+			}
+			for _, ref := range test.findAll("assessmentItemRef") {
+				testOrder = append(testOrder, path.Join(path.Dir(href), ref.attr("href")))
+			}
+		}
+	}
+	if len(testOrder) > 0 {
+		itemOrder = mergeItemOrder(testOrder, itemOrder)
+	}
+
+	var issues []models.ImportItemIssue
+	for _, href := range itemOrder {
+		item, err := readZipXML(files, href)
+		if err != nil {
+			issues = append(issues, models.ImportItemIssue{Identifier: href, Reason: fmt.Sprintf("cannot read item: %v", err)})
+			continue
+		}
+
+		q, issue := qtiItemToQuestion(item)
+		if issue != nil {
+			if issue.Identifier == "" {
+				issue.Identifier = href
+			}
+			issues = append(issues, *issue)
+			continue
+		}
+		q.Tags = append(q.Tags, itemTags[href]...)
+		assessment.Questions = append(assessment.Questions, q)
+	}
+
+	return assessment, issues, nil
+}
+
+// mergeItemOrder returns the test order followed by any manifest items the test does not reference.
+func mergeItemOrder(testOrder, manifestOrder []string) []string {
+	seen := make(map[string]bool, len(testOrder))
+	order := append([]string(nil), testOrder...)
+	for _, h := range testOrder {
+		seen[h] = true
+	}
+	for _, h := range manifestOrder {
+		if !seen[h] {
+			order = append(order, h)
+			seen[h] = true
+		}
+	}
+	return order
+}
+
+func readZipXML(files map[string]*zip.File, name string) (*xmlNode, error) {
+	f, ok := files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found in package", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return parseXMLNode(data)
+}
+
+// qtiMetadataTags maps LOM keywords to standalone tags and LOM taxon paths to parent/child tags.
+func qtiMetadataTags(resource *xmlNode) []models.TagRequest {
+	var tags []models.TagRequest
+
+	var keywords []string
+	for _, kw := range resource.findAll("keyword") {
+		if v := kw.text(false); v != "" {
+			keywords = append(keywords, v)
+		}
+	}
+	if len(keywords) > 0 {
+		tags = append(tags, models.TagRequest{ChildTags: keywords})
+	}
+
+	for _, tp := range resource.findAll("taxonPath") {
+		var entries []string
+		for _, taxon := range tp.findAll("taxon") {
+			if entry := taxon.find("entry"); entry != nil && entry.text(false) != "" {
+				entries = append(entries, entry.text(false))
+			}
+		}
+		switch len(entries) {
+		case 0:
+		case 1:
+			tags = append(tags, models.TagRequest{ParentTag: entries[0]})
+		default:
+			tags = append(tags, models.TagRequest{ParentTag: entries[0], ChildTags: []string{entries[len(entries)-1]}})
+		}
+	}
+
+	return tags
+}
+
+func qtiItemToQuestion(item *xmlNode) (models.SheetQuestion, *models.ImportItemIssue) {
+	identifier := item.attr("identifier")
+	itemTitle := item.attr("title")
+
+	body := item.find("itemBody")
+	if body == nil {
+		return models.SheetQuestion{}, &models.ImportItemIssue{Identifier: identifier, Title: itemTitle, Reason: "item has no itemBody"}
+	}
+
+	interactions := collectInteractions(body)
+	if len(interactions) != 1 {
+		names := make([]string, 0, len(interactions))
+		for _, n := range interactions {
+			names = append(names, n.XMLName.Local)
+		}
+		return models.SheetQuestion{}, &models.ImportItemIssue{
+			Identifier:  identifier,
+			Title:       itemTitle,
+			Interaction: strings.Join(names, ","),
+			Reason:      fmt.Sprintf("expected exactly one interaction, found %d", len(interactions)),
+		}
+	}
+	interaction := interactions[0]
+
+	decl := qtiResponseDeclaration(item, interaction.attr("responseIdentifier"))
+	if decl == nil {
+		return models.SheetQuestion{}, &models.ImportItemIssue{Identifier: identifier, Title: itemTitle, Interaction: interaction.XMLName.Local, Reason: "missing responseDeclaration"}
+	}
+
+	correct := make(map[string]bool)
+	var correctValues []string
+	if cr := decl.find("correctResponse"); cr != nil {
+		for _, v := range cr.findAll("value") {
+			val := v.text(false)
+			correct[val] = true
+			correctValues = append(correctValues, val)
+		}
+	}
+	mapped := make(map[string]float64)
+	for _, entry := range decl.findAll("mapEntry") {
+		if v, err := strconv.ParseFloat(entry.attr("mappedValue"), 64); err == nil {
+			mapped[entry.attr("mapKey")] = v
+		}
+	}
+
+	title := ""
+	if prompt := interaction.find("prompt"); prompt != nil {
+		title = prompt.text(false)
+	}
+	if title == "" {
+		title = body.text(true)
+	}
+	if title == "" {
+		title = itemTitle
+	}
+
+	q := models.SheetQuestion{
+		Title:             title,
+		MandatoryToAnswer: true,
+		Options:           []models.SheetOption{},
+	}
+
+	switch {
+	case interaction.is("choiceInteraction"):
+		q.QuestionType = "simple_choice"
+		if strings.EqualFold(decl.attr("cardinality"), "multiple") {
+			q.QuestionType = "multiple_choice"
+		}
+		for _, choice := range interaction.findAll("simpleChoice") {
+			id := choice.attr("identifier")
+			score := 0
+			if v, ok := mapped[id]; ok {
+				score = int(v)
+			} else if correct[id] {
+				score = 1
+			}
+			q.Options = append(q.Options, models.SheetOption{
+				Label:     choice.text(false),
+				IsCorrect: correct[id],
+				Score:     score,
+			})
+		}
+		if len(q.Options) == 0 {
+			return models.SheetQuestion{}, &models.ImportItemIssue{Identifier: identifier, Title: title, Interaction: interaction.XMLName.Local, Reason: "choice interaction has no choices"}
+		}
+
+	case interaction.is("textEntryInteraction"):
+		switch strings.ToLower(decl.attr("baseType")) {
+		case "float", "integer":
+			q.QuestionType = "numerical_box"
+		default:
+			q.QuestionType = "free_text"
+		}
+		for _, v := range correctValues {
+			score := 1
+			if m, ok := mapped[v]; ok {
+				score = int(m)
+			}
+			q.Options = append(q.Options, models.SheetOption{Label: v, IsCorrect: true, Score: score})
+		}
+
+	case interaction.is("extendedTextInteraction"):
+		q.QuestionType = "free_text"
+
+	default:
+		return models.SheetQuestion{}, &models.ImportItemIssue{
+			Identifier:  identifier,
+			Title:       title,
+			Interaction: interaction.XMLName.Local,
+			Reason:      "unsupported interaction type",
+		}
+	}
+
+	return q, nil
+}
+
+func collectInteractions(n *xmlNode) []*xmlNode {
+	var out []*xmlNode
+	for i := range n.Children {
+		c := &n.Children[i]
+		if isInteraction(c) {
+			out = append(out, c)
+			continue
+		}
+		out = append(out, collectInteractions(c)...)
+	}
+	return out
+}
+
+func qtiResponseDeclaration(item *xmlNode, responseID string) *xmlNode {
+	decls := item.findAll("responseDeclaration")
+	for _, d := range decls {
+		if d.attr("identifier") == responseID {
+			return d
+		}
+	}
+	if len(decls) == 1 {
+		return decls[0]
+	}
+	return nil
+}
+
+const (
+	qtiItemNamespace     = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiManifestNamespace = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiLOMNamespace      = "http://ltsc.ieee.org/xsd/LOM"
+)
+
+type qtiItemXML struct {
+	XMLName             xml.Name              `xml:"assessmentItem"`
+	Xmlns               string                `xml:"xmlns,attr"`
+	Identifier          string                `xml:"identifier,attr"`
+	Title               string                `xml:"title,attr"`
+	Adaptive            bool                  `xml:"adaptive,attr"`
+	TimeDependent       bool                  `xml:"timeDependent,attr"`
+	ResponseDeclaration qtiResponseDeclXML    `xml:"responseDeclaration"`
+	OutcomeDeclaration  qtiOutcomeDeclXML     `xml:"outcomeDeclaration"`
+	ItemBody            qtiItemBodyXML        `xml:"itemBody"`
+	ResponseProcessing  qtiResponseProcessXML `xml:"responseProcessing"`
+}
+
+type qtiResponseDeclXML struct {
+	Identifier      string         `xml:"identifier,attr"`
+	Cardinality     string         `xml:"cardinality,attr"`
+	BaseType        string         `xml:"baseType,attr"`
+	CorrectResponse *qtiValuesXML  `xml:"correctResponse,omitempty"`
+	Mapping         *qtiMappingXML `xml:"mapping,omitempty"`
+}
+
+type qtiValuesXML struct {
+	Values []string `xml:"value"`
+}
+
+type qtiMappingXML struct {
+	DefaultValue float64          `xml:"defaultValue,attr"`
+	Entries      []qtiMapEntryXML `xml:"mapEntry"`
+}
+
+type qtiMapEntryXML struct {
+	MapKey      string  `xml:"mapKey,attr"`
+	MappedValue float64 `xml:"mappedValue,attr"`
+}
+
+type qtiOutcomeDeclXML struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiItemBodyXML struct {
+	Paragraph               string              `xml:"p,omitempty"`
+	ChoiceInteraction       *qtiChoiceXML       `xml:"choiceInteraction,omitempty"`
+	TextEntryInteraction    *qtiTextEntryXML    `xml:"textEntryInteraction,omitempty"`
+	ExtendedTextInteraction *qtiExtendedTextXML `xml:"extendedTextInteraction,omitempty"`
+}
+
+type qtiChoiceXML struct {
+	ResponseIdentifier string               `xml:"responseIdentifier,attr"`
+	Shuffle            bool                 `xml:"shuffle,attr"`
+	MaxChoices         int                  `xml:"maxChoices,attr"`
+	Prompt             string               `xml:"prompt"`
+	Choices            []qtiSimpleChoiceXML `xml:"simpleChoice"`
+}
+
+type qtiSimpleChoiceXML struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiTextEntryXML struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+}
+
+type qtiExtendedTextXML struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	Prompt             string `xml:"prompt"`
+}
+
+type qtiResponseProcessXML struct {
+	Template string `xml:"template,attr"`
+}
+
+type qtiTestXML struct {
+	XMLName    xml.Name       `xml:"assessmentTest"`
+	Xmlns      string         `xml:"xmlns,attr"`
+	Identifier string         `xml:"identifier,attr"`
+	Title      string         `xml:"title,attr"`
+	TestPart   qtiTestPartXML `xml:"testPart"`
+}
+
+type qtiTestPartXML struct {
+	Identifier     string            `xml:"identifier,attr"`
+	NavigationMode string            `xml:"navigationMode,attr"`
+	SubmissionMode string            `xml:"submissionMode,attr"`
+	Section        qtiTestSectionXML `xml:"assessmentSection"`
+}
+
+type qtiTestSectionXML struct {
+	Identifier string          `xml:"identifier,attr"`
+	Title      string          `xml:"title,attr"`
+	Visible    bool            `xml:"visible,attr"`
+	ItemRefs   []qtiItemRefXML `xml:"assessmentItemRef"`
+}
+
+type qtiItemRefXML struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type qtiManifestXML struct {
+	XMLName       xml.Name            `xml:"manifest"`
+	Xmlns         string              `xml:"xmlns,attr"`
+	XmlnsLOM      string              `xml:"xmlns:imsmd,attr"`
+	Identifier    string              `xml:"identifier,attr"`
+	Organizations struct{}            `xml:"organizations"`
+	Resources     []qtiManifestResXML `xml:"resources>resource"`
+}
+
+type qtiManifestResXML struct {
+	Identifier string               `xml:"identifier,attr"`
+	Type       string               `xml:"type,attr"`
+	Href       string               `xml:"href,attr"`
+	Metadata   *qtiLOMXML           `xml:"metadata>imsmd:lom,omitempty"`
+	Files      []qtiManifestFileXML `xml:"file"`
+	Depends    []qtiDependencyXML   `xml:"dependency"`
+}
+
+type qtiManifestFileXML struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependencyXML struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiLOMXML struct {
+	Keywords       []string        `xml:"imsmd:general>imsmd:keyword>imsmd:string"`
+	Classification *qtiLOMClassXML `xml:"imsmd:classification,omitempty"`
+}
+
+type qtiLOMClassXML struct {
+	TaxonPaths []qtiLOMTaxonPathXML `xml:"imsmd:taxonPath"`
+}
+
+type qtiLOMTaxonPathXML struct {
+	Taxons []qtiLOMTaxonXML `xml:"imsmd:taxon"`
+}
+
+type qtiLOMTaxonXML struct {
+	Entry string `xml:"imsmd:entry>imsmd:string"`
+}
+
+// BuildQTIPackage writes the assessment as a QTI 2.1 content package. Question types that QTI
+// cannot express with the supported interactions are skipped and reported.
+func BuildQTIPackage(assessment models.SheetAssessment) ([]byte, []models.ImportItemIssue, error) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	manifest := qtiManifestXML{
+		Xmlns:      qtiManifestNamespace,
+		XmlnsLOM:   qtiLOMNamespace,
+		Identifier: "MANIFEST-" + qtiIdentifier(assessment.AssessmentSequence),
+	}
+	test := qtiTestXML{
+		Xmlns:      qtiItemNamespace,
+		Identifier: "TEST-" + qtiIdentifier(assessment.AssessmentSequence),
+		Title:      assessment.AssessmentName,
+		TestPart: qtiTestPartXML{
+			Identifier:     "part1",
+			NavigationMode: "linear",
+			SubmissionMode: "individual",
+			Section: qtiTestSectionXML{
+				Identifier: "section1",
+				Title:      assessment.AssessmentName,
+				Visible:    true,
+			},
+		},
+	}
+	testRes := qtiManifestResXML{
+		Identifier: test.Identifier,
+		Type:       "imsqti_test_xmlv2p1",
+		Href:       "assessment.xml",
+		Files:      []qtiManifestFileXML{{Href: "assessment.xml"}},
+	}
+
+	var issues []models.ImportItemIssue
+	for idx, q := range assessment.Questions {
+		identifier := fmt.Sprintf("ITEM-%03d", idx+1)
+		item, err := questionToQTIItem(identifier, q)
+		if err != nil {
+			issues = append(issues, models.ImportItemIssue{
+				Identifier:  identifier,
+				Title:       q.Title,
+				Interaction: q.QuestionType,
+				Reason:      err.Error(),
+			})
+			continue
+		}
+
+		href := "items/" + identifier + ".xml"
+		if err := writeZipXML(zw, href, item); err != nil {
+			return nil, nil, err
+		}
+
+		test.TestPart.Section.ItemRefs = append(test.TestPart.Section.ItemRefs, qtiItemRefXML{Identifier: identifier, Href: href})
+		testRes.Depends = append(testRes.Depends, qtiDependencyXML{IdentifierRef: identifier})
+		manifest.Resources = append(manifest.Resources, qtiManifestResXML{
+			Identifier: identifier,
+			Type:       "imsqti_item_xmlv2p1",
+			Href:       href,
+			Metadata:   tagsToLOM(q.Tags),
+			Files:      []qtiManifestFileXML{{Href: href}},
+		})
+	}
+
+	if err := writeZipXML(zw, "assessment.xml", test); err != nil {
+		return nil, nil, err
+	}
+	manifest.Resources = append([]qtiManifestResXML{testRes}, manifest.Resources...)
+	if err := writeZipXML(zw, "imsmanifest.xml", manifest); err != nil {
+		return nil, nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), issues, nil
+}
+
+func questionToQTIItem(identifier string, q models.SheetQuestion) (*qtiItemXML, error) {
+	item := &qtiItemXML{
+		Xmlns:      qtiItemNamespace,
+		Identifier: identifier,
+		Title:      q.Title,
+		OutcomeDeclaration: qtiOutcomeDeclXML{
+			Identifier:  "SCORE",
+			Cardinality: "single",
+			BaseType:    "float",
+		},
+		ResponseProcessing: qtiResponseProcessXML{
+			Template: "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response",
+		},
+	}
+	decl := qtiResponseDeclXML{Identifier: "RESPONSE", Cardinality: "single"}
+
+	switch q.QuestionType {
+	case "simple_choice", "multiple_choice":
+		if len(q.Options) == 0 {
+			return nil, fmt.Errorf("choice question has no options")
+		}
+		decl.BaseType = "identifier"
+		maxChoices := 1
+		if q.QuestionType == "multiple_choice" {
+			decl.Cardinality = "multiple"
+			maxChoices = 0
+		}
+
+		choice := &qtiChoiceXML{ResponseIdentifier: "RESPONSE", MaxChoices: maxChoices, Prompt: q.Title}
+		correct := &qtiValuesXML{}
+		mapping := &qtiMappingXML{}
+		for i, opt := range q.Options {
+			id := fmt.Sprintf("CHOICE_%d", i+1)
+			choice.Choices = append(choice.Choices, qtiSimpleChoiceXML{Identifier: id, Text: opt.Label})
+			if opt.IsCorrect {
+				correct.Values = append(correct.Values, id)
+			}
+			if opt.Score != 0 {
+				mapping.Entries = append(mapping.Entries, qtiMapEntryXML{MapKey: id, MappedValue: float64(opt.Score)})
+			}
+		}
+		if len(correct.Values) > 0 {
+			decl.CorrectResponse = correct
+		}
+		if len(mapping.Entries) > 0 {
+			decl.Mapping = mapping
+		}
+		item.ItemBody.ChoiceInteraction = choice
+
+	case "numerical_box", "free_text", "textbox":
+		decl.BaseType = "string"
+		if q.QuestionType == "numerical_box" {
+			decl.BaseType = "float"
+		}
+
+		correct := &qtiValuesXML{}
+		mapping := &qtiMappingXML{}
+		for _, opt := range q.Options {
+			if !opt.IsCorrect || opt.Label == "" {
+				continue
+			}
+			correct.Values = append(correct.Values, opt.Label)
+			if opt.Score != 0 {
+				mapping.Entries = append(mapping.Entries, qtiMapEntryXML{MapKey: opt.Label, MappedValue: float64(opt.Score)})
+			}
+		}
+
+		if len(correct.Values) == 0 {
+			if q.QuestionType == "numerical_box" {
+				return nil, fmt.Errorf("numeric question has no correct value")
+			}
+			// open answers without a key are graded manually
+			item.ItemBody.ExtendedTextInteraction = &qtiExtendedTextXML{ResponseIdentifier: "RESPONSE", Prompt: q.Title}
+			item.ResponseProcessing = qtiResponseProcessXML{}
+			break
+		}
+
+		decl.CorrectResponse = correct
+		if len(mapping.Entries) > 0 {
+			decl.Mapping = mapping
+		}
+		item.ItemBody.Paragraph = q.Title
+		item.ItemBody.TextEntryInteraction = &qtiTextEntryXML{ResponseIdentifier: "RESPONSE"}
+
+	default:
+		return nil, fmt.Errorf("question type %q has no QTI equivalent", q.QuestionType)
+	}
+
+	if decl.Mapping == nil && item.ResponseProcessing.Template != "" {
+		item.ResponseProcessing.Template = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	}
+	item.ResponseDeclaration = decl
+	return item, nil
+}
+
+func tagsToLOM(tags []models.TagRequest) *qtiLOMXML {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	lom := &qtiLOMXML{}
+	class := &qtiLOMClassXML{}
+	for _, t := range tags {
+		if t.ParentTag == "" {
+			lom.Keywords = append(lom.Keywords, t.ChildTags...)
+			continue
+		}
+		if len(t.ChildTags) == 0 {
+			class.TaxonPaths = append(class.TaxonPaths, qtiLOMTaxonPathXML{Taxons: []qtiLOMTaxonXML{{Entry: t.ParentTag}}})
+			continue
+		}
+		for _, child := range t.ChildTags {
+			class.TaxonPaths = append(class.TaxonPaths, qtiLOMTaxonPathXML{Taxons: []qtiLOMTaxonXML{{Entry: t.ParentTag}, {Entry: child}}})
+		}
+	}
+	if len(class.TaxonPaths) > 0 {
+		lom.Classification = class
+	}
+	return lom
+}
+
+func writeZipXML(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func qtiIdentifier(seq string) string {
+	if seq == "" {
+		return "ASSESSMENT"
+	}
+	return seq
+}
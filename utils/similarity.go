@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minHashSize is the number of hash functions in a MinHash signature.
+	minHashSize = 64
+	// shingleSize is the length of the character shingles taken from normalized text.
+	shingleSize = 5
+	// lshBands splits a signature into bands for candidate lookup; 16 bands of 4 rows find
+	// pairs from roughly 50% estimated similarity upwards.
+	lshBands = 16
+	lshRows  = minHashSize / lshBands
+
+	// textWeight is the share of the stem in the combined score when options are compared.
+	textWeight = 0.75
+)
+
+// minHashSeeds are fixed so signatures are stable between runs.
+var minHashSeeds = func() [minHashSize]uint64 {
+	var seeds [minHashSize]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		x = splitMix64(x)
+		seeds[i] = x
+	}
+	return seeds
+}()
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// NormalizeQuestionText lower-cases text, drops markup and punctuation and collapses white
+// space, so cosmetic edits do not hide duplicates.
+func NormalizeQuestionText(s string) string {
+	s = strings.ToLower(stripHTML(s))
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return sb.String()
+}
+
+// QuestionFingerprint is the comparable form of a question: a MinHash signature of its stem
+// and the normalized set of option labels.
+type QuestionFingerprint struct {
+	Text      string
+	Signature [minHashSize]uint64
+	Options   map[string]bool
+}
+
+func NewQuestionFingerprint(stem string, options []string) QuestionFingerprint {
+	fp := QuestionFingerprint{Text: NormalizeQuestionText(stem), Options: map[string]bool{}}
+	for _, opt := range options {
+		if o := NormalizeQuestionText(opt); o != "" {
+			fp.Options[o] = true
+		}
+	}
+
+	for i := range fp.Signature {
+		fp.Signature[i] = math.MaxUint64
+	}
+	for _, shingle := range shingles(fp.Text) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		base := h.Sum64()
+		for i, seed := range minHashSeeds {
+			if v := splitMix64(base ^ seed); v < fp.Signature[i] {
+				fp.Signature[i] = v
+			}
+		}
+	}
+	return fp
+}
+
+func shingles(text string) []string {
+	runes := []rune(text)
+	if len(runes) <= shingleSize {
+		if len(runes) == 0 {
+			return nil
+		}
+		return []string{text}
+	}
+	out := make([]string, 0, len(runes)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		out = append(out, string(runes[i:i+shingleSize]))
+	}
+	return out
+}
+
+// TextSimilarity estimates the Jaccard similarity of the two stems from their signatures.
+func (f QuestionFingerprint) TextSimilarity(other QuestionFingerprint) float64 {
+	if f.Text == "" || other.Text == "" {
+		return 0
+	}
+	if f.Text == other.Text {
+		return 1
+	}
+	equal := 0
+	for i := range f.Signature {
+		if f.Signature[i] == other.Signature[i] {
+			equal++
+		}
+	}
+	return float64(equal) / minHashSize
+}
+
+// OptionSimilarity is the Jaccard similarity of the two option sets.
+func (f QuestionFingerprint) OptionSimilarity(other QuestionFingerprint) float64 {
+	if len(f.Options) == 0 && len(other.Options) == 0 {
+		return 1
+	}
+	shared := 0
+	for o := range f.Options {
+		if other.Options[o] {
+			shared++
+		}
+	}
+	union := len(f.Options) + len(other.Options) - shared
+	return float64(shared) / float64(union)
+}
+
+// Similarity combines stem and option similarity. Questions without options on either side
+// are compared on their stems only.
+func (f QuestionFingerprint) Similarity(other QuestionFingerprint) (score, text, options float64) {
+	text = f.TextSimilarity(other)
+	if len(f.Options) == 0 && len(other.Options) == 0 {
+		return text, text, 1
+	}
+	options = f.OptionSimilarity(other)
+	return textWeight*text + (1-textWeight)*options, text, options
+}
+
+// bandKeys hashes each LSH band of the signature; fingerprints sharing a key are candidates.
+func (f QuestionFingerprint) bandKeys() [lshBands]uint64 {
+	var keys [lshBands]uint64
+	for b := 0; b < lshBands; b++ {
+		h := uint64(b) + 1
+		for r := 0; r < lshRows; r++ {
+			h = splitMix64(h ^ f.Signature[b*lshRows+r])
+		}
+		keys[b] = h
+	}
+	return keys
+}
+
+// SimilarityIndex finds near-duplicate questions without comparing every pair.
+type SimilarityIndex struct {
+	ids          []int64
+	fingerprints []QuestionFingerprint
+	buckets      map[uint64][]int
+}
+
+func NewSimilarityIndex() *SimilarityIndex {
+	return &SimilarityIndex{buckets: map[uint64][]int{}}
+}
+
+func (idx *SimilarityIndex) Add(id int64, fp QuestionFingerprint) {
+	if fp.Text == "" {
+		return
+	}
+	pos := len(idx.ids)
+	idx.ids = append(idx.ids, id)
+	idx.fingerprints = append(idx.fingerprints, fp)
+	for _, key := range fp.bandKeys() {
+		idx.buckets[key] = append(idx.buckets[key], pos)
+	}
+}
+
+// SimilarityMatch is an indexed question scoring at or above the requested threshold.
+type SimilarityMatch struct {
+	ID               int64
+	Score            float64
+	TextSimilarity   float64
+	OptionSimilarity float64
+}
+
+// Query returns indexed questions similar to fp, best first.
+func (idx *SimilarityIndex) Query(fp QuestionFingerprint, threshold float64) []SimilarityMatch {
+	if fp.Text == "" {
+		return nil
+	}
+	seen := map[int]bool{}
+	var matches []SimilarityMatch
+	for _, key := range fp.bandKeys() {
+		for _, pos := range idx.buckets[key] {
+			if seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			score, text, options := fp.Similarity(idx.fingerprints[pos])
+			if score >= threshold {
+				matches = append(matches, SimilarityMatch{ID: idx.ids[pos], Score: score, TextSimilarity: text, OptionSimilarity: options})
+			}
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// Clusters groups indexed questions connected by similarity at or above threshold. Each
+// cluster lists ids in insertion order together with the lowest pairwise score that joined it.
+func (idx *SimilarityIndex) Clusters(threshold float64) ([][]int64, []float64) {
+	parent := make([]int, len(idx.ids))
+	minScore := make([]float64, len(idx.ids))
+	for i := range parent {
+		parent[i] = i
+		minScore[i] = 1
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for _, bucket := range idx.buckets {
+		for a := 0; a < len(bucket); a++ {
+			for b := a + 1; b < len(bucket); b++ {
+				i, j := bucket[a], bucket[b]
+				score, _, _ := idx.fingerprints[i].Similarity(idx.fingerprints[j])
+				if score < threshold {
+					continue
+				}
+				ri, rj := find(i), find(j)
+				low := math.Min(score, math.Min(minScore[ri], minScore[rj]))
+				if ri != rj {
+					parent[rj] = ri
+				}
+				minScore[ri] = low
+			}
+		}
+	}
+
+	groups := map[int][]int64{}
+	var roots []int
+	for i := range idx.ids {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], idx.ids[i])
+	}
+
+	var clusters [][]int64
+	var scores []float64
+	for _, r := range roots {
+		if len(groups[r]) > 1 {
+			clusters = append(clusters, groups[r])
+			scores = append(scores, minScore[r])
+		}
+	}
+	return clusters, scores
+}
+
+func sortMatches(matches []SimilarityMatch) {
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].Score > matches[j-1].Score; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+}